@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,25 +10,32 @@ import (
 	"syscall"
 	"time"
 
-	syncpkg "sync"
-
 	"github.com/darchlabs/synchronizer-v2"
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/chainhead"
 	"github.com/darchlabs/synchronizer-v2/internal/cronjob"
 	"github.com/darchlabs/synchronizer-v2/internal/env"
+	"github.com/darchlabs/synchronizer-v2/internal/explorer"
 	"github.com/darchlabs/synchronizer-v2/internal/httpclient"
+	"github.com/darchlabs/synchronizer-v2/internal/leader"
+	"github.com/darchlabs/synchronizer-v2/internal/rpcpool"
+	"github.com/darchlabs/synchronizer-v2/internal/shard"
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
 	eventstorage "github.com/darchlabs/synchronizer-v2/internal/storage/event"
 	scuserstorage "github.com/darchlabs/synchronizer-v2/internal/storage/scuser"
 	smartcontractstorage "github.com/darchlabs/synchronizer-v2/internal/storage/smartcontract"
 	transactionstorage "github.com/darchlabs/synchronizer-v2/internal/storage/transaction"
 	webhookstorage "github.com/darchlabs/synchronizer-v2/internal/storage/webhook"
+	"github.com/darchlabs/synchronizer-v2/internal/supervisor"
 	"github.com/darchlabs/synchronizer-v2/internal/sync"
 	txsengine "github.com/darchlabs/synchronizer-v2/internal/txsengine"
 	"github.com/darchlabs/synchronizer-v2/internal/webhooksender"
 	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	adminAPI "github.com/darchlabs/synchronizer-v2/pkg/api/admin"
 	EventAPI "github.com/darchlabs/synchronizer-v2/pkg/api/events"
 	"github.com/darchlabs/synchronizer-v2/pkg/api/metrics"
 	smartcontractsAPI "github.com/darchlabs/synchronizer-v2/pkg/api/smartcontracts"
+	subscriptionsAPI "github.com/darchlabs/synchronizer-v2/pkg/api/subscriptions"
 	"github.com/darchlabs/synchronizer-v2/pkg/util"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -44,6 +52,13 @@ var (
 	cronjobSvc          synchronizer.Cronjob
 	transactionStorage  synchronizer.TransactionStorage
 	txsEngine           txsengine.TxsEngine
+	rpcPool             *rpcpool.Pool
+	headTracker         *chainhead.Tracker
+	workers             *supervisor.Supervisor
+	elector             *leader.Elector
+	shards              *shard.Coordinator
+	stopElector         context.CancelFunc
+	electorDone         chan struct{}
 )
 
 func main() {
@@ -52,6 +67,11 @@ func main() {
 	err := envconfig.Process("", &env)
 	check(err)
 
+	// the sharded replicas run the work that can't be done twice only on the leader
+	if env.ShardCount > 0 && !env.LeaderElection {
+		log.Fatal("SHARD_COUNT requires LEADER_ELECTION to be enabled")
+	}
+
 	networksEtherscanURL, err := util.ParseStringifiedMap(env.NetworksEtherscanURL)
 	check(err)
 
@@ -61,6 +81,18 @@ func main() {
 	networksNodeURL, err := util.ParseStringifiedMap(env.NetworksNodeURL)
 	check(err)
 
+	networksNodeURLs, err := util.ParseStringifiedMap(env.NetworksNodeURLs)
+	check(err)
+
+	networksConfirmations, err := util.ParseStringifiedMap(env.NetworksConfirmations)
+	check(err)
+
+	networksFinalityTag, err := util.ParseStringifiedMap(env.NetworksFinalityTag)
+	check(err)
+
+	headConfigs, err := blockchain.ParseHeadConfigs(networksConfirmations, networksFinalityTag)
+	check(err)
+
 	// initialize storage
 	s, err := storage.New(env.DatabaseDSN)
 	check(err)
@@ -89,16 +121,9 @@ func main() {
 	})
 	webhookStorage := webhookstorage.New(s)
 
-	// initialize webhook sender, start processing events and retrying failed webhooks
+	// initialize webhook sender, it processes events and retries failed webhooks once started
 	webhookSender := webhooksender.NewWebhookSender(webhookStorage, &http.Client{}, time.Duration(env.WebhooksIntervalSeconds+2))
 
-	// Inicializar los webhooks desde el almacenamiento persistente
-	if err := webhookSender.InitializeFromStorage(); err != nil {
-		log.Fatalf("Error initializing webhooks from storage: %v", err)
-	}
-	go webhookSender.ProcessWebhooks()
-	go webhookSender.StartRetries()
-
 	// initialize fiber
 	server := fiber.New()
 	server.Use(logger.New())
@@ -106,8 +131,52 @@ func main() {
 		Format: "[${ip}]:${port} ${status} - ${method} ${path}\n",
 	}))
 
-	// create clients map
-	var clients syncpkg.Map
+	// initialize the rpc pool with the nodes of each network
+	rpcPool = rpcpool.New(rpcpool.Config{
+		Endpoints:     rpcpool.ParseEndpoints(networksNodeURL, networksNodeURLs),
+		MaxHeadLag:    env.RPCPoolMaxHeadLag,
+		MaxErrorRate:  env.RPCPoolMaxErrorRate,
+		EvictAfter:    env.RPCPoolEvictAfter,
+		CheckInterval: time.Duration(env.RPCPoolCheckSeconds) * time.Second,
+		ChainIDs:      util.SupportedNetworks,
+		DateGen:       time.Now,
+	})
+	rpcPool.Start()
+
+	// initialize the chain head tracker of the networks
+	headTracker = chainhead.New(chainhead.Config{
+		Pool:         rpcPool,
+		HeadConfigs:  headConfigs,
+		Networks:     rpcPool.Networks(),
+		PollInterval: time.Duration(env.HeadPollIntervalSeconds) * time.Second,
+		MaxAge:       time.Duration(env.HeadMaxAgeSeconds) * time.Second,
+		DateGen:      time.Now,
+	})
+	headTracker.Start()
+
+	// identify the replica in the leases shared with the other replicas
+	replicaID := env.ReplicaID
+	if replicaID == "" {
+		hostname, _ := os.Hostname()
+		replicaID = fmt.Sprintf("%s-%s", hostname, uuid.NewString())
+	}
+
+	// when the ingestion is sharded every replica syncs the contracts of the shards it holds
+	var shardFilter cronjob.ShardFilter
+	if env.ShardCount > 0 {
+		shards = shard.New(shard.Config{
+			Store:  syncEngine,
+			Holder: replicaID,
+			Shards: env.ShardCount,
+			TTL:    time.Duration(env.ShardLeaseSeconds) * time.Second,
+			// the runs of the dropped shards stop before other replica takes them
+			OnRelease: func() {
+				cronjobSvc.StopUnowned()
+			},
+			DateGen: time.Now,
+		})
+		shardFilter = shards
+	}
 
 	// initialize the cronjob
 	//cronjobSvc = cronjob.New(env.CronjobIntervalSeconds, eventStorage, smartContactStorage, &clients, env.Debug, uuid.NewString, time.Now, webhookSender)
@@ -115,12 +184,25 @@ func main() {
 		Seconds:          env.CronjobIntervalSeconds,
 		EventDataStorage: eventStorage,
 		SCStorage:        smartContactStorage,
-		Clients:          &clients,
+		Pool:             rpcPool,
 		Debug:            env.Debug,
 		IDGen:            uuid.NewString,
 		DateGen:          time.Now,
 		WebhookSender:    webhookSender,
 		Engine:           syncEngine,
+		ReorgDepth:       env.ReorgDepth,
+		Heads:            headTracker,
+		WindowConfig: blockchain.WindowConfig{
+			Size:     env.LogsWindowSize,
+			Max:      env.LogsWindowMaxSize,
+			Increase: env.LogsWindowIncrease,
+		},
+		LiveLogs:         env.LiveLogs,
+		OnNewBlocks:      env.SyncOnNewBlocks,
+		CoverageInterval: time.Duration(env.CoverageVerifySeconds) * time.Second,
+		MaxWorkers:       env.SchedulerMaxWorkers,
+		MaxBackoff:       time.Duration(env.SchedulerMaxBackoffSecs) * time.Second,
+		Shards:           shardFilter,
 	})
 
 	// initialize http client with rate limiter
@@ -137,9 +219,67 @@ func main() {
 		IdGen:              uuid.NewString,
 		EtherscanUrlMap:    networksEtherscanURL,
 		ApiKeyMap:          networksEtherscanAPIKey,
-		NodesUrlMap:        networksNodeURL,
+		Pool:               rpcPool,
+		Heads:              headTracker,
 		Client:             client,
 		MaxTransactions:    env.MaxTransactions,
+		Seconds:            env.CronjobIntervalSeconds + 1,
+	})
+
+	// the supervisor owns the background workers, they're shut down in the reverse order
+	workers = supervisor.New(supervisor.Config{
+		DrainTimeout: time.Duration(env.SupervisorDrainSeconds) * time.Second,
+		DateGen:      time.Now,
+	})
+
+	// the workers run by every replica, and the ones run only by the leader replica
+	var replicaWorkers, leaderWorkers []string
+	if shards != nil {
+		// each replica syncs its shards and delivers its webhooks, the failed webhooks are
+		// retried by the leader
+		check(workers.Register("shards", shards))
+		check(workers.Register("webhooks", supervisor.WorkerFunc(webhookSender.Deliver)))
+		check(workers.Register("cronjob", cronjobSvc))
+		check(workers.Register("webhook-retries", supervisor.WorkerFunc(webhookSender.Retry)))
+		check(workers.Register("txsengine", txsEngine))
+		replicaWorkers = []string{"shards", "webhooks", "cronjob"}
+		leaderWorkers = []string{"webhook-retries", "txsengine"}
+	} else {
+		check(workers.Register("webhooks", webhookSender))
+		check(workers.Register("cronjob", cronjobSvc))
+		check(workers.Register("txsengine", txsEngine))
+		leaderWorkers = []string{"webhooks", "cronjob", "txsengine"}
+	}
+
+	// when the leader election is enabled only the leader replica runs the leader workers, while
+	// every replica serves the api
+	if env.LeaderElection {
+		// the leader workers are stopped one after the other, so the leadership is given up
+		// early enough for all of them to drain before the lease expires
+		elector, err = leader.New(leader.Config{
+			Store:        syncEngine,
+			Holder:       replicaID,
+			TTL:          time.Duration(env.LeaderLeaseSeconds) * time.Second,
+			DrainTimeout: time.Duration(int64(len(leaderWorkers))*env.SupervisorDrainSeconds) * time.Second,
+			OnElected: func() {
+				err := workers.StartWorkers(leaderWorkers...)
+				if err != nil {
+					log.Printf("Error starting the workers of the leader: %v", err)
+				}
+			},
+			OnDemoted: func() {
+				workers.StopWorkers(leaderWorkers...)
+			},
+			DateGen: time.Now,
+		})
+		check(err)
+	}
+
+	// initialize the explorer client used to fetch the abi of the contracts
+	explorerClient := explorer.New(explorer.Config{
+		Client:  client,
+		URLs:    networksEtherscanURL,
+		APIKeys: networksEtherscanAPIKey,
 	})
 
 	// configure routers
@@ -147,6 +287,9 @@ func main() {
 		Storage:      smartContactStorage,
 		EventStorage: eventStorage,
 		TxsEngine:    txsEngine,
+		RPCPool:      rpcPool,
+		Heads:        headTracker,
+		Explorer:     explorerClient,
 		IDGen:        uuid.NewString,
 		DateGen:      time.Now,
 		Engine:       syncEngine,
@@ -155,6 +298,26 @@ func main() {
 	EventAPI.Route(server, &api.Context{
 		Env:        &env,
 		SyncEngine: syncEngine,
+		Heads:      headTracker,
+		IDGen:      uuid.NewString,
+		DateGen:    time.Now,
+	})
+	adminAPI.Route(server, &api.Context{
+		Env:           &env,
+		SyncEngine:    syncEngine,
+		RPCPool:       rpcPool,
+		Heads:         headTracker,
+		Cronjob:       cronjobSvc,
+		Supervisor:    workers,
+		Leader:        elector,
+		LeaderWorkers: leaderWorkers,
+		Shards:        shards,
+	})
+	subscriptionsAPI.Route(server, &api.Context{
+		Env:        &env,
+		SyncEngine: syncEngine,
+		RPCPool:    rpcPool,
+		DateGen:    time.Now,
 	})
 	metrics.Route(server, metrics.Context{
 		SmartContractStorage: smartContactStorage,
@@ -163,16 +326,25 @@ func main() {
 		Engine:               txsEngine,
 	})
 
-	// run process
-	err = cronjobSvc.Start()
+	// run the webhooks, the cronjob and the txs engine processes, or campaign to run them
+	err = workers.StartWorkers(replicaWorkers...)
 	check(err)
+	if elector != nil {
+		var ctx context.Context
+		ctx, stopElector = context.WithCancel(context.Background())
+		electorDone = make(chan struct{})
+		go func() {
+			defer close(electorDone)
+			elector.Run(ctx)
+		}()
+	} else {
+		err = workers.StartWorkers(leaderWorkers...)
+		check(err)
+	}
 	go func() {
 		server.Listen(fmt.Sprintf(":%s", env.Port))
 	}()
 
-	// Run txs engine process
-	txsEngine.Start(env.CronjobIntervalSeconds + 1)
-
 	// listen interrupt
 	quit := make(chan struct{})
 	listenInterrupt(quit)
@@ -195,11 +367,21 @@ func listenInterrupt(quit chan struct{}) {
 func gracefullShutdown() {
 	log.Println("Gracefully shutdown")
 
-	// stop cronjob ticker
-	cronjobSvc.Halt()
+	// give up the leadership, so other replica takes it once the workers are stopped
+	if stopElector != nil {
+		stopElector()
+		<-electorDone
+	}
+
+	// stop the txs engine and the cronjob waiting for their in-flight jobs, deliver the queued
+	// webhooks and release the shards of the replica
+	workers.Shutdown()
+
+	// stop following the chain heads
+	headTracker.Stop()
 
-	// stop txs engine
-	txsEngine.Halt()
+	// stop rpc pool health checks
+	rpcPool.Stop()
 
 	// close databanse connection
 	err := eventStorage.Stop()
@@ -0,0 +1,59 @@
+package abitemplate
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Template is a standard contract interface with a built-in abi, so contracts implementing it
+// can be registered without uploading the abi.
+type Template string
+
+const (
+	TemplateNone    Template = ""
+	TemplateERC20   Template = "erc20"
+	TemplateERC721  Template = "erc721"
+	TemplateERC1155 Template = "erc1155"
+)
+
+var ErrTemplateNotFound = errors.New("abi template not found")
+
+var abis = map[Template]string{
+	TemplateERC20:   erc20ABI,
+	TemplateERC721:  erc721ABI,
+	TemplateERC1155: erc1155ABI,
+}
+
+// Parse returns the template with the given name, names are case insensitive.
+func Parse(name string) (Template, error) {
+	t := Template(strings.ToLower(name))
+	if _, ok := abis[t]; !ok {
+		return TemplateNone, errors.Wrapf(ErrTemplateNotFound, "abitemplate: Parse template=%s error", name)
+	}
+
+	return t, nil
+}
+
+// ABI returns the standard abi of the template.
+func ABI(t Template) (json.RawMessage, error) {
+	a, ok := abis[t]
+	if !ok {
+		return nil, errors.Wrapf(ErrTemplateNotFound, "abitemplate: ABI template=%s error", t)
+	}
+
+	return json.RawMessage(a), nil
+}
+
+// Templates returns the built-in templates sorted by name.
+func Templates() []Template {
+	templates := make([]Template, 0)
+	for t := range abis {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i] < templates[j] })
+
+	return templates
+}
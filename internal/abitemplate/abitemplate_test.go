@@ -0,0 +1,51 @@
+package abitemplate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/jaekwon/testify/require"
+	"github.com/pkg/errors"
+)
+
+func Test_ABI(t *testing.T) {
+	events := map[Template][]string{
+		TemplateERC20:   {"Transfer(address,address,uint256)", "Approval(address,address,uint256)"},
+		TemplateERC721:  {"Transfer(address,address,uint256)", "Approval(address,address,uint256)", "ApprovalForAll(address,address,bool)"},
+		TemplateERC1155: {"TransferSingle(address,address,address,uint256,uint256)", "TransferBatch(address,address,address,uint256[],uint256[])", "ApprovalForAll(address,address,bool)", "URI(string,uint256)"},
+	}
+	require.Equal(t, []Template{TemplateERC1155, TemplateERC20, TemplateERC721}, Templates())
+
+	for template, signatures := range events {
+		b, err := ABI(template)
+		require.NoError(t, err)
+
+		contractABI, err := abi.JSON(strings.NewReader(string(b)))
+		require.NoError(t, err)
+		require.Len(t, contractABI.Events, len(signatures))
+		for _, signature := range signatures {
+			found := false
+			for _, ev := range contractABI.Events {
+				found = found || ev.Sig == signature
+			}
+			require.True(t, found, "template=%s event=%s", template, signature)
+		}
+	}
+
+	// the erc721 transfer shares the erc20 signature, but the token id is indexed
+	b, err := ABI(TemplateERC721)
+	require.NoError(t, err)
+	contractABI, err := abi.JSON(strings.NewReader(string(b)))
+	require.NoError(t, err)
+	require.True(t, contractABI.Events["Transfer"].Inputs[2].Indexed)
+}
+
+func Test_Parse(t *testing.T) {
+	template, err := Parse("ERC20")
+	require.NoError(t, err)
+	require.Equal(t, TemplateERC20, template)
+
+	_, err = Parse("erc4626")
+	require.Equal(t, ErrTemplateNotFound, errors.Cause(err))
+}
@@ -0,0 +1,41 @@
+package blockchain
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Client wraps the go-ethereum client keeping the underlying rpc client, needed for the
+// calls that ethclient doesn't expose like reading blocks by finality tag.
+type Client struct {
+	*ethclient.Client
+
+	rpc *rpc.Client
+}
+
+func Dial(nodeURL string) (*Client, error) {
+	rpcClient, err := rpc.Dial(nodeURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		Client: ethclient.NewClient(rpcClient),
+		rpc:    rpcClient,
+	}, nil
+}
+
+// HeaderByTag returns the header of the block identified by the given tag (latest, safe or finalized).
+func (c *Client) HeaderByTag(ctx context.Context, tag FinalityTag) (*types.Header, error) {
+	var header *types.Header
+	err := c.rpc.CallContext(ctx, &header, "eth_getBlockByNumber", string(tag), false)
+	if err == nil && header == nil {
+		err = ethereum.NotFound
+	}
+
+	return header, err
+}
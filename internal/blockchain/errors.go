@@ -0,0 +1,189 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	ErrRateLimited     = errors.New("blockchain: node rate limited the request")
+	ErrTimeout         = errors.New("blockchain: node request timed out")
+	ErrNodeUnavailable = errors.New("blockchain: node is unavailable")
+)
+
+// RangeTooLargeError is returned when the node refuses an eth_getLogs request because the
+// block range or the response are too large. When the provider tells which range should
+// work, it is available in SuggestedFrom and SuggestedTo, and when it tells the maximum
+// number of blocks allowed it is available in MaxBlocks.
+type RangeTooLargeError struct {
+	SuggestedFrom *int64
+	SuggestedTo   *int64
+	MaxBlocks     *int64
+
+	err error
+}
+
+func (e *RangeTooLargeError) Error() string {
+	if e.SuggestedFrom != nil && e.SuggestedTo != nil {
+		return fmt.Sprintf("blockchain: block range too large, suggested range [%d, %d]: %s", *e.SuggestedFrom, *e.SuggestedTo, e.err)
+	}
+
+	if e.MaxBlocks != nil {
+		return fmt.Sprintf("blockchain: block range too large, max blocks %d: %s", *e.MaxBlocks, e.err)
+	}
+
+	return fmt.Sprintf("blockchain: block range too large: %s", e.err)
+}
+
+func (e *RangeTooLargeError) Unwrap() error {
+	return e.err
+}
+
+const (
+	// rpcLimitExceededCode is the JSON-RPC code of the requests over the limits of the node,
+	// the providers return it for the eth_getLogs requests with too many results
+	rpcLimitExceededCode = -32005
+)
+
+var (
+	// Alchemy: "... this block range should work: [0x0, 0x88c025]"
+	suggestedRangeRegexp = regexp.MustCompile(`(?i)range should work:\s*\[\s*(0x[0-9a-f]+)\s*,\s*(0x[0-9a-f]+)\s*\]`)
+
+	// QuickNode: "eth_getLogs and eth_newFilter are limited to a 10,000 blocks range"
+	maxBlocksRegexp = regexp.MustCompile(`(?i)limited to an? ([0-9,]+) blocks? range`)
+
+	// the phrases are matched in full, generic words like "block range" are also used by
+	// errors of malformed requests, e.g. geth's "invalid block range params"
+	rangeTooLargeMessages = []string{
+		// Infura: "query returned more than 10000 results"
+		"query returned more than",
+		// Alchemy: "Log response size exceeded. ..."
+		"log response size exceeded",
+		// Alchemy: "Query timeout exceeded. Consider reducing your block range. ..."
+		"consider reducing your block range",
+		// Ankr, BSC: "exceed maximum block range: 5000"
+		"exceed maximum block range",
+		// Chainstack, Blast: "block range is too large", "block range too large"
+		"block range is too large",
+		"block range too large",
+		// HTTP 413 of the providers behind a proxy
+		"request entity too large",
+	}
+	rateLimitedMessages = []string{
+		// HTTP 429 of the providers, e.g. "429 Too Many Requests"
+		"too many requests",
+		// Infura: "project ID request rate exceeded"
+		"request rate exceeded",
+		// Alchemy: "Your app has exceeded its compute units per second capacity"
+		"exceeded its compute units per second capacity",
+		// Chainstack, public nodes: "rate limit exceeded", "rate limited"
+		"rate limit exceeded",
+		"rate limited",
+	}
+	timeoutMessages = []string{
+		"timeout",
+		"timed out",
+		"504",
+	}
+	nodeUnavailableMessages = []string{
+		"connection refused",
+		"connection reset",
+		"no such host",
+		"502",
+		"503",
+		"bad gateway",
+		"service unavailable",
+		"eof",
+	}
+)
+
+// ClassifyError maps a node error to the typed errors of this package. Range errors are
+// returned as *RangeTooLargeError, the rest are wrapped so errors.Is works with ErrRateLimited,
+// ErrTimeout and ErrNodeUnavailable. Errors that are not recognized are returned as is.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// context errors are controlled by the caller
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s", ErrTimeout, err)
+	}
+
+	msg := strings.ToLower(err.Error())
+
+	// the codes of the JSON-RPC and HTTP errors are checked before the messages
+	var rpcErr rpc.Error
+	rpcLimitExceeded := errors.As(err, &rpcErr) && rpcErr.ErrorCode() == rpcLimitExceededCode
+	statusCode := 0
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		statusCode = httpErr.StatusCode
+	}
+
+	if rpcLimitExceeded || statusCode == http.StatusRequestEntityTooLarge || containsAny(msg, rangeTooLargeMessages) || suggestedRangeRegexp.MatchString(msg) || maxBlocksRegexp.MatchString(msg) {
+		return newRangeTooLargeError(err)
+	}
+
+	if statusCode == http.StatusTooManyRequests || containsAny(msg, rateLimitedMessages) {
+		return fmt.Errorf("%w: %s", ErrRateLimited, err)
+	}
+
+	var netErr net.Error
+	if (errors.As(err, &netErr) && netErr.Timeout()) || containsAny(msg, timeoutMessages) {
+		return fmt.Errorf("%w: %s", ErrTimeout, err)
+	}
+
+	if containsAny(msg, nodeUnavailableMessages) {
+		return fmt.Errorf("%w: %s", ErrNodeUnavailable, err)
+	}
+
+	return err
+}
+
+func newRangeTooLargeError(err error) *RangeTooLargeError {
+	rangeErr := &RangeTooLargeError{err: err}
+
+	// parse suggested range
+	if matches := suggestedRangeRegexp.FindStringSubmatch(err.Error()); len(matches) == 3 {
+		from, fromErr := strconv.ParseUint(matches[1][2:], 16, 64)
+		to, toErr := strconv.ParseUint(matches[2][2:], 16, 64)
+		if fromErr == nil && toErr == nil && from <= to {
+			suggestedFrom := int64(from)
+			suggestedTo := int64(to)
+			rangeErr.SuggestedFrom = &suggestedFrom
+			rangeErr.SuggestedTo = &suggestedTo
+		}
+	}
+
+	// parse max blocks range
+	if matches := maxBlocksRegexp.FindStringSubmatch(err.Error()); len(matches) == 2 {
+		maxBlocks, parseErr := strconv.ParseInt(strings.ReplaceAll(matches[1], ",", ""), 10, 64)
+		if parseErr == nil && maxBlocks > 0 {
+			rangeErr.MaxBlocks = &maxBlocks
+		}
+	}
+
+	return rangeErr
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,151 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/jaekwon/testify/require"
+)
+
+func Test_ClassifyError_RangeTooLarge(t *testing.T) {
+	cases := []struct {
+		name          string
+		message       string
+		suggestedFrom *int64
+		suggestedTo   *int64
+		maxBlocks     *int64
+	}{
+		{
+			name:    "Infura(Polygon)",
+			message: "query returned more than 10000 results",
+		},
+		{
+			name: "Alchemy(Ethereum)",
+			message: "Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range " +
+				"and no limit on the response size, or you can request any block range with a cap of 10K logs in " +
+				"the response. Based on your parameters and the response size limit, this block range should work: " +
+				"[0x0, 0x88c025]",
+			suggestedFrom: int64Ptr(0),
+			suggestedTo:   int64Ptr(0x88c025),
+		},
+		{
+			name: "Alchemy(Polygon)",
+			message: "Query git out exceeded. Consider reducing your block range. Based on your parameters and the " +
+				"response size limit, this block range should work: [0x0, 0x1360b8a]",
+			suggestedFrom: int64Ptr(0),
+			suggestedTo:   int64Ptr(0x1360b8a),
+		},
+		{
+			name: "QuickNode(Polygon)",
+			message: `413 Request Entity Too Large: {"jsonrpc":"2.0","id":2,"result":null,"error":{"code":-32602,` +
+				`"message":"eth_getLogs and eth_newFilter are limited to a 10,000 blocks range"}}`,
+			maxBlocks: int64Ptr(10000),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ClassifyError(errors.New(c.message))
+
+			var rangeErr *RangeTooLargeError
+			require.True(t, errors.As(err, &rangeErr))
+			require.Equal(t, c.suggestedFrom, rangeErr.SuggestedFrom)
+			require.Equal(t, c.suggestedTo, rangeErr.SuggestedTo)
+			require.Equal(t, c.maxBlocks, rangeErr.MaxBlocks)
+		})
+	}
+}
+
+func Test_ClassifyError_Typed(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "too many requests",
+			err:      errors.New("429 Too Many Requests"),
+			expected: ErrRateLimited,
+		},
+		{
+			name:     "compute units capacity",
+			err:      errors.New("Your app has exceeded its compute units per second capacity"),
+			expected: ErrRateLimited,
+		},
+		{
+			name:     "deadline exceeded",
+			err:      fmt.Errorf("post failed: %w", context.DeadlineExceeded),
+			expected: ErrTimeout,
+		},
+		{
+			name:     "gateway timeout",
+			err:      errors.New("504 Gateway Timeout"),
+			expected: ErrTimeout,
+		},
+		{
+			name:     "connection refused",
+			err:      errors.New("dial tcp 127.0.0.1:8545: connect: connection refused"),
+			expected: ErrNodeUnavailable,
+		},
+		{
+			name:     "service unavailable",
+			err:      errors.New("503 Service Unavailable"),
+			expected: ErrNodeUnavailable,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ClassifyError(c.err)
+			require.True(t, errors.Is(err, c.expected))
+
+			var rangeErr *RangeTooLargeError
+			require.False(t, errors.As(err, &rangeErr))
+		})
+	}
+}
+
+// rpcError is a JSON-RPC error of the node.
+type rpcError struct {
+	code    int
+	message string
+}
+
+func (e *rpcError) Error() string  { return e.message }
+func (e *rpcError) ErrorCode() int { return e.code }
+
+func Test_ClassifyError_Codes(t *testing.T) {
+	var rangeErr *RangeTooLargeError
+	require.True(t, errors.As(ClassifyError(&rpcError{code: -32005, message: "limit exceeded"}), &rangeErr))
+	require.True(t, errors.As(ClassifyError(rpc.HTTPError{StatusCode: 413, Status: "413"}), &rangeErr))
+	require.True(t, errors.Is(ClassifyError(rpc.HTTPError{StatusCode: 429, Status: "429"}), ErrRateLimited))
+}
+
+func Test_ClassifyError_Unknown(t *testing.T) {
+	original := errors.New("execution reverted")
+	require.Equal(t, original, ClassifyError(original))
+
+	// the errors mentioning ranges, sizes or capacity that aren't sent by the limits of the node
+	unrelated := []error{
+		errors.New("invalid block range params"),
+		&rpcError{code: -32602, message: "invalid block range params"},
+		errors.New("fromBlock is after toBlock, the block range is invalid"),
+		errors.New("response size should be a multiple of 32"),
+		errors.New("insufficient capacity for the transaction"),
+		errors.New("header not found for block 4290"),
+		&rpcError{code: -32000, message: "max fee per gas less than block base fee"},
+	}
+	for _, err := range unrelated {
+		require.Equal(t, err, ClassifyError(err), err.Error())
+	}
+
+	require.Equal(t, context.Canceled, ClassifyError(context.Canceled))
+	require.Nil(t, ClassifyError(nil))
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
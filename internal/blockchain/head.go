@@ -0,0 +1,129 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type FinalityTag string
+
+const (
+	FinalityLatest    FinalityTag = "latest"
+	FinalitySafe      FinalityTag = "safe"
+	FinalityFinalized FinalityTag = "finalized"
+)
+
+// HeadReader is the subset of the node client used to resolve the sync head.
+type HeadReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByTag(ctx context.Context, tag FinalityTag) (*types.Header, error)
+}
+
+// HeadConfig defines how far behind the chain head a network is synced. Blocks are only
+// indexed once they have Confirmations blocks on top of them and, when the Tag is safe or
+// finalized, once the node reports them under that tag.
+type HeadConfig struct {
+	Confirmations int64
+	Tag           FinalityTag
+}
+
+// Head is the chain head observed from the node and the highest block that reached the
+// configured finality, which is the one used as sync head. The hash and timestamp of the
+// observed block are only known when the head is built from its header.
+type Head struct {
+	Observed  int64  `json:"observed"`
+	Finalized int64  `json:"finalized"`
+	Hash      string `json:"hash,omitempty"`
+	Timestamp uint64 `json:"timestamp,omitempty"`
+}
+
+func IsValidFinalityTag(tag FinalityTag) bool {
+	switch tag {
+	case FinalityLatest, FinalitySafe, FinalityFinalized:
+		return true
+	}
+
+	return false
+}
+
+// GetHead returns the observed and finalized heads of the node using the given config.
+func GetHead(ctx context.Context, client HeadReader, conf HeadConfig) (*Head, error) {
+	blockNumber, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return finalizeHead(ctx, client, conf, int64(blockNumber))
+}
+
+// HeadFromHeader returns the heads of the node using the header of the observed block, like
+// the ones received from a newHeads subscription.
+func HeadFromHeader(ctx context.Context, client HeadReader, conf HeadConfig, header *types.Header) (*Head, error) {
+	head, err := finalizeHead(ctx, client, conf, header.Number.Int64())
+	if err != nil {
+		return nil, err
+	}
+	head.Hash = header.Hash().Hex()
+	head.Timestamp = header.Time
+
+	return head, nil
+}
+
+// finalizeHead resolves the finalized head for the observed block number.
+func finalizeHead(ctx context.Context, client HeadReader, conf HeadConfig, observed int64) (*Head, error) {
+	// apply confirmation depth over the latest block
+	finalized := observed - conf.Confirmations
+	if finalized < 0 {
+		finalized = 0
+	}
+
+	// use the lower value between confirmation depth and the block reported by the tag
+	if conf.Tag != "" && conf.Tag != FinalityLatest {
+		header, err := client.HeaderByTag(ctx, conf.Tag)
+		if err != nil {
+			return nil, err
+		}
+
+		if tagged := header.Number.Int64(); tagged < finalized {
+			finalized = tagged
+		}
+	}
+
+	return &Head{
+		Observed:  observed,
+		Finalized: finalized,
+	}, nil
+}
+
+// ParseHeadConfigs builds the head config of each network using the stringified maps of
+// confirmations and finality tags defined in the env.
+func ParseHeadConfigs(confirmations map[string]string, tags map[string]string) (map[string]HeadConfig, error) {
+	configs := make(map[string]HeadConfig)
+
+	for network, value := range confirmations {
+		c, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || c < 0 {
+			return nil, fmt.Errorf("invalid confirmations=%s for the %s network", value, network)
+		}
+
+		conf := configs[network]
+		conf.Confirmations = c
+		configs[network] = conf
+	}
+
+	for network, value := range tags {
+		tag := FinalityTag(value)
+		if !IsValidFinalityTag(tag) {
+			return nil, fmt.Errorf("invalid finality tag=%s for the %s network", value, network)
+		}
+
+		conf := configs[network]
+		conf.Tag = tag
+		configs[network] = conf
+	}
+
+	return configs, nil
+}
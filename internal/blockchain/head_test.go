@@ -0,0 +1,172 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jaekwon/testify/require"
+)
+
+// fakeHeadReader reports the observed block number and the block of every finality tag.
+type fakeHeadReader struct {
+	blockNumber uint64
+	tagged      map[FinalityTag]int64
+	err         error
+}
+
+func (f *fakeHeadReader) BlockNumber(ctx context.Context) (uint64, error) {
+	return f.blockNumber, nil
+}
+
+func (f *fakeHeadReader) HeaderByTag(ctx context.Context, tag FinalityTag) (*types.Header, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return &types.Header{Number: big.NewInt(f.tagged[tag])}, nil
+}
+
+func Test_GetHead(t *testing.T) {
+	client := &fakeHeadReader{
+		blockNumber: 100,
+		tagged: map[FinalityTag]int64{
+			FinalitySafe:      95,
+			FinalityFinalized: 68,
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		client   *fakeHeadReader
+		conf     HeadConfig
+		expected *Head
+		err      bool
+	}{
+		{
+			name:     "unknown network syncs up to the observed head",
+			client:   client,
+			conf:     HeadConfig{},
+			expected: &Head{Observed: 100, Finalized: 100},
+		},
+		{
+			name:     "confirmations behind the observed head",
+			client:   client,
+			conf:     HeadConfig{Confirmations: 12},
+			expected: &Head{Observed: 100, Finalized: 88},
+		},
+		{
+			name:     "depth greater than the observed head",
+			client:   client,
+			conf:     HeadConfig{Confirmations: 150},
+			expected: &Head{Observed: 100, Finalized: 0},
+		},
+		{
+			name:     "latest tag only applies the confirmations",
+			client:   client,
+			conf:     HeadConfig{Confirmations: 3, Tag: FinalityLatest},
+			expected: &Head{Observed: 100, Finalized: 97},
+		},
+		{
+			name:     "tag behind the confirmations",
+			client:   client,
+			conf:     HeadConfig{Confirmations: 12, Tag: FinalityFinalized},
+			expected: &Head{Observed: 100, Finalized: 68},
+		},
+		{
+			name:     "confirmations behind the tag",
+			client:   client,
+			conf:     HeadConfig{Confirmations: 12, Tag: FinalitySafe},
+			expected: &Head{Observed: 100, Finalized: 88},
+		},
+		{
+			name:   "tag not supported by the node",
+			client: &fakeHeadReader{blockNumber: 100, err: errors.New("finalized block not found")},
+			conf:   HeadConfig{Tag: FinalityFinalized},
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			head, err := GetHead(context.Background(), tc.client, tc.conf)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, head)
+		})
+	}
+}
+
+func Test_ParseHeadConfigs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		confirmations map[string]string
+		tags          map[string]string
+		expected      map[string]HeadConfig
+		err           bool
+	}{
+		{
+			name:          "empty configs",
+			confirmations: map[string]string{},
+			tags:          map[string]string{},
+			expected:      map[string]HeadConfig{},
+		},
+		{
+			name:          "confirmations and tags of different networks",
+			confirmations: map[string]string{"ethereum": "12", "polygon": "128"},
+			tags:          map[string]string{"ethereum": "finalized", "arbitrum": "safe"},
+			expected: map[string]HeadConfig{
+				"ethereum": {Confirmations: 12, Tag: FinalityFinalized},
+				"polygon":  {Confirmations: 128},
+				"arbitrum": {Tag: FinalitySafe},
+			},
+		},
+		{
+			name:          "confirmations that aren't a number",
+			confirmations: map[string]string{"ethereum": "twelve"},
+			err:           true,
+		},
+		{
+			name:          "decimal confirmations",
+			confirmations: map[string]string{"ethereum": "1.5"},
+			err:           true,
+		},
+		{
+			name:          "empty confirmations",
+			confirmations: map[string]string{"ethereum": ""},
+			err:           true,
+		},
+		{
+			name:          "negative confirmations",
+			confirmations: map[string]string{"ethereum": "-1"},
+			err:           true,
+		},
+		{
+			name: "unknown finality tag",
+			tags: map[string]string{"ethereum": "pending"},
+			err:  true,
+		},
+		{
+			name: "finality tag with other case",
+			tags: map[string]string{"ethereum": "Finalized"},
+			err:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configs, err := ParseHeadConfigs(tc.confirmations, tc.tags)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, configs)
+		})
+	}
+}
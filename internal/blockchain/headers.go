@@ -0,0 +1,94 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultHeaderCacheSize is the number of block headers kept by a cache when no size is defined.
+const DefaultHeaderCacheSize = 10000
+
+// HeaderClient is the subset of the node client used to get block headers.
+type HeaderClient interface {
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// Block is the header data of the block of a log.
+type Block struct {
+	Number     uint64      `json:"number"`
+	Hash       common.Hash `json:"hash"`
+	ParentHash common.Hash `json:"parentHash"`
+	Timestamp  uint64      `json:"timestamp"`
+	// BaseFee is nil for the blocks mined before London
+	BaseFee *big.Int `json:"baseFee"`
+	GasUsed uint64   `json:"gasUsed"`
+}
+
+func NewBlock(header *types.Header) *Block {
+	b := &Block{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		ParentHash: header.ParentHash,
+		Timestamp:  header.Time,
+		GasUsed:    header.GasUsed,
+	}
+	if header.BaseFee != nil {
+		b.BaseFee = new(big.Int).Set(header.BaseFee)
+	}
+
+	return b
+}
+
+// HeaderCache keeps the headers of the latest requested blocks by hash, so the logs of the
+// same block only request its header once. When it is full the oldest header is evicted.
+type HeaderCache struct {
+	mu     sync.Mutex
+	size   int
+	blocks map[common.Hash]*Block
+	order  []common.Hash
+}
+
+func NewHeaderCache(size int) *HeaderCache {
+	if size <= 0 {
+		size = DefaultHeaderCacheSize
+	}
+
+	return &HeaderCache{
+		size:   size,
+		blocks: make(map[common.Hash]*Block),
+		order:  make([]common.Hash, 0),
+	}
+}
+
+// Block returns the header of the block from the cache, or requests it to the node.
+func (hc *HeaderCache) Block(ctx context.Context, client HeaderClient, hash common.Hash) (*Block, error) {
+	hc.mu.Lock()
+	b, ok := hc.blocks[hash]
+	hc.mu.Unlock()
+	if ok {
+		return b, nil
+	}
+
+	header, err := client.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	b = NewBlock(header)
+
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if _, ok := hc.blocks[hash]; !ok {
+		hc.blocks[hash] = b
+		hc.order = append(hc.order, hash)
+	}
+	for len(hc.order) > hc.size {
+		delete(hc.blocks, hc.order[0])
+		hc.order = hc.order[1:]
+	}
+
+	return b, nil
+}
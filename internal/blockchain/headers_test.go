@@ -0,0 +1,63 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jaekwon/testify/require"
+)
+
+// fakeHeaderClient returns a header per block hash, numbered by the last byte of the hash.
+type fakeHeaderClient struct {
+	requests int
+}
+
+func (f *fakeHeaderClient) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	f.requests++
+	number := int64(hash[len(hash)-1])
+
+	return &types.Header{
+		Number:     big.NewInt(number),
+		ParentHash: common.BigToHash(big.NewInt(number - 1)),
+		Time:       uint64(1700000000 + number),
+		GasUsed:    21000,
+		BaseFee:    big.NewInt(7),
+	}, nil
+}
+
+func Test_HeaderCache(t *testing.T) {
+	client := &fakeHeaderClient{}
+	cache := NewHeaderCache(2)
+	ctx := context.Background()
+
+	b, err := cache.Block(ctx, client, common.BigToHash(big.NewInt(1)))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), b.Number)
+	require.Equal(t, uint64(1700000001), b.Timestamp)
+	require.Equal(t, common.BigToHash(big.NewInt(0)), b.ParentHash)
+	require.Equal(t, uint64(21000), b.GasUsed)
+	require.Equal(t, int64(7), b.BaseFee.Int64())
+
+	// the header of the same block is only requested once
+	_, err = cache.Block(ctx, client, common.BigToHash(big.NewInt(1)))
+	require.NoError(t, err)
+	require.Equal(t, 1, client.requests)
+
+	// the oldest header is evicted when the cache is full
+	_, err = cache.Block(ctx, client, common.BigToHash(big.NewInt(2)))
+	require.NoError(t, err)
+	_, err = cache.Block(ctx, client, common.BigToHash(big.NewInt(3)))
+	require.NoError(t, err)
+	require.Equal(t, 3, client.requests)
+
+	_, err = cache.Block(ctx, client, common.BigToHash(big.NewInt(1)))
+	require.NoError(t, err)
+	require.Equal(t, 4, client.requests)
+
+	_, err = cache.Block(ctx, client, common.BigToHash(big.NewInt(3)))
+	require.NoError(t, err)
+	require.Equal(t, 4, client.requests)
+}
@@ -9,32 +9,114 @@ import (
 	"strings"
 	"time"
 
-	// "time"
-
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	defaultBatchInterval    = 1 * time.Second
+	defaultRateLimitBackoff = 1 * time.Second
+	maxRateLimitBackoff     = 30 * time.Second
 )
 
+// LogClient is the subset of the node client used to get logs.
+type LogClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
 type Config struct {
-	Client          *ethclient.Client
+	Client          LogClient
 	ABI             string
-	EventName       string
+	EventSignatures []string
 	Address         string
+	// Addresses are requested together with the Address in a single query, so the logs of an
+	// event signature are ingested from a set of contracts at once.
+	Addresses []string
+	// AllAddresses requests the logs of every address of the network, the TopicFilters should
+	// restrict them since any contract emitting the events matches.
+	AllAddresses    bool
 	FromBlockNumber *int64
 	ToBlockNumber   *int64
 	MaxRetry        int64
 	LogsChannel     chan []LogData
-	Logger          bool
+	// BatchesChannel receives each batch with the block range it covers, it's used instead
+	// of the LogsChannel when it is defined.
+	BatchesChannel chan LogBatch
+	Logger         bool
+
+	// BatchInterval is the time waited between eth_getLogs requests, 1 second by default.
+	BatchInterval time.Duration
+	// RateLimitBackoff is the first wait after the node rate limits a request, it is doubled
+	// on each consecutive rate limit. 1 second by default.
+	RateLimitBackoff time.Duration
+	// Window is the adaptive block range used for each request. When it is nil the whole range
+	// is requested first and only reduced after the node refuses it.
+	Window *Window
+	// Headers is the cache of block headers shared between walks, a new one is used for the
+	// walk when it is nil.
+	Headers *HeaderCache
+	// TopicFilters are the filters on the indexed arguments by event signature, a log is
+	// kept when it matches any filter of its event. Events without filters keep every log.
+	TopicFilters map[string][]TopicFilter
+	// RawLogs requests every log of the address, the logs that don't belong to a tracked
+	// event or don't match its filters are sent as raw logs.
+	RawLogs bool
 }
 
 type LogData struct {
-	Tx          common.Hash            `json:"tx"`
-	BlockNumber uint64                 `json:"blockNumber"`
-	Data        map[string]interface{} `json:"data"`
+	EventName      string                 `json:"eventName"`
+	EventSignature string                 `json:"eventSignature"`
+	Tx             common.Hash            `json:"tx"`
+	TxIndex        uint                   `json:"txIndex"`
+	LogIndex       uint                   `json:"logIndex"`
+	BlockNumber    uint64                 `json:"blockNumber"`
+	BlockHash      common.Hash            `json:"blockHash"`
+	BlockTimestamp uint64                 `json:"blockTimestamp"`
+	Data           map[string]interface{} `json:"data"`
+	Topics         []common.Hash          `json:"topics"`
+	RawData        []byte                 `json:"rawData"`
+	// Address is the contract that emitted the log
+	Address common.Address `json:"address"`
+	// DecodeError is the error found decoding the log with the event definition, the Data is
+	// empty when it is defined.
+	DecodeError string `json:"decodeError,omitempty"`
+	// Removed is true when the log was reverted by a chain reorganization, it's only sent
+	// by subscriptions.
+	Removed bool `json:"removed,omitempty"`
+	// Block is the header of the block of the log
+	Block *Block `json:"block,omitempty"`
 }
 
+// LogBatch is the logs of a block range requested with a single eth_getLogs call, every log
+// of the blocks from FromBlockNumber to ToBlockNumber is in the batch.
+type LogBatch struct {
+	FromBlockNumber int64
+	ToBlockNumber   int64
+	Logs            []LogData
+}
+
+// IsRaw returns true when the log doesn't belong to a tracked event, it only has the raw
+// topics and data of the log.
+func (d *LogData) IsRaw() bool {
+	return d.EventSignature == ""
+}
+
+// GetLogs walks the block range from FromBlockNumber to ToBlockNumber (or the latest block
+// of the node) requesting the logs of the events by batches, and sends the decoded logs of
+// each batch to the LogsChannel. All the events are requested with a single eth_getLogs call
+// per batch, OR'ing their topic0, and each log is decoded with the event matching its topic0.
+// Logs that can't be decoded don't stop the walk, they're sent with their DecodeError.
+// It returns the number of logs found and the latest block number that was fully processed,
+// also when the walk stops with an error, so the batches already sent aren't walked again.
+//
+// When the node refuses a range because it is too large, the walker jumps to the range
+// suggested by the provider or, when there is no suggestion, halves the range. Rate limited
+// requests are retried with an exponential backoff, any other error is retried up to MaxRetry.
+// When a Window is given, batches start with its size and it's updated after each request.
 func GetLogs(ctx context.Context, c Config) (int64, int64, error) {
 	// check config params
 	if c.Client == nil {
@@ -43,10 +125,10 @@ func GetLogs(ctx context.Context, c Config) (int64, int64, error) {
 	if c.ABI == "" {
 		return 0, 0, errors.New("invalid ABI config param")
 	}
-	if c.EventName == "" {
-		return 0, 0, errors.New("invalid EventName config param")
+	if len(c.EventSignatures) == 0 {
+		return 0, 0, errors.New("invalid EventSignatures config param")
 	}
-	if c.Address == "" {
+	if c.Address == "" && len(c.Addresses) == 0 && !c.AllAddresses {
 		return 0, 0, errors.New("invalid Address config param")
 	}
 	if c.FromBlockNumber == nil {
@@ -55,33 +137,37 @@ func GetLogs(ctx context.Context, c Config) (int64, int64, error) {
 	if c.ToBlockNumber != nil && *c.FromBlockNumber > *c.ToBlockNumber {
 		return 0, 0, errors.New("invalid ToBlockNumber number because is lower than FromBlockNumber")
 	}
-	if c.LogsChannel == nil {
+	if c.LogsChannel == nil && c.BatchesChannel == nil {
 		return 0, 0, errors.New("invalid LogsChannel config param")
 	}
+	if c.BatchInterval <= 0 {
+		c.BatchInterval = defaultBatchInterval
+	}
+	if c.RateLimitBackoff <= 0 {
+		c.RateLimitBackoff = defaultRateLimitBackoff
+	}
 
-	// prepare contract instance using ABI definition
-	contractWithAbi, err := abi.JSON(strings.NewReader(c.ABI))
+	// close log channel when finish
+	if c.BatchesChannel != nil {
+		defer close(c.BatchesChannel)
+	} else {
+		defer close(c.LogsChannel)
+	}
+
+	// prepare the events definition using ABI definition
+	filter, err := newEventFilter(c.ABI, c.EventSignatures, c.TopicFilters)
 	if err != nil {
 		return 0, 0, err
 	}
-
-	// get event definition for getting event id
-	event, ok := contractWithAbi.Events[c.EventName]
-	if !ok {
-		return 0, 0, fmt.Errorf("event_name=%s is not defined in abi", c.EventName)
+	if c.RawLogs {
+		filter.keepRawLogs()
 	}
 
-	// define from block and interval numbers
-	logsCount := int64(0)
-	interval := int64(0)
-	fromBlock := int64(*c.FromBlockNumber)
-	retry := int64(0)
-
-	// set toBlock and temporalToBlock using config or lastest value from node
+	// set toBlock using config or lastest value from node
 	var toBlock int64
 	if c.ToBlockNumber == nil {
 		// get current latest block from
-		blockNumber, err := c.Client.BlockNumber(context.Background())
+		blockNumber, err := c.Client.BlockNumber(ctx)
 		if err != nil {
 			return 0, 0, err
 		}
@@ -89,151 +175,472 @@ func GetLogs(ctx context.Context, c Config) (int64, int64, error) {
 	} else {
 		toBlock = *c.ToBlockNumber
 	}
-	temporalToBlock := toBlock
+
+	// define from block, the end of the current batch and the span of blocks of each batch
+	logsCount := int64(0)
+	fromBlock := *c.FromBlockNumber
+	span := toBlock - fromBlock + 1
+	if c.Window != nil {
+		span = c.Window.Size()
+	}
+	endBlock := minBlock(fromBlock+span-1, toBlock)
+	retry := int64(0)
+	backoff := c.RateLimitBackoff
+
+	// the latest block number fully processed, never lower than the given from block
+	latestBlock := func() int64 {
+		if fromBlock-1 < *c.FromBlockNumber {
+			return *c.FromBlockNumber
+		}
+		return fromBlock - 1
+	}
+
+	// the addresses of the query, every address is requested when it is nil
+	var addresses []common.Address
+	if !c.AllAddresses {
+		addresses = queryAddresses(c.Address, c.Addresses)
+	}
 
 	// we need to request log by batches using interval block number
 	if c.Logger {
-		log.Printf("\nmaking batches requests for event_name%s", c.EventName)
+		log.Printf("\nmaking batches requests for event_signatures=%s", strings.Join(c.EventSignatures, ","))
 	}
 
 	// define values to manage the ticker
-	// TODO(ca): should to implement rate limit approach
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := time.NewTicker(c.BatchInterval)
 	defer ticker.Stop()
 
-loop:
-	for count := 0; ; count++ {
+	// block headers by block hash, several logs usually share the same block
+	headers := c.Headers
+	if headers == nil {
+		headers = NewHeaderCache(0)
+	}
+
+	for count := 0; fromBlock <= toBlock; count++ {
 		select {
 		case <-ctx.Done():
-			fmt.Println("case when `<-ctx.Done()` with ctx:", ctx.Err())
-			// close log channel and finish
-			close(c.LogsChannel)
-			return logsCount, int64(temporalToBlock - interval), ctx.Err()
+			return logsCount, latestBlock(), ctx.Err()
 		case <-ticker.C:
-			if c.Logger {
-				log.Printf("\naddress=%s event_name=%s iteration=%d from=%d to=%d interval=%d ", c.Address, c.EventName, count, fromBlock, temporalToBlock, interval)
-			}
+		}
 
-			// prepare query params
-			query := ethereum.FilterQuery{
-				FromBlock: big.NewInt(fromBlock),
-				ToBlock:   big.NewInt(temporalToBlock),
-				Addresses: []common.Address{
-					common.HexToAddress(c.Address),
-				},
-				Topics: [][]common.Hash{{event.ID}},
-			}
+		if c.Logger {
+			log.Printf("\naddress=%s addresses=%d events=%d iteration=%d from=%d to=%d span=%d ", c.Address, len(addresses), len(c.EventSignatures), count, fromBlock, endBlock, span)
+		}
 
-			// get logs from contract
-			logs, err := c.Client.FilterLogs(context.Background(), query)
-			if err != nil {
-				// TODO(ca): should implement a better recongnition for node log limit error
-				//
-				// Infura(Polygon): query returned more than 10000 results
-				//
-				// Alchemy(Ethereum): Log response size exceeded. You can make eth_getLogs requests
-				// with up to a 2K block range and no limit on the response size, or you can request
-				// any block range with a cap of 10K logs in the response. Based on your parameters
-				// and the response size limit, this block range should work: [0x0, 0x88c025]
-				//
-				// Alchemy(Polygon): Query git out exceeded. Consider reducing your block range. Based
-				// on your parameters and the response size limit, this block range should work: [0x0, 0x1360b8a]
-				//
-				// QuickNode(Polygon): 413 Request Entity Too Large: {"jsonrpc":"2.0","id":2,"result":null,"error":
-				// {"code":-32602,"message":"eth_getLogs and eth_newFilter are limited to a 10,000 blocks range"}}
-				//
-				if strings.Contains(err.Error(), "returned") || strings.Contains(err.Error(), "exceeded") || strings.Contains(err.Error(), "reducing") || strings.Contains(err.Error(), "limited") {
-					interval = (temporalToBlock - fromBlock) / 2
-					temporalToBlock = temporalToBlock - interval
-
-					continue
-				}
+		// prepare query params
+		query := ethereum.FilterQuery{
+			FromBlock: big.NewInt(fromBlock),
+			ToBlock:   big.NewInt(endBlock),
+			Addresses: addresses,
+			Topics:    filter.topics,
+		}
 
-				// retry process
-				retry++
-				if retry > c.MaxRetry {
-					return 0, 0, fmt.Errorf("max_retry, error=%s", err.Error())
+		// get logs from contract
+		logs, err := c.Client.FilterLogs(ctx, query)
+		if err != nil {
+			err = ClassifyError(err)
+
+			// the range was refused by the node, so reduce it and try again
+			var rangeErr *RangeTooLargeError
+			if errors.As(err, &rangeErr) && endBlock > fromBlock {
+				if c.Window != nil {
+					span = c.Window.Shrink(rangeErr, fromBlock, endBlock)
 				} else {
-					log.Printf("Error: c.Client.FilterLogs(context.Background(), query), err%s \n", err.Error())
+					span = nextSpan(rangeErr, fromBlock, endBlock)
 				}
+				endBlock = minBlock(fromBlock+span-1, toBlock)
+				continue
+			}
 
+			// wait before retrying the same range when the node is rate limiting
+			if errors.Is(err, ErrRateLimited) {
+				log.Printf("blockchain.GetLogs rate limited, waiting %s \n", backoff)
+				select {
+				case <-ctx.Done():
+					return logsCount, latestBlock(), ctx.Err()
+				case <-time.After(backoff):
+				}
+
+				backoff = backoff * 2
+				if backoff > maxRateLimitBackoff {
+					backoff = maxRateLimitBackoff
+				}
 				continue
 			}
 
-			// define reset and data log slice
-			retry = 0
-			data := make([]LogData, 0)
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return logsCount, latestBlock(), err
+			}
 
-			if c.Logger {
-				log.Printf("logs=%d\n", len(logs))
+			// retry process
+			retry++
+			if retry > c.MaxRetry {
+				return logsCount, latestBlock(), fmt.Errorf("max_retry, error=%w", err)
 			}
+			log.Printf("Error: c.Client.FilterLogs(ctx, query), err%s \n", err.Error())
 
-			// iterate over logs
-			for _, vLog := range logs {
-				// continue if event data are empty
-				if len(vLog.Data) == 0 {
-					continue
-				}
+			continue
+		}
 
-				// get event from contract log
-				eventData := make(map[string]interface{})
-				err := contractWithAbi.UnpackIntoMap(eventData, c.EventName, vLog.Data)
-				if err != nil {
-					return 0, 0, err
-				}
+		// define reset and data log slice
+		retry = 0
+		backoff = c.RateLimitBackoff
+		data := make([]LogData, 0)
+
+		if c.Logger {
+			log.Printf("logs=%d\n", len(logs))
+		}
+
+		// iterate over logs
+		for _, vLog := range logs {
+			// logs of events that aren't tracked are skipped
+			d, ok, err := filter.toLogData(ctx, c.Client, headers, vLog)
+			if err != nil {
+				return logsCount, latestBlock(), err
+			}
+			if !ok {
+				continue
+			}
+
+			// append log in data log slice
+			data = append(data, d)
+		}
+
+		// send log data to channel
+		if c.BatchesChannel != nil {
+			c.BatchesChannel <- LogBatch{FromBlockNumber: fromBlock, ToBlockNumber: endBlock, Logs: data}
+		} else {
+			c.LogsChannel <- data
+		}
+		logsCount += int64(len(data))
+
+		// move to the next batch keeping the span that worked or growing the window
+		if c.Window != nil {
+			span = c.Window.Grow()
+		}
+		fromBlock = endBlock + 1
+		endBlock = minBlock(fromBlock+span-1, toBlock)
+	}
+
+	return logsCount, toBlock, nil
+}
+
+// eventFilter matches the logs of a contract with the definition of the tracked events.
+type eventFilter struct {
+	// events by event id (topic0), anonymous events don't have it so they're matched by
+	// the number of topics
+	events    map[common.Hash]abi.Event
+	anonymous []abi.Event
+	// filters on the indexed arguments by event signature
+	filters map[string][]TopicFilter
+	// topics filter of the query, it's nil when an anonymous event is tracked
+	topics [][]common.Hash
+	// raw keeps the logs that don't match the tracked events as raw logs
+	raw bool
+}
+
+func newEventFilter(contractABI string, signatures []string, filters map[string][]TopicFilter) (*eventFilter, error) {
+	// prepare contract instance using ABI definition
+	contractWithAbi, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return nil, err
+	}
+
+	// abi.JSON renames overloaded events (Transfer, Transfer0), so events are found by signature
+	eventsBySignature := make(map[string]abi.Event)
+	for _, event := range contractWithAbi.Events {
+		eventsBySignature[event.Sig] = event
+	}
+
+	f := &eventFilter{
+		events:    make(map[common.Hash]abi.Event),
+		anonymous: make([]abi.Event, 0),
+		filters:   make(map[string][]TopicFilter),
+	}
+	topics := make([]common.Hash, 0)
+	for _, signature := range signatures {
+		event, ok := eventsBySignature[signature]
+		if !ok {
+			return nil, fmt.Errorf("event_signature=%s is not defined in abi", signature)
+		}
+
+		if event.Anonymous {
+			f.anonymous = append(f.anonymous, event)
+			continue
+		}
+
+		if _, ok := f.events[event.ID]; !ok {
+			topics = append(topics, event.ID)
+		}
+		f.events[event.ID] = event
+	}
+
+	for signature, eventFilters := range filters {
+		if len(eventFilters) > 0 {
+			f.filters[signature] = eventFilters
+		}
+	}
+
+	// filter by topic0 only when all the events have it, otherwise every log of the address is requested
+	if len(f.anonymous) == 0 {
+		f.topics = append([][]common.Hash{topics}, f.indexedTopics()...)
+	}
+
+	return f, nil
+}
 
-				// filter only indexed elements from events inputs
-				indexedInputs := make([]abi.Argument, 0)
-				for _, e := range contractWithAbi.Events[c.EventName].Inputs {
-					if e.Indexed {
-						indexedInputs = append(indexedInputs, e)
+// keepRawLogs makes the filter request every log of the address and keep the ones that
+// don't match the tracked events as raw logs.
+func (f *eventFilter) keepRawLogs() {
+	f.raw = true
+	f.topics = nil
+}
+
+// indexedTopics returns the topics of the query for the indexed arguments, each position
+// has the values allowed by any filter of any event. A position matches any value when an
+// event or one of its filters doesn't restrict it, since the node can't filter by less.
+func (f *eventFilter) indexedTopics() [][]common.Hash {
+	topics := make([][]common.Hash, 0)
+	if len(f.events) == 0 {
+		return topics
+	}
+
+	// logs have up to 3 topics after the event id
+	for position := 0; position < 3; position++ {
+		values := make([]common.Hash, 0)
+		seen := make(map[common.Hash]bool)
+		for _, event := range f.events {
+			for _, filter := range f.filters[event.Sig] {
+				if position >= len(filter) || len(filter[position]) == 0 {
+					values = nil
+					break
+				}
+				for _, value := range filter[position] {
+					if !seen[value] {
+						seen[value] = true
+						values = append(values, value)
 					}
 				}
+			}
+			if len(f.filters[event.Sig]) == 0 || values == nil {
+				values = nil
+				break
+			}
+		}
+		topics = append(topics, values)
+	}
 
-				// get indexed topics from log and parse to map
-				topics := make(map[string]interface{})
-				err = abi.ParseTopicsIntoMap(topics, indexedInputs, vLog.Topics[1:])
-				if err != nil {
-					return 0, 0, err
-				}
+	// trailing positions matching any value are removed
+	for len(topics) > 0 && topics[len(topics)-1] == nil {
+		topics = topics[:len(topics)-1]
+	}
 
-				// iterate indexed topics and add to eventData map
-				for key, t := range topics {
-					eventData[key] = t
-				}
+	return topics
+}
 
-				// prepare event data
-				d := LogData{
-					Tx:          vLog.TxHash,
-					BlockNumber: vLog.BlockNumber,
-					Data:        eventData,
-				}
+// toLogData decodes the log with the event it belongs to, it returns false when the log
+// doesn't match any tracked event, unless the filter keeps raw logs. The block headers are
+// cached by block hash since several logs usually share the same block.
+func (f *eventFilter) toLogData(ctx context.Context, client HeaderClient, headers *HeaderCache, vLog types.Log) (LogData, bool, error) {
+	// get the event definition of the log, the logs that don't match the filters on the
+	// indexed arguments of the event are skipped like the ones of untracked events
+	event, ok := matchEvent(f.events, f.anonymous, vLog)
+	if ok && !f.matchFilters(event, vLog.Topics) {
+		ok = false
+	}
+	if !ok && !f.raw {
+		return LogData{}, false, nil
+	}
 
-				// append log in data log slice and increase the counter
-				data = append(data, d)
-				logsCount++
-			}
+	// get block header from cache or node
+	block, err := headers.Block(ctx, client, vLog.BlockHash)
+	if err != nil {
+		return LogData{}, false, err
+	}
 
-			// send log data to channel
-			c.LogsChannel <- data
+	// prepare log data
+	d := LogData{
+		Tx:             vLog.TxHash,
+		TxIndex:        vLog.TxIndex,
+		LogIndex:       vLog.Index,
+		BlockNumber:    vLog.BlockNumber,
+		BlockHash:      vLog.BlockHash,
+		BlockTimestamp: block.Timestamp,
+		Block:          block,
+		Topics:         vLog.Topics,
+		RawData:        vLog.Data,
+		Address:        vLog.Address,
+		Removed:        vLog.Removed,
+	}
+	if !ok {
+		return d, true, nil
+	}
 
-			// condition for finish the bucle
-			if temporalToBlock == int64(toBlock) {
-				break loop
-			}
+	// decode the log, undecodable logs are sent with the decode error so they can be quarantined
+	eventData, decodeErr := DecodeLog(event, vLog.Topics, vLog.Data)
+	d.EventName = event.RawName
+	d.EventSignature = event.Sig
+	d.Data = eventData
+	if decodeErr != nil {
+		log.Printf("blockchain.eventFilter undecodable log tx=%s log_index=%d error=%s \n", vLog.TxHash.Hex(), vLog.Index, decodeErr.Error())
+		d.DecodeError = decodeErr.Error()
+	}
 
-			// add interval value to fromBlock and toBlock numbers
-			// TODO(ca): maybe is interval + 1
-			fromBlock = fromBlock + interval
-			if temporalToBlock+interval > int64(toBlock) {
-				temporalToBlock = int64(toBlock)
-			} else {
-				temporalToBlock = temporalToBlock + interval
-			}
+	return d, true, nil
+}
+
+// matchFilters returns true when the event doesn't have filters or the topics of the log
+// match any of them.
+func (f *eventFilter) matchFilters(event abi.Event, topics []common.Hash) bool {
+	eventFilters, ok := f.filters[event.Sig]
+	if !ok {
+		return true
+	}
+
+	for _, filter := range eventFilters {
+		if filter.Match(topics, event.Anonymous) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchEvent returns the event definition of the log. Logs are matched by topic0 and, when
+// it doesn't match any event, with the anonymous events that have as many indexed arguments
+// as topics has the log. When several anonymous events have the same number of indexed
+// arguments, the first one that decodes the log is used.
+func matchEvent(events map[common.Hash]abi.Event, anonymous []abi.Event, vLog types.Log) (abi.Event, bool) {
+	if len(vLog.Topics) > 0 {
+		if event, ok := events[vLog.Topics[0]]; ok {
+			return event, true
 		}
 	}
 
-	// close log channel
-	close(c.LogsChannel)
-	return logsCount, int64(temporalToBlock), nil
+	candidates := make([]abi.Event, 0)
+	for _, event := range anonymous {
+		if countIndexed(event) == len(vLog.Topics) {
+			candidates = append(candidates, event)
+		}
+	}
+	if len(candidates) == 0 {
+		return abi.Event{}, false
+	}
+
+	for _, event := range candidates {
+		if _, err := DecodeLog(event, vLog.Topics, vLog.Data); err == nil {
+			return event, true
+		}
+	}
+
+	return candidates[0], true
+}
+
+func countIndexed(event abi.Event) int {
+	count := 0
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			count++
+		}
+	}
+
+	return count
+}
+
+// DecodeLog decodes the topics and data of a log using the event definition, returning the
+// indexed and non indexed arguments in a single map. Events whose arguments are all indexed
+// are decoded only from the topics, and anonymous events don't have the topic0 with the
+// event id, so all their topics are indexed arguments.
+func DecodeLog(event abi.Event, topics []common.Hash, data []byte) (map[string]interface{}, error) {
+	indexedTopics := topics
+	if !event.Anonymous {
+		if len(topics) == 0 || topics[0] != event.ID {
+			return nil, fmt.Errorf("log topic0 doesn't match the event_id=%s", event.ID.Hex())
+		}
+		indexedTopics = topics[1:]
+	}
+
+	// get event from contract log
+	eventData := make(map[string]interface{})
+	err := event.Inputs.UnpackIntoMap(eventData, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// filter only indexed elements from events inputs
+	indexedInputs := make([]abi.Argument, 0)
+	for _, e := range event.Inputs {
+		if e.Indexed {
+			indexedInputs = append(indexedInputs, e)
+		}
+	}
+
+	// check the number of topics before parsing them, a log with a different layout would panic
+	if len(indexedTopics) != len(indexedInputs) {
+		return nil, fmt.Errorf("log has %d indexed topics but event_signature=%s defines %d", len(indexedTopics), event.Sig, len(indexedInputs))
+	}
+
+	// get indexed topics from log and parse to map
+	indexed := make(map[string]interface{})
+	err = abi.ParseTopicsIntoMap(indexed, indexedInputs, indexedTopics)
+	if err != nil {
+		return nil, err
+	}
+
+	// iterate indexed topics and add to eventData map
+	for key, t := range indexed {
+		eventData[key] = t
+	}
+
+	return eventData, nil
+}
+
+// nextSpan returns the number of blocks of the next request after the node refused the
+// range [fromBlock, endBlock]. The provider suggestion is used when it is available, the
+// span is halved otherwise.
+func nextSpan(rangeErr *RangeTooLargeError, fromBlock int64, endBlock int64) int64 {
+	current := endBlock - fromBlock + 1
+	span := current / 2
+
+	if rangeErr.SuggestedFrom != nil && rangeErr.SuggestedTo != nil {
+		// providers suggest a range starting at the requested from block
+		suggested := *rangeErr.SuggestedTo - *rangeErr.SuggestedFrom + 1
+		if suggested < current {
+			span = suggested
+		}
+	} else if rangeErr.MaxBlocks != nil && *rangeErr.MaxBlocks < current {
+		span = *rangeErr.MaxBlocks
+	}
+
+	if span < 1 {
+		span = 1
+	}
+
+	return span
+}
+
+// queryAddresses returns the address and the addresses without duplicates.
+func queryAddresses(address string, others []string) []common.Address {
+	addresses := make([]common.Address, 0)
+	seen := make(map[common.Address]bool)
+	for _, a := range append([]string{address}, others...) {
+		if a == "" {
+			continue
+		}
+
+		hex := common.HexToAddress(a)
+		if !seen[hex] {
+			seen[hex] = true
+			addresses = append(addresses, hex)
+		}
+	}
+
+	return addresses
+}
+
+func minBlock(a int64, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
 }
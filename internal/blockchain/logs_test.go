@@ -0,0 +1,769 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jaekwon/testify/require"
+)
+
+const transferABI = `[{"anonymous":false,"inputs":[` +
+	`{"indexed":true,"internalType":"address","name":"from","type":"address"},` +
+	`{"indexed":true,"internalType":"address","name":"to","type":"address"},` +
+	`{"indexed":false,"internalType":"uint256","name":"value","type":"uint256"}],` +
+	`"name":"Transfer","type":"event"}]`
+
+var testContractAddress = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+// fakeLogClient is a FilterLogs client that refuses ranges larger than maxBlocks using
+// the given provider error and rate limits the first rateLimited requests.
+type fakeLogClient struct {
+	logs        []types.Log
+	head        uint64
+	maxBlocks   int64
+	rangeErr    func(from int64, to int64) error
+	rateLimited int
+	// headerErrs are the errors returned for the headers of the given block hashes
+	headerErrs map[common.Hash]error
+
+	queries []ethereum.FilterQuery
+}
+
+func (f *fakeLogClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return f.head, nil
+}
+
+func (f *fakeLogClient) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	if err, ok := f.headerErrs[hash]; ok {
+		return nil, err
+	}
+
+	return &types.Header{Number: big.NewInt(1), Time: 1700000000}, nil
+}
+
+func (f *fakeLogClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	f.queries = append(f.queries, q)
+
+	if f.rateLimited > 0 {
+		f.rateLimited--
+		return nil, errors.New("429 Too Many Requests")
+	}
+
+	from := q.FromBlock.Int64()
+	to := q.ToBlock.Int64()
+	if f.maxBlocks > 0 && to-from+1 > f.maxBlocks {
+		return nil, f.rangeErr(from, to)
+	}
+
+	addresses := make(map[common.Address]bool)
+	for _, address := range q.Addresses {
+		addresses[address] = true
+	}
+
+	logs := make([]types.Log, 0)
+	for _, l := range f.logs {
+		if len(addresses) > 0 && !addresses[l.Address] {
+			continue
+		}
+		if int64(l.BlockNumber) >= from && int64(l.BlockNumber) <= to {
+			logs = append(logs, l)
+		}
+	}
+
+	return logs, nil
+}
+
+func newTransferLog(t *testing.T, blockNumber uint64, logIndex uint, value int64) types.Log {
+	t.Helper()
+
+	parsed, err := abi.JSON(strings.NewReader(transferABI))
+	require.NoError(t, err)
+
+	data, err := parsed.Events["Transfer"].Inputs.NonIndexed().Pack(big.NewInt(value))
+	require.NoError(t, err)
+
+	return types.Log{
+		Address: testContractAddress,
+		Topics: []common.Hash{
+			parsed.Events["Transfer"].ID,
+			common.BytesToHash(common.HexToAddress("0x01").Bytes()),
+			common.BytesToHash(common.HexToAddress("0x02").Bytes()),
+		},
+		Data:        data,
+		BlockNumber: blockNumber,
+		BlockHash:   common.BigToHash(big.NewInt(int64(blockNumber))),
+		TxHash:      common.BigToHash(big.NewInt(int64(blockNumber*1000) + int64(logIndex))),
+		Index:       logIndex,
+	}
+}
+
+func runGetLogs(t *testing.T, client *fakeLogClient, from int64, to int64) (int64, int64, []LogData, error) {
+	t.Helper()
+
+	logsChannel := make(chan []LogData)
+	received := make([]LogData, 0)
+	done := make(chan struct{})
+	go func() {
+		for batch := range logsChannel {
+			received = append(received, batch...)
+		}
+		close(done)
+	}()
+
+	count, latest, err := GetLogs(context.Background(), Config{
+		Client:           client,
+		ABI:              transferABI,
+		EventSignatures:  []string{"Transfer(address,address,uint256)"},
+		Address:          testContractAddress.Hex(),
+		FromBlockNumber:  &from,
+		ToBlockNumber:    &to,
+		MaxRetry:         1,
+		LogsChannel:      logsChannel,
+		BatchInterval:    time.Millisecond,
+		RateLimitBackoff: time.Millisecond,
+	})
+	<-done
+
+	return count, latest, received, err
+}
+
+// requireContiguousQueries checks the successful queries cover [from, to] without gaps or overlaps
+func requireContiguousQueries(t *testing.T, client *fakeLogClient, from int64, to int64) {
+	t.Helper()
+
+	next := from
+	for _, q := range client.queries {
+		qFrom := q.FromBlock.Int64()
+		qTo := q.ToBlock.Int64()
+		if client.maxBlocks > 0 && qTo-qFrom+1 > client.maxBlocks {
+			continue
+		}
+		if qFrom != next {
+			continue
+		}
+		next = qTo + 1
+	}
+
+	require.Equal(t, to+1, next)
+}
+
+func Test_GetLogs_ProviderRangeErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		rangeErr func(from int64, to int64) error
+		// maximum number of refused requests expected for the whole walk
+		maxRefused int
+	}{
+		{
+			name: "Infura(Polygon)",
+			rangeErr: func(from int64, to int64) error {
+				return errors.New("query returned more than 10000 results")
+			},
+			maxRefused: 3,
+		},
+		{
+			name: "Alchemy(Ethereum)",
+			rangeErr: func(from int64, to int64) error {
+				return fmt.Errorf("Log response size exceeded. You can make eth_getLogs requests with up to a 2K block "+
+					"range and no limit on the response size, or you can request any block range with a cap of 10K "+
+					"logs in the response. Based on your parameters and the response size limit, this block range "+
+					"should work: [0x%x, 0x%x]", from, from+24)
+			},
+			maxRefused: 1,
+		},
+		{
+			name: "Alchemy(Polygon)",
+			rangeErr: func(from int64, to int64) error {
+				return fmt.Errorf("Query git out exceeded. Consider reducing your block range. Based on your "+
+					"parameters and the response size limit, this block range should work: [0x%x, 0x%x]", from, from+24)
+			},
+			maxRefused: 1,
+		},
+		{
+			name: "QuickNode(Polygon)",
+			rangeErr: func(from int64, to int64) error {
+				return errors.New(`413 Request Entity Too Large: {"jsonrpc":"2.0","id":2,"result":null,"error":` +
+					`{"code":-32602,"message":"eth_getLogs and eth_newFilter are limited to a 25 blocks range"}}`)
+			},
+			maxRefused: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &fakeLogClient{
+				maxBlocks: 25,
+				rangeErr:  c.rangeErr,
+				logs: []types.Log{
+					newTransferLog(t, 5, 0, 10),
+					newTransferLog(t, 5, 1, 20),
+					newTransferLog(t, 40, 0, 30),
+					newTransferLog(t, 99, 3, 40),
+				},
+			}
+
+			count, latest, received, err := runGetLogs(t, client, 0, 100)
+			require.NoError(t, err)
+			require.Equal(t, int64(4), count)
+			require.Equal(t, int64(100), latest)
+			require.Len(t, received, 4)
+			require.Equal(t, uint(1), received[1].LogIndex)
+			require.Equal(t, big.NewInt(30), received[2].Data["value"])
+			require.Equal(t, uint64(1700000000), received[2].BlockTimestamp)
+
+			refused := 0
+			for _, q := range client.queries {
+				if q.ToBlock.Int64()-q.FromBlock.Int64()+1 > client.maxBlocks {
+					refused++
+				}
+			}
+			require.True(t, refused <= c.maxRefused, "refused=%d", refused)
+			requireContiguousQueries(t, client, 0, 100)
+		})
+	}
+}
+
+func Test_GetLogs_RateLimitedBackoff(t *testing.T) {
+	client := &fakeLogClient{
+		rateLimited: 3,
+		logs: []types.Log{
+			newTransferLog(t, 7, 0, 10),
+		},
+	}
+
+	count, latest, received, err := runGetLogs(t, client, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+	require.Equal(t, int64(10), latest)
+	require.Len(t, received, 1)
+
+	// rate limited requests are retried over the same range and don't count as retries
+	require.Len(t, client.queries, 4)
+	for _, q := range client.queries {
+		require.Equal(t, int64(0), q.FromBlock.Int64())
+		require.Equal(t, int64(10), q.ToBlock.Int64())
+	}
+}
+
+func Test_GetLogs_MaxRetry(t *testing.T) {
+	client := &fakeLogClient{
+		maxBlocks: 1,
+		rangeErr: func(from int64, to int64) error {
+			return errors.New("execution reverted")
+		},
+	}
+
+	_, _, _, err := runGetLogs(t, client, 0, 10)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "max_retry")
+}
+
+func Test_GetLogs_ErrorKeepsProgress(t *testing.T) {
+	blockErr := errors.New("header not found")
+	client := &fakeLogClient{
+		logs: []types.Log{
+			newTransferLog(t, 2, 0, 10),
+			newTransferLog(t, 3, 0, 20),
+			newTransferLog(t, 7, 0, 30),
+		},
+		headerErrs: map[common.Hash]error{
+			common.BigToHash(big.NewInt(7)): blockErr,
+		},
+	}
+
+	logsChannel := make(chan []LogData)
+	received := make([]LogData, 0)
+	done := make(chan struct{})
+	go func() {
+		for batch := range logsChannel {
+			received = append(received, batch...)
+		}
+		close(done)
+	}()
+
+	// the header of the block of the second batch can't be fetched
+	from := int64(0)
+	to := int64(14)
+	count, latest, err := GetLogs(context.Background(), Config{
+		Client:           client,
+		ABI:              transferABI,
+		EventSignatures:  []string{"Transfer(address,address,uint256)"},
+		Address:          testContractAddress.Hex(),
+		FromBlockNumber:  &from,
+		ToBlockNumber:    &to,
+		MaxRetry:         1,
+		LogsChannel:      logsChannel,
+		BatchInterval:    time.Millisecond,
+		RateLimitBackoff: time.Millisecond,
+		Window:           NewWindow(WindowConfig{Size: 5, Max: 5}),
+	})
+	<-done
+	require.True(t, errors.Is(err, blockErr))
+
+	// the first batch was sent, so its logs and blocks are reported
+	require.Equal(t, int64(2), count)
+	require.Equal(t, int64(4), latest)
+	require.Len(t, received, 2)
+}
+
+func Test_GetLogs_ContractEvents(t *testing.T) {
+	approvalABI := `{"anonymous":false,"inputs":[` +
+		`{"indexed":true,"internalType":"address","name":"owner","type":"address"},` +
+		`{"indexed":true,"internalType":"address","name":"spender","type":"address"},` +
+		`{"indexed":false,"internalType":"uint256","name":"value","type":"uint256"}],` +
+		`"name":"Approval","type":"event"}`
+	contractABI := strings.TrimSuffix(transferABI, "]") + "," + approvalABI + "]"
+
+	parsed, err := abi.JSON(strings.NewReader(contractABI))
+	require.NoError(t, err)
+
+	approval := newTransferLog(t, 8, 1, 50)
+	approval.Topics[0] = parsed.Events["Approval"].ID
+
+	client := &fakeLogClient{
+		logs: []types.Log{
+			newTransferLog(t, 3, 0, 10),
+			approval,
+		},
+	}
+
+	logsChannel := make(chan []LogData)
+	received := make([]LogData, 0)
+	done := make(chan struct{})
+	go func() {
+		for batch := range logsChannel {
+			received = append(received, batch...)
+		}
+		close(done)
+	}()
+
+	from := int64(0)
+	to := int64(10)
+	count, _, err := GetLogs(context.Background(), Config{
+		Client:           client,
+		ABI:              contractABI,
+		EventSignatures:  []string{"Transfer(address,address,uint256)", "Approval(address,address,uint256)"},
+		Address:          testContractAddress.Hex(),
+		FromBlockNumber:  &from,
+		ToBlockNumber:    &to,
+		LogsChannel:      logsChannel,
+		BatchInterval:    time.Millisecond,
+		RateLimitBackoff: time.Millisecond,
+	})
+	<-done
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	// a single request with both topic0 OR'ed
+	require.Len(t, client.queries, 1)
+	require.Equal(t, [][]common.Hash{{parsed.Events["Transfer"].ID, parsed.Events["Approval"].ID}}, client.queries[0].Topics)
+
+	// each log is decoded with its own event
+	require.Len(t, received, 2)
+	require.Equal(t, "Transfer", received[0].EventName)
+	require.Equal(t, "Approval", received[1].EventName)
+	require.Equal(t, big.NewInt(50), received[1].Data["value"])
+	require.Contains(t, received[1].Data, "spender")
+}
+
+func Test_GetLogs_UndecodableLogs(t *testing.T) {
+	// a log with the Transfer topic0 but without the indexed arguments, like after an upgrade
+	// that changed the event layout
+	malformed := newTransferLog(t, 4, 0, 10)
+	malformed.Topics = malformed.Topics[:1]
+
+	// a log whose data is shorter than the non indexed arguments
+	truncated := newTransferLog(t, 6, 0, 10)
+	truncated.Data = truncated.Data[:8]
+
+	client := &fakeLogClient{
+		logs: []types.Log{
+			malformed,
+			truncated,
+			newTransferLog(t, 9, 0, 30),
+		},
+	}
+
+	count, latest, received, err := runGetLogs(t, client, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+	require.Equal(t, int64(10), latest)
+	require.Len(t, received, 3)
+
+	require.NotEmpty(t, received[0].DecodeError)
+	require.Nil(t, received[0].Data)
+	require.Equal(t, malformed.Topics, received[0].Topics)
+	require.Equal(t, malformed.Data, received[0].RawData)
+
+	require.NotEmpty(t, received[1].DecodeError)
+
+	require.Empty(t, received[2].DecodeError)
+	require.Equal(t, big.NewInt(30), received[2].Data["value"])
+}
+
+// loadLogs reads logs in the eth_getLogs JSON format from the testdata directory
+func loadLogs(t *testing.T, name string) []types.Log {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	logs := make([]types.Log, 0)
+	require.NoError(t, json.Unmarshal(b, &logs))
+
+	return logs
+}
+
+func runContractLogs(t *testing.T, client *fakeLogClient, contractABI string, address string, signatures []string, from int64, to int64) []LogData {
+	t.Helper()
+
+	logsChannel := make(chan []LogData)
+	received := make([]LogData, 0)
+	done := make(chan struct{})
+	go func() {
+		for batch := range logsChannel {
+			received = append(received, batch...)
+		}
+		close(done)
+	}()
+
+	_, _, err := GetLogs(context.Background(), Config{
+		Client:           client,
+		ABI:              contractABI,
+		EventSignatures:  signatures,
+		Address:          address,
+		FromBlockNumber:  &from,
+		ToBlockNumber:    &to,
+		LogsChannel:      logsChannel,
+		BatchInterval:    time.Millisecond,
+		RateLimitBackoff: time.Millisecond,
+	})
+	<-done
+	require.NoError(t, err)
+
+	return received
+}
+
+func Test_GetLogs_AllIndexedEvents(t *testing.T) {
+	// ERC721 events, every argument is indexed so the logs don't have data
+	erc721ABI := `[` +
+		`{"anonymous":false,"inputs":[` +
+		`{"indexed":true,"internalType":"address","name":"from","type":"address"},` +
+		`{"indexed":true,"internalType":"address","name":"to","type":"address"},` +
+		`{"indexed":true,"internalType":"uint256","name":"tokenId","type":"uint256"}],` +
+		`"name":"Transfer","type":"event"},` +
+		`{"anonymous":false,"inputs":[` +
+		`{"indexed":true,"internalType":"address","name":"owner","type":"address"},` +
+		`{"indexed":true,"internalType":"address","name":"approved","type":"address"},` +
+		`{"indexed":true,"internalType":"uint256","name":"tokenId","type":"uint256"}],` +
+		`"name":"Approval","type":"event"}]`
+
+	logs := loadLogs(t, "erc721_logs.json")
+	client := &fakeLogClient{logs: logs}
+
+	received := runContractLogs(t, client, erc721ABI, logs[0].Address.Hex(), []string{"Transfer(address,address,uint256)", "Approval(address,address,uint256)"}, 12292900, 16120500)
+	require.Len(t, received, 3)
+
+	// the query keeps filtering by topic0
+	require.Len(t, client.queries[0].Topics, 1)
+
+	mint := received[0]
+	require.Empty(t, mint.DecodeError)
+	require.Equal(t, "Transfer", mint.EventName)
+	require.Equal(t, common.Address{}, mint.Data["from"])
+	require.Equal(t, common.HexToAddress("0xaba7161a7fb69c88e16ed9f455ce62b791ee4d03"), mint.Data["to"])
+	require.Zero(t, mint.Data["tokenId"].(*big.Int).Sign())
+	require.Equal(t, uint(130), mint.LogIndex)
+	require.Equal(t, uint(96), mint.TxIndex)
+
+	approval := received[1]
+	require.Empty(t, approval.DecodeError)
+	require.Equal(t, "Approval", approval.EventName)
+	require.Equal(t, common.HexToAddress("0x1e0049783f008a0085193e00003d00cd54003c71"), approval.Data["approved"])
+	require.Equal(t, big.NewInt(7495), approval.Data["tokenId"])
+
+	transfer := received[2]
+	require.Empty(t, transfer.DecodeError)
+	require.Equal(t, "Transfer", transfer.EventName)
+	require.Equal(t, big.NewInt(7495), transfer.Data["tokenId"])
+}
+
+func Test_GetLogs_AnonymousEvents(t *testing.T) {
+	// DSNote LogNote and an anonymous Mint, both without topic0
+	anonymousABI := `[` +
+		`{"anonymous":true,"inputs":[` +
+		`{"indexed":true,"internalType":"bytes4","name":"sig","type":"bytes4"},` +
+		`{"indexed":true,"internalType":"address","name":"usr","type":"address"},` +
+		`{"indexed":true,"internalType":"bytes32","name":"arg1","type":"bytes32"},` +
+		`{"indexed":true,"internalType":"bytes32","name":"arg2","type":"bytes32"},` +
+		`{"indexed":false,"internalType":"uint256","name":"wad","type":"uint256"},` +
+		`{"indexed":false,"internalType":"bytes","name":"fax","type":"bytes"}],` +
+		`"name":"LogNote","type":"event"},` +
+		`{"anonymous":true,"inputs":[` +
+		`{"indexed":true,"internalType":"address","name":"to","type":"address"},` +
+		`{"indexed":false,"internalType":"uint256","name":"amount","type":"uint256"}],` +
+		`"name":"Mint","type":"event"}]`
+
+	logs := loadLogs(t, "anonymous_logs.json")
+	client := &fakeLogClient{logs: logs}
+
+	received := runContractLogs(t, client, anonymousABI, logs[0].Address.Hex(), []string{"LogNote(bytes4,address,bytes32,bytes32,uint256,bytes)", "Mint(address,uint256)"}, 8928150, 8928160)
+
+	// anonymous events can't be filtered by topic0, only by address
+	require.Nil(t, client.queries[0].Topics)
+	require.Equal(t, []common.Address{logs[0].Address}, client.queries[0].Addresses)
+
+	// the log with three topics doesn't match any tracked event
+	require.Len(t, received, 2)
+
+	note := received[0]
+	require.Empty(t, note.DecodeError)
+	require.Equal(t, "LogNote", note.EventName)
+	require.Equal(t, [4]byte{0x76, 0x08, 0x87, 0x03}, note.Data["sig"])
+	require.Equal(t, common.HexToAddress("0x5ef30b9986345249bc32d8928b7ee64de9435e39"), note.Data["usr"])
+	require.Zero(t, note.Data["wad"].(*big.Int).Sign())
+	require.Len(t, note.Data["fax"], 196)
+	require.Equal(t, []byte{0x76, 0x08, 0x87, 0x03}, note.Data["fax"].([]byte)[:4])
+
+	mint := received[1]
+	require.Empty(t, mint.DecodeError)
+	require.Equal(t, "Mint", mint.EventName)
+	require.Equal(t, common.HexToAddress("0x5ef30b9986345249bc32d8928b7ee64de9435e39"), mint.Data["to"])
+	require.Equal(t, big.NewInt(1000000000000000000), mint.Data["amount"])
+}
+
+func Test_GetLogs_OverloadedEvents(t *testing.T) {
+	// ERC223 overloads Transfer with a bytes argument, abi.JSON renames it to Transfer0
+	overloadedABI := `[` +
+		`{"anonymous":false,"inputs":[` +
+		`{"indexed":true,"internalType":"address","name":"from","type":"address"},` +
+		`{"indexed":true,"internalType":"address","name":"to","type":"address"},` +
+		`{"indexed":false,"internalType":"uint256","name":"value","type":"uint256"}],` +
+		`"name":"Transfer","type":"event"},` +
+		`{"anonymous":false,"inputs":[` +
+		`{"indexed":true,"internalType":"address","name":"from","type":"address"},` +
+		`{"indexed":true,"internalType":"address","name":"to","type":"address"},` +
+		`{"indexed":false,"internalType":"uint256","name":"value","type":"uint256"},` +
+		`{"indexed":false,"internalType":"bytes","name":"data","type":"bytes"}],` +
+		`"name":"Transfer","type":"event"}]`
+
+	parsed, err := abi.JSON(strings.NewReader(overloadedABI))
+	require.NoError(t, err)
+	withData := parsed.Events["Transfer0"]
+	require.Equal(t, "Transfer(address,address,uint256,bytes)", withData.Sig)
+
+	data, err := withData.Inputs.NonIndexed().Pack(big.NewInt(7), []byte("memo"))
+	require.NoError(t, err)
+
+	erc223Log := newTransferLog(t, 11, 0, 0)
+	erc223Log.Topics[0] = withData.ID
+	erc223Log.Data = data
+
+	client := &fakeLogClient{logs: []types.Log{newTransferLog(t, 10, 0, 5), erc223Log}}
+
+	// only the overload with data is tracked
+	received := runContractLogs(t, client, overloadedABI, testContractAddress.Hex(), []string{"Transfer(address,address,uint256,bytes)"}, 1, 20)
+	require.Equal(t, [][]common.Hash{{withData.ID}}, client.queries[0].Topics)
+	require.Len(t, received, 1)
+	require.Equal(t, "Transfer", received[0].EventName)
+	require.Equal(t, "Transfer(address,address,uint256,bytes)", received[0].EventSignature)
+	require.Equal(t, []byte("memo"), received[0].Data["data"])
+
+	// both overloads are dispatched by signature
+	client = &fakeLogClient{logs: client.logs}
+	received = runContractLogs(t, client, overloadedABI, testContractAddress.Hex(), []string{"Transfer(address,address,uint256)", "Transfer(address,address,uint256,bytes)"}, 1, 20)
+	require.Len(t, received, 2)
+	require.Equal(t, "Transfer(address,address,uint256)", received[0].EventSignature)
+	require.Equal(t, big.NewInt(5), received[0].Data["value"])
+	require.Equal(t, "Transfer(address,address,uint256,bytes)", received[1].EventSignature)
+	require.Equal(t, big.NewInt(7), received[1].Data["value"])
+}
+
+func Test_GetLogs_RawLogs(t *testing.T) {
+	unknown := newTransferLog(t, 5, 0, 20)
+	unknown.Topics[0] = common.HexToHash("0x01")
+
+	client := &fakeLogClient{
+		logs: []types.Log{
+			newTransferLog(t, 3, 0, 10),
+			unknown,
+		},
+	}
+
+	logsChannel := make(chan []LogData)
+	received := make([]LogData, 0)
+	done := make(chan struct{})
+	go func() {
+		for batch := range logsChannel {
+			received = append(received, batch...)
+		}
+		close(done)
+	}()
+
+	from := int64(0)
+	to := int64(10)
+	count, _, err := GetLogs(context.Background(), Config{
+		Client:           client,
+		ABI:              transferABI,
+		EventSignatures:  []string{"Transfer(address,address,uint256)"},
+		Address:          testContractAddress.Hex(),
+		FromBlockNumber:  &from,
+		ToBlockNumber:    &to,
+		LogsChannel:      logsChannel,
+		BatchInterval:    time.Millisecond,
+		RateLimitBackoff: time.Millisecond,
+		RawLogs:          true,
+	})
+	<-done
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	// every log of the address is requested
+	require.Len(t, client.queries, 1)
+	require.Nil(t, client.queries[0].Topics)
+
+	// the logs of tracked events are decoded, the other ones are sent raw
+	require.Len(t, received, 2)
+	require.False(t, received[0].IsRaw())
+	require.Equal(t, big.NewInt(10), received[0].Data["value"])
+	require.True(t, received[1].IsRaw())
+	require.Nil(t, received[1].Data)
+	require.Equal(t, unknown.Topics, received[1].Topics)
+	require.Equal(t, unknown.Data, received[1].RawData)
+	require.Equal(t, uint64(1700000000), received[1].BlockTimestamp)
+}
+
+func Test_GetLogs_Addresses(t *testing.T) {
+	tokenA := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	tokenB := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	other := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+
+	logA := newTransferLog(t, 2, 0, 10)
+	logA.Address = tokenA
+	logB := newTransferLog(t, 4, 0, 20)
+	logB.Address = tokenB
+	logOther := newTransferLog(t, 6, 0, 30)
+	logOther.Address = other
+
+	run := func(c Config) ([]LogData, *fakeLogClient) {
+		client := &fakeLogClient{logs: []types.Log{logA, logB, logOther}}
+
+		logsChannel := make(chan []LogData)
+		received := make([]LogData, 0)
+		done := make(chan struct{})
+		go func() {
+			for batch := range logsChannel {
+				received = append(received, batch...)
+			}
+			close(done)
+		}()
+
+		from := int64(0)
+		to := int64(10)
+		c.Client = client
+		c.ABI = transferABI
+		c.EventSignatures = []string{"Transfer(address,address,uint256)"}
+		c.FromBlockNumber = &from
+		c.ToBlockNumber = &to
+		c.LogsChannel = logsChannel
+		c.BatchInterval = time.Millisecond
+		c.RateLimitBackoff = time.Millisecond
+		_, _, err := GetLogs(context.Background(), c)
+		<-done
+		require.NoError(t, err)
+
+		return received, client
+	}
+
+	// the address set is requested with a single query and each log keeps its emitter
+	received, client := run(Config{Addresses: []string{tokenA.Hex(), tokenB.Hex(), tokenA.Hex()}})
+	require.Len(t, client.queries, 1)
+	require.Equal(t, []common.Address{tokenA, tokenB}, client.queries[0].Addresses)
+	require.Len(t, received, 2)
+	require.Equal(t, tokenA, received[0].Address)
+	require.Equal(t, tokenB, received[1].Address)
+
+	// every address of the network is requested without addresses in the query
+	received, client = run(Config{AllAddresses: true})
+	require.Len(t, client.queries, 1)
+	require.Nil(t, client.queries[0].Addresses)
+	require.Len(t, received, 3)
+	require.Equal(t, other, received[2].Address)
+
+	// an address is required unless every address is requested
+	_, _, err := GetLogs(context.Background(), Config{
+		Client:          &fakeLogClient{},
+		ABI:             transferABI,
+		EventSignatures: []string{"Transfer(address,address,uint256)"},
+	})
+	require.EqualError(t, err, "invalid Address config param")
+}
+
+func Test_GetLogs_Batches(t *testing.T) {
+	client := &fakeLogClient{
+		maxBlocks: 25,
+		rangeErr: func(from int64, to int64) error {
+			return errors.New("query returned more than 10000 results")
+		},
+		logs: []types.Log{
+			newTransferLog(t, 5, 0, 10),
+			newTransferLog(t, 40, 0, 20),
+		},
+	}
+
+	batchesChannel := make(chan LogBatch)
+	batches := make([]LogBatch, 0)
+	done := make(chan struct{})
+	go func() {
+		for batch := range batchesChannel {
+			batches = append(batches, batch)
+		}
+		close(done)
+	}()
+
+	from := int64(0)
+	to := int64(60)
+	count, latest, err := GetLogs(context.Background(), Config{
+		Client:           client,
+		ABI:              transferABI,
+		EventSignatures:  []string{"Transfer(address,address,uint256)"},
+		Address:          testContractAddress.Hex(),
+		FromBlockNumber:  &from,
+		ToBlockNumber:    &to,
+		MaxRetry:         1,
+		BatchesChannel:   batchesChannel,
+		BatchInterval:    time.Millisecond,
+		RateLimitBackoff: time.Millisecond,
+	})
+	<-done
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+	require.Equal(t, int64(60), latest)
+
+	// the batches cover the whole range without gaps or overlaps, each one with its logs
+	next := from
+	logs := 0
+	for _, batch := range batches {
+		require.Equal(t, next, batch.FromBlockNumber)
+		require.True(t, batch.ToBlockNumber >= batch.FromBlockNumber)
+		for _, l := range batch.Logs {
+			require.True(t, int64(l.BlockNumber) >= batch.FromBlockNumber && int64(l.BlockNumber) <= batch.ToBlockNumber)
+		}
+		logs += len(batch.Logs)
+		next = batch.ToBlockNumber + 1
+	}
+	require.Equal(t, to+1, next)
+	require.Equal(t, 2, logs)
+}
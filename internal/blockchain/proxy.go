@@ -0,0 +1,88 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ProxyKind is the standard followed by a proxy contract to store its implementation.
+type ProxyKind string
+
+const (
+	ProxyNone    ProxyKind = ""
+	ProxyEIP1967 ProxyKind = "eip1967"
+	ProxyEIP1822 ProxyKind = "eip1822"
+	ProxyBeacon  ProxyKind = "beacon"
+)
+
+var (
+	// eip1967ImplementationSlot is bytes32(uint256(keccak256("eip1967.proxy.implementation")) - 1)
+	eip1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+	// eip1967BeaconSlot is bytes32(uint256(keccak256("eip1967.proxy.beacon")) - 1)
+	eip1967BeaconSlot = common.HexToHash("0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50")
+	// eip1822ProxiableSlot is keccak256("PROXIABLE")
+	eip1822ProxiableSlot = common.HexToHash("0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7")
+	// beaconImplementationSelector is the selector of implementation() of the beacon
+	beaconImplementationSelector = hexutil.MustDecode("0x5c60da1b")
+)
+
+// ProxyClient is the subset of the node client used to find the implementation of a proxy.
+type ProxyClient interface {
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// ProxyImplementation returns the implementation of the contract when it is an EIP-1967,
+// EIP-1822 or beacon proxy, reading the storage slot defined by each standard. It returns
+// ProxyNone when the contract isn't a proxy.
+func ProxyImplementation(ctx context.Context, client ProxyClient, address common.Address) (common.Address, ProxyKind, error) {
+	implementation, err := storageAddress(ctx, client, address, eip1967ImplementationSlot)
+	if err != nil {
+		return common.Address{}, ProxyNone, err
+	}
+	if implementation != (common.Address{}) {
+		return implementation, ProxyEIP1967, nil
+	}
+
+	// beacon proxies store the beacon, which returns the implementation shared by its proxies
+	beacon, err := storageAddress(ctx, client, address, eip1967BeaconSlot)
+	if err != nil {
+		return common.Address{}, ProxyNone, err
+	}
+	if beacon != (common.Address{}) {
+		b, err := client.CallContract(ctx, ethereum.CallMsg{To: &beacon, Data: beaconImplementationSelector}, nil)
+		if err != nil {
+			return common.Address{}, ProxyNone, err
+		}
+		if len(b) >= common.HashLength {
+			implementation = common.BytesToAddress(b[:common.HashLength])
+		}
+		if implementation != (common.Address{}) {
+			return implementation, ProxyBeacon, nil
+		}
+	}
+
+	implementation, err = storageAddress(ctx, client, address, eip1822ProxiableSlot)
+	if err != nil {
+		return common.Address{}, ProxyNone, err
+	}
+	if implementation != (common.Address{}) {
+		return implementation, ProxyEIP1822, nil
+	}
+
+	return common.Address{}, ProxyNone, nil
+}
+
+// storageAddress reads the address stored in the slot of the contract.
+func storageAddress(ctx context.Context, client ProxyClient, address common.Address, slot common.Hash) (common.Address, error) {
+	b, err := client.StorageAt(ctx, address, slot, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return common.BytesToAddress(b), nil
+}
@@ -0,0 +1,96 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// HeaderReader is the subset of the node client used to read canonical block headers.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// BlockRef is a block number and the hash that was stored for it when its logs were indexed.
+type BlockRef struct {
+	Number int64
+	Hash   common.Hash
+}
+
+// FindReorg compares the stored block references against the canonical chain of the node
+// and returns the first block number to ingest again, or nil when every reference is still
+// canonical.
+//
+// Because each canonical header commits to its parent, once the newest reference matches
+// every older one does as well, so the walk goes from newest to oldest and stops at the
+// first match. The references are sparse, so the fork is right after the newest matching
+// reference, or at the oldest reference when none of them matches.
+func FindReorg(ctx context.Context, client HeaderReader, refs []BlockRef) (*int64, error) {
+	if client == nil {
+		return nil, errors.New("invalid client param")
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	// sort references from newest to oldest
+	sorted := make([]BlockRef, len(refs))
+	copy(sorted, refs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Number > sorted[j].Number
+	})
+
+	var forkBlock *int64
+	for _, ref := range sorted {
+		header, err := client.HeaderByNumber(ctx, big.NewInt(ref.Number))
+		if err != nil {
+			return nil, err
+		}
+
+		// stop walking when the stored hash is still part of the canonical chain
+		if header.Hash() == ref.Hash {
+			if forkBlock != nil {
+				number := ref.Number + 1
+				forkBlock = &number
+			}
+			break
+		}
+
+		number := ref.Number
+		forkBlock = &number
+	}
+
+	return forkBlock, nil
+}
+
+// HeaderMemo reads the canonical headers by number once, so the references of the events of
+// the same contract are compared with a single request per block.
+type HeaderMemo struct {
+	client  HeaderReader
+	headers map[int64]*types.Header
+}
+
+func NewHeaderMemo(client HeaderReader) *HeaderMemo {
+	return &HeaderMemo{
+		client:  client,
+		headers: make(map[int64]*types.Header),
+	}
+}
+
+func (hm *HeaderMemo) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if header, ok := hm.headers[number.Int64()]; ok {
+		return header, nil
+	}
+
+	header, err := hm.client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	hm.headers[number.Int64()] = header
+
+	return header, nil
+}
@@ -0,0 +1,114 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jaekwon/testify/require"
+)
+
+// fakeChain returns the canonical headers of a chain whose blocks from forkBlock on were
+// replaced by a reorganization.
+type fakeChain struct {
+	forkBlock int64
+	requests  int
+}
+
+func (f *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	f.requests++
+	return chainHeader(number.Int64(), number.Int64() >= f.forkBlock), nil
+}
+
+func chainHeader(number int64, reorged bool) *types.Header {
+	header := &types.Header{Number: big.NewInt(number)}
+	if reorged {
+		header.Extra = []byte("reorged")
+	}
+
+	return header
+}
+
+// storedRefs returns the references stored before the reorganization for the given blocks.
+func storedRefs(numbers ...int64) []BlockRef {
+	refs := make([]BlockRef, 0)
+	for _, n := range numbers {
+		refs = append(refs, BlockRef{Number: n, Hash: chainHeader(n, false).Hash()})
+	}
+
+	return refs
+}
+
+func Test_FindReorg(t *testing.T) {
+	testCases := []struct {
+		name      string
+		forkBlock int64
+		refs      []BlockRef
+		expected  *int64
+		requests  int
+	}{
+		{
+			name:      "without references",
+			forkBlock: 1,
+			refs:      nil,
+			expected:  nil,
+			requests:  0,
+		},
+		{
+			name:      "every reference is canonical",
+			forkBlock: 200,
+			refs:      storedRefs(90, 100, 110),
+			expected:  nil,
+			requests:  1,
+		},
+		{
+			name:      "the fork is right after the newest canonical reference",
+			forkBlock: 105,
+			refs:      storedRefs(110, 90, 100, 120),
+			expected:  int64Ptr(101),
+			requests:  3,
+		},
+		{
+			name:      "no reference is canonical",
+			forkBlock: 50,
+			refs:      storedRefs(90, 100, 110),
+			expected:  int64Ptr(90),
+			requests:  3,
+		},
+		{
+			name:      "the checkpoint block without logs was reorganized",
+			forkBlock: 130,
+			refs:      storedRefs(100, 110, 140),
+			expected:  int64Ptr(111),
+			requests:  2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := &fakeChain{forkBlock: tc.forkBlock}
+
+			forkBlock, err := FindReorg(context.Background(), chain, tc.refs)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, forkBlock)
+			require.Equal(t, tc.requests, chain.requests)
+		})
+	}
+}
+
+func Test_HeaderMemo(t *testing.T) {
+	chain := &fakeChain{forkBlock: 105}
+	headers := NewHeaderMemo(chain)
+	ctx := context.Background()
+
+	// the references of two events of the contract share the header requests
+	forkBlock, err := FindReorg(ctx, headers, storedRefs(100, 110))
+	require.NoError(t, err)
+	require.Equal(t, int64Ptr(101), forkBlock)
+
+	forkBlock, err = FindReorg(ctx, headers, storedRefs(90, 110))
+	require.NoError(t, err)
+	require.Equal(t, int64Ptr(91), forkBlock)
+	require.Equal(t, 3, chain.requests)
+}
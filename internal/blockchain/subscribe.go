@@ -0,0 +1,165 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LogSubscriber is the subset of the node client used to stream new logs, only the clients
+// connected through WebSocket support subscriptions.
+type LogSubscriber interface {
+	LogClient
+	SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+}
+
+type SubscribeConfig struct {
+	Client          LogSubscriber
+	ABI             string
+	EventSignatures []string
+	Address         string
+	// Headers is the cache of block headers, a new one is used when it is nil
+	Headers *HeaderCache
+	// TopicFilters are the filters on the indexed arguments by event signature, like in Config
+	TopicFilters map[string][]TopicFilter
+	// RawLogs streams every log of the address, like in Config
+	RawLogs bool
+}
+
+// LogSubscription streams the decoded logs of the events of a contract as they are mined.
+type LogSubscription struct {
+	sub  ethereum.Subscription
+	logs chan LogData
+	err  chan error
+
+	quit chan struct{}
+	once sync.Once
+}
+
+// IsWebSocketURL returns true when the node url uses the WebSocket protocol, the only one
+// supporting log subscriptions.
+func IsWebSocketURL(nodeURL string) bool {
+	url := strings.ToLower(nodeURL)
+	return strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://")
+}
+
+// SubscribeLogs subscribes to the new logs of the events of the contract using eth_subscribe.
+// The subscription is established when it returns, so the logs mined from that moment are
+// delivered by Logs. Logs reverted by a reorganization are delivered again with Removed set.
+// When the subscription fails the error is sent once to Err and no more logs are delivered.
+func SubscribeLogs(ctx context.Context, c SubscribeConfig) (*LogSubscription, error) {
+	// check config params
+	if c.Client == nil {
+		return nil, errors.New("invalid Client config param")
+	}
+	if c.ABI == "" {
+		return nil, errors.New("invalid ABI config param")
+	}
+	if len(c.EventSignatures) == 0 {
+		return nil, errors.New("invalid EventSignatures config param")
+	}
+	if c.Address == "" {
+		return nil, errors.New("invalid Address config param")
+	}
+
+	// prepare the events definition using ABI definition
+	filter, err := newEventFilter(c.ABI, c.EventSignatures, c.TopicFilters)
+	if err != nil {
+		return nil, err
+	}
+	if c.RawLogs {
+		filter.keepRawLogs()
+	}
+
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{
+			common.HexToAddress(c.Address),
+		},
+		Topics: filter.topics,
+	}
+
+	rawLogs := make(chan types.Log)
+	sub, err := c.Client.SubscribeFilterLogs(ctx, query, rawLogs)
+	if err != nil {
+		return nil, ClassifyError(err)
+	}
+
+	s := &LogSubscription{
+		sub:  sub,
+		logs: make(chan LogData),
+		err:  make(chan error, 1),
+		quit: make(chan struct{}),
+	}
+
+	go func() {
+		defer sub.Unsubscribe()
+
+		// block headers by block hash, several logs usually share the same block
+		headers := c.Headers
+		if headers == nil {
+			headers = NewHeaderCache(0)
+		}
+
+		for {
+			select {
+			case <-s.quit:
+				return
+
+			case <-ctx.Done():
+				s.err <- ctx.Err()
+				return
+
+			case err := <-sub.Err():
+				if err == nil {
+					err = errors.New("subscription closed")
+				}
+				s.err <- ClassifyError(err)
+				return
+
+			case vLog := <-rawLogs:
+				// logs of events that aren't tracked are skipped
+				d, ok, err := filter.toLogData(ctx, c.Client, headers, vLog)
+				if err != nil {
+					s.err <- ClassifyError(err)
+					return
+				}
+				if !ok {
+					continue
+				}
+
+				select {
+				case s.logs <- d:
+				case <-s.quit:
+					return
+				case <-ctx.Done():
+					s.err <- ctx.Err()
+					return
+				}
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+// Logs returns the channel with the decoded logs of the subscription.
+func (s *LogSubscription) Logs() <-chan LogData {
+	return s.logs
+}
+
+// Err returns the channel receiving the error that ended the subscription.
+func (s *LogSubscription) Err() <-chan error {
+	return s.err
+}
+
+// Unsubscribe stops the subscription, it's safe to call it several times.
+func (s *LogSubscription) Unsubscribe() {
+	s.once.Do(func() {
+		close(s.quit)
+	})
+}
@@ -0,0 +1,98 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jaekwon/testify/require"
+)
+
+// fakeSubscription is the subscription returned by fakeLogSubscriber.
+type fakeSubscription struct {
+	err chan error
+}
+
+func (s *fakeSubscription) Unsubscribe() {}
+
+func (s *fakeSubscription) Err() <-chan error {
+	return s.err
+}
+
+// fakeLogSubscriber sends the given logs to the subscription channel and then fails with subErr.
+type fakeLogSubscriber struct {
+	fakeLogClient
+
+	subLogs []types.Log
+	subErr  error
+	query   ethereum.FilterQuery
+}
+
+func (f *fakeLogSubscriber) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	f.query = q
+	sub := &fakeSubscription{err: make(chan error, 1)}
+
+	go func() {
+		for _, l := range f.subLogs {
+			ch <- l
+		}
+		sub.err <- f.subErr
+	}()
+
+	return sub, nil
+}
+
+func Test_IsWebSocketURL(t *testing.T) {
+	require.True(t, IsWebSocketURL("wss://mainnet.infura.io/ws/v3/key"))
+	require.True(t, IsWebSocketURL("WS://localhost:8546"))
+	require.False(t, IsWebSocketURL("https://mainnet.infura.io/v3/key"))
+	require.False(t, IsWebSocketURL("http://localhost:8545"))
+}
+
+func Test_SubscribeLogs(t *testing.T) {
+	removed := newTransferLog(t, 10, 1, 7)
+	removed.Removed = true
+
+	client := &fakeLogSubscriber{
+		subLogs: []types.Log{newTransferLog(t, 10, 0, 5), removed},
+		subErr:  errors.New("websocket: close 1006 (abnormal closure)"),
+	}
+
+	sub, err := SubscribeLogs(context.Background(), SubscribeConfig{
+		Client:          client,
+		ABI:             transferABI,
+		EventSignatures: []string{"Transfer(address,address,uint256)"},
+		Address:         testContractAddress.Hex(),
+	})
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	// the subscription filters by address and topic0 like the eth_getLogs walk
+	require.Len(t, client.query.Topics, 1)
+	require.Nil(t, client.query.FromBlock)
+
+	received := make([]LogData, 0)
+	var subErr error
+	timeout := time.After(time.Second)
+	for subErr == nil {
+		select {
+		case l := <-sub.Logs():
+			received = append(received, l)
+		case subErr = <-sub.Err():
+		case <-timeout:
+			t.Fatal("subscription didn't finish")
+		}
+	}
+
+	require.Len(t, received, 2)
+	require.Equal(t, "Transfer(address,address,uint256)", received[0].EventSignature)
+	require.Equal(t, big.NewInt(5), received[0].Data["value"])
+	require.False(t, received[0].Removed)
+	require.True(t, received[1].Removed)
+	require.Equal(t, uint64(1700000000), received[1].BlockTimestamp)
+	require.Contains(t, subErr.Error(), "abnormal closure")
+}
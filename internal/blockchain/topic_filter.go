@@ -0,0 +1,150 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TopicFilter restricts the logs of an event by the values of its indexed arguments. Each
+// position has the allowed topics of an indexed argument, in the order they're defined in the
+// event, and an empty position matches any value.
+type TopicFilter [][]common.Hash
+
+// NewTopicFilter builds the topic filter of the event from the allowed values of its indexed
+// arguments by name. Values are given as strings: addresses and bytes as hex, integers in
+// decimal or hex and bools as true or false. Values of dynamic types are hashed like the
+// node does, and any value can also be given as the 32 bytes topic.
+func NewTopicFilter(event abi.Event, args map[string][]string) (TopicFilter, error) {
+	indexed := make([]abi.Argument, 0)
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+
+	filter := make(TopicFilter, len(indexed))
+	for name, values := range args {
+		position := -1
+		for i, input := range indexed {
+			if input.Name == name {
+				position = i
+				break
+			}
+		}
+		if position < 0 {
+			return nil, fmt.Errorf("argument=%s isn't an indexed argument of event=%s", name, event.Sig)
+		}
+
+		for _, value := range values {
+			topic, err := toTopic(indexed[position].Type, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value=%s of argument=%s: %s", value, name, err.Error())
+			}
+			filter[position] = append(filter[position], topic)
+		}
+	}
+
+	// trailing positions without values are removed, they match any value
+	for len(filter) > 0 && len(filter[len(filter)-1]) == 0 {
+		filter = filter[:len(filter)-1]
+	}
+
+	return filter, nil
+}
+
+// Match returns true when the topics of the log have one of the allowed values on every
+// position of the filter. The topics of non anonymous events start with the event id.
+func (f TopicFilter) Match(topics []common.Hash, anonymous bool) bool {
+	offset := 1
+	if anonymous {
+		offset = 0
+	}
+
+	for i, allowed := range f {
+		if len(allowed) == 0 {
+			continue
+		}
+		if offset+i >= len(topics) {
+			return false
+		}
+
+		found := false
+		for _, topic := range allowed {
+			if topics[offset+i] == topic {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// toTopic encodes the value of an indexed argument as the topic of the log.
+func toTopic(t abi.Type, value string) (common.Hash, error) {
+	value = strings.TrimSpace(value)
+
+	switch t.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(value) {
+			return common.Hash{}, fmt.Errorf("invalid address")
+		}
+		return common.BytesToHash(common.HexToAddress(value).Bytes()), nil
+
+	case abi.IntTy, abi.UintTy:
+		n, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			return common.Hash{}, fmt.Errorf("invalid integer")
+		}
+		if t.T == abi.UintTy && n.Sign() < 0 {
+			return common.Hash{}, fmt.Errorf("negative unsigned integer")
+		}
+		return common.BytesToHash(math.U256Bytes(n)), nil
+
+	case abi.BoolTy:
+		switch strings.ToLower(value) {
+		case "true":
+			return common.BigToHash(big.NewInt(1)), nil
+		case "false":
+			return common.Hash{}, nil
+		}
+		return common.Hash{}, fmt.Errorf("invalid bool")
+
+	case abi.FixedBytesTy:
+		b, err := hexutil.Decode(value)
+		if err != nil || len(b) > t.Size {
+			return common.Hash{}, fmt.Errorf("invalid bytes%d", t.Size)
+		}
+		var topic common.Hash
+		copy(topic[:], b)
+		return topic, nil
+
+	case abi.StringTy:
+		return crypto.Keccak256Hash([]byte(value)), nil
+
+	case abi.BytesTy:
+		b, err := hexutil.Decode(value)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("invalid bytes")
+		}
+		return crypto.Keccak256Hash(b), nil
+	}
+
+	// arrays and tuples are only supported as the hash of their encoding
+	b, err := hexutil.Decode(value)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("values of type %s must be given as the 32 bytes topic", t.String())
+	}
+
+	return common.BytesToHash(b), nil
+}
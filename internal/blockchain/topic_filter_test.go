@@ -0,0 +1,108 @@
+package blockchain
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jaekwon/testify/require"
+)
+
+func Test_NewTopicFilter(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(transferABI))
+	require.NoError(t, err)
+	transfer := parsed.Events["Transfer"]
+
+	filter, err := NewTopicFilter(transfer, map[string][]string{
+		"to": {"0x0000000000000000000000000000000000000002", "0x0000000000000000000000000000000000000003"},
+	})
+	require.NoError(t, err)
+	require.Len(t, filter, 2)
+	require.Empty(t, filter[0])
+	require.Equal(t, common.BytesToHash(common.HexToAddress("0x02").Bytes()), filter[1][0])
+
+	// the log goes from 0x01 to 0x02
+	l := newTransferLog(t, 1, 0, 10)
+	require.True(t, filter.Match(l.Topics, false))
+
+	filter, err = NewTopicFilter(transfer, map[string][]string{
+		"from": {"0x0000000000000000000000000000000000000002"},
+	})
+	require.NoError(t, err)
+	require.Len(t, filter, 1)
+	require.False(t, filter.Match(l.Topics, false))
+
+	// only indexed arguments can be filtered
+	_, err = NewTopicFilter(transfer, map[string][]string{"value": {"10"}})
+	require.Error(t, err)
+
+	_, err = NewTopicFilter(transfer, map[string][]string{"to": {"not an address"}})
+	require.Error(t, err)
+}
+
+func Test_GetLogs_TopicFilters(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(transferABI))
+	require.NoError(t, err)
+	transfer := parsed.Events["Transfer"]
+
+	other := newTransferLog(t, 5, 0, 20)
+	other.Topics[2] = common.BytesToHash(common.HexToAddress("0x03").Bytes())
+	client := &fakeLogClient{
+		logs: []types.Log{
+			newTransferLog(t, 3, 0, 10),
+			other,
+		},
+	}
+
+	toTwo, err := NewTopicFilter(transfer, map[string][]string{"to": {"0x0000000000000000000000000000000000000002"}})
+	require.NoError(t, err)
+	fromOne, err := NewTopicFilter(transfer, map[string][]string{"from": {"0x0000000000000000000000000000000000000001"}})
+	require.NoError(t, err)
+
+	run := func(filters ...TopicFilter) []LogData {
+		logsChannel := make(chan []LogData)
+		received := make([]LogData, 0)
+		done := make(chan struct{})
+		go func() {
+			for batch := range logsChannel {
+				received = append(received, batch...)
+			}
+			close(done)
+		}()
+
+		from := int64(0)
+		to := int64(10)
+		_, _, err := GetLogs(context.Background(), Config{
+			Client:          client,
+			ABI:             transferABI,
+			EventSignatures: []string{transfer.Sig},
+			Address:         testContractAddress.Hex(),
+			FromBlockNumber: &from,
+			ToBlockNumber:   &to,
+			LogsChannel:     logsChannel,
+			BatchInterval:   time.Millisecond,
+			TopicFilters:    map[string][]TopicFilter{transfer.Sig: filters},
+		})
+		<-done
+		require.NoError(t, err)
+
+		return received
+	}
+
+	// the query is filtered by the indexed argument and logs not matching it are skipped
+	received := run(toTwo)
+	require.Len(t, received, 1)
+	require.Equal(t, uint64(3), received[0].BlockNumber)
+	query := client.queries[len(client.queries)-1]
+	require.Equal(t, [][]common.Hash{{transfer.ID}, nil, toTwo[1]}, query.Topics)
+
+	// filters of several users are OR'ed, the query only restricts the positions all of them restrict
+	received = run(toTwo, fromOne)
+	require.Len(t, received, 2)
+	query = client.queries[len(client.queries)-1]
+	require.Equal(t, [][]common.Hash{{transfer.ID}}, query.Topics)
+}
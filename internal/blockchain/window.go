@@ -0,0 +1,118 @@
+package blockchain
+
+import "sync"
+
+const (
+	DefaultWindowSize     = int64(1000)
+	DefaultWindowMinSize  = int64(1)
+	DefaultWindowMaxSize  = int64(100000)
+	DefaultWindowIncrease = int64(100)
+)
+
+// Window is the number of blocks requested on each eth_getLogs call. It follows AIMD: the size
+// grows by Increase blocks after each successful batch and it's halved, or reduced to the range
+// suggested by the provider, when the node refuses a range. A Window is safe for concurrent use
+// so the events synced against the same node share what was learned.
+type Window struct {
+	mu sync.Mutex
+
+	size     int64
+	min      int64
+	max      int64
+	increase int64
+	// learnedMax is the max number of blocks told by the provider, zero until it tells it
+	learnedMax int64
+}
+
+type WindowConfig struct {
+	Size     int64
+	Min      int64
+	Max      int64
+	Increase int64
+}
+
+func NewWindow(conf WindowConfig) *Window {
+	w := &Window{
+		size:     conf.Size,
+		min:      conf.Min,
+		max:      conf.Max,
+		increase: conf.Increase,
+	}
+
+	if w.min <= 0 {
+		w.min = DefaultWindowMinSize
+	}
+	if w.max <= 0 {
+		w.max = DefaultWindowMaxSize
+	}
+	if w.max < w.min {
+		w.max = w.min
+	}
+	if w.increase <= 0 {
+		w.increase = DefaultWindowIncrease
+	}
+	if w.size <= 0 {
+		w.size = DefaultWindowSize
+	}
+	w.size = w.clamp(w.size)
+
+	return w
+}
+
+// Size returns the current number of blocks of the window.
+func (w *Window) Size() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.size
+}
+
+// LearnedMax returns the max number of blocks told by the provider since the window was
+// created, or zero when the provider didn't tell it.
+func (w *Window) LearnedMax() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.learnedMax
+}
+
+// Grow increases the window additively after a successful batch.
+func (w *Window) Grow() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.size = w.clamp(w.size + w.increase)
+	return w.size
+}
+
+// Shrink reduces the window multiplicatively after the node refused the given range, using the
+// range suggested by the provider when it is lower. When the provider tells the max number of
+// blocks allowed, the window never grows above it again.
+func (w *Window) Shrink(rangeErr *RangeTooLargeError, fromBlock int64, endBlock int64) int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if rangeErr.MaxBlocks != nil && *rangeErr.MaxBlocks >= w.min && *rangeErr.MaxBlocks < w.max {
+		w.max = *rangeErr.MaxBlocks
+		w.learnedMax = w.max
+	}
+
+	span := nextSpan(rangeErr, fromBlock, endBlock)
+	if half := w.size / 2; half < span {
+		span = half
+	}
+
+	w.size = w.clamp(span)
+	return w.size
+}
+
+func (w *Window) clamp(size int64) int64 {
+	if size < w.min {
+		return w.min
+	}
+	if size > w.max {
+		return w.max
+	}
+
+	return size
+}
@@ -0,0 +1,86 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jaekwon/testify/require"
+)
+
+func Test_Window_AIMD(t *testing.T) {
+	w := NewWindow(WindowConfig{Size: 100, Max: 1000, Increase: 10})
+	require.Equal(t, int64(100), w.Size())
+
+	// additive increase
+	require.Equal(t, int64(110), w.Grow())
+
+	// multiplicative decrease without suggestion
+	rangeErr := ClassifyError(errors.New("query returned more than 10000 results")).(*RangeTooLargeError)
+	require.Equal(t, int64(55), w.Shrink(rangeErr, 0, 109))
+
+	// provider suggestion lower than the half
+	rangeErr = ClassifyError(fmt.Errorf("this block range should work: [0x0, 0x9]")).(*RangeTooLargeError)
+	require.Equal(t, int64(10), w.Shrink(rangeErr, 0, 54))
+
+	// provider max blocks caps the growth
+	rangeErr = ClassifyError(errors.New("eth_getLogs and eth_newFilter are limited to a 12 blocks range")).(*RangeTooLargeError)
+	w.Shrink(rangeErr, 0, 9)
+	for i := 0; i < 5; i++ {
+		w.Grow()
+	}
+	require.Equal(t, int64(12), w.Size())
+	require.Equal(t, int64(12), w.LearnedMax())
+
+	// the windows created with the restored max don't grow past it either
+	w = NewWindow(WindowConfig{Size: 10, Max: w.LearnedMax(), Increase: 10})
+	require.Equal(t, int64(12), w.Grow())
+	require.Equal(t, int64(0), w.LearnedMax())
+}
+
+func Test_GetLogs_Window(t *testing.T) {
+	client := &fakeLogClient{
+		maxBlocks: 25,
+		rangeErr: func(from int64, to int64) error {
+			return errors.New("query returned more than 10000 results")
+		},
+		logs: []types.Log{
+			newTransferLog(t, 5, 0, 10),
+			newTransferLog(t, 90, 0, 20),
+		},
+	}
+
+	logsChannel := make(chan []LogData)
+	go func() {
+		for range logsChannel {
+		}
+	}()
+
+	from := int64(0)
+	to := int64(100)
+	w := NewWindow(WindowConfig{Size: 40, Increase: 5})
+	count, latest, err := GetLogs(context.Background(), Config{
+		Client:           client,
+		ABI:              transferABI,
+		EventSignatures:  []string{"Transfer(address,address,uint256)"},
+		Address:          testContractAddress.Hex(),
+		FromBlockNumber:  &from,
+		ToBlockNumber:    &to,
+		MaxRetry:         1,
+		LogsChannel:      logsChannel,
+		BatchInterval:    time.Millisecond,
+		RateLimitBackoff: time.Millisecond,
+		Window:           w,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+	require.Equal(t, int64(100), latest)
+	requireContiguousQueries(t, client, 0, 100)
+
+	// the first request uses the window instead of the whole range
+	require.Equal(t, int64(39), client.queries[0].ToBlock.Int64())
+	require.True(t, w.Size() < 40, "size=%d", w.Size())
+}
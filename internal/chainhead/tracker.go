@@ -0,0 +1,408 @@
+package chainhead
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/rpcpool"
+	"github.com/darchlabs/synchronizer-v2/internal/wrapper"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultPollInterval = 5 * time.Second
+	DefaultMaxAge       = 1 * time.Minute
+
+	fetchTimeout = 10 * time.Second
+	// updatesBuffer is the number of updates kept for a subscriber that isn't reading them
+	updatesBuffer = 16
+)
+
+var (
+	ErrNoHead    = errors.New("no head")
+	ErrStaleHead = errors.New("stale head")
+)
+
+// Update is a new head of a network.
+type Update struct {
+	Network string
+	Head    *blockchain.Head
+}
+
+type Config struct {
+	Pool        *rpcpool.Pool
+	HeadConfigs map[string]blockchain.HeadConfig
+	// Networks are the networks followed since the tracker starts, other ones are followed
+	// the first time their head is requested
+	Networks []string
+	// PollInterval is the time between head requests of the networks without WebSocket node,
+	// and the time to wait before following a network again after an error
+	PollInterval time.Duration
+	// MaxAge is the age a followed head is served from the tracker, older heads are requested
+	// to the node again. 1 minute by default.
+	MaxAge  time.Duration
+	DateGen wrapper.DateGenerator
+}
+
+// network is the head followed for a network.
+type network struct {
+	name    string
+	nodeURL string
+
+	head      *blockchain.Head
+	updatedAt *time.Time
+	err       error
+}
+
+// NetworkHead is the state of the head followed for a network.
+type NetworkHead struct {
+	Network   string           `json:"network"`
+	Head      *blockchain.Head `json:"head"`
+	UpdatedAt *time.Time       `json:"updatedAt"`
+	Error     string           `json:"error"`
+}
+
+// Tracker follows the chain head of every network with a single newHeads subscription, or by
+// polling when the node of the network doesn't support WebSocket, and publishes each new head
+// to the subscribers. Consumers read the latest head from the tracker instead of requesting it
+// to the node on their own.
+type Tracker struct {
+	mu          sync.RWMutex
+	networks    map[string]*network
+	subscribers map[int]chan Update
+	nextID      int
+	started     bool
+
+	pool         *rpcpool.Pool
+	headConfigs  map[string]blockchain.HeadConfig
+	pollInterval time.Duration
+	maxAge       time.Duration
+	dateGen      wrapper.DateGenerator
+
+	quit chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+func New(conf Config) *Tracker {
+	t := &Tracker{
+		networks:     make(map[string]*network),
+		subscribers:  make(map[int]chan Update),
+		pool:         conf.Pool,
+		headConfigs:  conf.HeadConfigs,
+		pollInterval: conf.PollInterval,
+		maxAge:       conf.MaxAge,
+		dateGen:      conf.DateGen,
+		quit:         make(chan struct{}),
+	}
+
+	if t.pollInterval <= 0 {
+		t.pollInterval = DefaultPollInterval
+	}
+	if t.maxAge <= 0 {
+		t.maxAge = DefaultMaxAge
+	}
+	if t.dateGen == nil {
+		t.dateGen = time.Now
+	}
+	if t.headConfigs == nil {
+		t.headConfigs = make(map[string]blockchain.HeadConfig)
+	}
+
+	for _, name := range conf.Networks {
+		t.networks[name] = &network{name: name}
+	}
+
+	return t
+}
+
+// Start follows the heads of the configured networks.
+func (t *Tracker) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.started {
+		return
+	}
+	t.started = true
+
+	for _, n := range t.networks {
+		t.wg.Add(1)
+		go t.follow(n)
+	}
+}
+
+// Stop stops following the heads and closes the channels of the subscribers.
+func (t *Tracker) Stop() {
+	t.once.Do(func() {
+		close(t.quit)
+		t.wg.Wait()
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for id, ch := range t.subscribers {
+			close(ch)
+			delete(t.subscribers, id)
+		}
+	})
+}
+
+// Subscribe returns a channel receiving the new heads of every network and the function to
+// cancel the subscription. Updates are dropped while the channel is full, the latest head of
+// a network is always available from Head.
+func (t *Tracker) Subscribe() (<-chan Update, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id := t.nextID
+	t.nextID++
+	ch := make(chan Update, updatesBuffer)
+	t.subscribers[id] = ch
+
+	return ch, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		if ch, ok := t.subscribers[id]; ok {
+			close(ch)
+			delete(t.subscribers, id)
+		}
+	}
+}
+
+// Head returns the latest head of the network. The first time a network is requested it starts
+// following it, using the node url when the network hasn't configured nodes in the pool, and
+// the head is requested before returning. The node url is only used by the first request of
+// the network, the later ones follow it with the same node. When the followed head is older
+// than the max age it's requested again, and the head isn't served when the request fails.
+func (t *Tracker) Head(name string, nodeURL string) (*blockchain.Head, error) {
+	head, ok := t.fresh(name)
+	if ok {
+		return head, nil
+	}
+
+	t.mu.Lock()
+	n, ok := t.networks[name]
+	if !ok {
+		n = &network{name: name, nodeURL: nodeURL}
+		t.networks[name] = n
+		if t.started {
+			t.wg.Add(1)
+			go t.follow(n)
+		}
+	}
+	if n.nodeURL == "" {
+		n.nodeURL = nodeURL
+	}
+	t.mu.Unlock()
+
+	err := t.poll(n)
+	if err != nil {
+		t.setError(n, err)
+		if _, followed := t.Latest(name); followed {
+			err = errors.Wrap(ErrStaleHead, err.Error())
+		}
+		return nil, errors.Wrapf(err, "chainhead: Tracker.Head network=%s error", name)
+	}
+
+	head, ok = t.Latest(name)
+	if !ok {
+		return nil, errors.Wrapf(ErrNoHead, "chainhead: Tracker.Head network=%s error", name)
+	}
+
+	return head, nil
+}
+
+// fresh returns the latest head of the network when it was updated within the max age.
+func (t *Tracker) fresh(name string) (*blockchain.Head, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	n, ok := t.networks[name]
+	if !ok || n.head == nil || n.updatedAt == nil || t.dateGen().Sub(*n.updatedAt) > t.maxAge {
+		return nil, false
+	}
+	head := *n.head
+
+	return &head, true
+}
+
+// Latest returns the latest head of the network without requesting it to the node.
+func (t *Tracker) Latest(name string) (*blockchain.Head, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	n, ok := t.networks[name]
+	if !ok || n.head == nil {
+		return nil, false
+	}
+	head := *n.head
+
+	return &head, true
+}
+
+// Heads returns the state of the head of every followed network.
+func (t *Tracker) Heads() []*NetworkHead {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	heads := make([]*NetworkHead, 0)
+	for _, n := range t.networks {
+		h := &NetworkHead{
+			Network:   n.name,
+			UpdatedAt: n.updatedAt,
+		}
+		if n.head != nil {
+			head := *n.head
+			h.Head = &head
+		}
+		if n.err != nil {
+			h.Error = n.err.Error()
+		}
+		heads = append(heads, h)
+	}
+	sort.Slice(heads, func(i, j int) bool {
+		return heads[i].Network < heads[j].Network
+	})
+
+	return heads
+}
+
+// follow keeps the head of the network updated until the tracker stops. WebSocket nodes are
+// followed with a newHeads subscription, the other ones are polled. After an error the
+// network is polled again once the poll interval elapses.
+func (t *Tracker) follow(n *network) {
+	defer t.wg.Done()
+
+	for {
+		client, nodeURL, err := t.pool.Client(n.name, n.nodeURL)
+		if err == nil && blockchain.IsWebSocketURL(nodeURL) {
+			err = t.subscribe(n, client, nodeURL)
+		} else if err == nil {
+			err = t.fetch(n, client, nodeURL)
+		}
+		t.pool.Release(client)
+		if err != nil {
+			t.setError(n, err)
+			log.Printf("chainhead.follow network=%s error: %s \n", n.name, err.Error())
+		}
+
+		select {
+		case <-t.quit:
+			return
+		case <-time.After(t.pollInterval):
+		}
+	}
+}
+
+// subscribe follows the new heads of the node until the subscription fails or the tracker stops.
+func (t *Tracker) subscribe(n *network, client *blockchain.Client, nodeURL string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	headers := make(chan *types.Header)
+	sub, err := client.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		err = blockchain.ClassifyError(err)
+		t.pool.Report(nodeURL, err)
+		return errors.Wrap(err, "chainhead: Tracker.subscribe client.SubscribeNewHead error")
+	}
+	defer sub.Unsubscribe()
+
+	// the subscription only delivers the next heads, so the current one is requested
+	err = t.fetch(n, client, nodeURL)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-t.quit:
+			return nil
+
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			err = blockchain.ClassifyError(err)
+			t.pool.Report(nodeURL, err)
+			return errors.Wrap(err, "chainhead: Tracker.subscribe subscription error")
+
+		case header := <-headers:
+			headCtx, headCancel := context.WithTimeout(ctx, fetchTimeout)
+			head, err := blockchain.HeadFromHeader(headCtx, client, t.headConfigs[n.name], header)
+			headCancel()
+			t.pool.Report(nodeURL, err)
+			if err != nil {
+				return errors.Wrap(err, "chainhead: Tracker.subscribe blockchain.HeadFromHeader error")
+			}
+			t.update(n, head)
+		}
+	}
+}
+
+// poll requests the head of the network once.
+func (t *Tracker) poll(n *network) error {
+	client, nodeURL, err := t.pool.Client(n.name, n.nodeURL)
+	if err != nil {
+		return errors.Wrap(err, "chainhead: Tracker.poll t.pool.Client error")
+	}
+	defer t.pool.Release(client)
+
+	return t.fetch(n, client, nodeURL)
+}
+
+// fetch requests the header of the latest block and updates the head of the network.
+func (t *Tracker) fetch(n *network, client *blockchain.Client, nodeURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	header, err := client.HeaderByTag(ctx, blockchain.FinalityLatest)
+	if err == nil {
+		var head *blockchain.Head
+		head, err = blockchain.HeadFromHeader(ctx, client, t.headConfigs[n.name], header)
+		if err == nil {
+			t.update(n, head)
+		}
+	}
+	t.pool.Report(nodeURL, err)
+	if err != nil {
+		return errors.Wrap(err, "chainhead: Tracker.fetch error")
+	}
+
+	return nil
+}
+
+// update stores the head of the network and publishes it when the block changed.
+func (t *Tracker) update(n *network, head *blockchain.Head) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.dateGen()
+	n.updatedAt = &now
+	n.err = nil
+	if n.head != nil && n.head.Observed == head.Observed && n.head.Hash == head.Hash && n.head.Finalized == head.Finalized {
+		return
+	}
+	n.head = head
+
+	for _, ch := range t.subscribers {
+		published := *head
+		select {
+		case ch <- Update{Network: n.name, Head: &published}:
+		default:
+		}
+	}
+}
+
+func (t *Tracker) setError(n *network, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n.err = err
+}
@@ -0,0 +1,200 @@
+package chainhead
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/rpcpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jaekwon/testify/require"
+	"github.com/pkg/errors"
+)
+
+// newNode starts a JSON-RPC node of ethereum answering eth_getBlockByNumber with the header of
+// the given head for the latest tag, and of a block 5 blocks behind it for the finalized tag.
+// The node fails while the head is negative.
+func newNode(t *testing.T, head *int64) string {
+	return newChainNode(t, 1, head)
+}
+
+// newChainNode starts a newNode answering eth_chainId with the chain id.
+func newChainNode(t *testing.T, chainID int64, head *int64) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []interface{}   `json:"params"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method == "eth_chainId" {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x%x"}`, req.ID, chainID)
+			return
+		}
+		require.Equal(t, "eth_getBlockByNumber", req.Method)
+
+		number := atomic.LoadInt64(head)
+		if number < 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if req.Params[0] == string(blockchain.FinalityFinalized) {
+			number -= 5
+		}
+		header, err := json.Marshal(&types.Header{
+			Number:     big.NewInt(number),
+			Difficulty: big.NewInt(0),
+			Time:       uint64(1000 + number),
+		})
+		require.NoError(t, err)
+
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, header)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func Test_Tracker_Head(t *testing.T) {
+	head := int64(100)
+	url := newNode(t, &head)
+
+	pool := rpcpool.New(rpcpool.Config{
+		Endpoints: map[string][]string{"ethereum": {url}},
+		ChainIDs:  map[string]int64{"ethereum": 1, "polygon": 137},
+	})
+	defer pool.Stop()
+
+	tracker := New(Config{
+		Pool: pool,
+		HeadConfigs: map[string]blockchain.HeadConfig{
+			"ethereum": {Confirmations: 2, Tag: blockchain.FinalityFinalized},
+		},
+		Networks: pool.Networks(),
+	})
+	defer tracker.Stop()
+
+	// the head isn't known until the network is followed or requested
+	_, ok := tracker.Latest("ethereum")
+	require.False(t, ok)
+
+	h, err := tracker.Head("ethereum", "")
+	require.NoError(t, err)
+	require.Equal(t, int64(100), h.Observed)
+	require.Equal(t, int64(95), h.Finalized)
+	require.Equal(t, uint64(1100), h.Timestamp)
+	require.NotEmpty(t, h.Hash)
+
+	// networks without configured nodes are followed using the given node url
+	polygonURL := newChainNode(t, 137, &head)
+	h, err = tracker.Head("polygon", polygonURL)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), h.Observed)
+	require.Equal(t, int64(100), h.Finalized)
+
+	_, err = tracker.Head("fantom", "")
+	require.Error(t, err)
+}
+
+func Test_Tracker_StaleHead(t *testing.T) {
+	head := int64(100)
+	url := newNode(t, &head)
+
+	pool := rpcpool.New(rpcpool.Config{
+		Endpoints: map[string][]string{"ethereum": {url}},
+	})
+	defer pool.Stop()
+
+	now := time.Date(2023, 10, 26, 0, 0, 0, 0, time.UTC)
+	tracker := New(Config{
+		Pool:    pool,
+		MaxAge:  time.Minute,
+		DateGen: func() time.Time { return now },
+	})
+	defer tracker.Stop()
+
+	h, err := tracker.Head("ethereum", "")
+	require.NoError(t, err)
+	require.Equal(t, int64(100), h.Observed)
+
+	// the head is served from the tracker within the max age
+	atomic.StoreInt64(&head, 110)
+	now = now.Add(30 * time.Second)
+	h, err = tracker.Head("ethereum", "")
+	require.NoError(t, err)
+	require.Equal(t, int64(100), h.Observed)
+
+	// older heads are requested to the node again
+	now = now.Add(time.Minute)
+	h, err = tracker.Head("ethereum", "")
+	require.NoError(t, err)
+	require.Equal(t, int64(110), h.Observed)
+
+	// the stale head isn't served when the node fails
+	atomic.StoreInt64(&head, -1)
+	now = now.Add(2 * time.Minute)
+	_, err = tracker.Head("ethereum", "")
+	require.Error(t, err)
+	require.Equal(t, ErrStaleHead, errors.Cause(err))
+
+	// the followed head is still available without requesting it
+	h, ok := tracker.Latest("ethereum")
+	require.True(t, ok)
+	require.Equal(t, int64(110), h.Observed)
+}
+
+func Test_Tracker_Subscribe(t *testing.T) {
+	head := int64(100)
+	url := newNode(t, &head)
+
+	pool := rpcpool.New(rpcpool.Config{
+		Endpoints: map[string][]string{"ethereum": {url}},
+	})
+	defer pool.Stop()
+
+	tracker := New(Config{
+		Pool:         pool,
+		Networks:     pool.Networks(),
+		PollInterval: 10 * time.Millisecond,
+	})
+	updates, unsubscribe := tracker.Subscribe()
+	defer unsubscribe()
+
+	tracker.Start()
+	defer tracker.Stop()
+
+	next := func() Update {
+		select {
+		case u := <-updates:
+			return u
+		case <-time.After(time.Second):
+			require.FailNow(t, "no head update received")
+		}
+		return Update{}
+	}
+
+	u := next()
+	require.Equal(t, "ethereum", u.Network)
+	require.Equal(t, int64(100), u.Head.Observed)
+
+	// only new blocks are published
+	atomic.StoreInt64(&head, 101)
+	u = next()
+	require.Equal(t, int64(101), u.Head.Observed)
+
+	heads := tracker.Heads()
+	require.Len(t, heads, 1)
+	require.Equal(t, int64(101), heads[0].Head.Observed)
+	require.Empty(t, heads[0].Error)
+}
@@ -0,0 +1,147 @@
+package coverage
+
+import "sort"
+
+// Range is an inclusive block range.
+type Range struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// Blocks returns the number of blocks of the range.
+func (r Range) Blocks() int64 {
+	return r.To - r.From + 1
+}
+
+// Report is the coverage of an expected block range by the ranges recorded in the ledger.
+type Report struct {
+	// Covered are the recorded ranges merged, without overlaps and adjacent ranges joined
+	Covered []Range
+	// Holes are the blocks of the expected range that weren't recorded
+	Holes []Range
+	// Overlaps are the blocks recorded more than once
+	Overlaps []Range
+	// CoveredBlocks is the number of blocks of the expected range that were recorded
+	CoveredBlocks int64
+}
+
+// Analyze merges the recorded ranges and finds the holes of the expected range [from, to] and the
+// blocks recorded more than once. Invalid ranges, ending before they start, are ignored.
+func Analyze(ranges []Range, from int64, to int64) *Report {
+	sorted := sortRanges(ranges)
+
+	report := &Report{
+		Covered:  make([]Range, 0),
+		Holes:    make([]Range, 0),
+		Overlaps: make([]Range, 0),
+	}
+	for _, r := range sorted {
+		last := len(report.Covered) - 1
+		if last < 0 || r.From > report.Covered[last].To+1 {
+			report.Covered = append(report.Covered, r)
+			continue
+		}
+
+		// the blocks already covered by the previous ranges were recorded again
+		if r.From <= report.Covered[last].To {
+			report.Overlaps = appendRange(report.Overlaps, Range{From: r.From, To: min(r.To, report.Covered[last].To)})
+		}
+		if r.To > report.Covered[last].To {
+			report.Covered[last].To = r.To
+		}
+	}
+
+	// walk the expected range looking for the blocks between the covered ranges
+	next := from
+	for _, r := range report.Covered {
+		if next > to {
+			break
+		}
+		if r.To < next {
+			continue
+		}
+		if r.From > next {
+			hole := Range{From: next, To: min(r.From-1, to)}
+			report.Holes = append(report.Holes, hole)
+		}
+
+		covered := Range{From: max(r.From, next), To: min(r.To, to)}
+		if covered.To >= covered.From {
+			report.CoveredBlocks += covered.Blocks()
+		}
+		next = r.To + 1
+	}
+	if next <= to {
+		report.Holes = append(report.Holes, Range{From: next, To: to})
+	}
+
+	return report
+}
+
+// Subtract returns the blocks of the ranges that aren't in any of the excluded ranges.
+func Subtract(ranges []Range, excluded []Range) []Range {
+	result := make([]Range, 0)
+	excluded = Analyze(excluded, 0, -1).Covered
+	for _, r := range sortRanges(ranges) {
+		next := r.From
+		for _, e := range excluded {
+			if e.To < next || e.From > r.To {
+				continue
+			}
+			if e.From > next {
+				result = append(result, Range{From: next, To: e.From - 1})
+			}
+			next = e.To + 1
+		}
+		if next <= r.To {
+			result = append(result, Range{From: next, To: r.To})
+		}
+	}
+
+	return result
+}
+
+// sortRanges returns the valid ranges sorted by their first block.
+func sortRanges(ranges []Range) []Range {
+	sorted := make([]Range, 0)
+	for _, r := range ranges {
+		if r.To >= r.From {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].From == sorted[j].From {
+			return sorted[i].To < sorted[j].To
+		}
+		return sorted[i].From < sorted[j].From
+	})
+
+	return sorted
+}
+
+// appendRange appends the range joining it with the last one when they overlap or are adjacent.
+func appendRange(ranges []Range, r Range) []Range {
+	last := len(ranges) - 1
+	if last >= 0 && r.From <= ranges[last].To+1 {
+		if r.To > ranges[last].To {
+			ranges[last].To = r.To
+		}
+		return ranges
+	}
+
+	return append(ranges, r)
+}
+
+func min(a int64, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int64, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,51 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/jaekwon/testify/require"
+)
+
+func Test_Analyze(t *testing.T) {
+	// contiguous and adjacent ranges are merged without holes
+	report := Analyze([]Range{{From: 11, To: 20}, {From: 0, To: 10}, {From: 21, To: 30}}, 0, 30)
+	require.Equal(t, []Range{{From: 0, To: 30}}, report.Covered)
+	require.Empty(t, report.Holes)
+	require.Empty(t, report.Overlaps)
+	require.Equal(t, int64(31), report.CoveredBlocks)
+
+	// the blocks between the ranges and after the last one are holes
+	report = Analyze([]Range{{From: 0, To: 10}, {From: 15, To: 20}}, 0, 25)
+	require.Equal(t, []Range{{From: 0, To: 10}, {From: 15, To: 20}}, report.Covered)
+	require.Equal(t, []Range{{From: 11, To: 14}, {From: 21, To: 25}}, report.Holes)
+	require.Equal(t, int64(17), report.CoveredBlocks)
+
+	// the blocks recorded more than once are overlaps
+	report = Analyze([]Range{{From: 0, To: 10}, {From: 5, To: 12}, {From: 8, To: 9}, {From: 20, To: 30}, {From: 30, To: 31}}, 0, 31)
+	require.Equal(t, []Range{{From: 0, To: 12}, {From: 20, To: 31}}, report.Covered)
+	require.Equal(t, []Range{{From: 5, To: 10}, {From: 30, To: 30}}, report.Overlaps)
+	require.Equal(t, []Range{{From: 13, To: 19}}, report.Holes)
+
+	// only the expected range is checked for holes
+	report = Analyze([]Range{{From: 0, To: 10}, {From: 50, To: 60}}, 5, 55)
+	require.Equal(t, []Range{{From: 11, To: 49}}, report.Holes)
+	require.Equal(t, int64(12), report.CoveredBlocks)
+
+	// without ranges the whole expected range is a hole
+	report = Analyze(nil, 5, 9)
+	require.Empty(t, report.Covered)
+	require.Equal(t, []Range{{From: 5, To: 9}}, report.Holes)
+
+	// invalid ranges are ignored
+	report = Analyze([]Range{{From: 10, To: 5}}, 0, -1)
+	require.Empty(t, report.Covered)
+	require.Empty(t, report.Holes)
+}
+
+func Test_Subtract(t *testing.T) {
+	ranges := []Range{{From: 0, To: 10}, {From: 20, To: 30}}
+
+	require.Equal(t, ranges, Subtract(ranges, nil))
+	require.Equal(t, []Range{{From: 0, To: 4}, {From: 8, To: 10}, {From: 26, To: 30}}, Subtract(ranges, []Range{{From: 5, To: 7}, {From: 15, To: 25}}))
+	require.Empty(t, Subtract(ranges, []Range{{From: 0, To: 30}}))
+}
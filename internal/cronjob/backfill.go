@@ -0,0 +1,333 @@
+package cronjob
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxBackfillAttempts is the number of times a chunk is synced before it's marked as failed
+	maxBackfillAttempts = 3
+	// backfillRetryDelay is the time waited before syncing again a chunk that failed
+	backfillRetryDelay = 5 * time.Second
+)
+
+// backgroundRun is a backfill job, a contract or a signature subscription synced in background
+// independently of the ticks. It's canceled when the replica stops or drops its shard.
+type backgroundRun struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startBackfills starts the pending and running backfill jobs that aren't running yet, so the
+// jobs interrupted by a restart resume from the progress of their chunks.
+func (c *cronjob) startBackfills() error {
+	jobs, err := c.syncEngine.BackfillQuerier.SelectBackfillJobsQuery(c.syncEngine.GetDatabase(), &query.SelectBackfillJobsQueryFilters{
+		Statuses: []storage.BackfillStatus{storage.BackfillStatusPending, storage.BackfillStatusRunning},
+	})
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.startBackfills c.syncEngine.BackfillQuerier.SelectBackfillJobsQuery error")
+	}
+
+	c.backfillMu.Lock()
+	defer c.backfillMu.Unlock()
+
+	// stop the jobs of the shards the replica doesn't hold anymore, they resume on other replica
+	for id, run := range c.backfills {
+		if !c.owns(backfillKey(id)) {
+			run.cancel()
+		}
+	}
+
+	for _, job := range jobs {
+		if _, ok := c.backfills[job.ID]; ok || !c.owns(backfillKey(job.ID)) {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		run := &backgroundRun{
+			cancel: cancel,
+			done:   make(chan struct{}),
+		}
+		c.backfills[job.ID] = run
+
+		go func(job *storage.BackfillJobRecord) {
+			defer close(run.done)
+			defer cancel()
+
+			err := c.runBackfillJob(ctx, job)
+			if err != nil {
+				log.Printf("cronjob.startBackfills backfill job id=%s failed: %s \n", job.ID, err.Error())
+			}
+
+			c.backfillMu.Lock()
+			if c.backfills[job.ID] == run {
+				delete(c.backfills, job.ID)
+			}
+			c.backfillMu.Unlock()
+		}(job)
+	}
+
+	return nil
+}
+
+// backfillKey identifies the backfill job in the shards.
+func backfillKey(id string) string {
+	return "backfill:" + id
+}
+
+// stopBackfills stops every running backfill job and waits until they finish. The progress of
+// the chunks is kept, so the jobs resume when the cronjob starts again.
+func (c *cronjob) stopBackfills() {
+	stopped := make([]*backgroundRun, 0)
+
+	c.backfillMu.Lock()
+	for id, run := range c.backfills {
+		run.cancel()
+		stopped = append(stopped, run)
+		delete(c.backfills, id)
+	}
+	c.backfillMu.Unlock()
+
+	for _, run := range stopped {
+		<-run.done
+	}
+}
+
+// runBackfillJob syncs the unfinished chunks of the job, up to the concurrency of the job at the
+// same time. The job is completed when every chunk is, and failed when any chunk failed.
+func (c *cronjob) runBackfillJob(ctx context.Context, job *storage.BackfillJobRecord) error {
+	now := c.dateGen()
+	err := c.updateBackfillJob(job.ID, storage.BackfillStatusRunning, "", now)
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.runBackfillJob c.updateBackfillJob error")
+	}
+
+	// the job ingests the logs of every event of the contract
+	output, err := c.syncEngine.SelectEventsAndABI(&syncng.SelectEventsAndABIInput{
+		SmartContractAddress: job.SmartContractAddress,
+	})
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.runBackfillJob c.syncEngine.SelectEventsAndABI error")
+	}
+	if len(output.Events) == 0 {
+		return c.updateBackfillJob(job.ID, storage.BackfillStatusFailed, "contract has no events", now)
+	}
+	contractABI, signatures, eventsBySignature := c.prepareContractEvents(output.Events, now)
+	if len(signatures) == 0 {
+		return c.updateBackfillJob(job.ID, storage.BackfillStatusFailed, "invalid events abi", now)
+	}
+
+	chunks, err := c.syncEngine.BackfillQuerier.SelectBackfillChunksQuery(c.syncEngine.GetDatabase(), []string{job.ID})
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.runBackfillJob c.syncEngine.BackfillQuerier.SelectBackfillChunksQuery error")
+	}
+
+	// sync the chunks in parallel, bounded by the concurrency of the job
+	concurrency := job.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, chunk := range chunks {
+		if chunk.IsFinished() {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(chunk *storage.BackfillChunkRecord) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			c.runBackfillChunk(ctx, job, chunk, contractABI, signatures, eventsBySignature)
+		}(chunk)
+	}
+	wg.Wait()
+
+	// the job resumes from its chunks when it was stopped
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	chunks, err = c.syncEngine.BackfillQuerier.SelectBackfillChunksQuery(c.syncEngine.GetDatabase(), []string{job.ID})
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.runBackfillJob c.syncEngine.BackfillQuerier.SelectBackfillChunksQuery error")
+	}
+	failed := 0
+	for _, chunk := range chunks {
+		if chunk.Status == storage.BackfillStatusFailed {
+			failed++
+		}
+	}
+
+	now = c.dateGen()
+	if failed > 0 {
+		return c.updateBackfillJob(job.ID, storage.BackfillStatusFailed, fmt.Sprintf("%d chunks failed", failed), now)
+	}
+	log.Printf("cronjob.runBackfillJob backfill job id=%s of address=%s completed from block_number=%d to block_number=%d \n", job.ID, job.SmartContractAddress, job.FromBlockNumber, job.ToBlockNumber)
+
+	return c.updateBackfillJob(job.ID, storage.BackfillStatusCompleted, "", now)
+}
+
+// runBackfillChunk syncs the chunk from the block after its progress. The progress is saved with
+// each batch of logs, and the chunk is retried until it reaches the max attempts.
+func (c *cronjob) runBackfillChunk(ctx context.Context, job *storage.BackfillJobRecord, chunk *storage.BackfillChunkRecord, contractABI string, signatures []string, eventsBySignature map[string]*storage.EventRecord) {
+	first := anyEvent(eventsBySignature)
+	attempts := chunk.Attempts
+
+	// the chunk is running until it's completed, failed or stopped
+	now := c.dateGen()
+	running := storage.BackfillStatusRunning
+	_, err := c.syncEngine.BackfillQuerier.UpdateBackfillChunkQuery(c.syncEngine.GetDatabase(), &query.UpdateBackfillChunkQueryInput{
+		ID:        &chunk.ID,
+		Status:    &running,
+		UpdatedAt: &now,
+	})
+	if err != nil {
+		log.Printf("cronjob.runBackfillChunk error updating chunk id=%s: %s \n", chunk.ID, err.Error())
+		return
+	}
+	chunk.Status = running
+
+	for ctx.Err() == nil {
+		err := c.syncBackfillChunk(ctx, job, chunk, contractABI, signatures, eventsBySignature)
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := c.dateGen()
+		status := storage.BackfillStatusCompleted
+		errString := ""
+		if err != nil {
+			attempts++
+			status = storage.BackfillStatusRunning
+			errString = err.Error()
+			if attempts >= maxBackfillAttempts {
+				status = storage.BackfillStatusFailed
+			}
+			log.Printf("cronjob.runBackfillChunk chunk from block_number=%d to block_number=%d of address=%s failed: %s \n", chunk.FromBlockNumber, chunk.ToBlockNumber, first.Address, errString)
+		}
+
+		input := &query.UpdateBackfillChunkQueryInput{
+			ID:        &chunk.ID,
+			Status:    &status,
+			Attempts:  &attempts,
+			Error:     &errString,
+			UpdatedAt: &now,
+		}
+		if err == nil {
+			input.LatestBlockNumber = &chunk.ToBlockNumber
+		}
+		_, uerr := c.syncEngine.BackfillQuerier.UpdateBackfillChunkQuery(c.syncEngine.GetDatabase(), input)
+		if uerr != nil {
+			log.Printf("cronjob.runBackfillChunk error updating chunk id=%s: %s \n", chunk.ID, uerr.Error())
+			return
+		}
+		if status != storage.BackfillStatusRunning {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backfillRetryDelay):
+		}
+	}
+}
+
+// syncBackfillChunk walks the logs of the chunk that weren't ingested yet. The logs are stored
+// like the ones of the ticks, but the checkpoints of the events aren't modified.
+func (c *cronjob) syncBackfillChunk(ctx context.Context, job *storage.BackfillJobRecord, chunk *storage.BackfillChunkRecord, contractABI string, signatures []string, eventsBySignature map[string]*storage.EventRecord) error {
+	fromBlockNumber := chunk.LatestBlockNumber + 1
+	if fromBlockNumber > chunk.ToBlockNumber {
+		return nil
+	}
+
+	client, nodeURL, err := c.pool.Client(string(job.Network), job.NodeURL)
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.syncBackfillChunk c.pool.Client error")
+	}
+	defer c.pool.Release(client)
+
+	window, err := c.getWindow(nodeURL, job.Network)
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.syncBackfillChunk c.getWindow error")
+	}
+
+	first := anyEvent(eventsBySignature)
+	_, _, err = c.walkLogs(ctx, nodeURL, blockchain.Config{
+		Client:          client,
+		ABI:             contractABI,
+		EventSignatures: signatures,
+		Address:         first.Address,
+		FromBlockNumber: &fromBlockNumber,
+		ToBlockNumber:   &chunk.ToBlockNumber,
+		Logger:          c.debug,
+		Window:          window,
+		Headers:         c.headers,
+		TopicFilters:    topicFilters(eventsBySignature),
+		RawLogs:         rawLogs(first),
+	}, func(txx *sqlx.Tx, batch blockchain.LogBatch) error {
+		now := c.dateGen()
+		err := c.insertContractLogs(txx, eventsBySignature, batch.Logs, true, now)
+		if err != nil {
+			return err
+		}
+
+		err = c.insertCoverage(txx, eventsBySignature, nil, batch.FromBlockNumber, batch.ToBlockNumber, storage.CoverageSourceBackfill, now)
+		if err != nil {
+			return err
+		}
+
+		// every log of the blocks of the batch was ingested, a retry continues from the next block
+		_, err = c.syncEngine.BackfillQuerier.UpdateBackfillChunkQuery(txx, &query.UpdateBackfillChunkQueryInput{
+			ID:                &chunk.ID,
+			LatestBlockNumber: &batch.ToBlockNumber,
+			UpdatedAt:         &now,
+		})
+		if err != nil {
+			return err
+		}
+		chunk.LatestBlockNumber = batch.ToBlockNumber
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.syncBackfillChunk c.walkLogs error")
+	}
+
+	return nil
+}
+
+// updateBackfillJob updates the status of the job, the error is cleared when it's empty.
+func (c *cronjob) updateBackfillJob(id string, status storage.BackfillStatus, errString string, now time.Time) error {
+	_, err := c.syncEngine.BackfillQuerier.UpdateBackfillJobQuery(c.syncEngine.GetDatabase(), &query.UpdateBackfillJobQueryInput{
+		ID:        &id,
+		Status:    &status,
+		Error:     &errString,
+		UpdatedAt: &now,
+	})
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.updateBackfillJob c.syncEngine.BackfillQuerier.UpdateBackfillJobQuery error")
+	}
+
+	return nil
+}
@@ -0,0 +1,440 @@
+package cronjob
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/shard"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// groupEventsByContract groups the events synced from the same contract address, network and
+// node, keeping the order in which the events were received.
+func groupEventsByContract(events []*storage.EventRecord) [][]*storage.EventRecord {
+	keys := make([]string, 0)
+	groups := make(map[string][]*storage.EventRecord)
+	for _, ev := range events {
+		key := contractKey(ev)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], ev)
+	}
+
+	contracts := make([][]*storage.EventRecord, 0)
+	for _, key := range keys {
+		contracts = append(contracts, groups[key])
+	}
+
+	return contracts
+}
+
+// contractKey identifies the contract of the event by its network, node and address.
+func contractKey(ev *storage.EventRecord) string {
+	return shard.ContractKey(string(ev.Network), ev.NodeURL, ev.Address)
+}
+
+// syncContract ingests the logs of every event of a contract. All the events are requested
+// with a single eth_getLogs call per block range, each log is dispatched to its event by
+// topic0 and the checkpoints of the events are advanced together. The error is stored by the
+// scheduler, which retries the contract with a backoff.
+func (c *cronjob) syncContract(ctx context.Context, events []*storage.EventRecord) (err error) {
+	now := c.dateGen()
+
+	// all the events of the contract share the node and network
+	first := events[0]
+
+	// get a client of the event node, or of other node of the network when it isn't healthy
+	client, nodeURL, err := c.pool.Client(string(first.Network), first.NodeURL)
+	if err != nil {
+		return
+	}
+	defer c.pool.Release(client)
+
+	// get the observed head and the latest block that reached the network finality
+	head, err := c.heads.Head(string(first.Network), first.NodeURL)
+	if err != nil {
+		return
+	}
+
+	// the logs of contracts with a live subscription are ingested by the subscription, so
+	// only the heads are updated
+	if c.isLive(events) {
+		c.notifyLive(events, head)
+		err = c.updateHeads(events, head, now)
+		return
+	}
+
+	// rollback the event data orphaned by a chain reorganization before getting new logs
+	err = c.handleReorg(client, events, now)
+	if err != nil {
+		return
+	}
+
+	// only update heads of the events without new finalized blocks to sync
+	pending := make([]*storage.EventRecord, 0)
+	synced := make([]*storage.EventRecord, 0)
+	for _, ev := range events {
+		if ev.LatestBlockNumber < head.Finalized {
+			pending = append(pending, ev)
+			continue
+		}
+		synced = append(synced, ev)
+	}
+	err = c.updateHeads(synced, head, now)
+	if err != nil {
+		return
+	}
+	if len(pending) == 0 {
+		c.startLive(nodeURL, events, head)
+		return
+	}
+
+	// prepare the contract abi with the pending events, the walk starts from the oldest checkpoint
+	contractABI, signatures, eventsBySignature := c.prepareContractEvents(pending, now)
+	if len(signatures) == 0 {
+		return
+	}
+	fromBlockNumber := pending[0].LatestBlockNumber
+	for _, ev := range eventsBySignature {
+		if ev.LatestBlockNumber < fromBlockNumber {
+			fromBlockNumber = ev.LatestBlockNumber
+		}
+	}
+	starts := coverageStarts(eventsBySignature)
+
+	// get the block range window learned for the node
+	window, err := c.getWindow(nodeURL, first.Network)
+	if err != nil {
+		return
+	}
+
+	// define context with timeout for getting log proccess, the walk stops when the run is canceled
+	// TODO(ca): should to use env value
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// get contract logs
+	count, latestBlockNumber, err := c.walkLogs(ctx, nodeURL, blockchain.Config{
+		Client:          client,
+		ABI:             contractABI,
+		EventSignatures: signatures,
+		Address:         first.Address,
+		FromBlockNumber: &fromBlockNumber,
+		ToBlockNumber:   &head.Finalized,
+		Logger:          c.debug,
+		Window:          window,
+		Headers:         c.headers,
+		TopicFilters:    topicFilters(eventsBySignature),
+		RawLogs:         rawLogs(first),
+	}, func(txx *sqlx.Tx, batch blockchain.LogBatch) error {
+		err := c.insertContractLogs(txx, eventsBySignature, batch.Logs, false, now)
+		if err != nil {
+			return err
+		}
+		return c.insertCoverage(txx, eventsBySignature, starts, batch.FromBlockNumber, batch.ToBlockNumber, storage.CoverageSourceTick, now)
+	})
+
+	// persist the window even when the walk failed, it could have learned a smaller range
+	if werr := c.saveWindow(nodeURL, first.Network, window, now); werr != nil {
+		log.Printf("cronjob.job error saving block range window: %s \n", werr.Error())
+	}
+
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		err = nil
+	} else if err != nil {
+		return
+	}
+
+	// show count log
+	if count > 0 {
+		log.Printf("%d new events have been inserted into the database with %d latest block number \n", count, latestBlockNumber)
+	}
+
+	// keep the hash of the block the walk reached, the next ticks compare it with the canonical chain
+	checkpointBlockHash := c.blockHash(client, latestBlockNumber)
+
+	// advance the checkpoint of every pending event together
+	for _, ev := range eventsBySignature {
+		latest := latestBlockNumber
+		if ev.LatestBlockNumber > latest {
+			latest = ev.LatestBlockNumber
+		}
+
+		input := &query.UpdateEventQueryInput{
+			ID:                   &ev.ID,
+			LatestBlockNumber:    &latest,
+			ObservedBlockNumber:  &head.Observed,
+			FinalizedBlockNumber: &head.Finalized,
+			UpdatedAt:            &now,
+		}
+		if checkpointBlockHash != "" && latest == latestBlockNumber {
+			input.CheckpointBlockNumber = &latestBlockNumber
+			input.CheckpointBlockHash = &checkpointBlockHash
+		}
+
+		_, err = c.syncEngine.EventQuerier.UpdateEventQuery(c.syncEngine.GetDatabase(), input)
+		if err != nil {
+			return
+		}
+		ev.LatestBlockNumber = latest
+		if input.CheckpointBlockHash != nil {
+			ev.CheckpointBlockNumber = latestBlockNumber
+			ev.CheckpointBlockHash = checkpointBlockHash
+		}
+		ev.ObservedBlockNumber = head.Observed
+		ev.FinalizedBlockNumber = head.Finalized
+	}
+
+	// stream the new logs once the contract caught up with the sync head
+	c.startLive(nodeURL, events, head)
+
+	return nil
+}
+
+// blockHash returns the hash of the canonical block, or an empty hash when it can't be read, so
+// the checkpoint is stored again on the next tick.
+func (c *cronjob) blockHash(client blockchain.HeaderReader, blockNumber int64) string {
+	if blockNumber <= 0 {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	header, err := client.HeaderByNumber(ctx, big.NewInt(blockNumber))
+	if err != nil {
+		log.Printf("cronjob.blockHash error getting header of block_number=%d: %s \n", blockNumber, err.Error())
+		return ""
+	}
+
+	return header.Hash().Hex()
+}
+
+// updateHeads updates the observed and finalized heads of the events.
+func (c *cronjob) updateHeads(events []*storage.EventRecord, head *blockchain.Head, now time.Time) error {
+	for _, ev := range events {
+		_, err := c.syncEngine.EventQuerier.UpdateEventQuery(c.syncEngine.GetDatabase(), &query.UpdateEventQueryInput{
+			ID:                   &ev.ID,
+			ObservedBlockNumber:  &head.Observed,
+			FinalizedBlockNumber: &head.Finalized,
+			UpdatedAt:            &now,
+		})
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.updateHeads c.syncEngine.EventQuerier.UpdateEventQuery error")
+		}
+		ev.ObservedBlockNumber = head.Observed
+		ev.FinalizedBlockNumber = head.Finalized
+	}
+
+	return nil
+}
+
+// prepareContractEvents returns the abi with the definition of the events, their signatures and
+// the events by signature. Events whose abi can't be prepared are skipped and their error saved.
+func (c *cronjob) prepareContractEvents(events []*storage.EventRecord, now time.Time) (string, []string, map[string]*storage.EventRecord) {
+	abis := make([]string, 0)
+	signatures := make([]string, 0)
+	eventsBySignature := make(map[string]*storage.EventRecord)
+	for _, ev := range events {
+		b, err := ev.ABI.MarshalJson()
+		if err != nil {
+			c.updateEventError(ev.ID, err, now)
+			continue
+		}
+
+		// events stored before the signature column was added compute it from the abi
+		signature := ev.Signature
+		if signature == "" {
+			signature, _, err = ev.ABI.EventSignature()
+			if err != nil {
+				c.updateEventError(ev.ID, err, now)
+				continue
+			}
+		}
+
+		abis = append(abis, string(b))
+		signatures = append(signatures, signature)
+		eventsBySignature[signature] = ev
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(abis, ",")), signatures, eventsBySignature
+}
+
+// walkLogs gets the logs with the given config and stores each batch with insert while the
+// walk continues. The result of the walk is reported to the pool, and the insert error has
+// priority over the walk one.
+func (c *cronjob) walkLogs(ctx context.Context, nodeURL string, cf blockchain.Config, insert func(txx *sqlx.Tx, batch blockchain.LogBatch) error) (int64, int64, error) {
+	// define and read channel with log data in go routine
+	batchesChannel := make(chan blockchain.LogBatch)
+	done := make(chan error)
+	go func() {
+		var insertErr error
+		for batch := range batchesChannel {
+			// keep draining the channel after an error so the walk isn't blocked
+			if insertErr != nil {
+				continue
+			}
+
+			insertErr = c.syncEngine.InTransaction(func(txx *sqlx.Tx) error {
+				return insert(txx, batch)
+			})
+		}
+		done <- insertErr
+	}()
+
+	cf.BatchesChannel = batchesChannel
+	count, latestBlockNumber, err := blockchain.GetLogs(ctx, cf)
+	if err != context.DeadlineExceeded {
+		c.pool.Report(nodeURL, err)
+	}
+
+	// wait until every batch sent to the channel is stored
+	insertErr := <-done
+	if insertErr != nil {
+		return count, latestBlockNumber, insertErr
+	}
+
+	return count, latestBlockNumber, err
+}
+
+// insertContractLogs stores a batch of contract logs in the event each one belongs to, or in
+// quarantine when they couldn't be decoded, stores the headers of their blocks, updates the latest block number of the events and
+// sends the webhooks of the new event data. Contracts in raw logs mode store every log as raw log too.
+// The logs of backfill jobs are behind the checkpoints of the events, so they don't update them
+// nor send webhooks.
+func (c *cronjob) insertContractLogs(txx *sqlx.Tx, eventsBySignature map[string]*storage.EventRecord, logs []blockchain.LogData, backfill bool, now time.Time) error {
+	// the events share the contract, so any of them tells the contract mode
+	if ev := anyEvent(eventsBySignature); ev != nil && rawLogs(ev) {
+		err := c.insertRawLogs(txx, ev, logs, now)
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.insertContractLogs c.insertRawLogs error")
+		}
+	}
+
+	// parse each log to EventData of its event
+	eventsData := make(map[string][]*storage.EventDataRecord)
+	blocks := make([]*storage.BlockRecord, 0)
+	blockHashes := make(map[string]bool)
+	for _, l := range logs {
+		ev, ok := eventsBySignature[l.EventSignature]
+		if !ok {
+			continue
+		}
+
+		// keep the header of the block of the log
+		if l.Block != nil && !blockHashes[l.Block.Hash.Hex()] {
+			br := &storage.BlockRecord{}
+			br.FromBlock(l.Block, ev.Network, now)
+			blocks = append(blocks, br)
+			blockHashes[br.Hash] = true
+		}
+
+		// the block was already synced for this event on previous ticks
+		if !backfill && int64(l.BlockNumber) < ev.LatestBlockNumber {
+			continue
+		}
+
+		// store the logs that couldn't be decoded in quarantine and keep ingesting
+		if l.DecodeError != "" {
+			ql := &storage.QuarantinedLogRecord{}
+			err := ql.FromLogData(&l, c.idGen(), ev.ID, now)
+			if err != nil {
+				return errors.Wrap(err, "cronjob: cronjob.insertContractLogs ql.FromLogData error")
+			}
+
+			err = c.syncEngine.QuarantinedLogQuerier.InsertQuarantinedLogQuery(txx, ql)
+			if err != nil {
+				return errors.Wrap(err, "cronjob: cronjob.insertContractLogs c.syncEngine.QuarantinedLogQuerier.InsertQuarantinedLogQuery error")
+			}
+			continue
+		}
+
+		ed := &storage.EventDataRecord{}
+		err := ed.FromLogData(&l, c.idGen(), ev.ID, now)
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.insertContractLogs ed.FromLogData error")
+		}
+
+		eventsData[ev.ID] = append(eventsData[ev.ID], ed)
+	}
+
+	err := c.syncEngine.BlockQuerier.InsertBlockBatchQuery(txx, blocks)
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.insertContractLogs c.syncEngine.BlockQuerier.InsertBlockBatchQuery error")
+	}
+
+	for signature, ev := range eventsBySignature {
+		data, ok := eventsData[ev.ID]
+		if !ok {
+			continue
+		}
+
+		// insert logs data to event
+		err := c.syncEngine.EventDataQuerier.InsertEventDataBatchQuery(txx, data)
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.insertContractLogs c.syncEngine.EventDataQuerier.InsertEventDataBatchQuery error")
+		}
+
+		// register the child contracts created by the logs of factory events
+		err = c.registerFactoryChildren(txx, signature, ev, data)
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.insertContractLogs c.registerFactoryChildren error")
+		}
+
+		if backfill {
+			continue
+		}
+
+		// update latest block number using last data log, only when it is greater than event block number
+		logBlockNumber := data[len(data)-1].BlockNumber
+		if logBlockNumber > ev.LatestBlockNumber {
+			_, err = c.syncEngine.EventQuerier.UpdateEventQuery(txx, &query.UpdateEventQueryInput{
+				ID:                &ev.ID,
+				LatestBlockNumber: &logBlockNumber,
+				UpdatedAt:         &now,
+			})
+			if err != nil {
+				return errors.Wrap(err, "cronjob: cronjob.insertContractLogs c.syncEngine.EventQuerier.UpdateEventQuery error")
+			}
+			ev.LatestBlockNumber = logBlockNumber
+		}
+
+		// webhook related
+		if logBlockNumber < ev.SmartContract.InitialBlockNumber {
+			continue
+		}
+		for _, scu := range ev.SmartContractUsers {
+			if scu.WebhookURL == "" {
+				continue
+			}
+
+			for _, evData := range data {
+				// users only get the webhooks of the logs matching their filters
+				if !scu.TopicFilters.Match(signature, evData.Topics) {
+					continue
+				}
+
+				wh, err := evData.ToWebhookEvent(c.idGen(), ev, scu.WebhookURL, now)
+				if err != nil {
+					return errors.Wrap(err, "cronjob: cronjob.insertContractLogs evData.ToWebhookEvent error")
+				}
+
+				err = c.sendWebhook(scu.UserID, wh)
+				if err != nil {
+					return errors.Wrap(err, "cronjob: cronjob.insertContractLogs c.sendWebhook error")
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package cronjob
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/coverage"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// coverageStarts returns the first block not covered yet by each event. The walks start from
+// the checkpoint of the events, which was already covered by the previous walk.
+func coverageStarts(eventsBySignature map[string]*storage.EventRecord) map[string]int64 {
+	starts := make(map[string]int64)
+	for _, ev := range eventsBySignature {
+		starts[ev.ID] = ev.LatestBlockNumber + 1
+	}
+
+	return starts
+}
+
+// insertCoverage records in the ledger of every event that the block range was fetched and
+// committed, within the transaction that stores its logs. When starts is defined the range of
+// each event begins at its start.
+func (c *cronjob) insertCoverage(txx *sqlx.Tx, eventsBySignature map[string]*storage.EventRecord, starts map[string]int64, from int64, to int64, source storage.CoverageSource, now time.Time) error {
+	records := make([]*storage.CoverageRangeRecord, 0)
+	for _, ev := range eventsBySignature {
+		fromBlockNumber := from
+		if start, ok := starts[ev.ID]; ok && start > fromBlockNumber {
+			fromBlockNumber = start
+		}
+		if fromBlockNumber > to {
+			continue
+		}
+
+		records = append(records, &storage.CoverageRangeRecord{
+			ID:              c.idGen(),
+			EventID:         ev.ID,
+			FromBlockNumber: fromBlockNumber,
+			ToBlockNumber:   to,
+			Source:          source,
+			CreatedAt:       now,
+		})
+	}
+
+	err := c.syncEngine.CoverageQuerier.InsertCoverageRangeBatchQuery(txx, records)
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.insertCoverage c.syncEngine.CoverageQuerier.InsertCoverageRangeBatchQuery error")
+	}
+
+	return nil
+}
+
+// verifyCoverage checks the ledger of the events of the contracts when the coverage interval
+// elapsed. The ranges of each event are compacted, and the holes between the first covered
+// block and the checkpoint are fetched again, except the ones that a backfill job is ingesting.
+func (c *cronjob) verifyCoverage(contracts [][]*storage.EventRecord) {
+	now := c.dateGen()
+	if c.coverageInterval <= 0 || now.Sub(c.lastCoverage) < c.coverageInterval {
+		return
+	}
+	c.lastCoverage = now
+
+	jobs, err := c.syncEngine.BackfillQuerier.SelectBackfillJobsQuery(c.syncEngine.GetDatabase(), &query.SelectBackfillJobsQueryFilters{
+		Statuses: []storage.BackfillStatus{storage.BackfillStatusPending, storage.BackfillStatusRunning},
+	})
+	if err != nil {
+		log.Printf("cronjob.verifyCoverage error selecting backfill jobs: %s \n", err.Error())
+		return
+	}
+	backfilling := make(map[string][]coverage.Range)
+	for _, job := range jobs {
+		backfilling[job.SmartContractAddress] = append(backfilling[job.SmartContractAddress], coverage.Range{From: job.FromBlockNumber, To: job.ToBlockNumber})
+	}
+
+	for _, events := range contracts {
+		eventIDs := make([]string, 0)
+		for _, ev := range events {
+			eventIDs = append(eventIDs, ev.ID)
+		}
+
+		ranges, err := c.syncEngine.CoverageQuerier.SelectCoverageRangesQuery(c.syncEngine.GetDatabase(), eventIDs)
+		if err != nil {
+			log.Printf("cronjob.verifyCoverage error selecting coverage of address=%s: %s \n", events[0].Address, err.Error())
+			continue
+		}
+		rangesByEvent := make(map[string][]*storage.CoverageRangeRecord)
+		for _, r := range ranges {
+			rangesByEvent[r.EventID] = append(rangesByEvent[r.EventID], r)
+		}
+
+		for _, ev := range events {
+			ec := syncng.NewEventCoverage(ev, rangesByEvent[ev.ID])
+			if len(ec.Report.Overlaps) > 0 {
+				log.Printf("cronjob.verifyCoverage event_id=%s has %d overlapping ranges \n", ev.ID, len(ec.Report.Overlaps))
+			}
+
+			err := c.syncEngine.CompactCoverage(&syncng.CompactCoverageInput{
+				EventID:   ev.ID,
+				Ranges:    ec.Ranges,
+				CreatedAt: now,
+			})
+			if err != nil {
+				log.Printf("cronjob.verifyCoverage error compacting coverage of event_id=%s: %s \n", ev.ID, err.Error())
+				continue
+			}
+
+			holes := coverage.Subtract(ec.Report.Holes, backfilling[ev.SmartContractAddress])
+			for _, hole := range holes {
+				log.Printf("cronjob.verifyCoverage repairing event_id=%s from block_number=%d to block_number=%d \n", ev.ID, hole.From, hole.To)
+
+				err := c.repairCoverage(ev, hole, now)
+				if err != nil {
+					log.Printf("cronjob.verifyCoverage error repairing event_id=%s: %s \n", ev.ID, err.Error())
+					break
+				}
+			}
+		}
+	}
+}
+
+// repairCoverage fetches the logs of the hole of the event again. The logs are stored like the
+// ones of the backfill jobs, without modifying the checkpoint of the event, and each batch is
+// recorded in the ledger so an interrupted repair continues on the next verification.
+func (c *cronjob) repairCoverage(ev *storage.EventRecord, hole coverage.Range, now time.Time) error {
+	contractABI, signatures, eventsBySignature := c.prepareContractEvents([]*storage.EventRecord{ev}, now)
+	if len(signatures) == 0 {
+		return errors.New("cronjob: cronjob.repairCoverage invalid event abi error")
+	}
+
+	client, nodeURL, err := c.pool.Client(string(ev.Network), ev.NodeURL)
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.repairCoverage c.pool.Client error")
+	}
+	defer c.pool.Release(client)
+
+	window, err := c.getWindow(nodeURL, ev.Network)
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.repairCoverage c.getWindow error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, _, err = c.walkLogs(ctx, nodeURL, blockchain.Config{
+		Client:          client,
+		ABI:             contractABI,
+		EventSignatures: signatures,
+		Address:         ev.Address,
+		FromBlockNumber: &hole.From,
+		ToBlockNumber:   &hole.To,
+		Logger:          c.debug,
+		Window:          window,
+		Headers:         c.headers,
+		TopicFilters:    topicFilters(eventsBySignature),
+	}, func(txx *sqlx.Tx, batch blockchain.LogBatch) error {
+		err := c.insertContractLogs(txx, eventsBySignature, batch.Logs, true, now)
+		if err != nil {
+			return err
+		}
+		return c.insertCoverage(txx, eventsBySignature, nil, batch.FromBlockNumber, batch.ToBlockNumber, storage.CoverageSourceRepair, now)
+	})
+	if err == context.DeadlineExceeded {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.repairCoverage c.walkLogs error")
+	}
+
+	return nil
+}
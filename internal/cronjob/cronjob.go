@@ -8,16 +8,15 @@ import (
 	"time"
 
 	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/chainhead"
+	"github.com/darchlabs/synchronizer-v2/internal/rpcpool"
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
 	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
-	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
 	"github.com/darchlabs/synchronizer-v2/internal/webhooksender"
 	"github.com/darchlabs/synchronizer-v2/internal/wrapper"
 	"github.com/darchlabs/synchronizer-v2/pkg/event"
 	"github.com/darchlabs/synchronizer-v2/pkg/smartcontract"
 	"github.com/darchlabs/synchronizer-v2/pkg/webhook"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 )
 
@@ -34,10 +33,19 @@ type SmartContractStorage interface {
 	GetSmartContractByAddress(address string) (*smartcontract.SmartContract, error)
 }
 
+// ShardFilter tells the work assigned to the replica when the ingestion is sharded.
+type ShardFilter interface {
+	Owns(key string) bool
+}
+
 type WebhookSender interface {
 	CreateAndSendWebhook(wh *webhook.Webhook) error
 }
 
+// defaultReorgDepth is the number of blocks behind the latest synced block that are
+// compared against the canonical chain on every tick when no depth is configured
+const defaultReorgDepth = int64(64)
+
 type CronjobStatus string
 
 const (
@@ -49,17 +57,45 @@ const (
 )
 
 type cronjob struct {
-	ticker *time.Ticker
-	quit   chan struct{}
+	// mu guards the status and the error, they're read by the api while the cronjob runs
+	mu     sync.Mutex
+	status CronjobStatus
 	error  error
 
 	seconds       int64
-	clients       *sync.Map
+	pool          *rpcpool.Pool
 	storage       EventDataStorage
 	scStorage     SmartContractStorage
 	debug         bool
-	status        CronjobStatus
 	webhookSender WebhookSender
+	reorgDepth    int64
+	heads         *chainhead.Tracker
+	onNewBlocks   bool
+	windowConfig  blockchain.WindowConfig
+	windows       sync.Map
+	headers       *blockchain.HeaderCache
+	liveLogs      bool
+	liveMu        sync.Mutex
+	subscriptions map[string]*liveSubscription
+	backfillMu    sync.Mutex
+	backfills     map[string]*backgroundRun
+	signatureMu   sync.Mutex
+	signatureRuns map[string]*backgroundRun
+
+	coverageInterval time.Duration
+	lastCoverage     time.Time
+
+	// scheduler of the contracts
+	schedMu          sync.Mutex
+	scheduled        map[string]*scheduledContract
+	runningContracts int
+	maxWorkers       int
+	maxBackoff       time.Duration
+	runs             sync.WaitGroup
+	wake             chan struct{}
+
+	// shards of the replica, every contract is synced when it's nil
+	shards ShardFilter
 
 	// sync engine
 	syncEngine *syncng.Engine
@@ -72,19 +108,50 @@ type Config struct {
 	Seconds          int64
 	EventDataStorage EventDataStorage
 	SCStorage        SmartContractStorage
-	Clients          *sync.Map
+	Pool             *rpcpool.Pool
 	Debug            bool
 	IDGen            wrapper.IDGenerator
 	DateGen          wrapper.DateGenerator
 	WebhookSender    *webhooksender.WebhookSender
 	Engine           *syncng.Engine
+	ReorgDepth       int64
+	Heads            *chainhead.Tracker
+	WindowConfig     blockchain.WindowConfig
+	// LiveLogs enables the subscription to new logs for the events synced from WebSocket nodes
+	LiveLogs bool
+	// OnNewBlocks syncs the events of a network each time the head tracker publishes a new
+	// block, the ticker keeps syncing every network as fallback
+	OnNewBlocks bool
+	// CoverageInterval is the time between the verifications of the coverage ledger of the
+	// events, the verification is disabled when it is zero
+	CoverageInterval time.Duration
+	// MaxWorkers is the number of contracts synced at the same time
+	MaxWorkers int
+	// MaxBackoff is the longest delay between the retries of a failing contract
+	MaxBackoff time.Duration
+	// Shards filters the contracts, backfill jobs and signature subscriptions synced by the
+	// replica when the ingestion is sharded across replicas
+	Shards ShardFilter
 }
 
 func New(config *Config) *cronjob {
+	reorgDepth := config.ReorgDepth
+	if reorgDepth <= 0 {
+		reorgDepth = defaultReorgDepth
+	}
+	maxWorkers := config.MaxWorkers
+	if maxWorkers <= 0 {
+		maxWorkers = defaultMaxWorkers
+	}
+	maxBackoff := config.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
 	return &cronjob{
 		seconds: config.Seconds,
 		status:  StatusIdle,
-		clients: config.Clients,
+		pool:    config.Pool,
 
 		storage:       config.EventDataStorage,
 		scStorage:     config.SCStorage,
@@ -93,296 +160,228 @@ func New(config *Config) *cronjob {
 		dateGen:       config.DateGen,
 		webhookSender: config.WebhookSender,
 		syncEngine:    config.Engine,
+		reorgDepth:    reorgDepth,
+		heads:         config.Heads,
+		onNewBlocks:   config.OnNewBlocks,
+		windowConfig:  config.WindowConfig,
+		headers:       blockchain.NewHeaderCache(0),
+		liveLogs:      config.LiveLogs,
+		subscriptions: make(map[string]*liveSubscription),
+		backfills:     make(map[string]*backgroundRun),
+		signatureRuns: make(map[string]*backgroundRun),
+
+		coverageInterval: config.CoverageInterval,
+		scheduled:        make(map[string]*scheduledContract),
+		maxWorkers:       maxWorkers,
+		maxBackoff:       maxBackoff,
+		wake:             make(chan struct{}, 1),
+		shards:           config.Shards,
 	}
 }
 
-func (c *cronjob) Start() error {
-	if c.status == StatusRunning {
-		return errors.New("cronjob its already running")
-	}
+// Run syncs the events until the context is canceled. On cancel it stops the live
+// subscriptions and the backfill jobs, and waits for the contracts being synced to finish.
+func (c *cronjob) Run(ctx context.Context) error {
+	log.Printf("Running ticker each %d seconds \n", c.seconds)
+	c.setStatus(StatusRunning, nil)
 
-	if c.status == StatusStopping {
-		return errors.New("cronjob is stopping now, wait few seconds")
+	ticker := time.NewTicker(time.Duration(c.seconds) * time.Second)
+	defer ticker.Stop()
+
+	// the timer wakes up the scheduler when the next contract is due
+	timer := time.NewTimer(time.Duration(c.seconds) * time.Second)
+	defer timer.Stop()
+
+	// subscribe to the new blocks of the networks, a nil channel never receives
+	var blocks <-chan chainhead.Update
+	if c.onNewBlocks {
+		var unsubscribe func()
+		blocks, unsubscribe = c.heads.Subscribe()
+		defer unsubscribe()
 	}
 
-	log.Printf("Running ticker each %d seconds \n", c.seconds)
+	for {
+		log.Printf("===== \n")
+		log.Printf("Here inside for ticker \n")
 
-	// initialize ticker
-	c.ticker = time.NewTicker(time.Duration(time.Duration(c.seconds) * time.Second))
-	c.quit = make(chan struct{})
-	c.status = StatusRunning
-	c.error = nil
-
-	// run gourutine associated to the ticker
-	go func() {
-		for {
-			log.Printf("===== \n")
-			log.Printf("Here inside for ticker \n")
-
-			select {
-			case <-c.ticker.C:
-				// call job method to run de ticker process
-				err := c.job()
-				if err != nil {
-					c.status = StatusError
-					c.error = err
-
-					log.Printf("Cronjob has error: %s", err.Error())
-					return
-				}
+		var err error
+		select {
+		case <-ticker.C:
+			// call job method to run de ticker process
+			err = c.job(nil)
 
-			case <-c.quit:
-				c.ticker.Stop()
-				c.status = StatusStopped
-				c.ticker = nil
-				return
+		case u, ok := <-blocks:
+			if !ok {
+				blocks = nil
+				continue
 			}
+
+			// sync every network with new blocks published while the previous job was running
+			networks := map[string]bool{u.Network: true}
+			for pending := len(blocks); pending > 0; pending-- {
+				u, ok := <-blocks
+				if !ok {
+					break
+				}
+				networks[u.Network] = true
+			}
+			err = c.job(networks)
+
+		// a contract finished or is due, so other ones can run
+		case <-c.wake:
+			resetTimer(timer, c.dispatch(c.dateGen()))
+			continue
+		case <-timer.C:
+			resetTimer(timer, c.dispatch(c.dateGen()))
+			continue
+
+		case <-ctx.Done():
+			c.setStatus(StatusStopping, nil)
+			c.drain()
+			c.setStatus(StatusStopped, nil)
+			return nil
 		}
-	}()
 
-	return nil
+		if err != nil {
+			log.Printf("Cronjob has error: %s", err.Error())
+			c.setStatus(StatusError, err)
+			c.drain()
+			return err
+		}
+		resetTimer(timer, c.dispatch(c.dateGen()))
+	}
 }
 
-func (c *cronjob) Restart() error {
-	if c.status == StatusIdle {
-		return errors.New("cronjob isn't ready yet, wait few seconds")
-	}
+// drain stops the live subscriptions and the backfill jobs, and waits for the contracts being
+// synced to finish.
+func (c *cronjob) drain() {
+	c.stopLive(nil)
+	c.stopBackfills()
+	c.runs.Wait()
+}
 
-	if c.status == StatusStopping {
-		return errors.New("cronjob is stopping now, wait few seconds")
-	}
+// job refreshes the contracts of the scheduler with the running and errored events when networks
+// is nil, or makes the contracts of the given networks due. The contracts are synced by the
+// scheduler on their own cadence, so a slow contract doesn't delay the other ones.
+func (c *cronjob) job(networks map[string]bool) (err error) {
+	now := c.dateGen()
 
-	if c.status == StatusRunning {
-		err := c.Stop()
+	if networks == nil {
+		output, err := c.syncEngine.SelectEventsAndABI(&syncng.SelectEventsAndABIInput{
+			EventStatuses: []string{string(storage.EventStatusRunning), string(storage.EventStatusError)},
+		})
 		if err != nil {
-			return err
+			return errors.Wrap(err, "cronjob: cronjob.job c.syncEngine.SelectEventsAndABI error")
 		}
-	}
 
-	// wait c.Seconds for restart, always the neccesary time is < c.Seconds when the cronjob are stopping
-	if c.status == StatusStopping {
-		time.Sleep(time.Duration(c.seconds) * time.Second)
-	}
-
-	err := c.Start()
-	if err != nil {
-		return err
-	}
+		// group the events by contract, every contract is synced with a single eth_getLogs per range.
+		// Only the contracts of the shards of the replica are synced
+		contracts := make([][]*storage.EventRecord, 0)
+		for _, events := range groupEventsByContract(output.Events) {
+			if c.owns(contractKey(events[0])) {
+				contracts = append(contracts, events)
+			}
+		}
 
-	return nil
-}
+		// stop the live subscriptions of the contracts without running events
+		keys := make(map[string]bool)
+		for _, events := range contracts {
+			keys[contractKey(events[0])] = true
+		}
+		c.stopLive(keys)
+		c.refreshSchedule(contracts, now)
 
-func (c *cronjob) Stop() error {
-	if c.status == StatusIdle {
-		return errors.New("cronjob isn't ready yet, wait few seconds")
+		// the backfill jobs run in background, independently of the ticks
+		err = c.startBackfills()
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.job c.startBackfills error")
+		}
+	} else {
+		// only sync the contracts of the networks with new blocks
+		c.markDue(networks, now)
 	}
+	c.dispatch(now)
 
-	if c.status == StatusStopping {
-		return errors.New("cronjob is stopping now, wait few seconds")
+	// the signature subscriptions are synced in background like the contracts
+	err = c.syncSignatureSubscriptions(networks)
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.job c.syncSignatureSubscriptions error")
 	}
 
-	if c.status == StatusStopped {
-		return errors.New("cronjob is already stopped")
+	// look for holes in the coverage of the contracts that aren't running
+	if networks == nil {
+		c.verifyCoverage(c.idleContracts())
 	}
 
-	c.status = StatusStopping
-	c.quit <- struct{}{}
-
 	return nil
 }
 
-func (c *cronjob) job() (err error) {
-	output, err := c.syncEngine.SelectEventsAndABI(&syncng.SelectEventsAndABIInput{
-		EventStatus: string(storage.EventStatusRunning),
-	})
-	if err != nil {
-		return errors.Wrap(err, "cronjob: cronjob.job c.syncEngine.SelectEventsAndABI error")
+// StopUnowned cancels the contracts, signature subscriptions, backfill jobs and live
+// subscriptions of the shards the replica doesn't hold anymore, and waits until they finish.
+// The shards are released after it returns, so other replica never syncs them at the same time.
+func (c *cronjob) StopUnowned() {
+	runs := make([]*backgroundRun, 0)
+
+	c.schedMu.Lock()
+	for key, s := range c.scheduled {
+		if s.running && !c.owns(key) {
+			s.run.cancel()
+			runs = append(runs, s.run)
+		}
 	}
+	c.schedMu.Unlock()
 
-	// define waitgroup for proccessing the events logs
-	var wg sync.WaitGroup
-	wg.Add(len(output.Events))
-
-	// iterate over events
-	for _, e := range output.Events {
-		go func(ev *storage.EventRecord) {
-			now := c.dateGen()
-			var err error
-			defer wg.Done()
-			defer func() {
-				if err != nil {
-					c.updateEventError(ev.ID, err, now)
-				}
-			}()
-
-			log.Printf("conjob.job getting client")
-			// get client from map or create and save
-			cl, ok := c.clients.Load(ev.NodeURL)
-			var client *ethclient.Client
-			//client, ok := (*c.clients)[ev.NodeURL]
-			if !ok {
-				log.Printf("conjob.job client not found")
-				// validate client works
-				client, err = ethclient.Dial(ev.NodeURL)
-				if err != nil {
-					return
-				}
-
-				// save client in map
-				c.clients.Store(ev.NodeURL, client)
-				log.Printf("conjob.job client created")
-			} else {
-				client = cl.(*ethclient.Client)
-			}
-
-			// parse abi to string
-			b, err := ev.ABI.MarshalJson()
-			if err != nil {
-				return
-			}
-
-			// define and read channel with log data in go routine
-			logsChannel := make(chan []blockchain.LogData)
-			go func(e *storage.EventRecord) {
-				now := c.dateGen()
-				defer func() {
-					if err != nil {
-						c.updateEventError(e.ID, err, now)
-					}
-				}()
-
-				for logs := range logsChannel {
-					c.syncEngine.InTransaction(func(txx *sqlx.Tx) error {
-
-						// parse each log to EventData
-						eventDatas := make([]*storage.EventDataRecord, 0)
-						for _, log := range logs {
-							ed := &storage.EventDataRecord{}
-							err := ed.FromLogData(&log, c.idGen(), e.ID, now)
-							if err != nil {
-								return err
-							}
-
-							eventDatas = append(eventDatas, ed)
-						}
-
-						// insert logs data to event
-						err := c.syncEngine.EventDataQuerier.InsertEventDataBatchQuery(txx, eventDatas)
-						if err != nil {
-							return err
-						}
-
-						// update latest block number using last dataLog
-						var logBlockNumber int64
-						if len(logs) > 0 {
-							logBlockNumber = int64(logs[len(logs)-1].BlockNumber)
-
-							// update only when log_block_number is greater than event block number
-							if logBlockNumber > e.LatestBlockNumber {
-
-								_, err = c.syncEngine.EventQuerier.UpdateEventQuery(txx, &query.UpdateEventQueryInput{
-									ID:                &e.ID,
-									LatestBlockNumber: &logBlockNumber,
-									UpdatedAt:         &now,
-								})
-								if err != nil {
-									return err
-								}
-							}
-						}
-
-						// webhook related
-						for _, scu := range e.SmartContractUsers {
-							if scu.WebhookURL != "" && logBlockNumber >= e.SmartContract.InitialBlockNumber {
-								for _, evData := range eventDatas {
-									wh, err := evData.ToWebhookEvent(c.idGen(), e, scu.WebhookURL, now)
-									if err != nil {
-										return err
-									}
-
-									err = c.webhookSender.CreateAndSendWebhook(&webhook.Webhook{
-										ID:          wh.ID,
-										Tx:          wh.Tx,
-										UserID:      scu.UserID,
-										EntityType:  webhook.WebhookEntityType(wh.EntityType),
-										EntityID:    wh.EntityID,
-										Endpoint:    wh.Endpoint,
-										Payload:     wh.Payload,
-										MaxAttempts: wh.MaxAttempts,
-										CreatedAt:   wh.CreatedAt,
-										UpdatedAt:   wh.UpdatedAt,
-										SentAt:      wh.SentAt,
-										Attempts:    wh.Attempts,
-										NextRetryAt: wh.NextRetryAt,
-										Status:      webhook.WebhookStatus(wh.Status),
-									})
-									if err != nil {
-										return err
-									}
-								}
-							}
-						}
-
-						return nil
-					})
-
-				}
-			}(ev)
-
-			// define context with timeout for getting log proccess
-			// TODO(ca): should to use env value
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second) // 10 minutos de límite
-			defer cancel()
-
-			// get event logs from contract
-			cf := blockchain.Config{
-				Client:          client,
-				ABI:             fmt.Sprintf("[%s]", string(b)),
-				EventName:       ev.Name,
-				Address:         ev.Address,
-				FromBlockNumber: &ev.LatestBlockNumber,
-				LogsChannel:     logsChannel,
-				Logger:          c.debug,
-			}
-			count, latestBlockNumber, err := blockchain.GetLogs(ctx, cf)
-			if err == context.Canceled || err == context.DeadlineExceeded {
-			} else if err != nil {
-				c.updateEventError(ev.ID, err, now)
-				return
-			}
-
-			// show count log
-			if count > 0 {
-				log.Printf("%d new events have been inserted into the database with %d latest block number \n", count, latestBlockNumber)
-			}
+	c.signatureMu.Lock()
+	for id, run := range c.signatureRuns {
+		if !c.owns(signatureSubscriptionKey(id)) {
+			run.cancel()
+			runs = append(runs, run)
+		}
+	}
+	c.signatureMu.Unlock()
 
-			// update latest block number
-			_, err = c.syncEngine.EventQuerier.UpdateEventQuery(c.syncEngine.GetDatabase(), &query.UpdateEventQueryInput{
-				ID:                &ev.ID,
-				LatestBlockNumber: &latestBlockNumber,
-				UpdatedAt:         &now,
-			}) // TODO: update this
-			if err != nil {
-				c.updateEventError(ev.ID, err, now)
-				return
-			}
+	c.backfillMu.Lock()
+	for id, run := range c.backfills {
+		if !c.owns(backfillKey(id)) {
+			run.cancel()
+			runs = append(runs, run)
+		}
+	}
+	c.backfillMu.Unlock()
 
-			return
-		}(e)
+	for _, run := range runs {
+		<-run.done
 	}
 
-	wg.Wait()
+	// the contracts don't start new subscriptions once their runs finished
+	keys := make(map[string]bool)
+	c.liveMu.Lock()
+	for key := range c.subscriptions {
+		if c.owns(key) {
+			keys[key] = true
+		}
+	}
+	c.liveMu.Unlock()
+	c.stopLive(keys)
+}
 
-	return nil
+// owns returns true when the work identified by the key is assigned to the replica.
+func (c *cronjob) owns(key string) bool {
+	return c.shards == nil || c.shards.Owns(key)
 }
 
-func (c *cronjob) Halt() {
-	c.ticker.Stop()
-	c.status = StatusStopped
-	c.ticker = nil
+func (c *cronjob) setStatus(status CronjobStatus, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.status = status
+	c.error = err
 }
 
 func (c *cronjob) GetStatus() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return string(c.status)
 }
 
@@ -391,6 +390,9 @@ func (c *cronjob) GetSeconds() int64 {
 }
 
 func (c *cronjob) GetError() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.error != nil {
 		return c.error.Error()
 	}
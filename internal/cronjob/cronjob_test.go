@@ -0,0 +1,79 @@
+package cronjob
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/webhook"
+	"github.com/jmoiron/sqlx"
+)
+
+// nopConnector opens connections whose transactions do nothing, the queries of the tests are
+// answered by the fake queriers of the engine.
+type nopConnector struct{}
+
+func (nopConnector) Connect(ctx context.Context) (driver.Conn, error) { return nopConn{}, nil }
+func (nopConnector) Driver() driver.Driver                            { return nil }
+
+type nopConn struct{}
+
+func (nopConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("nop connection doesn't run queries")
+}
+func (nopConn) Close() error              { return nil }
+func (nopConn) Begin() (driver.Tx, error) { return nopTx{}, nil }
+func (nopConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return nopTx{}, nil
+}
+
+type nopTx struct{}
+
+func (nopTx) Commit() error   { return nil }
+func (nopTx) Rollback() error { return nil }
+
+// newTestEngine returns a sync engine with a database that only opens transactions.
+func newTestEngine() *syncng.Engine {
+	return syncng.NewEngine(&syncng.EngineConfig{
+		Database: &storage.Store{DB: sqlx.NewDb(sql.OpenDB(nopConnector{}), "postgres")},
+	})
+}
+
+// newTestCronjob returns a cronjob with the given engine that records the webhooks it sends.
+func newTestCronjob(engine *syncng.Engine) (*cronjob, *fakeWebhookSender) {
+	sender := &fakeWebhookSender{}
+	now := time.Date(2023, 10, 25, 0, 0, 0, 0, time.UTC)
+
+	ids := 0
+	c := New(&Config{
+		Seconds: 1,
+		Engine:  engine,
+		IDGen: func() string {
+			ids++
+			return fmt.Sprintf("id-%d", ids)
+		},
+		DateGen: func() time.Time { return now },
+	})
+	c.webhookSender = sender
+
+	return c, sender
+}
+
+type fakeWebhookSender struct {
+	mu       sync.Mutex
+	webhooks []*webhook.Webhook
+}
+
+func (f *fakeWebhookSender) CreateAndSendWebhook(wh *webhook.Webhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.webhooks = append(f.webhooks, wh)
+	return nil
+}
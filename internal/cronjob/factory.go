@@ -0,0 +1,83 @@
+package cronjob
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// registerFactoryChildren registers the child contracts created by the logs of a factory event
+// for the users of the factory, within the transaction that ingests the logs. The children
+// are synced from their creation block. Users already subscribed to a child keep their own
+// subscription, so the logs ingested again after a reorganization don't change it.
+func (c *cronjob) registerFactoryChildren(txx *sqlx.Tx, signature string, ev *storage.EventRecord, data []*storage.EventDataRecord) error {
+	ft := ev.FactoryTemplate
+	if ft == nil {
+		return nil
+	}
+
+	for _, evData := range data {
+		values := make(map[string]interface{})
+		err := json.Unmarshal(evData.Data, &values)
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.registerFactoryChildren json.Unmarshal error")
+		}
+
+		value, ok := values[ft.ChildAddressArg].(string)
+		if !ok || !common.IsHexAddress(value) {
+			log.Printf("cronjob.registerFactoryChildren log tx=%s of event=%s has no child address in arg=%s \n", evData.Tx, ev.ID, ft.ChildAddressArg)
+			continue
+		}
+		address := common.HexToAddress(value).Hex()
+
+		scUsers, err := c.syncEngine.SmartContractUserQuerier.SelectSmartContractUserQuery(txx, address)
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.registerFactoryChildren c.syncEngine.SmartContractUserQuerier.SelectSmartContractUserQuery error")
+		}
+		subscribed := make(map[string]bool)
+		for _, scu := range scUsers {
+			subscribed[scu.UserID] = true
+		}
+
+		abis, err := ft.ChildABIRecords(address)
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.registerFactoryChildren ft.ChildABIRecords error")
+		}
+
+		for _, scu := range ev.SmartContractUsers {
+			// users only get the children created by the logs matching their filters
+			if subscribed[scu.UserID] || !scu.TopicFilters.Match(signature, evData.Topics) {
+				continue
+			}
+
+			fromBlockNumber := evData.BlockNumber
+			_, err := c.syncEngine.InsertAtomicSmartContractTx(txx, &syncng.InsertAtomicSmartContractInput{
+				UserID:     scu.UserID,
+				Name:       fmt.Sprintf("%s %s", ft.ChildName, address),
+				NodeURL:    ev.NodeURL,
+				WebhookURL: scu.WebhookURL,
+				SmartContract: &storage.SmartContractRecord{
+					Address:            address,
+					Network:            storage.Network(ev.Network),
+					InitialBlockNumber: evData.BlockNumber,
+				},
+				ABI:             abis,
+				FromBlockNumber: &fromBlockNumber,
+			})
+			if err != nil {
+				return errors.Wrap(err, "cronjob: cronjob.registerFactoryChildren c.syncEngine.InsertAtomicSmartContractTx error")
+			}
+			subscribed[scu.UserID] = true
+
+			log.Printf("cronjob.registerFactoryChildren registered child address=%s of factory=%s for user_id=%s from block_number=%d \n", address, ev.Address, scu.UserID, fromBlockNumber)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package cronjob
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jaekwon/testify/require"
+)
+
+const childABI = `[{"name":"Swap","type":"event","anonymous":false,"inputs":[` +
+	`{"indexed":true,"internalType":"address","name":"sender","type":"address"},` +
+	`{"indexed":false,"internalType":"uint256","name":"amount","type":"uint256"}]}]`
+
+var childAddress = common.HexToAddress("0x00000000000000000000000000000000000000cc").Hex()
+
+// fakeContractQuerier keeps the contracts registered by the factories in memory.
+type fakeContractQuerier struct {
+	syncng.SmartContractQuerier
+	syncng.ABIQuerier
+	syncng.SmartContractUserQuerier
+	syncng.EventQuerier
+	contracts map[string]*storage.SmartContractRecord
+	abis      map[string][]*storage.ABIRecord
+	events    map[string][]*storage.EventRecord
+	users     map[string][]*storage.SmartContractUserRecord
+}
+
+func newFakeContractQuerier() *fakeContractQuerier {
+	return &fakeContractQuerier{
+		contracts: make(map[string]*storage.SmartContractRecord),
+		abis:      make(map[string][]*storage.ABIRecord),
+		events:    make(map[string][]*storage.EventRecord),
+		users:     make(map[string][]*storage.SmartContractUserRecord),
+	}
+}
+
+func (f *fakeContractQuerier) InsertSmartContractQuery(tx storage.QueryContext, sc *storage.SmartContractRecord) error {
+	f.contracts[sc.Address] = sc
+	return nil
+}
+
+func (f *fakeContractQuerier) SelectSmartContractByAddressQuery(tx storage.Transaction, address string) (*storage.SmartContractRecord, error) {
+	sc, ok := f.contracts[address]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return sc, nil
+}
+
+func (f *fakeContractQuerier) InsertABIBatchQuery(tx storage.QueryContext, abis []*storage.ABIRecord, address string) error {
+	f.abis[address] = append(f.abis[address], abis...)
+	return nil
+}
+
+func (f *fakeContractQuerier) SelectABIByAddressQuery(tx storage.Transaction, address string) ([]*storage.ABIRecord, error) {
+	return f.abis[address], nil
+}
+
+func (f *fakeContractQuerier) InsertEventBatchQuery(tx storage.QueryContext, events []*storage.EventRecord, address string) error {
+	f.events[address] = append(f.events[address], events...)
+	return nil
+}
+
+func (f *fakeContractQuerier) SelectEventsByAddressQuery(tx storage.Transaction, address string) ([]*storage.EventRecord, error) {
+	return f.events[address], nil
+}
+
+func (f *fakeContractQuerier) UpsertSmartContractUserQuery(tx storage.Transaction, scu *storage.SmartContractUserRecord) error {
+	f.users[scu.SmartContractAddress] = append(f.users[scu.SmartContractAddress], scu)
+	return nil
+}
+
+func (f *fakeContractQuerier) SelectSmartContractUserQuery(tx storage.Transaction, address string) ([]*storage.SmartContractUserRecord, error) {
+	return f.users[address], nil
+}
+
+func newFactoryCronjob() (*cronjob, *fakeContractQuerier) {
+	engine := newTestEngine()
+	contracts := newFakeContractQuerier()
+	engine.SmartContractQuerier = contracts
+	engine.ABIQuerier = contracts
+	engine.SmartContractUserQuerier = contracts
+	engine.EventQuerier = contracts
+
+	c, _ := newTestCronjob(engine)
+	return c, contracts
+}
+
+// factoryEvent returns a factory event followed by the given users, its logs tell the address
+// of the child in the pool argument.
+func factoryEvent(userIDs ...string) *storage.EventRecord {
+	ev := &storage.EventRecord{
+		ID:      "factory-event",
+		Address: "0x00000000000000000000000000000000000000Fa",
+		Network: "ethereum",
+		NodeURL: "https://node",
+		FactoryTemplate: &storage.FactoryTemplateRecord{
+			ChildAddressArg: "pool",
+			ChildName:       "Pool",
+			ChildABI:        json.RawMessage(childABI),
+		},
+	}
+	for _, userID := range userIDs {
+		ev.SmartContractUsers = append(ev.SmartContractUsers, &storage.SmartContractUserRecord{
+			UserID:     userID,
+			WebhookURL: "https://" + userID + ".hook",
+		})
+	}
+
+	return ev
+}
+
+func factoryLog(blockNumber int64, pool string) *storage.EventDataRecord {
+	return &storage.EventDataRecord{
+		Tx:          "0xtx",
+		Data:        json.RawMessage(`{"pool":"` + pool + `"}`),
+		BlockNumber: blockNumber,
+	}
+}
+
+func Test_Cronjob_RegisterFactoryChildren(t *testing.T) {
+	t.Run("registers the child and its events for the users of the factory", func(t *testing.T) {
+		c, contracts := newFactoryCronjob()
+
+		err := c.registerFactoryChildren(nil, "PoolCreated(address)", factoryEvent("user-1", "user-2"), []*storage.EventDataRecord{
+			factoryLog(120, childAddress),
+		})
+		require.NoError(t, err)
+
+		sc, ok := contracts.contracts[childAddress]
+		require.True(t, ok)
+		require.Equal(t, int64(120), sc.InitialBlockNumber)
+		require.Equal(t, storage.Network("ethereum"), sc.Network)
+
+		// the events of the child sync from the creation block
+		require.Len(t, contracts.events[childAddress], 1)
+		ev := contracts.events[childAddress][0]
+		require.Equal(t, "Swap(address,uint256)", ev.Signature)
+		require.Equal(t, int64(120), ev.LatestBlockNumber)
+		require.Equal(t, "https://node", ev.NodeURL)
+
+		users := contracts.users[childAddress]
+		require.Len(t, users, 2)
+		require.Equal(t, "user-1", users[0].UserID)
+		require.Equal(t, "https://user-1.hook", users[0].WebhookURL)
+		require.Equal(t, "Pool "+childAddress, users[0].Name)
+		require.Equal(t, "user-2", users[1].UserID)
+	})
+
+	t.Run("ignores the children already registered", func(t *testing.T) {
+		c, contracts := newFactoryCronjob()
+		ev := factoryEvent("user-1")
+
+		// the same child is created by two logs of the batch
+		err := c.registerFactoryChildren(nil, "PoolCreated(address)", ev, []*storage.EventDataRecord{
+			factoryLog(120, childAddress),
+			factoryLog(121, childAddress),
+		})
+		require.NoError(t, err)
+
+		// and the log is ingested again after a reorganization
+		err = c.registerFactoryChildren(nil, "PoolCreated(address)", ev, []*storage.EventDataRecord{
+			factoryLog(125, childAddress),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, contracts.contracts, 1)
+		require.Len(t, contracts.events[childAddress], 1)
+		require.Len(t, contracts.users[childAddress], 1)
+		require.Equal(t, int64(120), contracts.contracts[childAddress].InitialBlockNumber)
+	})
+
+	t.Run("skips the logs without child address", func(t *testing.T) {
+		c, contracts := newFactoryCronjob()
+
+		err := c.registerFactoryChildren(nil, "PoolCreated(address)", factoryEvent("user-1"), []*storage.EventDataRecord{
+			factoryLog(120, "not an address"),
+		})
+		require.NoError(t, err)
+		require.Len(t, contracts.contracts, 0)
+	})
+}
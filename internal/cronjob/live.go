@@ -0,0 +1,382 @@
+package cronjob
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// liveSubscription is the live ingestion of a contract. While it's running the logs of the
+// contract are only ingested from the subscription and the ticks just update the heads.
+type liveSubscription struct {
+	eventIDs string
+	cancel   context.CancelFunc
+	done     chan struct{}
+	// finalized receives the finalized head of the ticks, it only keeps the latest one
+	finalized chan int64
+}
+
+// eventIDsKey identifies the set of events ingested by a live subscription and the topic
+// filters of their users.
+func eventIDsKey(events []*storage.EventRecord) string {
+	ids := make([]string, 0)
+	for _, ev := range events {
+		ids = append(ids, ev.ID+":"+topicFiltersKey(ev))
+	}
+	sort.Strings(ids)
+
+	return strings.Join(ids, ",")
+}
+
+// isLive returns true when the contract is ingested by a live subscription. When the events
+// of the contract or the filters of their users changed the subscription is stopped, so the
+// contract goes back to polling until all the events caught up again.
+func (c *cronjob) isLive(events []*storage.EventRecord) bool {
+	c.liveMu.Lock()
+	key := contractKey(events[0])
+	live, ok := c.subscriptions[key]
+	if !ok {
+		c.liveMu.Unlock()
+		return false
+	}
+	if live.eventIDs == eventIDsKey(events) {
+		c.liveMu.Unlock()
+		return true
+	}
+	delete(c.subscriptions, key)
+	c.liveMu.Unlock()
+
+	// wait for the subscription to finish so the polling doesn't overlap with it
+	live.cancel()
+	<-live.done
+
+	return false
+}
+
+// notifyLive sends the finalized head of the tick to the live subscription of the contract, so
+// it stores the held logs of the blocks that reached the network finality.
+func (c *cronjob) notifyLive(events []*storage.EventRecord, head *blockchain.Head) {
+	c.liveMu.Lock()
+	live, ok := c.subscriptions[contractKey(events[0])]
+	c.liveMu.Unlock()
+	if !ok {
+		return
+	}
+
+	// replace the head that wasn't received yet, only the latest one matters
+	select {
+	case <-live.finalized:
+	default:
+	}
+	select {
+	case live.finalized <- head.Finalized:
+	default:
+	}
+}
+
+// hasLive returns true when the contract of the key is ingested by a live subscription.
+func (c *cronjob) hasLive(key string) bool {
+	c.liveMu.Lock()
+	defer c.liveMu.Unlock()
+
+	_, ok := c.subscriptions[key]
+	return ok
+}
+
+// startLive starts the live ingestion of a contract synced from a WebSocket node once every
+// event caught up with the sync head. It does nothing when the contract is already live.
+func (c *cronjob) startLive(nodeURL string, events []*storage.EventRecord, head *blockchain.Head) {
+	first := events[0]
+	if !c.liveLogs || !blockchain.IsWebSocketURL(nodeURL) {
+		return
+	}
+
+	// the subscription starts from the oldest checkpoint, so the gap-fill covers every event
+	fromBlockNumber := first.LatestBlockNumber
+	for _, ev := range events {
+		if ev.LatestBlockNumber < head.Finalized {
+			return
+		}
+		if ev.LatestBlockNumber < fromBlockNumber {
+			fromBlockNumber = ev.LatestBlockNumber
+		}
+	}
+
+	key := contractKey(first)
+	ctx, cancel := context.WithCancel(context.Background())
+	live := &liveSubscription{
+		eventIDs:  eventIDsKey(events),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		finalized: make(chan int64, 1),
+	}
+
+	c.liveMu.Lock()
+	if _, ok := c.subscriptions[key]; ok {
+		c.liveMu.Unlock()
+		cancel()
+		return
+	}
+	c.subscriptions[key] = live
+	c.liveMu.Unlock()
+
+	go func() {
+		defer close(live.done)
+		defer cancel()
+
+		// the subscription keeps its own client of the node while it runs
+		client, liveURL, err := c.pool.Client(string(first.Network), nodeURL)
+		if err == nil && liveURL != nodeURL {
+			c.pool.Release(client)
+			err = errors.New("cronjob: cronjob.startLive node isn't healthy")
+		}
+		if err == nil {
+			err = c.runLive(ctx, client, nodeURL, events, fromBlockNumber, head.Finalized, live.finalized)
+			c.pool.Release(client)
+		}
+
+		// fall back to polling, the next tick ingests again from the event checkpoints
+		c.liveMu.Lock()
+		if c.subscriptions[key] == live {
+			delete(c.subscriptions, key)
+		}
+		c.liveMu.Unlock()
+
+		if err != nil {
+			log.Printf("cronjob.startLive subscription of address=%s failed, falling back to polling: %s \n", first.Address, err.Error())
+			now := c.dateGen()
+			for _, ev := range events {
+				c.updateEventError(ev.ID, err, now)
+			}
+		}
+	}()
+}
+
+// runLive subscribes to the new logs of the contract and fills the gap between the event
+// checkpoints and the moment the subscription was established. The logs of the blocks
+// covered by the gap-fill are skipped from the subscription so they aren't ingested twice.
+// The logs after the finalized head are held until their block reaches the finality of the
+// network, like the ones of the ticks, so the rows are never written from blocks that could
+// still be reorganized. It returns when the context is canceled or the subscription fails.
+func (c *cronjob) runLive(ctx context.Context, client *blockchain.Client, nodeURL string, events []*storage.EventRecord, fromBlockNumber int64, finalized int64, heads <-chan int64) error {
+	first := events[0]
+
+	contractABI, signatures, eventsBySignature := c.prepareContractEvents(events, c.dateGen())
+	if len(signatures) != len(events) {
+		return errors.New("cronjob: cronjob.runLive invalid events abi error")
+	}
+	filters := topicFilters(eventsBySignature)
+
+	// subscribe before the gap-fill, so the logs mined meanwhile are received
+	sub, err := blockchain.SubscribeLogs(ctx, blockchain.SubscribeConfig{
+		Client:          client,
+		ABI:             contractABI,
+		EventSignatures: signatures,
+		Address:         first.Address,
+		Headers:         c.headers,
+		TopicFilters:    filters,
+		RawLogs:         rawLogs(first),
+	})
+	if err != nil {
+		c.pool.Report(nodeURL, err)
+		return errors.Wrap(err, "cronjob: cronjob.runLive blockchain.SubscribeLogs error")
+	}
+	defer sub.Unsubscribe()
+
+	window, err := c.getWindow(nodeURL, first.Network)
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.runLive c.getWindow error")
+	}
+	starts := coverageStarts(eventsBySignature)
+
+	// held are the logs after the finalized head, in the order they were received
+	held := make([]blockchain.LogData, 0)
+	coveredTo := fromBlockNumber - 1
+
+	// fill the gap up to the latest block of the node, later blocks come from the subscription
+	_, gapEnd, err := c.walkLogs(ctx, nodeURL, blockchain.Config{
+		Client:          client,
+		ABI:             contractABI,
+		EventSignatures: signatures,
+		Address:         first.Address,
+		FromBlockNumber: &fromBlockNumber,
+		Logger:          c.debug,
+		Window:          window,
+		Headers:         c.headers,
+		TopicFilters:    filters,
+		RawLogs:         rawLogs(first),
+	}, func(txx *sqlx.Tx, batch blockchain.LogBatch) error {
+		ready := make([]blockchain.LogData, 0)
+		for _, l := range batch.Logs {
+			if int64(l.BlockNumber) > finalized {
+				held = append(held, l)
+				continue
+			}
+			ready = append(ready, l)
+		}
+
+		now := c.dateGen()
+		err := c.insertContractLogs(txx, eventsBySignature, ready, false, now)
+		if err != nil || batch.FromBlockNumber > finalized {
+			return err
+		}
+		toBlockNumber := batch.ToBlockNumber
+		if toBlockNumber > finalized {
+			toBlockNumber = finalized
+		}
+		coveredTo = toBlockNumber
+		return c.insertCoverage(txx, eventsBySignature, starts, batch.FromBlockNumber, toBlockNumber, storage.CoverageSourceLive, now)
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return errors.Wrap(err, "cronjob: cronjob.runLive c.walkLogs error")
+	}
+
+	log.Printf("cronjob.runLive streaming logs of address=%s from block_number=%d \n", first.Address, gapEnd+1)
+
+	// release stores the held logs of the blocks that reached the finalized head. The logs are
+	// received in order, so a log covers every block since the previous one.
+	release := func() error {
+		ready := make([]blockchain.LogData, 0)
+		pending := make([]blockchain.LogData, 0)
+		for _, l := range held {
+			if int64(l.BlockNumber) <= finalized {
+				ready = append(ready, l)
+				continue
+			}
+			pending = append(pending, l)
+		}
+		if len(ready) == 0 {
+			return nil
+		}
+
+		now := c.dateGen()
+		toBlockNumber := int64(ready[len(ready)-1].BlockNumber)
+		err := c.syncEngine.InTransaction(func(txx *sqlx.Tx) error {
+			err := c.insertContractLogs(txx, eventsBySignature, ready, false, now)
+			if err != nil || toBlockNumber <= coveredTo {
+				return err
+			}
+			return c.insertCoverage(txx, eventsBySignature, nil, coveredTo+1, toBlockNumber, storage.CoverageSourceLive, now)
+		})
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.runLive c.insertContractLogs error")
+		}
+		if toBlockNumber > coveredTo {
+			coveredTo = toBlockNumber
+		}
+		held = pending
+
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err := <-sub.Err():
+			if ctx.Err() != nil {
+				return nil
+			}
+			c.pool.Report(nodeURL, err)
+			return errors.Wrap(err, "cronjob: cronjob.runLive subscription error")
+
+		case f := <-heads:
+			if f <= finalized {
+				continue
+			}
+			finalized = f
+
+			err := release()
+			if err != nil {
+				return err
+			}
+
+		case l := <-sub.Logs():
+			now := c.dateGen()
+			ev, ok := eventsBySignature[l.EventSignature]
+			if !ok && !l.IsRaw() {
+				continue
+			}
+			blockNumber := int64(l.BlockNumber)
+
+			// the log was reverted by a reorganization, its canonical replacement is received later
+			if l.Removed {
+				// the held logs of the block weren't stored yet
+				if blockNumber > finalized {
+					kept := make([]blockchain.LogData, 0)
+					for _, h := range held {
+						if h.BlockHash != l.BlockHash {
+							kept = append(kept, h)
+						}
+					}
+					held = kept
+					if blockNumber <= gapEnd {
+						gapEnd = blockNumber - 1
+					}
+					continue
+				}
+
+				var err error
+				if ok {
+					err = c.rollbackEvent(ev, events, blockNumber, now)
+				} else {
+					// raw logs without event are only stored by contracts in raw logs mode
+					err = c.syncEngine.RawLogQuerier.DeleteRawLogsFromBlockQuery(c.syncEngine.GetDatabase(), first.SmartContractAddress, blockNumber)
+				}
+				if err != nil {
+					return errors.Wrap(err, "cronjob: cronjob.runLive rollback error")
+				}
+				if blockNumber <= gapEnd {
+					gapEnd = blockNumber - 1
+				}
+				if blockNumber <= coveredTo {
+					coveredTo = blockNumber - 1
+				}
+				continue
+			}
+
+			// the block was already ingested by the gap-fill
+			if blockNumber <= gapEnd {
+				continue
+			}
+
+			held = append(held, l)
+			err := release()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// stopLive stops the live subscriptions of the contracts that aren't running anymore, or every
+// subscription when keys is nil, and waits until they finish.
+func (c *cronjob) stopLive(keys map[string]bool) {
+	stopped := make([]*liveSubscription, 0)
+
+	c.liveMu.Lock()
+	for key, live := range c.subscriptions {
+		if keys != nil && keys[key] {
+			continue
+		}
+
+		live.cancel()
+		stopped = append(stopped, live)
+		delete(c.subscriptions, key)
+	}
+	c.liveMu.Unlock()
+
+	for _, live := range stopped {
+		<-live.done
+	}
+}
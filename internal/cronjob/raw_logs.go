@@ -0,0 +1,42 @@
+package cronjob
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// rawLogs returns true when every log of the contract of the event is stored as a raw log.
+func rawLogs(ev *storage.EventRecord) bool {
+	return ev.SmartContract != nil && ev.SmartContract.RawLogs
+}
+
+// anyEvent returns one of the events, or nil when there isn't any.
+func anyEvent(eventsBySignature map[string]*storage.EventRecord) *storage.EventRecord {
+	for _, ev := range eventsBySignature {
+		return ev
+	}
+
+	return nil
+}
+
+// insertRawLogs stores every log of a contract in raw logs mode, whether or not it belongs
+// to an event of the contract, so the events added later are backfilled from them.
+func (c *cronjob) insertRawLogs(txx *sqlx.Tx, ev *storage.EventRecord, logs []blockchain.LogData, now time.Time) error {
+	records := make([]*storage.RawLogRecord, 0)
+	for _, l := range logs {
+		rl := &storage.RawLogRecord{}
+		rl.FromLogData(&l, c.idGen(), ev.SmartContractAddress, now)
+		records = append(records, rl)
+	}
+
+	err := c.syncEngine.RawLogQuerier.InsertRawLogBatchQuery(txx, records)
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.insertRawLogs c.syncEngine.RawLogQuerier.InsertRawLogBatchQuery error")
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package cronjob
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// handleReorg compares the block hashes stored for the latest synced blocks of the events of
+// a contract, and the hash of their checkpoint block, with the canonical chain of the node. The
+// headers are requested once for all the events. When they differ, the orphaned event data is
+// rolled back, the event latest block number is rewound to the block before the fork so the
+// range is ingested again, and a "removed" webhook is sent for every log that disappeared.
+func (c *cronjob) handleReorg(client blockchain.HeaderReader, events []*storage.EventRecord, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	headers := blockchain.NewHeaderMemo(client)
+	for _, ev := range events {
+		blocks, err := c.syncEngine.EventDataQuerier.SelectEventDataBlocksQuery(
+			c.syncEngine.GetDatabase(),
+			ev.ID,
+			ev.LatestBlockNumber-c.reorgDepth,
+		)
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.handleReorg c.syncEngine.EventDataQuerier.SelectEventDataBlocksQuery error")
+		}
+
+		refs := make([]blockchain.BlockRef, 0)
+		for _, b := range blocks {
+			refs = append(refs, blockchain.BlockRef{
+				Number: b.BlockNumber,
+				Hash:   common.HexToHash(b.BlockHash),
+			})
+		}
+
+		// the checkpoint block detects the reorganizations of blocks without logs of the event
+		if ev.CheckpointBlockHash != "" {
+			refs = append(refs, blockchain.BlockRef{
+				Number: ev.CheckpointBlockNumber,
+				Hash:   common.HexToHash(ev.CheckpointBlockHash),
+			})
+		}
+		if len(refs) == 0 {
+			continue
+		}
+
+		forkBlock, err := blockchain.FindReorg(ctx, headers, refs)
+		if err != nil {
+			return errors.Wrap(err, "cronjob: cronjob.handleReorg blockchain.FindReorg error")
+		}
+		if forkBlock == nil {
+			continue
+		}
+
+		log.Printf("cronjob.handleReorg reorg detected for event_id=%s at block_number=%d \n", ev.ID, *forkBlock)
+
+		err = c.rollbackEvent(ev, events, *forkBlock, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollbackEvent rolls back the event data from the fork block, with the data of the other events
+// of the contract synced past it, and sends a "removed" webhook for every log that disappeared.
+func (c *cronjob) rollbackEvent(ev *storage.EventRecord, events []*storage.EventRecord, forkBlock int64, now time.Time) error {
+	output, err := c.syncEngine.RollbackEventData(&syncng.RollbackEventDataInput{
+		EventID:         ev.ID,
+		ForkBlockNumber: forkBlock,
+		UpdatedAt:       now,
+	})
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.rollbackEvent c.syncEngine.RollbackEventData error")
+	}
+
+	byID := make(map[string]*storage.EventRecord)
+	for _, e := range events {
+		byID[e.ID] = e
+	}
+	byID[ev.ID] = ev
+	for _, rolledBack := range append([]*syncng.RollbackEventDataOutput{output}, output.Siblings...) {
+		// the events of the contract that aren't synced by the run, like the stopped ones, aren't notified
+		e, ok := byID[rolledBack.Event.ID]
+		if !ok {
+			continue
+		}
+		e.LatestBlockNumber = rolledBack.Event.LatestBlockNumber
+		e.CheckpointBlockNumber = rolledBack.Event.CheckpointBlockNumber
+		e.CheckpointBlockHash = rolledBack.Event.CheckpointBlockHash
+
+		err = c.notifyRemoved(e, rolledBack.RemovedEventsData, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyRemoved sends a "removed" webhook to the users of the event for every log rolled back.
+func (c *cronjob) notifyRemoved(ev *storage.EventRecord, removed []*storage.EventDataRecord, now time.Time) error {
+	for _, scu := range ev.SmartContractUsers {
+		if scu.WebhookURL == "" {
+			continue
+		}
+
+		for _, evData := range removed {
+			// logs before the initial block number never had a webhook sent
+			if evData.BlockNumber < ev.SmartContract.InitialBlockNumber {
+				continue
+			}
+
+			// users only got the webhooks of the logs matching their filters
+			if !scu.TopicFilters.Match(ev.Signature, evData.Topics) {
+				continue
+			}
+
+			wh, err := evData.ToRemovedWebhookEvent(c.idGen(), ev, scu.WebhookURL, now)
+			if err != nil {
+				return errors.Wrap(err, "cronjob: cronjob.notifyRemoved evData.ToRemovedWebhookEvent error")
+			}
+
+			err = c.sendWebhook(scu.UserID, wh)
+			if err != nil {
+				return errors.Wrap(err, "cronjob: cronjob.notifyRemoved c.sendWebhook error")
+			}
+		}
+	}
+
+	return nil
+}
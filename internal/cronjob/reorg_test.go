@@ -0,0 +1,294 @@
+package cronjob
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/darchlabs/synchronizer-v2/pkg/webhook"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jaekwon/testify/require"
+)
+
+// reorgChain returns the canonical headers of a chain whose blocks from forkBlock on were
+// replaced by a reorganization.
+type reorgChain struct {
+	forkBlock int64
+	requests  map[int64]int
+}
+
+func (f *reorgChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	f.requests[number.Int64()]++
+	return reorgHeader(number.Int64(), number.Int64() >= f.forkBlock), nil
+}
+
+func reorgHeader(number int64, reorged bool) *types.Header {
+	header := &types.Header{Number: big.NewInt(number)}
+	if reorged {
+		header.Extra = []byte("reorged")
+	}
+
+	return header
+}
+
+// fakeEventDataQuerier keeps the event data of the events in memory.
+type fakeEventDataQuerier struct {
+	syncng.EventDataQuerier
+	data map[string][]*storage.EventDataRecord
+}
+
+func (f *fakeEventDataQuerier) SelectEventDataBlocksQuery(tx storage.Transaction, eventID string, fromBlockNumber int64) ([]*storage.EventDataBlockRecord, error) {
+	blocks := make([]*storage.EventDataBlockRecord, 0)
+	for _, ed := range f.data[eventID] {
+		if ed.BlockNumber >= fromBlockNumber && ed.BlockHash != "" {
+			blocks = append(blocks, &storage.EventDataBlockRecord{BlockNumber: ed.BlockNumber, BlockHash: ed.BlockHash})
+		}
+	}
+
+	return blocks, nil
+}
+
+func (f *fakeEventDataQuerier) DeleteEventDataFromBlockQuery(tx storage.Transaction, eventID string, fromBlockNumber int64) ([]*storage.EventDataRecord, error) {
+	kept := make([]*storage.EventDataRecord, 0)
+	removed := make([]*storage.EventDataRecord, 0)
+	for _, ed := range f.data[eventID] {
+		if ed.BlockNumber >= fromBlockNumber {
+			removed = append(removed, ed)
+			continue
+		}
+		kept = append(kept, ed)
+	}
+	f.data[eventID] = kept
+
+	return removed, nil
+}
+
+// fakeEventQuerier keeps the events in memory.
+type fakeEventQuerier struct {
+	syncng.EventQuerier
+	events map[string]*storage.EventRecord
+}
+
+func (f *fakeEventQuerier) SelectEventsByAddressQuery(tx storage.Transaction, address string) ([]*storage.EventRecord, error) {
+	events := make([]*storage.EventRecord, 0)
+	for _, ev := range f.events {
+		if ev.Address == address {
+			copied := *ev
+			events = append(events, &copied)
+		}
+	}
+
+	return events, nil
+}
+
+func (f *fakeEventQuerier) UpdateEventQuery(tx storage.Transaction, input *query.UpdateEventQueryInput) (*storage.EventRecord, error) {
+	ev := f.events[*input.ID]
+	if input.LatestBlockNumber != nil {
+		ev.LatestBlockNumber = *input.LatestBlockNumber
+	}
+	if input.CheckpointBlockNumber != nil {
+		ev.CheckpointBlockNumber = *input.CheckpointBlockNumber
+	}
+	if input.CheckpointBlockHash != nil {
+		ev.CheckpointBlockHash = *input.CheckpointBlockHash
+	}
+	if input.Failures != nil {
+		ev.Failures = *input.Failures
+	}
+	if input.NextRunAt != nil {
+		ev.NextRunAt = input.NextRunAt
+	}
+	if input.Status != nil {
+		ev.Status = *input.Status
+	}
+	if input.Error != nil {
+		ev.Error = *input.Error
+	}
+
+	copied := *ev
+	return &copied, nil
+}
+
+// fakeRollbackQuerier records the blocks the rows orphaned by a reorganization are deleted from.
+type fakeRollbackQuerier struct {
+	syncng.QuarantinedLogQuerier
+	syncng.CoverageQuerier
+	syncng.RawLogQuerier
+	syncng.BlockQuerier
+	forks  []int64
+	blocks []string
+}
+
+func (f *fakeRollbackQuerier) DeleteQuarantinedLogsFromBlockQuery(tx storage.Transaction, eventID string, fromBlockNumber int64) error {
+	f.forks = append(f.forks, fromBlockNumber)
+	return nil
+}
+
+func (f *fakeRollbackQuerier) DeleteCoverageFromBlockQuery(tx storage.Transaction, eventID string, fromBlockNumber int64) error {
+	return nil
+}
+
+func (f *fakeRollbackQuerier) DeleteRawLogsFromBlockQuery(tx storage.Transaction, scAddress string, fromBlockNumber int64) error {
+	return nil
+}
+
+func (f *fakeRollbackQuerier) DeleteBlocksByHashesQuery(tx storage.Transaction, network storage.EventNetwork, hashes []string) error {
+	f.blocks = append(f.blocks, hashes...)
+	return nil
+}
+
+func storedEventData(eventID string, numbers ...int64) []*storage.EventDataRecord {
+	data := make([]*storage.EventDataRecord, 0)
+	for _, n := range numbers {
+		data = append(data, &storage.EventDataRecord{
+			ID:          eventID + "-" + big.NewInt(n).String(),
+			EventID:     eventID,
+			Tx:          "0xtx",
+			Data:        json.RawMessage(`{}`),
+			BlockNumber: n,
+			BlockHash:   reorgHeader(n, false).Hash().Hex(),
+		})
+	}
+
+	return data
+}
+
+func newReorgCronjob(events []*storage.EventRecord, data map[string][]*storage.EventDataRecord) (*cronjob, *fakeWebhookSender, *fakeRollbackQuerier) {
+	engine := newTestEngine()
+	rollback := &fakeRollbackQuerier{}
+	stored := make(map[string]*storage.EventRecord)
+	for _, ev := range events {
+		copied := *ev
+		stored[ev.ID] = &copied
+	}
+	engine.EventQuerier = &fakeEventQuerier{events: stored}
+	engine.EventDataQuerier = &fakeEventDataQuerier{data: data}
+	engine.QuarantinedLogQuerier = rollback
+	engine.CoverageQuerier = rollback
+	engine.RawLogQuerier = rollback
+	engine.BlockQuerier = rollback
+
+	c, sender := newTestCronjob(engine)
+	return c, sender, rollback
+}
+
+func Test_Cronjob_HandleReorg(t *testing.T) {
+	sc := &storage.SmartContractRecord{InitialBlockNumber: 105}
+	users := []*storage.SmartContractUserRecord{
+		{UserID: "with-webhook", WebhookURL: "https://example.com/webhook"},
+		{UserID: "without-webhook"},
+	}
+
+	t.Run("rolls back the event data of the orphaned blocks", func(t *testing.T) {
+		transfer := &storage.EventRecord{ID: "transfer", Address: "0x1", Network: "ethereum", Signature: "Transfer(address,address,uint256)", LatestBlockNumber: 120, SmartContract: sc, SmartContractUsers: users}
+		approval := &storage.EventRecord{ID: "approval", Address: "0x1", Network: "ethereum", Signature: "Approval(address,address,uint256)", LatestBlockNumber: 120, SmartContract: sc, SmartContractUsers: users}
+		data := map[string][]*storage.EventDataRecord{
+			"transfer": storedEventData("transfer", 100, 108, 112, 118),
+			"approval": storedEventData("approval", 100, 108),
+		}
+		c, sender, rollback := newReorgCronjob([]*storage.EventRecord{transfer, approval}, data)
+		chain := &reorgChain{forkBlock: 110, requests: make(map[int64]int)}
+
+		err := c.handleReorg(chain, []*storage.EventRecord{transfer, approval}, c.dateGen())
+		require.NoError(t, err)
+
+		// the fork is right after the newest canonical block of the event, and the other event
+		// of the contract is rewound with it because the raw logs of the contract are deleted
+		require.Equal(t, []int64{109, 109}, rollback.forks)
+		require.Equal(t, int64(108), transfer.LatestBlockNumber)
+		require.Len(t, data["transfer"], 2)
+		require.Equal(t, int64(108), approval.LatestBlockNumber)
+		require.Len(t, data["approval"], 2)
+
+		// only the headers of the removed rows are deleted
+		require.Equal(t, []string{reorgHeader(112, false).Hash().Hex(), reorgHeader(118, false).Hash().Hex()}, rollback.blocks)
+
+		// the headers are requested once for the events of the contract
+		for number, requests := range chain.requests {
+			require.Equal(t, 1, requests, "block %d", number)
+		}
+
+		// only the users with webhooks are notified of the removed logs
+		require.Len(t, sender.webhooks, 2)
+		for _, wh := range sender.webhooks {
+			require.Equal(t, "with-webhook", wh.UserID)
+			require.Equal(t, webhook.WebhookEntityType(storage.WebhookEntityTypeEvent), wh.EntityType)
+
+			var payload webhook.WebhookEventPayload
+			require.NoError(t, json.Unmarshal(wh.Payload, &payload))
+			require.True(t, payload.Removed)
+			require.True(t, payload.BlockNumber >= 110)
+		}
+	})
+
+	t.Run("keeps the data of other contracts of the network", func(t *testing.T) {
+		transfer := &storage.EventRecord{ID: "transfer", Address: "0x1", Network: "ethereum", LatestBlockNumber: 120, SmartContract: sc}
+		other := &storage.EventRecord{ID: "other", Address: "0x2", Network: "ethereum", LatestBlockNumber: 120, SmartContract: sc}
+		data := map[string][]*storage.EventDataRecord{
+			"transfer": storedEventData("transfer", 100, 112),
+			"other":    storedEventData("other", 100, 115),
+		}
+		c, _, rollback := newReorgCronjob([]*storage.EventRecord{transfer, other}, data)
+		chain := &reorgChain{forkBlock: 110, requests: make(map[int64]int)}
+
+		err := c.handleReorg(chain, []*storage.EventRecord{transfer}, c.dateGen())
+		require.NoError(t, err)
+		require.Equal(t, []int64{101}, rollback.forks)
+		require.Equal(t, int64(100), transfer.LatestBlockNumber)
+		require.Equal(t, []string{reorgHeader(112, false).Hash().Hex()}, rollback.blocks)
+
+		// the event of the other contract detects the reorganization on its own run
+		require.Equal(t, int64(120), other.LatestBlockNumber)
+		require.Len(t, data["other"], 2)
+	})
+
+	t.Run("detects the reorganization of the checkpoint block without logs", func(t *testing.T) {
+		ev := &storage.EventRecord{
+			ID:                    "transfer",
+			LatestBlockNumber:     130,
+			CheckpointBlockNumber: 130,
+			CheckpointBlockHash:   reorgHeader(130, false).Hash().Hex(),
+			SmartContract:         sc,
+			SmartContractUsers:    users,
+		}
+		data := map[string][]*storage.EventDataRecord{
+			"transfer": storedEventData("transfer", 100),
+		}
+		c, sender, rollback := newReorgCronjob([]*storage.EventRecord{ev}, data)
+		chain := &reorgChain{forkBlock: 125, requests: make(map[int64]int)}
+
+		err := c.handleReorg(chain, []*storage.EventRecord{ev}, c.dateGen())
+		require.NoError(t, err)
+		require.Equal(t, []int64{101}, rollback.forks)
+		require.Equal(t, int64(100), ev.LatestBlockNumber)
+		require.Equal(t, "", ev.CheckpointBlockHash)
+		require.Len(t, sender.webhooks, 0)
+	})
+
+	t.Run("keeps the canonical event data", func(t *testing.T) {
+		ev := &storage.EventRecord{
+			ID:                    "transfer",
+			LatestBlockNumber:     130,
+			CheckpointBlockNumber: 130,
+			CheckpointBlockHash:   reorgHeader(130, false).Hash().Hex(),
+			SmartContract:         sc,
+			SmartContractUsers:    users,
+		}
+		data := map[string][]*storage.EventDataRecord{
+			"transfer": storedEventData("transfer", 100, 120),
+		}
+		c, sender, rollback := newReorgCronjob([]*storage.EventRecord{ev}, data)
+		chain := &reorgChain{forkBlock: 200, requests: make(map[int64]int)}
+
+		err := c.handleReorg(chain, []*storage.EventRecord{ev}, c.dateGen())
+		require.NoError(t, err)
+		require.Len(t, rollback.forks, 0)
+		require.Equal(t, int64(130), ev.LatestBlockNumber)
+		require.Len(t, sender.webhooks, 0)
+		require.Equal(t, map[int64]int{130: 1}, chain.requests)
+	})
+}
@@ -0,0 +1,272 @@
+package cronjob
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+)
+
+const (
+	// defaultMaxWorkers is the number of contracts synced at the same time when no limit is configured
+	defaultMaxWorkers = 16
+	// defaultMaxBackoff is the longest delay between the runs of a failing contract when no
+	// limit is configured
+	defaultMaxBackoff = 10 * time.Minute
+)
+
+// scheduledContract is a contract synced by the scheduler on its own cadence. A contract that
+// fails is retried with an exponential backoff, and the contracts behind the head run again
+// as soon as a worker is free.
+type scheduledContract struct {
+	key      string
+	network  string
+	events   []*storage.EventRecord
+	nextRun  time.Time
+	failures int
+	running  bool
+	// run is the run of the contract while it's running
+	run *backgroundRun
+}
+
+// lag returns the number of finalized blocks the most delayed event of the contract is behind.
+func (s *scheduledContract) lag() int64 {
+	lag := int64(0)
+	for _, ev := range s.events {
+		if l := ev.FinalizedBlockNumber - ev.LatestBlockNumber; l > lag {
+			lag = l
+		}
+	}
+
+	return lag
+}
+
+// backoff returns the delay before the next run after the given consecutive failures.
+func (c *cronjob) backoff(failures int) time.Duration {
+	delay := time.Duration(c.seconds) * time.Second
+	if delay <= 0 {
+		delay = time.Second
+	}
+	for i := 1; i < failures && delay < c.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+
+	return delay
+}
+
+// refreshSchedule updates the contracts of the scheduler with the running and errored events.
+// New contracts keep the backoff stored by previous runs, and the contracts without events
+// are removed once their run finishes.
+func (c *cronjob) refreshSchedule(contracts [][]*storage.EventRecord, now time.Time) {
+	c.schedMu.Lock()
+	defer c.schedMu.Unlock()
+
+	keys := make(map[string]bool)
+	for _, events := range contracts {
+		key := contractKey(events[0])
+		keys[key] = true
+
+		s, ok := c.scheduled[key]
+		if !ok {
+			s = &scheduledContract{
+				key:     key,
+				network: string(events[0].Network),
+				nextRun: now,
+			}
+			for _, ev := range events {
+				if ev.Failures > s.failures {
+					s.failures = ev.Failures
+				}
+				if ev.NextRunAt != nil && ev.NextRunAt.After(s.nextRun) {
+					s.nextRun = *ev.NextRunAt
+				}
+			}
+			c.scheduled[key] = s
+		}
+
+		// the events of a running contract are replaced when the run finishes
+		if !s.running {
+			s.events = events
+		}
+	}
+
+	for key, s := range c.scheduled {
+		if !keys[key] && !s.running {
+			delete(c.scheduled, key)
+		}
+	}
+}
+
+// markDue makes the contracts of the networks run on the next dispatch, except the ones
+// waiting for their backoff.
+func (c *cronjob) markDue(networks map[string]bool, now time.Time) {
+	c.schedMu.Lock()
+	defer c.schedMu.Unlock()
+
+	for _, s := range c.scheduled {
+		if networks[s.network] && s.failures == 0 && s.nextRun.After(now) {
+			s.nextRun = now
+		}
+	}
+}
+
+// dispatch starts the contracts whose next run is due, up to the max workers running at the same
+// time. The contracts closer to the head run first, so a contract catching up with a long
+// range doesn't delay the ones following the chain. It returns the time until the next
+// contract is due.
+func (c *cronjob) dispatch(now time.Time) time.Duration {
+	c.schedMu.Lock()
+	defer c.schedMu.Unlock()
+
+	due := make([]*scheduledContract, 0)
+	next := time.Duration(c.seconds) * time.Second
+	for _, s := range c.scheduled {
+		// the contracts of the shards dropped by the replica are synced by other replica
+		if s.running || !c.owns(s.key) {
+			continue
+		}
+		if wait := s.nextRun.Sub(now); wait > 0 {
+			if wait < next {
+				next = wait
+			}
+			continue
+		}
+		due = append(due, s)
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].lag() == due[j].lag() {
+			return due[i].nextRun.Before(due[j].nextRun)
+		}
+		return due[i].lag() < due[j].lag()
+	})
+
+	for _, s := range due {
+		if c.runningContracts >= c.maxWorkers {
+			break
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		run := &backgroundRun{
+			cancel: cancel,
+			done:   make(chan struct{}),
+		}
+		s.running = true
+		s.run = run
+		c.runningContracts++
+		c.runs.Add(1)
+		go func(s *scheduledContract, events []*storage.EventRecord) {
+			defer c.runs.Done()
+			defer close(run.done)
+			defer cancel()
+
+			err := c.syncContract(ctx, events)
+			c.finishContract(s, err)
+		}(s, s.events)
+	}
+
+	return next
+}
+
+// finishContract stores the result of the run of the contract and schedules the next one. The
+// contracts still behind the head run again right away, the failed ones after the backoff.
+func (c *cronjob) finishContract(s *scheduledContract, err error) {
+	now := c.dateGen()
+
+	c.schedMu.Lock()
+	s.running = false
+	s.run = nil
+	c.runningContracts--
+
+	errString := ""
+	previousFailures := s.failures
+	if err != nil {
+		s.failures++
+		s.nextRun = now.Add(c.backoff(s.failures))
+		errString = err.Error()
+		log.Printf("cronjob.finishContract contract=%s failed %d times, retrying at %s: %s \n", s.key, s.failures, s.nextRun.Format(time.RFC3339), errString)
+	} else {
+		s.failures = 0
+		s.nextRun = now.Add(time.Duration(c.seconds) * time.Second)
+		if s.lag() > 0 && !c.hasLive(s.key) {
+			s.nextRun = now
+		}
+	}
+	failures := s.failures
+	nextRun := s.nextRun
+
+	// the events are only updated when they failed or recovered from an error. They are
+	// written before releasing the lock, the next dispatch reads them from another goroutine
+	errored := previousFailures > 0
+	for _, ev := range s.events {
+		if ev.Status == storage.EventStatusError || ev.Error != "" {
+			errored = true
+		}
+	}
+	ids := make([]string, 0)
+	if err != nil || errored {
+		for _, ev := range s.events {
+			ids = append(ids, ev.ID)
+			ev.Failures = failures
+			ev.Error = errString
+			ev.Status = storage.EventStatusRunning
+			if err != nil {
+				ev.Status = storage.EventStatusError
+			}
+		}
+	}
+	c.schedMu.Unlock()
+
+	// wake up the scheduler, the worker is free
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	uerr := c.syncEngine.ScheduleEvents(&syncng.ScheduleEventsInput{
+		EventIDs:  ids,
+		Failures:  failures,
+		NextRunAt: nextRun,
+		Error:     errString,
+		UpdatedAt: now,
+	})
+	if uerr != nil {
+		log.Printf("cronjob.finishContract error scheduling contract=%s: %s \n", s.key, uerr.Error())
+	}
+}
+
+// idleContracts returns the events of the contracts that aren't running.
+func (c *cronjob) idleContracts() [][]*storage.EventRecord {
+	c.schedMu.Lock()
+	defer c.schedMu.Unlock()
+
+	contracts := make([][]*storage.EventRecord, 0)
+	for _, s := range c.scheduled {
+		if !s.running {
+			contracts = append(contracts, s.events)
+		}
+	}
+
+	return contracts
+}
+
+// resetTimer waits the given duration with the timer, draining it when it already fired.
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
+}
@@ -0,0 +1,230 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/rpcpool"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/jaekwon/testify/require"
+)
+
+// fakeEventErrorQuerier records the errors stored for the events.
+type fakeEventErrorQuerier struct {
+	syncng.EventErrorQuerier
+	errors []*storage.EventErrorRecord
+}
+
+func (f *fakeEventErrorQuerier) InsertEventErrorQuery(tx storage.Transaction, record *storage.EventErrorRecord, max int) error {
+	f.errors = append(f.errors, record)
+	return nil
+}
+
+// fakeShards owns the keys of the set.
+type fakeShards struct {
+	owned map[string]bool
+}
+
+func (f *fakeShards) Owns(key string) bool {
+	return f.owned[key]
+}
+
+// newSchedulerCronjob returns a cronjob whose contracts fail to get a client, the events are
+// stored in memory.
+func newSchedulerCronjob(events ...*storage.EventRecord) (*cronjob, *fakeEventQuerier) {
+	engine := newTestEngine()
+	stored := make(map[string]*storage.EventRecord)
+	for _, ev := range events {
+		copied := *ev
+		stored[ev.ID] = &copied
+	}
+	querier := &fakeEventQuerier{events: stored}
+	engine.EventQuerier = querier
+	engine.EventErrorQuerier = &fakeEventErrorQuerier{}
+
+	c, _ := newTestCronjob(engine)
+	c.maxBackoff = 10 * time.Second
+	c.pool = rpcpool.New(rpcpool.Config{})
+
+	return c, querier
+}
+
+func schedulerEvent(id string, network string, address string, latest int64, finalized int64) *storage.EventRecord {
+	return &storage.EventRecord{
+		ID:                   id,
+		Network:              storage.EventNetwork(network),
+		NodeURL:              "http://" + network,
+		Address:              address,
+		Status:               storage.EventStatusRunning,
+		LatestBlockNumber:    latest,
+		FinalizedBlockNumber: finalized,
+	}
+}
+
+func Test_Cronjob_Backoff(t *testing.T) {
+	c, _ := newSchedulerCronjob()
+
+	testCases := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 0, expected: time.Second},
+		{failures: 1, expected: time.Second},
+		{failures: 2, expected: 2 * time.Second},
+		{failures: 3, expected: 4 * time.Second},
+		{failures: 4, expected: 8 * time.Second},
+		{failures: 5, expected: 10 * time.Second},
+		{failures: 50, expected: 10 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, c.backoff(tc.failures), "failures %d", tc.failures)
+	}
+}
+
+func Test_Cronjob_FinishContract(t *testing.T) {
+	ev := schedulerEvent("transfer", "ethereum", "0x1", 100, 100)
+	c, querier := newSchedulerCronjob(ev)
+	now := c.dateGen()
+	c.refreshSchedule([][]*storage.EventRecord{{ev}}, now)
+	s := c.scheduled[contractKey(ev)]
+
+	// the delay between the runs grows with each failure
+	for failures := 1; failures <= 3; failures++ {
+		s.running = true
+		c.runningContracts++
+		c.finishContract(s, errors.New("node unavailable"))
+
+		require.Equal(t, failures, s.failures)
+		require.Equal(t, now.Add(c.backoff(failures)), s.nextRun)
+		require.Equal(t, 0, c.runningContracts)
+		require.Equal(t, storage.EventStatusError, ev.Status)
+		require.Equal(t, failures, querier.events["transfer"].Failures)
+	}
+
+	// the run that succeeds resets the backoff and clears the error of the events
+	s.running = true
+	c.runningContracts++
+	c.finishContract(s, nil)
+
+	require.Equal(t, 0, s.failures)
+	require.Equal(t, now.Add(time.Second), s.nextRun)
+	require.Equal(t, storage.EventStatusRunning, ev.Status)
+	require.Equal(t, "", ev.Error)
+	require.Equal(t, storage.EventStatusRunning, querier.events["transfer"].Status)
+	require.Equal(t, 0, querier.events["transfer"].Failures)
+}
+
+func Test_Cronjob_Dispatch(t *testing.T) {
+	behind := schedulerEvent("behind", "ethereum", "0x1", 100, 5000)
+	following := schedulerEvent("following", "ethereum", "0x2", 4990, 5000)
+	waiting := schedulerEvent("waiting", "ethereum", "0x3", 5000, 5000)
+	c, _ := newSchedulerCronjob(behind, following, waiting)
+	now := c.dateGen()
+	c.refreshSchedule([][]*storage.EventRecord{{behind}, {following}, {waiting}}, now)
+	c.scheduled[contractKey(waiting)].nextRun = now.Add(500 * time.Millisecond)
+	c.maxWorkers = 1
+
+	// the contract closer to the head runs first
+	next := c.dispatch(now)
+	c.runs.Wait()
+	require.Equal(t, 500*time.Millisecond, next)
+	require.Equal(t, 1, c.scheduled[contractKey(following)].failures)
+	require.Equal(t, 0, c.scheduled[contractKey(behind)].failures)
+
+	c.dispatch(now)
+	c.runs.Wait()
+	require.Equal(t, 1, c.scheduled[contractKey(behind)].failures)
+
+	// the contracts waiting for their next run aren't started
+	require.Equal(t, 0, c.scheduled[contractKey(waiting)].failures)
+	require.Equal(t, 0, c.runningContracts)
+}
+
+func Test_Cronjob_MarkDue(t *testing.T) {
+	synced := schedulerEvent("synced", "ethereum", "0x1", 5000, 5000)
+	failing := schedulerEvent("failing", "ethereum", "0x2", 5000, 5000)
+	other := schedulerEvent("other", "polygon", "0x3", 5000, 5000)
+	c, _ := newSchedulerCronjob(synced, failing, other)
+	now := c.dateGen()
+	later := now.Add(time.Minute)
+	c.refreshSchedule([][]*storage.EventRecord{{synced}, {failing}, {other}}, now)
+	for _, s := range c.scheduled {
+		s.nextRun = later
+	}
+	c.scheduled[contractKey(failing)].failures = 2
+
+	c.markDue(map[string]bool{"ethereum": true}, now)
+
+	// only the contracts of the network that aren't waiting for a backoff run on the next dispatch
+	require.Equal(t, now, c.scheduled[contractKey(synced)].nextRun)
+	require.Equal(t, later, c.scheduled[contractKey(failing)].nextRun)
+	require.Equal(t, later, c.scheduled[contractKey(other)].nextRun)
+}
+
+func Test_Cronjob_DispatchOwned(t *testing.T) {
+	owned := schedulerEvent("owned", "ethereum", "0x1", 100, 5000)
+	dropped := schedulerEvent("dropped", "ethereum", "0x2", 4990, 5000)
+	c, _ := newSchedulerCronjob(owned, dropped)
+	now := c.dateGen()
+	c.refreshSchedule([][]*storage.EventRecord{{owned}, {dropped}}, now)
+
+	// the contract of a shard dropped after the refresh isn't started
+	c.shards = &fakeShards{owned: map[string]bool{contractKey(owned): true}}
+	c.dispatch(now)
+	c.runs.Wait()
+	require.Equal(t, 1, c.scheduled[contractKey(owned)].failures)
+	require.Equal(t, 0, c.scheduled[contractKey(dropped)].failures)
+}
+
+func Test_Cronjob_StopUnowned(t *testing.T) {
+	c, _ := newSchedulerCronjob()
+	shards := &fakeShards{owned: map[string]bool{
+		"contract:owned":                  true,
+		signatureSubscriptionKey("owned"): true,
+		backfillKey("owned"):              true,
+	}}
+	c.shards = shards
+
+	// every run blocks until it's canceled
+	stopped := make(chan string, 6)
+	start := func(name string) *backgroundRun {
+		ctx, cancel := context.WithCancel(context.Background())
+		run := &backgroundRun{cancel: cancel, done: make(chan struct{})}
+		go func() {
+			defer close(run.done)
+			<-ctx.Done()
+			stopped <- name
+		}()
+		return run
+	}
+	for _, key := range []string{"contract:owned", "contract:dropped"} {
+		c.scheduled[key] = &scheduledContract{key: key, running: true, run: start(key)}
+	}
+	for _, id := range []string{"owned", "dropped"} {
+		c.signatureRuns[id] = start(signatureSubscriptionKey(id))
+		c.backfills[id] = start(backfillKey(id))
+	}
+
+	c.StopUnowned()
+	require.Len(t, stopped, 3)
+	names := make([]string, 0)
+	for i := 0; i < 3; i++ {
+		names = append(names, <-stopped)
+	}
+	sort.Strings(names)
+
+	// only the runs of the shards dropped are canceled, and they finished before it returned
+	require.Equal(t, []string{"backfill:dropped", "contract:dropped", "subscription:dropped"}, names)
+	for _, key := range []string{"contract:owned", "contract:dropped"} {
+		c.scheduled[key].run.cancel()
+	}
+	for _, id := range []string{"owned", "dropped"} {
+		c.signatureRuns[id].cancel()
+		c.backfills[id].cancel()
+	}
+}
@@ -0,0 +1,313 @@
+package cronjob
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// syncSignatureSubscriptions starts the sync of the running signature subscriptions of the given
+// networks, or of every network when it is nil. Each subscription syncs in background apart
+// from the ticks and the contracts, and it isn't started again while its previous sync runs.
+func (c *cronjob) syncSignatureSubscriptions(networks map[string]bool) error {
+	subscriptions, err := c.syncEngine.SignatureSubscriptionQuerier.SelectSignatureSubscriptionsQuery(c.syncEngine.GetDatabase(), &query.SelectSignatureSubscriptionsQueryFilters{
+		Status: storage.EventStatusRunning,
+	})
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.syncSignatureSubscriptions c.syncEngine.SignatureSubscriptionQuerier.SelectSignatureSubscriptionsQuery error")
+	}
+
+	c.signatureMu.Lock()
+	defer c.signatureMu.Unlock()
+
+	for _, s := range subscriptions {
+		if networks != nil && !networks[string(s.Network)] {
+			continue
+		}
+		if _, ok := c.signatureRuns[s.ID]; ok || !c.owns(signatureSubscriptionKey(s.ID)) {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		run := &backgroundRun{
+			cancel: cancel,
+			done:   make(chan struct{}),
+		}
+		c.signatureRuns[s.ID] = run
+		c.runs.Add(1)
+		go func(s *storage.SignatureSubscriptionRecord) {
+			defer c.runs.Done()
+			defer close(run.done)
+			defer cancel()
+
+			c.syncSignatureSubscription(ctx, s)
+
+			c.signatureMu.Lock()
+			delete(c.signatureRuns, s.ID)
+			c.signatureMu.Unlock()
+		}(s)
+	}
+
+	return nil
+}
+
+// signatureSubscriptionKey identifies the signature subscription in the shards.
+func signatureSubscriptionKey(id string) string {
+	return "subscription:" + id
+}
+
+// syncSignatureSubscription ingests the finalized logs of the event signature emitted by the
+// addresses of the subscription, with a single eth_getLogs call per block range for every
+// address. It only syncs up to the finalized head, so reorganizations don't need to be handled.
+// The logs of the addresses added after the subscription started are backfilled afterwards.
+func (c *cronjob) syncSignatureSubscription(ctx context.Context, s *storage.SignatureSubscriptionRecord) {
+	now := c.dateGen()
+	var err error
+	defer func() {
+		if err != nil {
+			c.updateSignatureSubscriptionError(s.ID, err, now)
+		}
+	}()
+
+	client, nodeURL, err := c.pool.Client(string(s.Network), s.NodeURL)
+	if err != nil {
+		return
+	}
+	defer c.pool.Release(client)
+
+	head, err := c.heads.Head(string(s.Network), s.NodeURL)
+	if err != nil {
+		return
+	}
+	if s.LatestBlockNumber >= head.Finalized && len(s.BackfillAddresses) == 0 {
+		return
+	}
+
+	window, err := c.getWindow(nodeURL, s.Network)
+	if err != nil {
+		return
+	}
+
+	// the filter of the event restricts the logs of the addresses
+	filters := make(map[string][]blockchain.TopicFilter)
+	if f, ok := s.TopicFilters[s.Signature]; ok && f != nil {
+		filters[s.Signature] = []blockchain.TopicFilter{f.TopicFilter()}
+	}
+
+	// the backfill of the new addresses runs after the walk of the subscription
+	defer func() {
+		if err == nil && len(s.BackfillAddresses) > 0 {
+			err = c.backfillSignatureSubscription(ctx, client, nodeURL, s, filters, window, now)
+		}
+	}()
+	if s.LatestBlockNumber >= head.Finalized {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	fromBlockNumber := s.LatestBlockNumber
+	count, latestBlockNumber, err := c.walkLogs(ctx, nodeURL, blockchain.Config{
+		Client:          client,
+		ABI:             string(s.ABI),
+		EventSignatures: []string{s.Signature},
+		Addresses:       s.Addresses,
+		AllAddresses:    s.AllAddresses,
+		FromBlockNumber: &fromBlockNumber,
+		ToBlockNumber:   &head.Finalized,
+		Logger:          c.debug,
+		Window:          window,
+		Headers:         c.headers,
+		TopicFilters:    filters,
+	}, func(txx *sqlx.Tx, batch blockchain.LogBatch) error {
+		logBlockNumber, err := c.insertSignatureSubscriptionLogs(txx, s, batch.Logs, s.HasAddress, now)
+		if err != nil || logBlockNumber <= s.LatestBlockNumber {
+			return err
+		}
+		return c.advanceSignatureSubscription(txx, s, logBlockNumber, false, now)
+	})
+
+	// persist the window even when the walk failed, it could have learned a smaller range
+	if werr := c.saveWindow(nodeURL, s.Network, window, now); werr != nil {
+		log.Printf("cronjob.syncSignatureSubscription error saving block range window: %s \n", werr.Error())
+	}
+
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		err = nil
+	} else if err != nil {
+		return
+	}
+
+	if count > 0 {
+		log.Printf("%d new logs of signature=%s have been inserted into the database with %d latest block number \n", count, s.Signature, latestBlockNumber)
+	}
+
+	// advance the checkpoint, the error of previous ticks is cleared
+	if latestBlockNumber < s.LatestBlockNumber {
+		latestBlockNumber = s.LatestBlockNumber
+	}
+	err = c.advanceSignatureSubscription(c.syncEngine.GetDatabase(), s, latestBlockNumber, true, now)
+}
+
+// advanceSignatureSubscription updates the checkpoint of the subscription. It does nothing when
+// the address set was replaced while the logs were ingested, so the blocks walked without the
+// new addresses are walked again with them, the backfill only reaches the previous checkpoint.
+func (c *cronjob) advanceSignatureSubscription(txx storage.Transaction, s *storage.SignatureSubscriptionRecord, latestBlockNumber int64, clearError bool, now time.Time) error {
+	input := &query.UpdateSignatureSubscriptionQueryInput{
+		ID:                &s.ID,
+		LatestBlockNumber: &latestBlockNumber,
+		UpdatedAt:         &now,
+		CurrentAddresses:  &s.Addresses,
+	}
+	if clearError {
+		noError := ""
+		input.Error = &noError
+	}
+
+	_, err := c.syncEngine.SignatureSubscriptionQuerier.UpdateSignatureSubscriptionQuery(txx, input)
+	if errors.Cause(err) == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.advanceSignatureSubscription c.syncEngine.SignatureSubscriptionQuerier.UpdateSignatureSubscriptionQuery error")
+	}
+	s.LatestBlockNumber = latestBlockNumber
+
+	return nil
+}
+
+// backfillSignatureSubscription ingests the logs of the addresses added to the subscription from
+// the backfill checkpoint up to the block the subscription was synced when they were added.
+// The backfill is finished when the range is ingested, otherwise it continues on the next sync.
+func (c *cronjob) backfillSignatureSubscription(ctx context.Context, client *blockchain.Client, nodeURL string, s *storage.SignatureSubscriptionRecord, filters map[string][]blockchain.TopicFilter, window *blockchain.Window, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	fromBlockNumber := s.BackfillLatestBlockNumber
+	toBlockNumber := s.BackfillToBlockNumber
+	if fromBlockNumber > toBlockNumber {
+		fromBlockNumber = toBlockNumber
+	}
+	_, latestBlockNumber, err := c.walkLogs(ctx, nodeURL, blockchain.Config{
+		Client:          client,
+		ABI:             string(s.ABI),
+		EventSignatures: []string{s.Signature},
+		Addresses:       s.BackfillAddresses,
+		FromBlockNumber: &fromBlockNumber,
+		ToBlockNumber:   &toBlockNumber,
+		Logger:          c.debug,
+		Window:          window,
+		Headers:         c.headers,
+		TopicFilters:    filters,
+	}, func(txx *sqlx.Tx, batch blockchain.LogBatch) error {
+		_, err := c.insertSignatureSubscriptionLogs(txx, s, batch.Logs, s.IsBackfilling, now)
+		if err != nil {
+			return err
+		}
+		return c.advanceSignatureSubscriptionBackfill(txx, s, batch.ToBlockNumber, now)
+	})
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.backfillSignatureSubscription c.walkLogs error")
+	}
+	log.Printf("cronjob.backfillSignatureSubscription subscription id=%s backfilled %d addresses up to block_number=%d \n", s.ID, len(s.BackfillAddresses), latestBlockNumber)
+
+	return nil
+}
+
+// advanceSignatureSubscriptionBackfill updates the checkpoint of the backfill of the subscription,
+// the backfill finishes when it reaches its last block. Like the checkpoint of the subscription,
+// it does nothing when the address set was replaced while the logs were ingested.
+func (c *cronjob) advanceSignatureSubscriptionBackfill(txx storage.Transaction, s *storage.SignatureSubscriptionRecord, latestBlockNumber int64, now time.Time) error {
+	input := &query.UpdateSignatureSubscriptionQueryInput{
+		ID:                        &s.ID,
+		BackfillLatestBlockNumber: &latestBlockNumber,
+		UpdatedAt:                 &now,
+		CurrentAddresses:          &s.Addresses,
+	}
+	backfillAddresses := s.BackfillAddresses
+	if latestBlockNumber >= s.BackfillToBlockNumber {
+		backfillAddresses = make(pq.StringArray, 0)
+		input.BackfillAddresses = &backfillAddresses
+	}
+
+	_, err := c.syncEngine.SignatureSubscriptionQuerier.UpdateSignatureSubscriptionQuery(txx, input)
+	if errors.Cause(err) == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.advanceSignatureSubscriptionBackfill c.syncEngine.SignatureSubscriptionQuerier.UpdateSignatureSubscriptionQuery error")
+	}
+	s.BackfillLatestBlockNumber = latestBlockNumber
+	s.BackfillAddresses = backfillAddresses
+
+	return nil
+}
+
+// insertSignatureSubscriptionLogs stores a batch of logs of the subscription emitted by the
+// addresses accepted by hasAddress, with the address that emitted them. The logs that can't be
+// decoded are stored in quarantine. It returns the block of the last stored log.
+func (c *cronjob) insertSignatureSubscriptionLogs(txx *sqlx.Tx, s *storage.SignatureSubscriptionRecord, logs []blockchain.LogData, hasAddress func(address string) bool, now time.Time) (int64, error) {
+	data := make([]*storage.SignatureEventDataRecord, 0)
+	quarantined := make([]*storage.SignatureQuarantinedLogRecord, 0)
+	logBlockNumber := int64(0)
+	for _, l := range logs {
+		if l.EventSignature != s.Signature || !hasAddress(l.Address.Hex()) {
+			continue
+		}
+		logBlockNumber = int64(l.BlockNumber)
+
+		// store the logs that couldn't be decoded in quarantine and keep ingesting
+		if l.DecodeError != "" {
+			sq := &storage.SignatureQuarantinedLogRecord{}
+			err := sq.FromLogData(&l, c.idGen(), s.ID, now)
+			if err != nil {
+				return 0, errors.Wrap(err, "cronjob: cronjob.insertSignatureSubscriptionLogs sq.FromLogData error")
+			}
+			quarantined = append(quarantined, sq)
+			continue
+		}
+
+		sd := &storage.SignatureEventDataRecord{}
+		err := sd.FromLogData(&l, c.idGen(), s.ID, now)
+		if err != nil {
+			return 0, errors.Wrap(err, "cronjob: cronjob.insertSignatureSubscriptionLogs sd.FromLogData error")
+		}
+		data = append(data, sd)
+	}
+
+	err := c.syncEngine.SignatureSubscriptionQuerier.InsertSignatureEventDataBatchQuery(txx, data)
+	if err != nil {
+		return 0, errors.Wrap(err, "cronjob: cronjob.insertSignatureSubscriptionLogs c.syncEngine.SignatureSubscriptionQuerier.InsertSignatureEventDataBatchQuery error")
+	}
+
+	err = c.syncEngine.SignatureSubscriptionQuerier.InsertSignatureQuarantinedLogBatchQuery(txx, quarantined)
+	if err != nil {
+		return 0, errors.Wrap(err, "cronjob: cronjob.insertSignatureSubscriptionLogs c.syncEngine.SignatureSubscriptionQuerier.InsertSignatureQuarantinedLogBatchQuery error")
+	}
+
+	return logBlockNumber, nil
+}
+
+func (c *cronjob) updateSignatureSubscriptionError(id string, err error, date time.Time) {
+	errString := err.Error()
+	_, err = c.syncEngine.SignatureSubscriptionQuerier.UpdateSignatureSubscriptionQuery(c.syncEngine.GetDatabase(), &query.UpdateSignatureSubscriptionQueryInput{
+		ID:        &id,
+		Error:     &errString,
+		UpdatedAt: &date,
+	})
+	if err != nil {
+		log.Printf("cronjob.updateSignatureSubscriptionError subscription.ID=%s error=%s \n", id, err.Error())
+	}
+}
@@ -0,0 +1,62 @@
+package cronjob
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+)
+
+// topicFilters returns the filters on the indexed arguments of the events by signature, OR'ing
+// the filters of the users of the contract. Events with a user that doesn't filter them keep
+// every log, so they don't have filters.
+func topicFilters(eventsBySignature map[string]*storage.EventRecord) map[string][]blockchain.TopicFilter {
+	filters := make(map[string][]blockchain.TopicFilter)
+	for signature, ev := range eventsBySignature {
+		if len(ev.SmartContractUsers) == 0 {
+			continue
+		}
+
+		eventFilters := make([]blockchain.TopicFilter, 0)
+		for _, scu := range sortedUsers(ev.SmartContractUsers) {
+			f, ok := scu.TopicFilters[signature]
+			if !ok || f == nil {
+				eventFilters = nil
+				break
+			}
+			eventFilters = append(eventFilters, f.TopicFilter())
+		}
+		if len(eventFilters) > 0 {
+			filters[signature] = eventFilters
+		}
+	}
+
+	return filters
+}
+
+// topicFiltersKey identifies the filters of the users of the event, so a live subscription is
+// restarted when they change.
+func topicFiltersKey(ev *storage.EventRecord) string {
+	filters := make([]*storage.EventTopicFilter, 0)
+	for _, scu := range sortedUsers(ev.SmartContractUsers) {
+		filters = append(filters, scu.TopicFilters[ev.Signature])
+	}
+
+	b, err := json.Marshal(filters)
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
+
+func sortedUsers(users []*storage.SmartContractUserRecord) []*storage.SmartContractUserRecord {
+	sorted := make([]*storage.SmartContractUserRecord, len(users))
+	copy(sorted, users)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	return sorted
+}
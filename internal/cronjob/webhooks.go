@@ -0,0 +1,26 @@
+package cronjob
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/pkg/webhook"
+)
+
+// sendWebhook creates the webhook for the given smart contract user and enqueues it for delivery
+func (c *cronjob) sendWebhook(userID string, wh *storage.WebhookRecord) error {
+	return c.webhookSender.CreateAndSendWebhook(&webhook.Webhook{
+		ID:          wh.ID,
+		Tx:          wh.Tx,
+		UserID:      userID,
+		EntityType:  webhook.WebhookEntityType(wh.EntityType),
+		EntityID:    wh.EntityID,
+		Endpoint:    wh.Endpoint,
+		Payload:     wh.Payload,
+		MaxAttempts: wh.MaxAttempts,
+		CreatedAt:   wh.CreatedAt,
+		UpdatedAt:   wh.UpdatedAt,
+		SentAt:      wh.SentAt,
+		Attempts:    wh.Attempts,
+		NextRetryAt: wh.NextRetryAt,
+		Status:      webhook.WebhookStatus(wh.Status),
+	})
+}
@@ -0,0 +1,61 @@
+package cronjob
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// getWindow returns the block range window shared by the events synced against the same node
+// and network. The first time it is requested, the window persisted by previous ticks is
+// restored with the max told by the provider, and a new one is created using the configured size
+// when there isn't any.
+func (c *cronjob) getWindow(nodeURL string, network storage.EventNetwork) (*blockchain.Window, error) {
+	key := windowKey(nodeURL, network)
+	if w, ok := c.windows.Load(key); ok {
+		return w.(*blockchain.Window), nil
+	}
+
+	conf := c.windowConfig
+	record, err := c.syncEngine.BlockRangeWindowQuerier.SelectBlockRangeWindowQuery(c.syncEngine.GetDatabase(), nodeURL, network)
+	if err != nil && errors.Cause(err) != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "cronjob: cronjob.getWindow c.syncEngine.BlockRangeWindowQuerier.SelectBlockRangeWindowQuery error")
+	}
+	if record != nil {
+		conf.Size = record.Size
+
+		// the provider limit keeps capping the window, so it doesn't grow past it again
+		if record.MaxSize > 0 && (conf.Max <= 0 || record.MaxSize < conf.Max) {
+			conf.Max = record.MaxSize
+		}
+	}
+
+	// other event could have stored the window in the meantime
+	w, _ := c.windows.LoadOrStore(key, blockchain.NewWindow(conf))
+	return w.(*blockchain.Window), nil
+}
+
+// saveWindow persists the current size of the window so the next ticks start from it, and the
+// max told by the provider. The stored max is kept while the window didn't learn a new one.
+func (c *cronjob) saveWindow(nodeURL string, network storage.EventNetwork, w *blockchain.Window, now time.Time) error {
+	err := c.syncEngine.BlockRangeWindowQuerier.UpsertBlockRangeWindowQuery(c.syncEngine.GetDatabase(), &storage.BlockRangeWindowRecord{
+		NodeURL:   nodeURL,
+		Network:   network,
+		Size:      w.Size(),
+		MaxSize:   w.LearnedMax(),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return errors.Wrap(err, "cronjob: cronjob.saveWindow c.syncEngine.BlockRangeWindowQuerier.UpsertBlockRangeWindowQuery error")
+	}
+
+	return nil
+}
+
+func windowKey(nodeURL string, network storage.EventNetwork) string {
+	return fmt.Sprintf("%s:%s", network, nodeURL)
+}
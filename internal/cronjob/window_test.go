@@ -0,0 +1,71 @@
+package cronjob
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/jaekwon/testify/require"
+)
+
+// fakeWindowQuerier keeps the windows in memory, the max is kept while the window doesn't tell
+// a new one like the upsert query.
+type fakeWindowQuerier struct {
+	syncng.BlockRangeWindowQuerier
+	windows map[string]*storage.BlockRangeWindowRecord
+}
+
+func (f *fakeWindowQuerier) SelectBlockRangeWindowQuery(tx storage.Transaction, nodeURL string, network storage.EventNetwork) (*storage.BlockRangeWindowRecord, error) {
+	record, ok := f.windows[windowKey(nodeURL, network)]
+	if !ok {
+		return nil, nil
+	}
+
+	copied := *record
+	return &copied, nil
+}
+
+func (f *fakeWindowQuerier) UpsertBlockRangeWindowQuery(tx storage.Transaction, input *storage.BlockRangeWindowRecord) error {
+	copied := *input
+	if stored, ok := f.windows[windowKey(input.NodeURL, input.Network)]; ok && copied.MaxSize == 0 {
+		copied.MaxSize = stored.MaxSize
+	}
+	f.windows[windowKey(input.NodeURL, input.Network)] = &copied
+
+	return nil
+}
+
+func Test_Cronjob_Window(t *testing.T) {
+	engine := newTestEngine()
+	querier := &fakeWindowQuerier{windows: make(map[string]*storage.BlockRangeWindowRecord)}
+	engine.BlockRangeWindowQuerier = querier
+	c, _ := newTestCronjob(engine)
+	c.windowConfig = blockchain.WindowConfig{Size: 100, Max: 1000, Increase: 10}
+
+	// the provider tells its max, it's stored with the size
+	w, err := c.getWindow("https://node", "ethereum")
+	require.NoError(t, err)
+	rangeErr := blockchain.ClassifyError(errors.New("eth_getLogs and eth_newFilter are limited to a 50 blocks range")).(*blockchain.RangeTooLargeError)
+	w.Shrink(rangeErr, 0, 99)
+	require.NoError(t, c.saveWindow("https://node", "ethereum", w, c.dateGen()))
+	require.Equal(t, int64(50), querier.windows[windowKey("https://node", "ethereum")].MaxSize)
+
+	// after a restart the window doesn't grow past the provider max
+	for i := 0; i < 2; i++ {
+		restarted, _ := newTestCronjob(engine)
+		restarted.windowConfig = c.windowConfig
+
+		w, err = restarted.getWindow("https://node", "ethereum")
+		require.NoError(t, err)
+		for j := 0; j < 10; j++ {
+			w.Grow()
+		}
+		require.Equal(t, int64(50), w.Size())
+
+		// saving the window without a new max keeps the stored one
+		require.NoError(t, restarted.saveWindow("https://node", "ethereum", w, c.dateGen()))
+		require.Equal(t, int64(50), querier.windows[windowKey("https://node", "ethereum")].MaxSize)
+	}
+}
@@ -1,15 +1,40 @@
 package env
 
 type Env struct {
-	CronjobIntervalSeconds  int64  `envconfig:"cronjob_interval_seconds" required:"true"`
-	DatabaseDSN             string `envconfig:"database_dsn" required:"true"`
-	Port                    string `envconfig:"port" required:"true"`
-	Debug                   bool   `envconfig:"debug" default:"false"`
-	MigrationDir            string `envconfig:"migration_dir" required:"true"`
-	NetworksEtherscanURL    string `envconfig:"networks_etherscan_url" required:"true"`
-	NetworksEtherscanAPIKey string `envconfig:"networks_etherscan_api_key" required:"true"`
-	NetworksNodeURL         string `envconfig:"networks_node_url" required:"true"`
-	MaxTransactions         int    `envconfig:"max_transactions" required:"true"`
-	WebhooksIntervalSeconds int64  `envconfig:"webhooks_interval_seconds" required:"true"`
-	BackofficeApiURL        string `envconfig:"backoffice_api_url" required:"true"`
+	CronjobIntervalSeconds  int64   `envconfig:"cronjob_interval_seconds" required:"true"`
+	DatabaseDSN             string  `envconfig:"database_dsn" required:"true"`
+	Port                    string  `envconfig:"port" required:"true"`
+	Debug                   bool    `envconfig:"debug" default:"false"`
+	MigrationDir            string  `envconfig:"migration_dir" required:"true"`
+	NetworksEtherscanURL    string  `envconfig:"networks_etherscan_url" required:"true"`
+	NetworksEtherscanAPIKey string  `envconfig:"networks_etherscan_api_key" required:"true"`
+	NetworksNodeURL         string  `envconfig:"networks_node_url" required:"true"`
+	MaxTransactions         int     `envconfig:"max_transactions" required:"true"`
+	WebhooksIntervalSeconds int64   `envconfig:"webhooks_interval_seconds" required:"true"`
+	BackofficeApiURL        string  `envconfig:"backoffice_api_url" required:"true"`
+	ReorgDepth              int64   `envconfig:"reorg_depth" default:"64"`
+	NetworksConfirmations   string  `envconfig:"networks_confirmations" default:"{}"`
+	NetworksFinalityTag     string  `envconfig:"networks_finality_tag" default:"{}"`
+	LogsWindowSize          int64   `envconfig:"logs_window_size" default:"1000"`
+	LogsWindowMaxSize       int64   `envconfig:"logs_window_max_size" default:"100000"`
+	LogsWindowIncrease      int64   `envconfig:"logs_window_increase" default:"100"`
+	LiveLogs                bool    `envconfig:"live_logs" default:"false"`
+	NetworksNodeURLs        string  `envconfig:"networks_node_urls" default:"{}"`
+	RPCPoolMaxHeadLag       int64   `envconfig:"rpc_pool_max_head_lag" default:"10"`
+	RPCPoolMaxErrorRate     float64 `envconfig:"rpc_pool_max_error_rate" default:"0.5"`
+	RPCPoolEvictAfter       int     `envconfig:"rpc_pool_evict_after" default:"5"`
+	RPCPoolCheckSeconds     int64   `envconfig:"rpc_pool_check_seconds" default:"30"`
+	HeadPollIntervalSeconds int64   `envconfig:"head_poll_interval_seconds" default:"5"`
+	HeadMaxAgeSeconds       int64   `envconfig:"head_max_age_seconds" default:"60"`
+	SyncOnNewBlocks         bool    `envconfig:"sync_on_new_blocks" default:"true"`
+	CoverageVerifySeconds   int64   `envconfig:"coverage_verify_seconds" default:"600"`
+	SchedulerMaxWorkers     int     `envconfig:"scheduler_max_workers" default:"16"`
+	SchedulerMaxBackoffSecs int64   `envconfig:"scheduler_max_backoff_seconds" default:"600"`
+	SupervisorDrainSeconds  int64   `envconfig:"supervisor_drain_seconds" default:"30"`
+	LeaderElection          bool    `envconfig:"leader_election" default:"false"`
+	LeaderLeaseSeconds      int64   `envconfig:"leader_lease_seconds" default:"150"`
+	ReplicaID               string  `envconfig:"replica_id"`
+	ShardCount              int     `envconfig:"shard_count" default:"0"`
+	ShardLeaseSeconds       int64   `envconfig:"shard_lease_seconds" default:"30"`
+	AdminAPIToken           string  `envconfig:"admin_api_token"`
 }
@@ -0,0 +1,124 @@
+package explorer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNotConfigured = errors.New("explorer not configured for the network")
+	ErrABINotFound   = errors.New("contract abi not verified in the explorer")
+)
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Config struct {
+	Client HTTPClient
+	// URLs and APIKeys are the etherscan compatible explorer api url and key of each network
+	URLs    map[string]string
+	APIKeys map[string]string
+}
+
+// Client requests the verified contracts of the etherscan compatible explorer of each network.
+type Client struct {
+	client  HTTPClient
+	urls    map[string]string
+	apiKeys map[string]string
+}
+
+func New(conf Config) *Client {
+	c := &Client{
+		client:  conf.Client,
+		urls:    conf.URLs,
+		apiKeys: conf.APIKeys,
+	}
+
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+	if c.urls == nil {
+		c.urls = make(map[string]string)
+	}
+	if c.apiKeys == nil {
+		c.apiKeys = make(map[string]string)
+	}
+
+	return c
+}
+
+// GetABI returns the abi of the verified contract using the getabi action of the explorer.
+func (c *Client) GetABI(ctx context.Context, network string, address string) (json.RawMessage, error) {
+	apiURL := c.urls[network]
+	if apiURL == "" {
+		return nil, errors.Wrapf(ErrNotConfigured, "explorer: Client.GetABI network=%s error", network)
+	}
+
+	type Response struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Result  string `json:"result"`
+	}
+
+	// parse url
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "explorer: Client.GetABI url.Parse error")
+	}
+
+	// define params and encode
+	params := url.Values{}
+	params.Set("module", "contract")
+	params.Set("action", "getabi")
+	params.Set("address", address)
+	params.Set("apikey", c.apiKeys[network])
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "explorer: Client.GetABI http.NewRequest error")
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "explorer: Client.GetABI c.client.Do error")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("explorer: Client.GetABI request failed with status code: %d", res.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "explorer: Client.GetABI ioutil.ReadAll error")
+	}
+
+	var body Response
+	err = json.Unmarshal(b, &body)
+	if err != nil {
+		return nil, errors.Wrap(err, "explorer: Client.GetABI json.Unmarshal error")
+	}
+
+	// the result has the error message when the request fails
+	if body.Status != "1" {
+		if strings.Contains(strings.ToLower(body.Result), "not verified") {
+			return nil, errors.Wrapf(ErrABINotFound, "explorer: Client.GetABI address=%s error", address)
+		}
+		return nil, fmt.Errorf("explorer: Client.GetABI request failed with message: %s, result: %s", body.Message, body.Result)
+	}
+
+	if !json.Valid([]byte(body.Result)) {
+		return nil, errors.New("explorer: Client.GetABI invalid abi error")
+	}
+
+	return json.RawMessage(body.Result), nil
+}
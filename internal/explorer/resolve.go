@@ -0,0 +1,112 @@
+package explorer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// SourceExplorer is the source of the abis fetched from the explorer of the network.
+const SourceExplorer = "explorer"
+
+// ResolvedABI is the abi of a contract fetched from the explorer. The abi of proxies is
+// merged with the abi of their implementation, since the events are emitted by the proxy
+// address but defined by the implementation.
+type ResolvedABI struct {
+	ABI            json.RawMessage
+	Source         string
+	Proxy          blockchain.ProxyKind
+	Implementation string
+}
+
+// abiEntry is the part of an abi entry that identifies it.
+type abiEntry struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Inputs []struct {
+		Type string `json:"type"`
+	} `json:"inputs"`
+}
+
+func (e *abiEntry) key() string {
+	types := make([]string, 0)
+	for _, input := range e.Inputs {
+		types = append(types, input.Type)
+	}
+
+	return e.Type + ":" + e.Name + "(" + strings.Join(types, ",") + ")"
+}
+
+// ResolveABI fetches the abi of the contract from the explorer. When the contract is an
+// EIP-1967, EIP-1822 or beacon proxy the abi of the implementation is fetched and merged,
+// and the proxy abi is optional since proxies are often not verified.
+func (c *Client) ResolveABI(ctx context.Context, client blockchain.ProxyClient, network string, address string) (*ResolvedABI, error) {
+	implementation, proxy, err := blockchain.ProxyImplementation(ctx, client, common.HexToAddress(address))
+	if err != nil {
+		return nil, errors.Wrap(err, "explorer: Client.ResolveABI blockchain.ProxyImplementation error")
+	}
+
+	proxyABI, err := c.GetABI(ctx, network, address)
+	if err != nil && (proxy == blockchain.ProxyNone || errors.Cause(err) != ErrABINotFound) {
+		return nil, errors.Wrap(err, "explorer: Client.ResolveABI c.GetABI error")
+	}
+
+	resolved := &ResolvedABI{
+		ABI:    proxyABI,
+		Source: SourceExplorer,
+		Proxy:  proxy,
+	}
+	if proxy == blockchain.ProxyNone {
+		return resolved, nil
+	}
+	resolved.Implementation = implementation.Hex()
+
+	implementationABI, err := c.GetABI(ctx, network, resolved.Implementation)
+	if err != nil {
+		return nil, errors.Wrap(err, "explorer: Client.ResolveABI c.GetABI implementation error")
+	}
+
+	resolved.ABI, err = mergeABI(proxyABI, implementationABI)
+	if err != nil {
+		return nil, errors.Wrap(err, "explorer: Client.ResolveABI mergeABI error")
+	}
+
+	return resolved, nil
+}
+
+// mergeABI returns the entries of both abis, the entries defined by both are only kept once.
+func mergeABI(abis ...json.RawMessage) (json.RawMessage, error) {
+	merged := make([]json.RawMessage, 0)
+	keys := make(map[string]bool)
+	for _, a := range abis {
+		if len(a) == 0 {
+			continue
+		}
+
+		entries := make([]json.RawMessage, 0)
+		err := json.Unmarshal(a, &entries)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			var e abiEntry
+			err := json.Unmarshal(entry, &e)
+			if err != nil {
+				return nil, err
+			}
+
+			if keys[e.key()] {
+				continue
+			}
+			keys[e.key()] = true
+			merged = append(merged, entry)
+		}
+	}
+
+	return json.Marshal(merged)
+}
@@ -0,0 +1,115 @@
+package explorer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jaekwon/testify/require"
+	"github.com/pkg/errors"
+)
+
+const (
+	proxyABI = `[{"anonymous":false,"inputs":[{"indexed":false,"name":"implementation","type":"address"}],"name":"Upgraded","type":"event"}]`
+	tokenABI = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},` +
+		`{"anonymous":false,"inputs":[{"indexed":false,"name":"implementation","type":"address"}],"name":"Upgraded","type":"event"}]`
+)
+
+var (
+	proxyAddress          = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	implementationAddress = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+)
+
+// fakeProxyClient stores the given slots of the proxy address.
+type fakeProxyClient struct {
+	slots map[common.Hash]common.Address
+}
+
+func (f *fakeProxyClient) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	if account != proxyAddress {
+		return common.Hash{}.Bytes(), nil
+	}
+
+	return common.BytesToHash(f.slots[key].Bytes()).Bytes(), nil
+}
+
+func (f *fakeProxyClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return nil, fmt.Errorf("unexpected call")
+}
+
+// newExplorer starts an explorer api answering getabi with the abi of each address.
+func newExplorer(t *testing.T, abis map[string]string) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "getabi", r.URL.Query().Get("action"))
+
+		abi, ok := abis[strings.ToLower(r.URL.Query().Get("address"))]
+		if !ok {
+			fmt.Fprint(w, `{"status":"0","message":"NOTOK","result":"Contract source code not verified"}`)
+			return
+		}
+
+		result, err := json.Marshal(abi)
+		require.NoError(t, err)
+		fmt.Fprintf(w, `{"status":"1","message":"OK","result":%s}`, result)
+	}))
+	t.Cleanup(server.Close)
+
+	return New(Config{
+		URLs:    map[string]string{"ethereum": server.URL},
+		APIKeys: map[string]string{"ethereum": "key"},
+	})
+}
+
+func Test_ResolveABI(t *testing.T) {
+	ctx := context.Background()
+	proxy := strings.ToLower(proxyAddress.Hex())
+	implementation := strings.ToLower(implementationAddress.Hex())
+
+	// contracts that aren't proxies use their own abi
+	c := newExplorer(t, map[string]string{proxy: tokenABI})
+	resolved, err := c.ResolveABI(ctx, &fakeProxyClient{}, "ethereum", proxyAddress.Hex())
+	require.NoError(t, err)
+	require.Equal(t, blockchain.ProxyNone, resolved.Proxy)
+	require.JSONEq(t, tokenABI, string(resolved.ABI))
+
+	// the abi of EIP-1967 proxies is merged with the implementation one
+	client := &fakeProxyClient{slots: map[common.Hash]common.Address{
+		common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc"): implementationAddress,
+	}}
+	c = newExplorer(t, map[string]string{proxy: proxyABI, implementation: tokenABI})
+	resolved, err = c.ResolveABI(ctx, client, "ethereum", proxyAddress.Hex())
+	require.NoError(t, err)
+	require.Equal(t, blockchain.ProxyEIP1967, resolved.Proxy)
+	require.Equal(t, implementationAddress.Hex(), resolved.Implementation)
+	require.Equal(t, SourceExplorer, resolved.Source)
+
+	entries := make([]abiEntry, 0)
+	require.NoError(t, json.Unmarshal(resolved.ABI, &entries))
+	require.Len(t, entries, 2)
+	require.Equal(t, "Upgraded", entries[0].Name)
+	require.Equal(t, "Transfer", entries[1].Name)
+
+	// unverified proxies only need the implementation abi
+	c = newExplorer(t, map[string]string{implementation: tokenABI})
+	resolved, err = c.ResolveABI(ctx, client, "ethereum", proxyAddress.Hex())
+	require.NoError(t, err)
+	require.JSONEq(t, tokenABI, string(resolved.ABI))
+
+	// unverified contracts can't be resolved
+	c = newExplorer(t, map[string]string{})
+	_, err = c.ResolveABI(ctx, &fakeProxyClient{}, "ethereum", proxyAddress.Hex())
+	require.Equal(t, ErrABINotFound, errors.Cause(err))
+
+	_, err = c.ResolveABI(ctx, &fakeProxyClient{}, "polygon", proxyAddress.Hex())
+	require.Equal(t, ErrNotConfigured, errors.Cause(err))
+}
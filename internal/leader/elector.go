@@ -0,0 +1,248 @@
+package leader
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/internal/wrapper"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultLeaseName is the lease held by the leader of the replicas
+	DefaultLeaseName = "leader"
+	// DefaultTTL is the time the lease is held without being renewed when no ttl is configured
+	DefaultTTL = 30 * time.Second
+)
+
+var (
+	ErrNotLeader  = errors.New("leader: replica isn't the leader")
+	ErrInvalidTTL = errors.New("leader: lease ttl doesn't leave time to drain the workers before it expires")
+)
+
+// LeaseStore takes, renews and frees the leases shared by the replicas.
+type LeaseStore interface {
+	AcquireLease(input *syncng.AcquireLeaseInput) (*syncng.AcquireLeaseOutput, error)
+	ReleaseLease(input *syncng.ReleaseLeaseInput) error
+}
+
+// Status is the leadership of the replica.
+type Status struct {
+	Holder string
+	Leader bool
+	// Lease is the lease held by the replica, it's nil while other replica is the leader
+	Lease *storage.LeaseRecord
+	Error string
+}
+
+// Elector campaigns for a lease shared by the replicas of the synchronizer, so a single replica
+// runs the work that can't be done twice. The lease is renewed every third of its ttl, and the
+// replica is demoted as soon as other replica takes the lease or the lease may not be renewed
+// in time to drain the work of the leader before it expires.
+type Elector struct {
+	mu       sync.Mutex
+	leader   bool
+	lease    *storage.LeaseRecord
+	deadline time.Time
+	err      error
+	// demoting is held while the replica is demoted and by the work started as leader, so the
+	// work is either refused or stopped by the demotion
+	demoting sync.Mutex
+
+	store     LeaseStore
+	name      string
+	holder    string
+	ttl       time.Duration
+	drain     time.Duration
+	onElected func()
+	onDemoted func()
+	dateGen   wrapper.DateGenerator
+}
+
+type Config struct {
+	Store LeaseStore
+	// Name is the lease the replicas campaign for
+	Name string
+	// Holder identifies the replica, it must be unique across the replicas
+	Holder string
+	TTL    time.Duration
+	// OnElected is called when the replica becomes the leader
+	OnElected func()
+	// OnDemoted is called when the replica stops being the leader, the lease is released or
+	// renewed again only after it returns
+	OnDemoted func()
+	// DrainTimeout is the longest time OnDemoted takes to stop the work of the leader, it must
+	// fit in the ttl after a renewal that failed
+	DrainTimeout time.Duration
+	DateGen      wrapper.DateGenerator
+}
+
+func New(conf Config) (*Elector, error) {
+	e := &Elector{
+		store:     conf.Store,
+		name:      conf.Name,
+		holder:    conf.Holder,
+		ttl:       conf.TTL,
+		drain:     conf.DrainTimeout,
+		onElected: conf.OnElected,
+		onDemoted: conf.OnDemoted,
+		dateGen:   conf.DateGen,
+	}
+
+	if e.name == "" {
+		e.name = DefaultLeaseName
+	}
+	if e.ttl <= 0 {
+		e.ttl = DefaultTTL
+	}
+	if e.onElected == nil {
+		e.onElected = func() {}
+	}
+	if e.onDemoted == nil {
+		e.onDemoted = func() {}
+	}
+	if e.dateGen == nil {
+		e.dateGen = time.Now
+	}
+
+	// the leader drains its work when the renewal fails, and the next renewal is a third of
+	// the ttl later
+	if e.drain < 0 || e.ttl/3+e.drain >= e.ttl {
+		return nil, errors.Wrapf(ErrInvalidTTL, "leader: New ttl=%s drain=%s", e.ttl, e.drain)
+	}
+
+	return e, nil
+}
+
+// Run campaigns for the lease until the context is canceled, then demotes the replica and
+// releases the lease so other replica takes it without waiting for it to expire.
+func (e *Elector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		e.campaign()
+
+		select {
+		case <-ctx.Done():
+			if e.demote(nil) {
+				err := e.store.ReleaseLease(&syncng.ReleaseLeaseInput{
+					Name:   e.name,
+					Holder: e.holder,
+				})
+				if err != nil {
+					log.Printf("leader: Elector.Run e.store.ReleaseLease error: %s \n", err.Error())
+				}
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsLeader returns true while the replica holds the lease.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.leader
+}
+
+// RunAsLeader calls fn only while the replica is the leader, and returns ErrNotLeader otherwise.
+// The replica isn't demoted while fn runs, so the work it starts is stopped by the demotion.
+func (e *Elector) RunAsLeader(fn func() error) error {
+	e.demoting.Lock()
+	defer e.demoting.Unlock()
+
+	if !e.IsLeader() {
+		return ErrNotLeader
+	}
+
+	return fn()
+}
+
+func (e *Elector) Status() *Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status := &Status{
+		Holder: e.holder,
+		Leader: e.leader,
+		Lease:  e.lease,
+	}
+	if e.err != nil {
+		status.Error = e.err.Error()
+	}
+
+	return status
+}
+
+// campaign takes or renews the lease, and calls the callbacks when the leadership changes.
+func (e *Elector) campaign() {
+	// the deadline is taken before the request, so it never exceeds the expiration in the database
+	start := e.dateGen()
+
+	output, err := e.store.AcquireLease(&syncng.AcquireLeaseInput{
+		Name:   e.name,
+		Holder: e.holder,
+		TTL:    e.ttl,
+	})
+	if err != nil {
+		log.Printf("leader: Elector.campaign e.store.AcquireLease error: %s \n", err.Error())
+
+		// keep the leadership while the next renewal, a third of the ttl later, leaves time to
+		// drain the work of the leader before the lease expires
+		e.mu.Lock()
+		expiring := e.leader && !start.Add(e.ttl/3+e.drain).Before(e.deadline)
+		e.err = err
+		e.mu.Unlock()
+		if expiring {
+			e.demote(err)
+		}
+		return
+	}
+
+	if !output.Acquired {
+		e.demote(nil)
+		return
+	}
+
+	e.mu.Lock()
+	elected := !e.leader
+	e.leader = true
+	e.lease = output.Lease
+	e.deadline = start.Add(e.ttl)
+	e.err = nil
+	e.mu.Unlock()
+
+	if elected {
+		log.Printf("leader: replica %s is the leader of %s \n", e.holder, e.name)
+		e.onElected()
+	}
+}
+
+// demote stops the leadership of the replica, it returns true when the replica was the leader.
+func (e *Elector) demote(err error) bool {
+	e.demoting.Lock()
+	defer e.demoting.Unlock()
+
+	e.mu.Lock()
+	wasLeader := e.leader
+	e.leader = false
+	e.lease = nil
+	if err != nil {
+		e.err = err
+	}
+	e.mu.Unlock()
+
+	if wasLeader {
+		log.Printf("leader: replica %s isn't the leader of %s anymore \n", e.holder, e.name)
+		e.onDemoted()
+	}
+
+	return wasLeader
+}
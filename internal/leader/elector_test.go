@@ -0,0 +1,271 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/jaekwon/testify/require"
+)
+
+// fakeStore keeps a single lease in memory, like the lease table shared by the replicas.
+type fakeStore struct {
+	mu     sync.Mutex
+	holder string
+	expiry time.Time
+	err    error
+	now    func() time.Time
+}
+
+func (s *fakeStore) AcquireLease(input *syncng.AcquireLeaseInput) (*syncng.AcquireLeaseOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	now := s.now()
+	if s.holder != "" && s.holder != input.Holder && now.Before(s.expiry) {
+		return &syncng.AcquireLeaseOutput{}, nil
+	}
+
+	s.holder = input.Holder
+	s.expiry = now.Add(input.TTL)
+	return &syncng.AcquireLeaseOutput{
+		Lease: &storage.LeaseRecord{
+			Name:      input.Name,
+			Holder:    input.Holder,
+			ExpiresAt: s.expiry,
+		},
+		Acquired: true,
+	}, nil
+}
+
+func (s *fakeStore) ReleaseLease(input *syncng.ReleaseLeaseInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.holder == input.Holder {
+		s.holder = ""
+	}
+	return nil
+}
+
+type counter struct {
+	elected int
+	demoted int
+}
+
+func newElector(t *testing.T, store *fakeStore, holder string, drain time.Duration, now func() time.Time, c *counter) *Elector {
+	e, err := New(Config{
+		Store:        store,
+		Holder:       holder,
+		TTL:          30 * time.Second,
+		OnElected:    func() { c.elected++ },
+		OnDemoted:    func() { c.demoted++ },
+		DrainTimeout: drain,
+		DateGen:      now,
+	})
+	require.NoError(t, err)
+
+	return e
+}
+
+func Test_New(t *testing.T) {
+	testCases := []struct {
+		name  string
+		ttl   time.Duration
+		drain time.Duration
+		err   error
+	}{
+		{name: "without drain", ttl: 30 * time.Second},
+		{name: "drain after a failed renewal", ttl: 30 * time.Second, drain: 19 * time.Second},
+		{name: "drain past the expiration", ttl: 30 * time.Second, drain: 20 * time.Second, err: ErrInvalidTTL},
+		{name: "drain longer than the ttl", ttl: 30 * time.Second, drain: 90 * time.Second, err: ErrInvalidTTL},
+		{name: "default ttl", drain: 90 * time.Second, err: ErrInvalidTTL},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(Config{Store: &fakeStore{}, Holder: "a", TTL: tc.ttl, DrainTimeout: tc.drain})
+			if tc.err != nil {
+				require.True(t, errors.Is(err, tc.err))
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_Elector_Campaign(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+	store := &fakeStore{now: clock}
+
+	var ca, cb counter
+	a := newElector(t, store, "a", 0, clock, &ca)
+	b := newElector(t, store, "b", 0, clock, &cb)
+
+	// a single replica is elected
+	a.campaign()
+	b.campaign()
+	require.True(t, a.IsLeader())
+	require.False(t, b.IsLeader())
+	require.Equal(t, counter{elected: 1}, ca)
+	require.Equal(t, counter{}, cb)
+
+	// renewing the lease doesn't elect the replica again
+	now = now.Add(10 * time.Second)
+	a.campaign()
+	require.True(t, a.IsLeader())
+	require.Equal(t, counter{elected: 1}, ca)
+	require.Equal(t, "a", a.Status().Lease.Holder)
+
+	// the lease can't be renewed, the leader keeps it while it doesn't expire
+	store.err = errors.New("connection refused")
+	now = now.Add(10 * time.Second)
+	a.campaign()
+	require.True(t, a.IsLeader())
+	require.Equal(t, "connection refused", a.Status().Error)
+
+	now = now.Add(10 * time.Second)
+	a.campaign()
+	require.False(t, a.IsLeader())
+	require.Nil(t, a.Status().Lease)
+	require.Equal(t, counter{elected: 1, demoted: 1}, ca)
+
+	// other replica takes the expired lease, and the previous leader isn't elected again
+	store.err = nil
+	now = now.Add(20 * time.Second)
+	b.campaign()
+	a.campaign()
+	require.True(t, b.IsLeader())
+	require.False(t, a.IsLeader())
+	require.Equal(t, counter{elected: 1}, cb)
+	require.Equal(t, counter{elected: 1, demoted: 1}, ca)
+}
+
+func Test_Elector_CampaignDrain(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+	store := &fakeStore{now: clock}
+
+	var c counter
+	e := newElector(t, store, "a", 15*time.Second, clock, &c)
+	e.campaign()
+	require.True(t, e.IsLeader())
+
+	// the lease expires 30s after the renewal, the next renewal 10s later would leave 5s to
+	// drain the workers, so the leader is demoted right away
+	store.err = errors.New("connection refused")
+	now = now.Add(10 * time.Second)
+	e.campaign()
+	require.False(t, e.IsLeader())
+	require.Equal(t, counter{elected: 1, demoted: 1}, c)
+
+	// the lease is taken again once it can be renewed
+	store.err = nil
+	now = now.Add(10 * time.Second)
+	e.campaign()
+	require.True(t, e.IsLeader())
+	require.Equal(t, counter{elected: 2, demoted: 1}, c)
+}
+
+func Test_Elector_Run(t *testing.T) {
+	store := &fakeStore{now: time.Now}
+
+	elected := make(chan struct{})
+	demoted := make(chan struct{})
+	e, err := New(Config{
+		Store:     store,
+		Holder:    "a",
+		TTL:       time.Second,
+		OnElected: func() { close(elected) },
+		OnDemoted: func() { close(demoted) },
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- e.Run(ctx)
+	}()
+
+	<-elected
+	require.True(t, e.IsLeader())
+
+	// the lease is released on cancel, after demoting the replica
+	cancel()
+	require.NoError(t, <-done)
+	<-demoted
+	require.False(t, e.IsLeader())
+	require.Equal(t, "", store.holder)
+}
+
+func Test_Elector_RunAsLeader(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+	store := &fakeStore{now: clock}
+
+	var c counter
+	e := newElector(t, store, "a", 0, clock, &c)
+
+	// the work is refused before the replica is elected
+	calls := 0
+	err := e.RunAsLeader(func() error {
+		calls++
+		return nil
+	})
+	require.True(t, errors.Is(err, ErrNotLeader))
+	require.Equal(t, 0, calls)
+
+	e.campaign()
+	require.NoError(t, e.RunAsLeader(func() error {
+		calls++
+		return nil
+	}))
+	require.Equal(t, 1, calls)
+
+	// the replica isn't demoted while the work of the leader starts
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- e.RunAsLeader(func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	demoted := make(chan struct{})
+	go func() {
+		e.demote(nil)
+		close(demoted)
+	}()
+	select {
+	case <-demoted:
+		t.Fatal("the replica was demoted while the work of the leader started")
+	case <-time.After(20 * time.Millisecond):
+	}
+	require.True(t, e.IsLeader())
+
+	close(release)
+	require.NoError(t, <-done)
+	<-demoted
+	require.False(t, e.IsLeader())
+	require.Equal(t, counter{elected: 1, demoted: 1}, c)
+
+	// and the work is refused after the demotion
+	err = e.RunAsLeader(func() error {
+		calls++
+		return nil
+	})
+	require.True(t, errors.Is(err, ErrNotLeader))
+	require.Equal(t, 1, calls)
+}
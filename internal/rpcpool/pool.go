@@ -0,0 +1,673 @@
+package rpcpool
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/wrapper"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultMaxHeadLag    = int64(10)
+	DefaultMaxErrorRate  = float64(0.5)
+	DefaultEvictAfter    = 5
+	DefaultCheckInterval = 30 * time.Second
+	// DefaultIdleChecks is the number of health checks a node url given by a user is kept
+	// without being requested
+	DefaultIdleChecks = 10
+
+	// errorRateDecay is the weight of the last request in the error rate of an endpoint
+	errorRateDecay = 0.2
+	checkTimeout   = 10 * time.Second
+)
+
+var (
+	ErrNoHealthyEndpoint = errors.New("no healthy endpoint")
+	ErrWrongChain        = errors.New("node url chain id doesn't match the network")
+	ErrUnknownNetwork    = errors.New("network chain id unknown")
+)
+
+// Dialer creates the client of a node url.
+type Dialer func(nodeURL string) (*blockchain.Client, error)
+
+type Config struct {
+	// Endpoints are the node urls of each network used for the weighted selection and failover
+	Endpoints map[string][]string
+	// MaxHeadLag is the max number of blocks an endpoint can be behind the best head of its network
+	MaxHeadLag int64
+	// MaxErrorRate is the max rate of failed requests of a healthy endpoint, between 0 and 1
+	MaxErrorRate float64
+	// EvictAfter is the number of consecutive failures after which the client is closed
+	EvictAfter int
+	// CheckInterval is the time between the health checks of the endpoints
+	CheckInterval time.Duration
+	// ChainIDs are the chain ids of the networks, the node urls given by users are only used
+	// once their chain id matches the one of their network
+	ChainIDs map[string]int64
+	// IdleChecks is the number of health checks a node url given by a user is kept without
+	// being requested
+	IdleChecks int
+	Dial       Dialer
+	DateGen    wrapper.DateGenerator
+}
+
+// endpoint is a node of a network. Configured endpoints are shared by every request of the
+// network, the other ones are node urls given by users and only used when they're preferred.
+type endpoint struct {
+	url        string
+	network    string
+	configured bool
+	conn       *conn
+	// idleChecks is the number of health checks since the endpoint was last requested
+	idleChecks int
+
+	head      int64
+	lag       int64
+	errorRate float64
+	failures  int
+	lastError string
+	checkedAt *time.Time
+}
+
+// conn is the client of an endpoint, refs is the number of requests using it. An evicted
+// client is closed once every request released it.
+type conn struct {
+	client  *blockchain.Client
+	refs    int
+	retired bool
+}
+
+// EndpointHealth is the health of an endpoint of the pool.
+type EndpointHealth struct {
+	Network    string     `json:"network"`
+	URL        string     `json:"url"`
+	Configured bool       `json:"configured"`
+	Connected  bool       `json:"connected"`
+	Healthy    bool       `json:"healthy"`
+	Score      float64    `json:"score"`
+	Head       int64      `json:"head"`
+	Lag        int64      `json:"lag"`
+	ErrorRate  float64    `json:"errorRate"`
+	Failures   int        `json:"failures"`
+	LastError  string     `json:"lastError"`
+	CheckedAt  *time.Time `json:"checkedAt"`
+}
+
+// Pool keeps the clients of the nodes of every network. Requests get a client of a healthy
+// endpoint, chosen by weighted random selection using the head lag and error rate of each
+// endpoint, report the result so failing endpoints stop being selected, and release the
+// client when they're done. The clients of the endpoints that fail consecutively are closed
+// and dialed again on the next health check.
+//
+// The node urls given by users aren't part of the shared pool: they're only used by the
+// requests preferring them, once their chain id matches the network, and they're dropped
+// when they aren't requested anymore.
+type Pool struct {
+	mu sync.Mutex
+	// endpoints are the configured endpoints by url, and networks the configured endpoints of
+	// each network
+	endpoints map[string]*endpoint
+	networks  map[string][]*endpoint
+	// private are the endpoints of the node urls given by users by url
+	private map[string]*endpoint
+	conns   map[*blockchain.Client]*conn
+	rand    *rand.Rand
+
+	maxHeadLag    int64
+	maxErrorRate  float64
+	evictAfter    int
+	checkInterval time.Duration
+	chainIDs      map[string]int64
+	idleChecks    int
+	dial          Dialer
+	dateGen       wrapper.DateGenerator
+
+	quit chan struct{}
+	once sync.Once
+}
+
+func New(conf Config) *Pool {
+	p := &Pool{
+		endpoints:     make(map[string]*endpoint),
+		networks:      make(map[string][]*endpoint),
+		private:       make(map[string]*endpoint),
+		conns:         make(map[*blockchain.Client]*conn),
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		maxHeadLag:    conf.MaxHeadLag,
+		maxErrorRate:  conf.MaxErrorRate,
+		evictAfter:    conf.EvictAfter,
+		checkInterval: conf.CheckInterval,
+		chainIDs:      conf.ChainIDs,
+		idleChecks:    conf.IdleChecks,
+		dial:          conf.Dial,
+		dateGen:       conf.DateGen,
+		quit:          make(chan struct{}),
+	}
+
+	if p.maxHeadLag <= 0 {
+		p.maxHeadLag = DefaultMaxHeadLag
+	}
+	if p.maxErrorRate <= 0 || p.maxErrorRate > 1 {
+		p.maxErrorRate = DefaultMaxErrorRate
+	}
+	if p.evictAfter <= 0 {
+		p.evictAfter = DefaultEvictAfter
+	}
+	if p.checkInterval <= 0 {
+		p.checkInterval = DefaultCheckInterval
+	}
+	if p.idleChecks <= 0 {
+		p.idleChecks = DefaultIdleChecks
+	}
+	if p.dial == nil {
+		p.dial = blockchain.Dial
+	}
+	if p.dateGen == nil {
+		p.dateGen = time.Now
+	}
+
+	for network, urls := range conf.Endpoints {
+		for _, url := range urls {
+			if _, ok := p.endpoints[url]; ok {
+				continue
+			}
+			ep := &endpoint{
+				url:        url,
+				network:    network,
+				configured: true,
+			}
+			p.endpoints[url] = ep
+			p.networks[network] = append(p.networks[network], ep)
+		}
+	}
+
+	return p
+}
+
+// ParseEndpoints merges the node url of each network with the comma separated list of extra
+// node urls of the network, removing duplicates.
+func ParseEndpoints(nodeURL map[string]string, nodeURLs map[string]string) map[string][]string {
+	endpoints := make(map[string][]string)
+	add := func(network string, url string) {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			return
+		}
+		for _, u := range endpoints[network] {
+			if u == url {
+				return
+			}
+		}
+		endpoints[network] = append(endpoints[network], url)
+	}
+
+	for network, url := range nodeURL {
+		add(network, url)
+	}
+	for network, urls := range nodeURLs {
+		for _, url := range strings.Split(urls, ",") {
+			add(network, url)
+		}
+	}
+
+	return endpoints
+}
+
+// Client returns the client of an endpoint of the network. The preferred node url is used
+// while it is healthy, otherwise a configured endpoint of the network is selected. It
+// returns the node url of the client, that must be used to report the result of the
+// requests, and the client must be released once the requests are done.
+func (p *Pool) Client(network string, preferredURL string) (*blockchain.Client, string, error) {
+	tried := make(map[string]bool)
+	if preferredURL != "" {
+		ep, err := p.preferred(network, preferredURL)
+		if errors.Is(err, ErrWrongChain) || errors.Is(err, ErrUnknownNetwork) {
+			return nil, "", errors.Wrapf(err, "rpcpool: Pool.Client network=%s error", network)
+		}
+		if err == nil {
+			p.mu.Lock()
+			healthy := p.healthy(ep)
+			p.mu.Unlock()
+			if healthy {
+				client, err := p.connect(ep)
+				if err == nil {
+					return client, ep.url, nil
+				}
+			}
+		}
+		tried[preferredURL] = true
+	}
+
+	// select a healthy configured endpoint by weight, failing over to the next one when it can't be dialed
+	for {
+		selected := p.selectEndpoint(network, tried)
+		if selected == nil {
+			return nil, "", errors.Wrapf(ErrNoHealthyEndpoint, "rpcpool: Pool.Client network=%s error", network)
+		}
+
+		client, err := p.connect(selected)
+		if err == nil {
+			return client, selected.url, nil
+		}
+		tried[selected.url] = true
+	}
+}
+
+// Release returns a client got from Client. An evicted client is closed once every request
+// using it released it.
+func (p *Pool) Release(client *blockchain.Client) {
+	if client == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, ok := p.conns[client]
+	if !ok {
+		return
+	}
+	c.refs--
+	p.closeIfUnused(c)
+}
+
+// Verify checks the node url belongs to the network. Node urls that aren't configured are kept
+// as private endpoints, only used by the requests preferring them.
+func (p *Pool) Verify(network string, nodeURL string) error {
+	_, err := p.preferred(network, nodeURL)
+	return err
+}
+
+// Networks returns the networks with configured endpoints.
+func (p *Pool) Networks() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	networks := make([]string, 0)
+	for network, endpoints := range p.networks {
+		for _, ep := range endpoints {
+			if ep.configured {
+				networks = append(networks, network)
+				break
+			}
+		}
+	}
+	sort.Strings(networks)
+
+	return networks
+}
+
+// Report records the result of a request made with the client of the node url. Errors that
+// aren't caused by the node, like a block range too large or a canceled context, are ignored.
+func (p *Pool) Report(nodeURL string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ep, ok := p.endpoints[nodeURL]
+	if !ok {
+		ep, ok = p.private[nodeURL]
+	}
+	if !ok {
+		return
+	}
+
+	if err == nil {
+		p.success(ep)
+		return
+	}
+
+	var rangeErr *blockchain.RangeTooLargeError
+	if errors.As(err, &rangeErr) || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	p.failure(ep, err)
+}
+
+// Check requests the block number of every endpoint, updating their head lag against the
+// best head of the configured endpoints of the network, so the node urls given by users can't
+// make the configured ones look behind. Endpoints without client are dialed again, and the node urls
+// given by users that weren't requested since the last checks are dropped.
+func (p *Pool) Check(ctx context.Context) {
+	p.mu.Lock()
+	endpoints := make([]*endpoint, 0)
+	for _, ep := range p.endpoints {
+		endpoints = append(endpoints, ep)
+	}
+	configured := len(endpoints)
+	for url, ep := range p.private {
+		ep.idleChecks++
+		if ep.idleChecks > p.idleChecks {
+			p.evict(ep)
+			delete(p.private, url)
+			continue
+		}
+		endpoints = append(endpoints, ep)
+	}
+	p.mu.Unlock()
+
+	// request the heads concurrently without holding the lock
+	heads := make([]int64, len(endpoints))
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range endpoints {
+		wg.Add(1)
+		go func(i int, ep *endpoint) {
+			defer wg.Done()
+
+			client, err := p.connect(ep)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer p.Release(client)
+
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+			head, err := client.BlockNumber(checkCtx)
+			heads[i] = int64(head)
+			errs[i] = err
+		}(i, ep)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.dateGen()
+	best := make(map[string]int64)
+	for i, ep := range endpoints {
+		ep.checkedAt = &now
+		if errs[i] != nil {
+			p.failure(ep, blockchain.ClassifyError(errs[i]))
+			continue
+		}
+
+		p.success(ep)
+		ep.head = heads[i]
+		if i < configured && ep.head > best[ep.network] {
+			best[ep.network] = ep.head
+		}
+	}
+
+	for i, ep := range endpoints {
+		if errs[i] != nil {
+			continue
+		}
+
+		// private endpoints can be ahead of the configured ones
+		ep.lag = best[ep.network] - ep.head
+		if ep.lag < 0 {
+			ep.lag = 0
+		}
+	}
+}
+
+// Start runs the health checks of the endpoints on every check interval until Stop is called.
+func (p *Pool) Start() {
+	go func() {
+		ticker := time.NewTicker(p.checkInterval)
+		defer ticker.Stop()
+
+		p.Check(context.Background())
+		for {
+			select {
+			case <-p.quit:
+				return
+			case <-ticker.C:
+				p.Check(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop stops the health checks and closes the clients of the pool.
+func (p *Pool) Stop() {
+	p.once.Do(func() {
+		close(p.quit)
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ep := range p.endpoints {
+		p.evict(ep)
+	}
+	for _, ep := range p.private {
+		p.evict(ep)
+	}
+}
+
+// Health returns the health of the configured endpoints of the network, or of every network
+// when it is empty. The node urls given by users aren't part of the shared pool.
+func (p *Pool) Health(network string) []*EndpointHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	health := make([]*EndpointHealth, 0)
+	for _, ep := range p.endpoints {
+		if network != "" && ep.network != network {
+			continue
+		}
+
+		health = append(health, &EndpointHealth{
+			Network:    ep.network,
+			URL:        ep.url,
+			Configured: ep.configured,
+			Connected:  ep.conn != nil,
+			Healthy:    p.healthy(ep),
+			Score:      p.score(ep),
+			Head:       ep.head,
+			Lag:        ep.lag,
+			ErrorRate:  ep.errorRate,
+			Failures:   ep.failures,
+			LastError:  ep.lastError,
+			CheckedAt:  ep.checkedAt,
+		})
+	}
+
+	sort.Slice(health, func(i, j int) bool {
+		if health[i].Network != health[j].Network {
+			return health[i].Network < health[j].Network
+		}
+		return health[i].URL < health[j].URL
+	})
+
+	return health
+}
+
+// preferred returns the endpoint of the node url. Node urls that aren't configured are
+// registered as private endpoints of the network once their chain id is verified.
+func (p *Pool) preferred(network string, nodeURL string) (*endpoint, error) {
+	p.mu.Lock()
+	ep, ok := p.endpoints[nodeURL]
+	if !ok {
+		ep, ok = p.private[nodeURL]
+	}
+	if ok {
+		ep.idleChecks = 0
+	}
+	p.mu.Unlock()
+
+	if ok {
+		if ep.network != network {
+			return nil, errors.Wrapf(ErrWrongChain, "rpcpool: Pool.preferred node url registered for network=%s", ep.network)
+		}
+		return ep, nil
+	}
+
+	// dial and verify the node without holding the lock
+	client, err := p.dial(nodeURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "rpcpool: Pool.preferred p.dial error")
+	}
+	err = p.verify(network, client)
+	if err != nil {
+		client.Close()
+		return nil, errors.Wrap(err, "rpcpool: Pool.preferred p.verify error")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// other request registered the node meanwhile
+	if ep, ok := p.private[nodeURL]; ok {
+		client.Close()
+		return ep, nil
+	}
+
+	ep = &endpoint{
+		url:     nodeURL,
+		network: network,
+		conn:    &conn{client: client},
+	}
+	p.private[nodeURL] = ep
+	p.conns[client] = ep.conn
+
+	return ep, nil
+}
+
+// verify checks the chain id of the node is the one of the network.
+func (p *Pool) verify(network string, client *blockchain.Client) error {
+	chainID, ok := p.chainIDs[network]
+	if !ok {
+		return errors.Wrapf(ErrUnknownNetwork, "network=%s", network)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	id, err := client.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if id.Int64() != chainID {
+		return errors.Wrapf(ErrWrongChain, "network=%s chain_id=%d node_chain_id=%s", network, chainID, id.String())
+	}
+
+	return nil
+}
+
+// selectEndpoint picks a healthy configured endpoint of the network by weight, skipping the
+// tried ones. It returns nil when there's none.
+func (p *Pool) selectEndpoint(network string, tried map[string]bool) *endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	candidates := make([]*endpoint, 0)
+	total := float64(0)
+	for _, ep := range p.networks[network] {
+		if tried[ep.url] || !p.healthy(ep) {
+			continue
+		}
+		candidates = append(candidates, ep)
+		total += p.score(ep)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	selected := candidates[len(candidates)-1]
+	r := p.rand.Float64() * total
+	for _, ep := range candidates {
+		r -= p.score(ep)
+		if r < 0 {
+			selected = ep
+			break
+		}
+	}
+
+	return selected
+}
+
+// connect returns the client of the endpoint retained for the request, dialing it without
+// holding the lock when it was never dialed or it was evicted.
+func (p *Pool) connect(ep *endpoint) (*blockchain.Client, error) {
+	p.mu.Lock()
+	if ep.conn != nil {
+		ep.conn.refs++
+		client := ep.conn.client
+		p.mu.Unlock()
+		return client, nil
+	}
+	p.mu.Unlock()
+
+	client, err := p.dial(ep.url)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		p.failure(ep, err)
+		return nil, err
+	}
+
+	// other request dialed the endpoint meanwhile
+	if ep.conn != nil {
+		go client.Close()
+	} else {
+		ep.conn = &conn{client: client}
+		p.conns[client] = ep.conn
+	}
+	ep.conn.refs++
+
+	return ep.conn.client, nil
+}
+
+func (p *Pool) success(ep *endpoint) {
+	ep.errorRate = ep.errorRate * (1 - errorRateDecay)
+	ep.failures = 0
+}
+
+func (p *Pool) failure(ep *endpoint, err error) {
+	ep.errorRate = ep.errorRate*(1-errorRateDecay) + errorRateDecay
+	ep.failures++
+	ep.lastError = err.Error()
+
+	// close the client of dead endpoints, the health check dials them again
+	if ep.failures >= p.evictAfter && ep.conn != nil {
+		log.Printf("rpcpool: evicting client of network=%s url=%s after %d failures \n", ep.network, ep.url, ep.failures)
+		p.evict(ep)
+	}
+}
+
+// evict detaches the client from the endpoint, it's closed once the requests using it are done.
+// It must be called holding the lock.
+func (p *Pool) evict(ep *endpoint) {
+	if ep.conn == nil {
+		return
+	}
+
+	ep.conn.retired = true
+	p.closeIfUnused(ep.conn)
+	ep.conn = nil
+}
+
+// closeIfUnused closes the client of an evicted connection that isn't used by any request. It
+// must be called holding the lock.
+func (p *Pool) closeIfUnused(c *conn) {
+	if !c.retired || c.refs > 0 {
+		return
+	}
+
+	delete(p.conns, c.client)
+	// closing a WebSocket client waits for the connection, so it's done without the lock
+	go c.client.Close()
+}
+
+// healthy returns true when the endpoint isn't failing and it's close to the best head.
+// Endpoints that were never checked are healthy until they fail.
+func (p *Pool) healthy(ep *endpoint) bool {
+	return ep.failures < p.evictAfter && ep.errorRate <= p.maxErrorRate && ep.lag <= p.maxHeadLag
+}
+
+// score is the weight of the endpoint in the selection, it decreases with the error rate
+// and the head lag.
+func (p *Pool) score(ep *endpoint) float64 {
+	return (1 - ep.errorRate) / float64(1+ep.lag)
+}
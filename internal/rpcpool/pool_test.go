@@ -0,0 +1,268 @@
+package rpcpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/jaekwon/testify/require"
+)
+
+// newNode starts a JSON-RPC node of ethereum answering eth_blockNumber with the given head, or
+// failing every request when head is negative.
+func newNode(t *testing.T, head *int64) string {
+	return newChainNode(t, 1, head)
+}
+
+// newChainNode starts a JSON-RPC node answering eth_chainId with the chain id and
+// eth_blockNumber with the given head, or failing every request when head is negative.
+func newChainNode(t *testing.T, chainID int64, head *int64) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		require.NoError(t, err)
+
+		current := atomic.LoadInt64(head)
+		if current < 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		result := current
+		if req.Method == "eth_chainId" {
+			result = chainID
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x%x"}`, req.ID, result)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func Test_Pool_Failover(t *testing.T) {
+	headA, headB := int64(100), int64(100)
+	urlA := newNode(t, &headA)
+	urlB := newNode(t, &headB)
+
+	pool := New(Config{
+		Endpoints:  map[string][]string{"ethereum": {urlA, urlB}},
+		EvictAfter: 2,
+	})
+	defer pool.Stop()
+
+	// both endpoints are selected while they're healthy
+	selected := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		_, url, err := pool.Client("ethereum", "")
+		require.NoError(t, err)
+		selected[url] = true
+	}
+	require.True(t, selected[urlA] && selected[urlB])
+
+	// the failing endpoint is evicted and the requests fail over to the other one
+	atomic.StoreInt64(&headA, -1)
+	pool.Check(context.Background())
+	pool.Check(context.Background())
+	for i := 0; i < 20; i++ {
+		_, url, err := pool.Client("ethereum", "")
+		require.NoError(t, err)
+		require.Equal(t, urlB, url)
+	}
+
+	health := pool.Health("ethereum")
+	require.Len(t, health, 2)
+	for _, h := range health {
+		if h.URL == urlA {
+			require.False(t, h.Healthy)
+			require.False(t, h.Connected)
+			require.Equal(t, 2, h.Failures)
+		}
+	}
+
+	// no endpoint left when both fail
+	atomic.StoreInt64(&headB, -1)
+	pool.Report(urlB, errors.New("503 Service Unavailable"))
+	pool.Report(urlB, errors.New("503 Service Unavailable"))
+	_, _, err := pool.Client("ethereum", "")
+	require.True(t, errors.Is(err, ErrNoHealthyEndpoint))
+
+	// the endpoint is dialed again and selected once it recovers
+	atomic.StoreInt64(&headA, 100)
+	pool.Check(context.Background())
+	_, url, err := pool.Client("ethereum", "")
+	require.NoError(t, err)
+	require.Equal(t, urlA, url)
+}
+
+func Test_Pool_HeadLag(t *testing.T) {
+	headA, headB := int64(100), int64(50)
+	urlA := newNode(t, &headA)
+	urlB := newNode(t, &headB)
+
+	pool := New(Config{
+		Endpoints:  map[string][]string{"ethereum": {urlA, urlB}},
+		MaxHeadLag: 10,
+	})
+	defer pool.Stop()
+	pool.Check(context.Background())
+
+	for i := 0; i < 20; i++ {
+		_, url, err := pool.Client("ethereum", "")
+		require.NoError(t, err)
+		require.Equal(t, urlA, url)
+	}
+
+	// a preferred endpoint behind the network head isn't used
+	_, url, err := pool.Client("ethereum", urlB)
+	require.NoError(t, err)
+	require.Equal(t, urlA, url)
+}
+
+func Test_Pool_PreferredURL(t *testing.T) {
+	head := int64(100)
+	configured := newNode(t, &head)
+	userNode := newNode(t, &head)
+
+	pool := New(Config{
+		Endpoints:  map[string][]string{"ethereum": {configured}},
+		ChainIDs:   map[string]int64{"ethereum": 1},
+		IdleChecks: 1,
+	})
+	defer pool.Stop()
+
+	_, url, err := pool.Client("ethereum", userNode)
+	require.NoError(t, err)
+	require.Equal(t, userNode, url)
+
+	// node urls given by users are never selected for other requests nor part of the pool health
+	for i := 0; i < 20; i++ {
+		_, url, err := pool.Client("ethereum", "")
+		require.NoError(t, err)
+		require.Equal(t, configured, url)
+	}
+	health := pool.Health("")
+	require.Len(t, health, 1)
+	require.Equal(t, configured, health[0].URL)
+
+	// errors that aren't caused by the node don't count as failures
+	max := int64(10)
+	for i := 0; i < 10; i++ {
+		pool.Report(userNode, &blockchain.RangeTooLargeError{MaxBlocks: &max})
+		pool.Report(userNode, context.Canceled)
+	}
+	_, url, err = pool.Client("ethereum", userNode)
+	require.NoError(t, err)
+	require.Equal(t, userNode, url)
+
+	// node urls that aren't requested anymore are dropped from the pool
+	pool.Check(context.Background())
+	pool.Check(context.Background())
+	pool.mu.Lock()
+	require.Len(t, pool.private, 0)
+	pool.mu.Unlock()
+}
+
+func Test_Pool_PreferredURLAhead(t *testing.T) {
+	head, userHead := int64(100), int64(1000)
+	configured := newNode(t, &head)
+	userNode := newNode(t, &userHead)
+
+	pool := New(Config{
+		Endpoints:  map[string][]string{"ethereum": {configured}},
+		ChainIDs:   map[string]int64{"ethereum": 1},
+		MaxHeadLag: 10,
+	})
+	defer pool.Stop()
+
+	_, url, err := pool.Client("ethereum", userNode)
+	require.NoError(t, err)
+	require.Equal(t, userNode, url)
+
+	// a node url given by a user ahead of the network doesn't make the configured ones lag
+	pool.Check(context.Background())
+	health := pool.Health("ethereum")
+	require.Len(t, health, 1)
+	require.True(t, health[0].Healthy)
+	require.Equal(t, int64(0), health[0].Lag)
+
+	_, url, err = pool.Client("ethereum", "")
+	require.NoError(t, err)
+	require.Equal(t, configured, url)
+}
+
+func Test_Pool_PreferredURLWrongChain(t *testing.T) {
+	head := int64(100)
+	configured := newNode(t, &head)
+	polygonNode := newChainNode(t, 137, &head)
+
+	pool := New(Config{
+		Endpoints: map[string][]string{"ethereum": {configured}},
+		ChainIDs:  map[string]int64{"ethereum": 1, "polygon": 137},
+	})
+	defer pool.Stop()
+
+	// a node of other chain claimed for the network is rejected
+	err := pool.Verify("ethereum", polygonNode)
+	require.True(t, errors.Is(err, ErrWrongChain))
+	_, _, err = pool.Client("ethereum", polygonNode)
+	require.True(t, errors.Is(err, ErrWrongChain))
+
+	// networks without chain id don't accept node urls given by users
+	err = pool.Verify("celo", polygonNode)
+	require.True(t, errors.Is(err, ErrUnknownNetwork))
+
+	err = pool.Verify("polygon", polygonNode)
+	require.NoError(t, err)
+}
+
+func Test_Pool_ReleaseEvicted(t *testing.T) {
+	head := int64(100)
+	url := newNode(t, &head)
+
+	pool := New(Config{
+		Endpoints:  map[string][]string{"ethereum": {url}},
+		EvictAfter: 1,
+	})
+	defer pool.Stop()
+
+	client, _, err := pool.Client("ethereum", "")
+	require.NoError(t, err)
+
+	// the evicted client is kept open while it's used by a request
+	pool.Report(url, errors.New("503 Service Unavailable"))
+	pool.mu.Lock()
+	c, ok := pool.conns[client]
+	pool.mu.Unlock()
+	require.True(t, ok)
+	require.True(t, c.retired)
+	_, err = client.BlockNumber(context.Background())
+	require.NoError(t, err)
+
+	pool.Release(client)
+	pool.mu.Lock()
+	_, ok = pool.conns[client]
+	pool.mu.Unlock()
+	require.False(t, ok)
+}
+
+func Test_ParseEndpoints(t *testing.T) {
+	endpoints := ParseEndpoints(
+		map[string]string{"ethereum": "https://a", "polygon": "https://p"},
+		map[string]string{"ethereum": "https://b, https://a,,https://c"},
+	)
+
+	require.Equal(t, []string{"https://a", "https://b", "https://c"}, endpoints["ethereum"])
+	require.Equal(t, []string{"https://p"}, endpoints["polygon"])
+}
@@ -0,0 +1,380 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/internal/wrapper"
+)
+
+const (
+	// ShardLeasePrefix is the prefix of the leases of the shards, followed by the shard number
+	ShardLeasePrefix = "shard:"
+	// ReplicaLeasePrefix is the prefix of the heartbeat leases of the replicas, followed by the
+	// holder of the replica
+	ReplicaLeasePrefix = "replica:"
+	// DefaultTTL is the time the leases are held without being renewed when no ttl is configured
+	DefaultTTL = 30 * time.Second
+)
+
+// ContractKey identifies a contract by its network, node and address, the contracts are
+// assigned to the shards by their key.
+func ContractKey(network string, nodeURL string, address string) string {
+	return fmt.Sprintf("%s:%s:%s", network, nodeURL, strings.ToLower(address))
+}
+
+// Of returns the shard of the key, between 0 and shards-1.
+func Of(key string, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// LeaseName returns the name of the lease of the shard.
+func LeaseName(shard int) string {
+	return ShardLeasePrefix + strconv.Itoa(shard)
+}
+
+// LeaseStore takes, renews, frees and lists the leases shared by the replicas.
+type LeaseStore interface {
+	AcquireLease(input *syncng.AcquireLeaseInput) (*syncng.AcquireLeaseOutput, error)
+	ReleaseLease(input *syncng.ReleaseLeaseInput) error
+	SelectLeases(input *syncng.SelectLeasesInput) (*syncng.SelectLeasesOutput, error)
+}
+
+// Status is the assignment of the shards to the replica.
+type Status struct {
+	Holder   string
+	Shards   int
+	Replicas int
+	// Target is the number of shards each replica holds when they're balanced
+	Target int
+	Owned  []int
+	Error  string
+}
+
+// Coordinator distributes the shards of the ingestion work across the replicas with a lease
+// per shard. Every replica heartbeats its own lease, and holds up to its fair share of the
+// shards given the replicas alive. The shards of a replica that dies expire and are taken by
+// the other ones, and a replica holding more shards than its share releases the surplus so
+// a new replica gets work.
+type Coordinator struct {
+	mu       sync.Mutex
+	owned    map[int]bool
+	draining map[int]bool
+	replicas int
+	target   int
+	deadline time.Time
+	err      error
+
+	store     LeaseStore
+	holder    string
+	shards    int
+	ttl       time.Duration
+	onRelease func()
+	dateGen   wrapper.DateGenerator
+}
+
+type Config struct {
+	Store LeaseStore
+	// Holder identifies the replica, it must be unique across the replicas
+	Holder string
+	// Shards is the number of shards of the ingestion work
+	Shards int
+	TTL    time.Duration
+	// OnRelease is called before the leases of the shards dropped by the replica are released,
+	// it must stop the work of the shards the replica doesn't hold anymore before returning
+	OnRelease func()
+	DateGen   wrapper.DateGenerator
+}
+
+func New(conf Config) *Coordinator {
+	c := &Coordinator{
+		owned:     make(map[int]bool),
+		draining:  make(map[int]bool),
+		store:     conf.Store,
+		holder:    conf.Holder,
+		shards:    conf.Shards,
+		ttl:       conf.TTL,
+		onRelease: conf.OnRelease,
+		dateGen:   conf.DateGen,
+	}
+
+	if c.shards <= 0 {
+		c.shards = 1
+	}
+	if c.ttl <= 0 {
+		c.ttl = DefaultTTL
+	}
+	if c.onRelease == nil {
+		c.onRelease = func() {}
+	}
+	if c.dateGen == nil {
+		c.dateGen = time.Now
+	}
+
+	return c
+}
+
+// Run heartbeats the replica and balances the shards every third of the ttl until the context
+// is canceled, then releases the leases of the replica so its shards are taken right away.
+func (c *Coordinator) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		c.balance()
+
+		select {
+		case <-ctx.Done():
+			c.release()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Owns returns true when the key belongs to a shard held by the replica.
+func (c *Coordinator) Owns(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.owned[Of(key, c.shards)]
+}
+
+func (c *Coordinator) Status() *Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := &Status{
+		Holder:   c.holder,
+		Shards:   c.shards,
+		Replicas: c.replicas,
+		Target:   c.target,
+		Owned:    sortedShards(c.owned),
+	}
+	if c.err != nil {
+		status.Error = c.err.Error()
+	}
+
+	return status
+}
+
+// balance renews the heartbeat and the shards of the replica, releases the surplus and takes
+// the free shards up to the share of the replica.
+func (c *Coordinator) balance() {
+	// the deadline is taken before the requests, so it never exceeds the expiration in the database
+	start := c.dateGen()
+
+	err := c.heartbeat()
+	if err != nil {
+		c.fail(start, err)
+		return
+	}
+
+	shardLeases, replicas, err := c.leases()
+	if err != nil {
+		c.fail(start, err)
+		return
+	}
+	target := (c.shards + replicas - 1) / replicas
+
+	// the shards dropped on the previous round are released once their in-flight runs are
+	// canceled and finished
+	c.mu.Lock()
+	draining := sortedShards(c.draining)
+	c.draining = make(map[int]bool)
+	c.mu.Unlock()
+	if len(draining) > 0 {
+		c.onRelease()
+	}
+	released := make(map[int]bool)
+	for _, shard := range draining {
+		err := c.store.ReleaseLease(&syncng.ReleaseLeaseInput{
+			Name:   LeaseName(shard),
+			Holder: c.holder,
+		})
+		if err != nil {
+			log.Printf("shard: Coordinator.balance c.store.ReleaseLease shard=%d error: %s \n", shard, err.Error())
+		}
+		released[shard] = true
+	}
+
+	// renew the shards of the replica, then take the free ones while it holds less than the
+	// target. The shards just released are left for the other replicas.
+	owned := make(map[int]bool)
+	for _, renew := range []bool{true, false} {
+		for shard := 0; shard < c.shards; shard++ {
+			lease, ok := shardLeases[shard]
+			mine := ok && lease.Holder == c.holder && !lease.Expired
+			free := !ok || lease.Expired
+			if released[shard] || (renew && !mine) || (!renew && (!free || len(owned) >= target)) {
+				continue
+			}
+
+			output, err := c.store.AcquireLease(&syncng.AcquireLeaseInput{
+				Name:   LeaseName(shard),
+				Holder: c.holder,
+				TTL:    c.ttl,
+			})
+			if err != nil {
+				c.fail(start, err)
+				return
+			}
+			if output.Acquired {
+				owned[shard] = true
+			}
+		}
+	}
+
+	// drop the surplus, it stops being synced now and it's released on the next round
+	surplus := make(map[int]bool)
+	for shards := sortedShards(owned); len(shards) > target; shards = shards[:len(shards)-1] {
+		shard := shards[len(shards)-1]
+		delete(owned, shard)
+		surplus[shard] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logChanges(owned)
+	c.owned = owned
+	c.draining = surplus
+	c.replicas = replicas
+	c.target = target
+	c.deadline = start.Add(c.ttl)
+	c.err = nil
+}
+
+func (c *Coordinator) heartbeat() error {
+	output, err := c.store.AcquireLease(&syncng.AcquireLeaseInput{
+		Name:   ReplicaLeasePrefix + c.holder,
+		Holder: c.holder,
+		TTL:    c.ttl,
+	})
+	if err != nil {
+		return err
+	}
+	if !output.Acquired {
+		return fmt.Errorf("shard: replica lease of %s is held by other replica", c.holder)
+	}
+
+	return nil
+}
+
+// leases returns the leases of the shards by shard number, and the number of replicas alive.
+func (c *Coordinator) leases() (map[int]*storage.LeaseRecord, int, error) {
+	output, err := c.store.SelectLeases(&syncng.SelectLeasesInput{
+		Prefix: ShardLeasePrefix,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	shardLeases := make(map[int]*storage.LeaseRecord)
+	for _, lease := range output.Leases {
+		shard, err := strconv.Atoi(strings.TrimPrefix(lease.Name, ShardLeasePrefix))
+		if err != nil {
+			continue
+		}
+		shardLeases[shard] = lease
+	}
+
+	output, err = c.store.SelectLeases(&syncng.SelectLeasesInput{
+		Prefix: ReplicaLeasePrefix,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	replicas := 0
+	for _, lease := range output.Leases {
+		if !lease.Expired {
+			replicas++
+		}
+	}
+	// the heartbeat of the replica was just renewed
+	if replicas == 0 {
+		replicas = 1
+	}
+
+	return shardLeases, replicas, nil
+}
+
+// fail keeps the shards of the replica while they can be renewed before they expire, and
+// drops all of them otherwise.
+func (c *Coordinator) fail(start time.Time, err error) {
+	log.Printf("shard: Coordinator.balance error: %s \n", err.Error())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.err = err
+	if !start.Add(c.ttl / 3).Before(c.deadline) {
+		c.logChanges(nil)
+		c.owned = make(map[int]bool)
+		c.draining = make(map[int]bool)
+	}
+}
+
+// release drops and frees the shards and the heartbeat of the replica.
+func (c *Coordinator) release() {
+	c.mu.Lock()
+	shards := sortedShards(c.owned)
+	shards = append(shards, sortedShards(c.draining)...)
+	c.owned = make(map[int]bool)
+	c.draining = make(map[int]bool)
+	c.mu.Unlock()
+
+	// the work of the shards stops before the leases are freed
+	c.onRelease()
+
+	names := []string{ReplicaLeasePrefix + c.holder}
+	for _, shard := range shards {
+		names = append(names, LeaseName(shard))
+	}
+	for _, name := range names {
+		err := c.store.ReleaseLease(&syncng.ReleaseLeaseInput{
+			Name:   name,
+			Holder: c.holder,
+		})
+		if err != nil {
+			log.Printf("shard: Coordinator.release c.store.ReleaseLease lease=%s error: %s \n", name, err.Error())
+		}
+	}
+}
+
+// logChanges logs the shards taken and dropped by the replica, it must be called with the lock.
+func (c *Coordinator) logChanges(owned map[int]bool) {
+	for shard := range owned {
+		if !c.owned[shard] {
+			log.Printf("shard: replica %s took shard %d \n", c.holder, shard)
+		}
+	}
+	for shard := range c.owned {
+		if !owned[shard] {
+			log.Printf("shard: replica %s dropped shard %d \n", c.holder, shard)
+		}
+	}
+}
+
+func sortedShards(set map[int]bool) []int {
+	shards := make([]int, 0, len(set))
+	for shard := range set {
+		shards = append(shards, shard)
+	}
+	sort.Ints(shards)
+
+	return shards
+}
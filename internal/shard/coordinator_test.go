@@ -0,0 +1,199 @@
+package shard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	syncng "github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/jaekwon/testify/require"
+)
+
+// fakeStore keeps the leases in memory, like the lease table shared by the replicas.
+type fakeStore struct {
+	mu     sync.Mutex
+	leases map[string]*storage.LeaseRecord
+	now    func() time.Time
+}
+
+func newFakeStore(now func() time.Time) *fakeStore {
+	return &fakeStore{
+		leases: make(map[string]*storage.LeaseRecord),
+		now:    now,
+	}
+}
+
+func (s *fakeStore) AcquireLease(input *syncng.AcquireLeaseInput) (*syncng.AcquireLeaseOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	lease, ok := s.leases[input.Name]
+	if ok && lease.Holder != input.Holder && now.Before(lease.ExpiresAt) {
+		return &syncng.AcquireLeaseOutput{}, nil
+	}
+
+	lease = &storage.LeaseRecord{
+		Name:      input.Name,
+		Holder:    input.Holder,
+		ExpiresAt: now.Add(input.TTL),
+		RenewedAt: now,
+	}
+	s.leases[input.Name] = lease
+	return &syncng.AcquireLeaseOutput{Lease: lease, Acquired: true}, nil
+}
+
+func (s *fakeStore) ReleaseLease(input *syncng.ReleaseLeaseInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lease, ok := s.leases[input.Name]; ok && lease.Holder == input.Holder {
+		delete(s.leases, input.Name)
+	}
+	return nil
+}
+
+func (s *fakeStore) SelectLeases(input *syncng.SelectLeasesInput) (*syncng.SelectLeasesOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	leases := make([]*storage.LeaseRecord, 0)
+	for name, lease := range s.leases {
+		if strings.HasPrefix(name, input.Prefix) {
+			l := *lease
+			l.Expired = !s.now().Before(lease.ExpiresAt)
+			leases = append(leases, &l)
+		}
+	}
+	sort.Slice(leases, func(i, j int) bool { return leases[i].Name < leases[j].Name })
+
+	return &syncng.SelectLeasesOutput{Leases: leases}, nil
+}
+
+func Test_Of(t *testing.T) {
+	counts := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		shard := Of(ContractKey("ethereum", "https://node", fmt.Sprintf("0x%040x", i)), 4)
+		require.True(t, shard >= 0 && shard < 4)
+		counts[shard]++
+	}
+	require.Len(t, counts, 4)
+
+	// the address isn't case sensitive
+	require.Equal(t,
+		Of(ContractKey("ethereum", "https://node", "0xABC"), 8),
+		Of(ContractKey("ethereum", "https://node", "0xabc"), 8),
+	)
+	require.Equal(t, 0, Of("any", 1))
+}
+
+func Test_Coordinator_Balance(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+	store := newFakeStore(clock)
+
+	newCoordinator := func(holder string) *Coordinator {
+		return New(Config{Store: store, Holder: holder, Shards: 4, TTL: 30 * time.Second, DateGen: clock})
+	}
+
+	// a single replica holds every shard
+	a := newCoordinator("a")
+	a.balance()
+	require.Equal(t, []int{0, 1, 2, 3}, a.Status().Owned)
+	require.Equal(t, 1, a.Status().Replicas)
+
+	// a new replica waits for the surplus of the other one
+	b := newCoordinator("b")
+	b.balance()
+	require.Empty(t, b.Status().Owned)
+	require.Equal(t, 2, b.Status().Target)
+
+	// the surplus stops being synced, and it's released on the next round
+	now = now.Add(10 * time.Second)
+	a.balance()
+	require.Equal(t, []int{0, 1}, a.Status().Owned)
+	require.False(t, a.Owns(keyOfShard(t, 3, 4)))
+
+	now = now.Add(10 * time.Second)
+	a.balance()
+	b.balance()
+	require.Equal(t, []int{0, 1}, a.Status().Owned)
+	require.Equal(t, []int{2, 3}, b.Status().Owned)
+	require.True(t, b.Owns(keyOfShard(t, 3, 4)))
+
+	// the shards of a replica that dies are taken once they expire
+	now = now.Add(10 * time.Second)
+	b.balance()
+	require.Equal(t, []int{2, 3}, b.Status().Owned)
+
+	now = now.Add(30 * time.Second)
+	b.balance()
+	require.Equal(t, 1, b.Status().Replicas)
+	require.Equal(t, []int{0, 1, 2, 3}, b.Status().Owned)
+}
+
+func Test_Coordinator_Release(t *testing.T) {
+	store := newFakeStore(time.Now)
+	a := New(Config{Store: store, Holder: "a", Shards: 2})
+	a.balance()
+	require.Equal(t, []int{0, 1}, a.Status().Owned)
+
+	a.release()
+	require.Empty(t, a.Status().Owned)
+	require.Empty(t, store.leases)
+}
+
+func Test_Coordinator_OnRelease(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+	store := newFakeStore(clock)
+
+	// the hook records the holder of the dropped shard when the runs are stopped
+	var a *Coordinator
+	holders := make([]string, 0)
+	a = New(Config{Store: store, Holder: "a", Shards: 2, TTL: 30 * time.Second, DateGen: clock, OnRelease: func() {
+		require.False(t, a.Owns(keyOfShard(t, 1, 2)))
+		holder := ""
+		if lease, ok := store.leases[LeaseName(1)]; ok {
+			holder = lease.Holder
+		}
+		holders = append(holders, holder)
+	}})
+	b := New(Config{Store: store, Holder: "b", Shards: 2, TTL: 30 * time.Second, DateGen: clock})
+	a.balance()
+	b.balance()
+	require.Empty(t, holders)
+
+	// the surplus is dropped, and its runs are stopped before the lease is released
+	now = now.Add(10 * time.Second)
+	a.balance()
+	require.Empty(t, holders)
+
+	now = now.Add(10 * time.Second)
+	a.balance()
+	require.Equal(t, []string{"a"}, holders)
+	_, held := store.leases[LeaseName(1)]
+	require.False(t, held)
+
+	// the runs are stopped too when the replica releases every shard
+	a.release()
+	require.Equal(t, []string{"a", ""}, holders)
+}
+
+// keyOfShard returns a contract key assigned to the shard.
+func keyOfShard(t *testing.T, shard int, shards int) string {
+	t.Helper()
+
+	for i := 0; i < 1000; i++ {
+		key := ContractKey("ethereum", "https://node", fmt.Sprintf("0x%040x", i))
+		if Of(key, shards) == shard {
+			return key
+		}
+	}
+	t.Fatalf("no key of shard %d", shard)
+	return ""
+}
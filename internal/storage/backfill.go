@@ -0,0 +1,56 @@
+package storage
+
+import "time"
+
+type BackfillStatus string
+
+const (
+	BackfillStatusPending   BackfillStatus = "pending"
+	BackfillStatusRunning   BackfillStatus = "running"
+	BackfillStatusCompleted BackfillStatus = "completed"
+	BackfillStatusFailed    BackfillStatus = "failed"
+)
+
+// BackfillJobRecord ingests the logs of the events of a contract in a historical block range.
+// The range is split in chunks of ChunkSize blocks, and up to Concurrency chunks are synced
+// in parallel while the events keep following the chain from their own checkpoint.
+type BackfillJobRecord struct {
+	ID                   string         `db:"id"`
+	UserID               string         `db:"user_id"`
+	SmartContractAddress string         `db:"sc_address"`
+	Network              EventNetwork   `db:"network"`
+	NodeURL              string         `db:"node_url"`
+	FromBlockNumber      int64          `db:"from_block_number"`
+	ToBlockNumber        int64          `db:"to_block_number"`
+	ChunkSize            int64          `db:"chunk_size"`
+	Concurrency          int            `db:"concurrency"`
+	Status               BackfillStatus `db:"status"`
+	Error                string         `db:"error"`
+	CreatedAt            time.Time      `db:"created_at"`
+	UpdatedAt            *time.Time     `db:"updated_at"`
+}
+
+// BackfillChunkRecord is a block range of a backfill job, LatestBlockNumber is the last block
+// fully ingested, so the chunk resumes from the next one.
+type BackfillChunkRecord struct {
+	ID                string         `db:"id"`
+	JobID             string         `db:"job_id"`
+	FromBlockNumber   int64          `db:"from_block_number"`
+	ToBlockNumber     int64          `db:"to_block_number"`
+	LatestBlockNumber int64          `db:"latest_block_number"`
+	Status            BackfillStatus `db:"status"`
+	Attempts          int            `db:"attempts"`
+	Error             string         `db:"error"`
+	CreatedAt         time.Time      `db:"created_at"`
+	UpdatedAt         *time.Time     `db:"updated_at"`
+}
+
+// IsFinished returns true when the chunk doesn't need to be synced anymore.
+func (c *BackfillChunkRecord) IsFinished() bool {
+	return c.Status == BackfillStatusCompleted || c.Status == BackfillStatusFailed
+}
+
+// SyncedBlocks returns the number of blocks of the chunk already ingested.
+func (c *BackfillChunkRecord) SyncedBlocks() int64 {
+	return c.LatestBlockNumber - c.FromBlockNumber + 1
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/coverage"
+)
+
+// CoverageSource is the ingestion that fetched and committed a range of the coverage ledger.
+type CoverageSource string
+
+const (
+	CoverageSourceTick     CoverageSource = "tick"
+	CoverageSourceLive     CoverageSource = "live"
+	CoverageSourceBackfill CoverageSource = "backfill"
+	CoverageSourceRepair   CoverageSource = "repair"
+	// CoverageSourceCompacted are the ranges merged by the verifier
+	CoverageSourceCompacted CoverageSource = "compacted"
+)
+
+// CoverageRangeRecord is a block range whose logs were fetched and committed for the event,
+// it's stored in the same transaction as the logs of the range.
+type CoverageRangeRecord struct {
+	ID              string         `db:"id"`
+	EventID         string         `db:"event_id"`
+	FromBlockNumber int64          `db:"from_block_number"`
+	ToBlockNumber   int64          `db:"to_block_number"`
+	Source          CoverageSource `db:"source"`
+	CreatedAt       time.Time      `db:"created_at"`
+}
+
+func (r *CoverageRangeRecord) Range() coverage.Range {
+	return coverage.Range{From: r.FromBlockNumber, To: r.ToBlockNumber}
+}
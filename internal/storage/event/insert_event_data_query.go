@@ -24,8 +24,8 @@ func (s *Storage) InsertEventData(e *event.Event, data []*event.EventData) error
 
 	// insert event data in db
 	batch, err := tx.Preparex(`
-		INSERT INTO event_data (id, event_id, tx, block_number, data, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6);`)
+		INSERT INTO event_data (id, event_id, tx, block_number, block_hash, block_timestamp, log_index, tx_index, data, topics, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);`)
 	if err != nil {
 		return errors.Wrap(err, "eventstorage: Storage.InsertEventData batch.Exec error")
 	}
@@ -34,7 +34,7 @@ func (s *Storage) InsertEventData(e *event.Event, data []*event.EventData) error
 	// iterate over logsData array for inserting on db
 	for _, ed := range data {
 		// execute que batch into the db
-		_, err = batch.Exec(ed.ID, e.ID, ed.Tx, ed.BlockNumber, ed.Data, ed.CreatedAt)
+		_, err = batch.Exec(ed.ID, e.ID, ed.Tx, ed.BlockNumber, ed.BlockHash, ed.BlockTimestamp, ed.LogIndex, ed.TxIndex, ed.Data, ed.Topics, ed.CreatedAt)
 		if err != nil {
 			return errors.Wrap(err, "eventstorage: Storage.InsertEventData batch.Exec error")
 		}
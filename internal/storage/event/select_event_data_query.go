@@ -22,10 +22,10 @@ func (s *Storage) ListEventData(address string, eventName string, sort string, l
 			JOIN abi ON event.abi_id = abi.id
 			WHERE event.address = $1
 			AND abi.name = $2
-			ORDER BY event_data.created_at %s
+			ORDER BY event_data.block_number %s, event_data.log_index %s
 			LIMIT $3
 			OFFSET $4;`,
-			sort),
+			sort, sort),
 		address,
 		eventName,
 		limit,
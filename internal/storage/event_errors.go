@@ -0,0 +1,13 @@
+package storage
+
+import "time"
+
+// EventErrorRecord is an error found syncing an event, Attempt is the number of consecutive
+// failed runs of the event when it happened.
+type EventErrorRecord struct {
+	ID        string    `db:"id"`
+	EventID   string    `db:"event_id"`
+	Error     string    `db:"error"`
+	Attempt   int       `db:"attempt"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FactoryTemplateRecord makes a factory of the contract of the event. Each log of the event
+// creates a child contract, its address is the ChildAddressArg argument of the log and it's
+// registered with the ChildABI for the users of the factory from the creation block.
+type FactoryTemplateRecord struct {
+	ID                   string          `db:"id"`
+	EventID              string          `db:"event_id"`
+	SmartContractAddress string          `db:"sc_address"`
+	UserID               string          `db:"user_id"`
+	ChildAddressArg      string          `db:"child_address_arg"`
+	ChildName            string          `db:"child_name"`
+	ChildABI             json.RawMessage `db:"child_abi"`
+	CreatedAt            time.Time       `db:"created_at"`
+	UpdatedAt            *time.Time      `db:"updated_at"`
+}
+
+// ChildABIRecords returns the abi records of the child contract deployed at the given address.
+func (ft *FactoryTemplateRecord) ChildABIRecords(address string) ([]*ABIRecord, error) {
+	abis := make([]*ABIRecord, 0)
+	err := json.Unmarshal(ft.ChildABI, &abis)
+	if err != nil {
+		return nil, errors.Wrap(err, "storage: FactoryTemplateRecord.ChildABIRecords json.Unmarshal error")
+	}
+
+	for _, a := range abis {
+		a.SmartContractAddress = address
+	}
+
+	return abis, nil
+}
@@ -0,0 +1,15 @@
+package storage
+
+import "time"
+
+// LeaseRecord is a named lease held by a replica of the synchronizer until it expires. The
+// holder renews it before expiring, otherwise other replica can take it.
+type LeaseRecord struct {
+	Name       string    `db:"name"`
+	Holder     string    `db:"holder"`
+	ExpiresAt  time.Time `db:"expires_at"`
+	AcquiredAt time.Time `db:"acquired_at"`
+	RenewedAt  time.Time `db:"renewed_at"`
+	// Expired is computed with the database clock when the leases are listed
+	Expired bool `db:"expired"`
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// RawLogRecord is a log emitted by a contract in raw logs mode, stored whether or not it
+// belongs to an event of the contract abi so it can be decoded later.
+type RawLogRecord struct {
+	ID                   string         `db:"id"`
+	SmartContractAddress string         `db:"sc_address"`
+	Tx                   string         `db:"tx"`
+	TxIndex              int64          `db:"tx_index"`
+	LogIndex             int64          `db:"log_index"`
+	BlockNumber          int64          `db:"block_number"`
+	BlockHash            string         `db:"block_hash"`
+	BlockTimestamp       *time.Time     `db:"block_timestamp"`
+	Topics               pq.StringArray `db:"topics"`
+	Data                 string         `db:"data"`
+	CreatedAt            time.Time      `db:"created_at"`
+}
+
+// FromLogData fills the raw log with the topics and data of a log of the contract.
+func (rl *RawLogRecord) FromLogData(logData *blockchain.LogData, id string, address string, createdAt time.Time) {
+	rl.ID = id
+	rl.SmartContractAddress = address
+	rl.Tx = logData.Tx.Hex()
+	rl.TxIndex = int64(logData.TxIndex)
+	rl.LogIndex = int64(logData.LogIndex)
+	rl.BlockNumber = int64(logData.BlockNumber)
+	rl.BlockHash = logData.BlockHash.Hex()
+	rl.Topics = hexTopics(logData.Topics)
+	rl.Data = hexutil.Encode(logData.RawData)
+	rl.CreatedAt = createdAt
+
+	if logData.BlockTimestamp > 0 {
+		blockTimestamp := time.Unix(int64(logData.BlockTimestamp), 0).UTC()
+		rl.BlockTimestamp = &blockTimestamp
+	}
+}
+
+// RawLog returns the topics and data of the raw log.
+func (rl *RawLogRecord) RawLog() ([]common.Hash, []byte, error) {
+	topics := make([]common.Hash, 0)
+	for _, topic := range rl.Topics {
+		topics = append(topics, common.HexToHash(topic))
+	}
+
+	data, err := hexutil.Decode(rl.Data)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "storage: RawLogRecord.RawLog hexutil.Decode error")
+	}
+
+	return topics, data, nil
+}
+
+// ToEventData builds the event data of the raw log decoded with the event.
+func (rl *RawLogRecord) ToEventData(id string, eventID string, data map[string]interface{}, createdAt time.Time) (*EventDataRecord, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "storage: RawLogRecord.ToEventData json.Marshal error")
+	}
+
+	return &EventDataRecord{
+		ID:             id,
+		EventID:        eventID,
+		Tx:             rl.Tx,
+		Data:           b,
+		BlockNumber:    rl.BlockNumber,
+		BlockHash:      rl.BlockHash,
+		BlockTimestamp: rl.BlockTimestamp,
+		LogIndex:       rl.LogIndex,
+		TxIndex:        rl.TxIndex,
+		Topics:         rl.Topics,
+		CreatedAt:      createdAt,
+	}, nil
+}
+
+// ToQuarantinedLog builds the quarantined log of the raw log that couldn't be decoded with
+// the event.
+func (rl *RawLogRecord) ToQuarantinedLog(id string, eventID string, decodeError string, createdAt time.Time) (*QuarantinedLogRecord, error) {
+	topics := make([]common.Hash, 0)
+	for _, topic := range rl.Topics {
+		topics = append(topics, common.HexToHash(topic))
+	}
+	b, err := json.Marshal(topics)
+	if err != nil {
+		return nil, errors.Wrap(err, "storage: RawLogRecord.ToQuarantinedLog json.Marshal error")
+	}
+
+	return &QuarantinedLogRecord{
+		ID:             id,
+		EventID:        eventID,
+		Tx:             rl.Tx,
+		TxIndex:        rl.TxIndex,
+		LogIndex:       rl.LogIndex,
+		BlockNumber:    rl.BlockNumber,
+		BlockHash:      rl.BlockHash,
+		BlockTimestamp: rl.BlockTimestamp,
+		Topics:         b,
+		Data:           rl.Data,
+		Error:          decodeError,
+		CreatedAt:      createdAt,
+	}, nil
+}
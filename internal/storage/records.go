@@ -4,10 +4,17 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
 	"github.com/darchlabs/synchronizer-v2/pkg/webhook"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
@@ -55,6 +62,10 @@ type SmartContractRecord struct {
 	Address            string  `db:"address"`
 	LastTxBlockSynced  int64   `db:"last_tx_block_synced"`
 	InitialBlockNumber int64   `db:"initial_block_number"`
+	// Template is the standard interface of the contracts registered with a built-in abi
+	Template string `db:"template"`
+	// RawLogs stores every log of the contract, the logs are decoded lazily with the abi events
+	RawLogs bool `db:"raw_logs"`
 
 	CreatedAt time.Time  `db:"created_at"`
 	UpdatedAt *time.Time `db:"updated_at"`
@@ -73,6 +84,7 @@ type SmartContractUserRecord struct {
 	WebhookURL           string                  `db:"webhook"`
 	NodeURL              string                  `db:"node_url"`
 	Status               SmartContractUserStatus `db:"status"`
+	TopicFilters         TopicFilters            `db:"topic_filters"`
 	CreatedAt            time.Time               `db:"created_at"`
 	DeletedAt            *time.Time              `db:"deleted_at"`
 	UpdatedAt            *time.Time              `db:"updated_at"`
@@ -130,6 +142,67 @@ func (r *ABIRecord) MarshalJson() ([]byte, error) {
 	})
 }
 
+// EventSignature returns the canonical signature of the event, like Transfer(address,address,uint256),
+// and the event id (topic0), the keccak256 hash of the signature.
+func (r *ABIRecord) EventSignature() (string, common.Hash, error) {
+	inputs := r.Inputs
+	if len(inputs) == 0 && len(r.InputsJSON) > 0 {
+		err := json.Unmarshal([]byte(r.InputsJSON), &inputs)
+		if err != nil {
+			return "", common.Hash{}, errors.Wrap(err, "cannot unmarshal InputsJSON")
+		}
+	}
+
+	types := make([]string, 0)
+	for _, input := range inputs {
+		types = append(types, canonicalType(input.Type))
+	}
+
+	signature := fmt.Sprintf("%s(%s)", r.Name, strings.Join(types, ","))
+	return signature, crypto.Keccak256Hash([]byte(signature)), nil
+}
+
+// Event returns the definition of the event of the abi record.
+func (r *ABIRecord) Event() (abi.Event, error) {
+	inputs := r.Inputs
+	if len(inputs) == 0 && len(r.InputsJSON) > 0 {
+		err := json.Unmarshal([]byte(r.InputsJSON), &inputs)
+		if err != nil {
+			return abi.Event{}, errors.Wrap(err, "cannot unmarshal InputsJSON")
+		}
+	}
+
+	arguments := make(abi.Arguments, 0)
+	for _, input := range inputs {
+		t, err := abi.NewType(input.Type, input.InternalType, nil)
+		if err != nil {
+			return abi.Event{}, errors.Wrapf(err, "invalid type of input=%s", input.Name)
+		}
+		arguments = append(arguments, abi.Argument{Name: input.Name, Type: t, Indexed: input.Indexed})
+	}
+
+	return abi.NewEvent(r.Name, r.Name, r.Anonymous, arguments), nil
+}
+
+// canonicalType expands the type aliases, like uint to uint256, as they are in the canonical signature.
+func canonicalType(t string) string {
+	base, suffix := t, ""
+	if i := strings.Index(t, "["); i >= 0 {
+		base, suffix = t[:i], t[i:]
+	}
+
+	switch base {
+	case "uint":
+		base = "uint256"
+	case "int":
+		base = "int256"
+	case "byte":
+		base = "bytes1"
+	}
+
+	return base + suffix
+}
+
 // TODO(mt): get rid of this record struct
 type InputRecord struct {
 	ID                   string `db:"id"`
@@ -145,19 +218,32 @@ type EventRecord struct {
 	AbiID                string       `db:"abi_id"`
 	Network              EventNetwork `db:"network"`
 	Name                 string       `db:"name"`
+	Signature            string       `db:"signature"`
+	Topic0               string       `db:"topic0"`
 	NodeURL              string       `db:"node_url"`
 	Address              string       `db:"address"`
 	LatestBlockNumber    int64        `db:"latest_block_number"`
+	ObservedBlockNumber  int64        `db:"observed_block_number"`
+	FinalizedBlockNumber int64        `db:"finalized_block_number"`
 	SmartContractAddress string       `db:"sc_address"`
 	Status               EventStatus  `db:"status"`
 	Error                string       `db:"error"`
 	CreatedAt            time.Time    `db:"created_at"`
 	UpdatedAt            *time.Time   `db:"updated_at"`
+	// Failures is the number of consecutive failed runs, the next run is delayed with an
+	// exponential backoff until NextRunAt
+	Failures  int        `db:"failures"`
+	NextRunAt *time.Time `db:"next_run_at"`
+	// CheckpointBlockHash is the hash of the block the event was synced up to, it detects
+	// the reorganizations of blocks without logs of the event
+	CheckpointBlockNumber int64  `db:"checkpoint_block_number"`
+	CheckpointBlockHash   string `db:"checkpoint_block_hash"`
 
 	// Agregation data only
 	ABI                *ABIRecord                 `db:"-"`
 	SmartContract      *SmartContractRecord       `db:"-"`
 	SmartContractUsers []*SmartContractUserRecord `db:"-"`
+	FactoryTemplate    *FactoryTemplateRecord     `db:"-"`
 }
 
 type WebhookRecord struct {
@@ -178,22 +264,92 @@ type WebhookRecord struct {
 }
 
 type EventDataRecord struct {
-	ID          string          `db:"id"`
-	EventID     string          `db:"event_id"`
-	Tx          string          `db:"tx"`
-	Data        json.RawMessage `db:"data"`
-	BlockNumber int64           `db:"block_number"`
-	CreatedAt   time.Time       `db:"created_at"`
+	ID             string          `db:"id"`
+	EventID        string          `db:"event_id"`
+	Tx             string          `db:"tx"`
+	Data           json.RawMessage `db:"data"`
+	BlockNumber    int64           `db:"block_number"`
+	BlockHash      string          `db:"block_hash"`
+	BlockTimestamp *time.Time      `db:"block_timestamp"`
+	LogIndex       int64           `db:"log_index"`
+	TxIndex        int64           `db:"tx_index"`
+	Topics         pq.StringArray  `db:"topics"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+type QuarantinedLogRecord struct {
+	ID             string          `db:"id"`
+	EventID        string          `db:"event_id"`
+	Tx             string          `db:"tx"`
+	TxIndex        int64           `db:"tx_index"`
+	LogIndex       int64           `db:"log_index"`
+	BlockNumber    int64           `db:"block_number"`
+	BlockHash      string          `db:"block_hash"`
+	BlockTimestamp *time.Time      `db:"block_timestamp"`
+	Topics         json.RawMessage `db:"topics"`
+	Data           string          `db:"data"`
+	Error          string          `db:"error"`
+	Attempts       int64           `db:"attempts"`
+	CreatedAt      time.Time       `db:"created_at"`
+	UpdatedAt      *time.Time      `db:"updated_at"`
+}
+
+type BlockRangeWindowRecord struct {
+	NodeURL string       `db:"node_url"`
+	Network EventNetwork `db:"network"`
+	Size    int64        `db:"size"`
+	// MaxSize is the max number of blocks told by the provider, it's zero until the provider
+	// refuses a range with its limit
+	MaxSize   int64      `db:"max_size"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+}
+
+type BlockRecord struct {
+	Network    EventNetwork `db:"network"`
+	Number     int64        `db:"number"`
+	Hash       string       `db:"hash"`
+	ParentHash string       `db:"parent_hash"`
+	Timestamp  time.Time    `db:"timestamp"`
+	BaseFee    *string      `db:"base_fee"`
+	GasUsed    int64        `db:"gas_used"`
+	CreatedAt  time.Time    `db:"created_at"`
+}
+
+// EventDataBucketRecord is the number of event data of the blocks mined in a time bucket.
+type EventDataBucketRecord struct {
+	Bucket time.Time `db:"bucket"`
+	Count  int64     `db:"count"`
+}
+
+type EventDataBlockRecord struct {
+	BlockNumber int64  `db:"block_number"`
+	BlockHash   string `db:"block_hash"`
 }
 
 func (ed *EventDataRecord) ToWebhookEvent(ID string, ev *EventRecord, endpoint string, date time.Time) (*WebhookRecord, error) {
+	return ed.toWebhookEvent(ID, ev, endpoint, date, false)
+}
+
+// ToRemovedWebhookEvent prepares the webhook notifying that the log was removed from
+// the canonical chain by a reorg, following go-ethereum types.Log.Removed semantics.
+func (ed *EventDataRecord) ToRemovedWebhookEvent(ID string, ev *EventRecord, endpoint string, date time.Time) (*WebhookRecord, error) {
+	return ed.toWebhookEvent(ID, ev, endpoint, date, true)
+}
+
+func (ed *EventDataRecord) toWebhookEvent(ID string, ev *EventRecord, endpoint string, date time.Time, removed bool) (*WebhookRecord, error) {
 	// prepare event payload
 	payload := &webhook.WebhookEventPayload{
-		Id:          ev.ID,
-		Name:        ev.Name,
-		BlockNumber: ed.BlockNumber,
-		Tx:          ed.Tx,
-		Data:        ed.Data,
+		Id:             ev.ID,
+		Name:           ev.Name,
+		BlockNumber:    ed.BlockNumber,
+		BlockHash:      ed.BlockHash,
+		BlockTimestamp: ed.BlockTimestamp,
+		Tx:             ed.Tx,
+		TxIndex:        ed.TxIndex,
+		LogIndex:       ed.LogIndex,
+		Data:           ed.Data,
+		Removed:        removed,
 	}
 
 	// parse payload to raw message
@@ -204,7 +360,7 @@ func (ed *EventDataRecord) ToWebhookEvent(ID string, ev *EventRecord, endpoint s
 
 	return &WebhookRecord{
 		ID:         ID,
-		Tx:         ed.Tx,
+		Tx:         ed.webhookTx(removed),
 		EntityType: WebhookEntityTypeEvent,
 		EntityID:   ev.ID,
 		Endpoint:   endpoint,
@@ -228,8 +384,130 @@ func (ed *EventDataRecord) FromLogData(logData *blockchain.LogData, id string, e
 	ed.EventID = eventID
 	ed.Tx = tx
 	ed.BlockNumber = int64(logData.BlockNumber)
+	ed.BlockHash = logData.BlockHash.Hex()
+	ed.LogIndex = int64(logData.LogIndex)
+	ed.TxIndex = int64(logData.TxIndex)
 	ed.Data = data
+	ed.Topics = hexTopics(logData.Topics)
 	ed.CreatedAt = createdAt
 
+	if logData.BlockTimestamp > 0 {
+		blockTimestamp := time.Unix(int64(logData.BlockTimestamp), 0).UTC()
+		ed.BlockTimestamp = &blockTimestamp
+	}
+
 	return nil
 }
+
+// FromBlock fills the block record with the header of a block of the network.
+func (br *BlockRecord) FromBlock(block *blockchain.Block, network EventNetwork, createdAt time.Time) {
+	br.Network = network
+	br.Number = int64(block.Number)
+	br.Hash = block.Hash.Hex()
+	br.ParentHash = block.ParentHash.Hex()
+	br.Timestamp = time.Unix(int64(block.Timestamp), 0).UTC()
+	br.GasUsed = int64(block.GasUsed)
+	br.CreatedAt = createdAt
+
+	if block.BaseFee != nil {
+		baseFee := block.BaseFee.String()
+		br.BaseFee = &baseFee
+	}
+}
+
+// FromLogData fills the quarantined log with the raw topics and data of a log that couldn't
+// be decoded.
+func (ql *QuarantinedLogRecord) FromLogData(logData *blockchain.LogData, id string, eventID string, createdAt time.Time) error {
+	topics, err := json.Marshal(logData.Topics)
+	if err != nil {
+		return err
+	}
+
+	ql.ID = id
+	ql.EventID = eventID
+	ql.Tx = logData.Tx.Hex()
+	ql.TxIndex = int64(logData.TxIndex)
+	ql.LogIndex = int64(logData.LogIndex)
+	ql.BlockNumber = int64(logData.BlockNumber)
+	ql.BlockHash = logData.BlockHash.Hex()
+	ql.Topics = topics
+	ql.Data = hexutil.Encode(logData.RawData)
+	ql.Error = logData.DecodeError
+	ql.CreatedAt = createdAt
+
+	if logData.BlockTimestamp > 0 {
+		blockTimestamp := time.Unix(int64(logData.BlockTimestamp), 0).UTC()
+		ql.BlockTimestamp = &blockTimestamp
+	}
+
+	return nil
+}
+
+// RawLog returns the topics and data of the quarantined log.
+func (ql *QuarantinedLogRecord) RawLog() ([]common.Hash, []byte, error) {
+	topics := make([]common.Hash, 0)
+	err := json.Unmarshal(ql.Topics, &topics)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "storage: QuarantinedLogRecord.RawLog json.Unmarshal error")
+	}
+
+	data, err := hexutil.Decode(ql.Data)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "storage: QuarantinedLogRecord.RawLog hexutil.Decode error")
+	}
+
+	return topics, data, nil
+}
+
+// ToEventData builds the event data of the quarantined log once it has been decoded.
+func (ql *QuarantinedLogRecord) ToEventData(id string, data map[string]interface{}, createdAt time.Time) (*EventDataRecord, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "storage: QuarantinedLogRecord.ToEventData json.Marshal error")
+	}
+
+	topics, _, err := ql.RawLog()
+	if err != nil {
+		return nil, errors.Wrap(err, "storage: QuarantinedLogRecord.ToEventData ql.RawLog error")
+	}
+
+	return &EventDataRecord{
+		ID:             id,
+		EventID:        ql.EventID,
+		Tx:             ql.Tx,
+		Data:           b,
+		BlockNumber:    ql.BlockNumber,
+		BlockHash:      ql.BlockHash,
+		BlockTimestamp: ql.BlockTimestamp,
+		LogIndex:       ql.LogIndex,
+		TxIndex:        ql.TxIndex,
+		Topics:         hexTopics(topics),
+		CreatedAt:      createdAt,
+	}, nil
+}
+
+// hexTopics returns the topics of a log as hex strings.
+func hexTopics(topics []common.Hash) pq.StringArray {
+	hexes := make(pq.StringArray, 0)
+	for _, topic := range topics {
+		hexes = append(hexes, topic.Hex())
+	}
+
+	return hexes
+}
+
+// webhookTx returns the value used to deduplicate the webhooks of the event data. The
+// block hash and log index are part of it so every log of a transaction is delivered, a
+// log re-included in another block after a reorg is delivered again, and removals never
+// collide with the original delivery.
+func (ed *EventDataRecord) webhookTx(removed bool) string {
+	if ed.BlockHash == "" {
+		return ed.Tx
+	}
+
+	if removed {
+		return fmt.Sprintf("%s:%s:%d:removed", ed.Tx, ed.BlockHash, ed.LogIndex)
+	}
+
+	return fmt.Sprintf("%s:%s:%d", ed.Tx, ed.BlockHash, ed.LogIndex)
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/lib/pq"
+)
+
+// SignatureSubscriptionRecord ingests the logs of an event signature emitted by a set of
+// addresses, or by every address of the network when AllAddresses is true. The logs of all
+// the addresses are requested together and each one is stored with its emitter.
+type SignatureSubscriptionRecord struct {
+	ID        string       `db:"id"`
+	UserID    string       `db:"user_id"`
+	Name      string       `db:"name"`
+	Network   EventNetwork `db:"network"`
+	NodeURL   string       `db:"node_url"`
+	Signature string       `db:"signature"`
+	Topic0    string       `db:"topic0"`
+	// ABI is the abi with the definition of the event
+	ABI          json.RawMessage `db:"abi"`
+	Addresses    pq.StringArray  `db:"addresses"`
+	AllAddresses bool            `db:"all_addresses"`
+	// TopicFilters has the filter on the indexed arguments of the event signature
+	TopicFilters      TopicFilters `db:"topic_filters"`
+	FromBlockNumber   int64        `db:"from_block_number"`
+	LatestBlockNumber int64        `db:"latest_block_number"`
+	Status            EventStatus  `db:"status"`
+	Error             string       `db:"error"`
+	CreatedAt         time.Time    `db:"created_at"`
+	UpdatedAt         *time.Time   `db:"updated_at"`
+	// BackfillAddresses were added to the set after the subscription started, their logs are
+	// ingested from FromBlockNumber up to BackfillToBlockNumber apart from the other addresses.
+	// BackfillLatestBlockNumber is the checkpoint of the backfill
+	BackfillAddresses         pq.StringArray `db:"backfill_addresses"`
+	BackfillToBlockNumber     int64          `db:"backfill_to_block_number"`
+	BackfillLatestBlockNumber int64          `db:"backfill_latest_block_number"`
+}
+
+// HasAddress returns true when the subscription ingests the logs emitted by the address.
+func (s *SignatureSubscriptionRecord) HasAddress(address string) bool {
+	if s.AllAddresses {
+		return true
+	}
+
+	for _, a := range s.Addresses {
+		if strings.EqualFold(a, address) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsBackfilling returns true when the logs of the address before the checkpoint of the
+// subscription are still being ingested.
+func (s *SignatureSubscriptionRecord) IsBackfilling(address string) bool {
+	for _, a := range s.BackfillAddresses {
+		if strings.EqualFold(a, address) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SignatureEventDataRecord is a log of the event of a signature subscription, Address is the
+// contract that emitted it.
+type SignatureEventDataRecord struct {
+	ID             string          `db:"id"`
+	SubscriptionID string          `db:"subscription_id"`
+	Address        string          `db:"address"`
+	Tx             string          `db:"tx"`
+	TxIndex        int64           `db:"tx_index"`
+	LogIndex       int64           `db:"log_index"`
+	BlockNumber    int64           `db:"block_number"`
+	BlockHash      string          `db:"block_hash"`
+	BlockTimestamp *time.Time      `db:"block_timestamp"`
+	Topics         pq.StringArray  `db:"topics"`
+	Data           json.RawMessage `db:"data"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+// FromLogData fills the event data with the decoded log of the subscription.
+func (sd *SignatureEventDataRecord) FromLogData(logData *blockchain.LogData, id string, subscriptionID string, createdAt time.Time) error {
+	data, err := json.Marshal(logData.Data)
+	if err != nil {
+		return err
+	}
+
+	sd.ID = id
+	sd.SubscriptionID = subscriptionID
+	sd.Address = strings.ToLower(logData.Address.Hex())
+	sd.Tx = logData.Tx.Hex()
+	sd.TxIndex = int64(logData.TxIndex)
+	sd.LogIndex = int64(logData.LogIndex)
+	sd.BlockNumber = int64(logData.BlockNumber)
+	sd.BlockHash = logData.BlockHash.Hex()
+	sd.Topics = hexTopics(logData.Topics)
+	sd.Data = data
+	sd.CreatedAt = createdAt
+
+	if logData.BlockTimestamp > 0 {
+		blockTimestamp := time.Unix(int64(logData.BlockTimestamp), 0).UTC()
+		sd.BlockTimestamp = &blockTimestamp
+	}
+
+	return nil
+}
+
+// SignatureQuarantinedLogRecord is a log of a signature subscription that couldn't be decoded
+// with the abi of the event, it's stored raw with the decode error.
+type SignatureQuarantinedLogRecord struct {
+	ID             string          `db:"id"`
+	SubscriptionID string          `db:"subscription_id"`
+	Address        string          `db:"address"`
+	Tx             string          `db:"tx"`
+	TxIndex        int64           `db:"tx_index"`
+	LogIndex       int64           `db:"log_index"`
+	BlockNumber    int64           `db:"block_number"`
+	BlockHash      string          `db:"block_hash"`
+	BlockTimestamp *time.Time      `db:"block_timestamp"`
+	Topics         json.RawMessage `db:"topics"`
+	Data           string          `db:"data"`
+	Error          string          `db:"error"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+// FromLogData fills the quarantined log with the raw log of the subscription.
+func (sq *SignatureQuarantinedLogRecord) FromLogData(logData *blockchain.LogData, id string, subscriptionID string, createdAt time.Time) error {
+	topics, err := json.Marshal(logData.Topics)
+	if err != nil {
+		return err
+	}
+
+	sq.ID = id
+	sq.SubscriptionID = subscriptionID
+	sq.Address = strings.ToLower(logData.Address.Hex())
+	sq.Tx = logData.Tx.Hex()
+	sq.TxIndex = int64(logData.TxIndex)
+	sq.LogIndex = int64(logData.LogIndex)
+	sq.BlockNumber = int64(logData.BlockNumber)
+	sq.BlockHash = logData.BlockHash.Hex()
+	sq.Topics = topics
+	sq.Data = hexutil.Encode(logData.RawData)
+	sq.Error = logData.DecodeError
+	sq.CreatedAt = createdAt
+
+	if logData.BlockTimestamp > 0 {
+		blockTimestamp := time.Unix(int64(logData.BlockTimestamp), 0).UTC()
+		sq.BlockTimestamp = &blockTimestamp
+	}
+
+	return nil
+}
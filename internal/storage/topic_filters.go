@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// TopicFilters are the filters on the indexed arguments of the events of a smart contract user
+// by event signature. Events without filter keep every log.
+type TopicFilters map[string]*EventTopicFilter
+
+// EventTopicFilter is the filter on the indexed arguments of an event. Args are the allowed
+// values by argument name as they were requested, and Topics the allowed topics by indexed
+// argument position.
+type EventTopicFilter struct {
+	Args      map[string][]string `json:"args"`
+	Topics    [][]string          `json:"topics"`
+	Anonymous bool                `json:"anonymous"`
+}
+
+// NewEventTopicFilter stores the topic filter built for the allowed values of the event arguments.
+func NewEventTopicFilter(args map[string][]string, filter blockchain.TopicFilter, anonymous bool) *EventTopicFilter {
+	topics := make([][]string, len(filter))
+	for i, values := range filter {
+		topics[i] = make([]string, 0)
+		for _, value := range values {
+			topics[i] = append(topics[i], value.Hex())
+		}
+	}
+
+	return &EventTopicFilter{
+		Args:      args,
+		Topics:    topics,
+		Anonymous: anonymous,
+	}
+}
+
+// TopicFilter returns the filter used to request the logs of the event.
+func (f *EventTopicFilter) TopicFilter() blockchain.TopicFilter {
+	filter := make(blockchain.TopicFilter, len(f.Topics))
+	for i, values := range f.Topics {
+		for _, value := range values {
+			filter[i] = append(filter[i], common.HexToHash(value))
+		}
+	}
+
+	return filter
+}
+
+// TopicPosition returns the index of the log topic filtered by the indexed argument position,
+// the topics of non anonymous events start with the event id.
+func (f *EventTopicFilter) TopicPosition(position int) int {
+	if f.Anonymous {
+		return position
+	}
+
+	return position + 1
+}
+
+// Match returns true when the event of the signature doesn't have filter or the topics of the
+// log match it.
+func (tf TopicFilters) Match(signature string, topics []string) bool {
+	f, ok := tf[signature]
+	if !ok || f == nil {
+		return true
+	}
+
+	hashes := make([]common.Hash, 0)
+	for _, topic := range topics {
+		hashes = append(hashes, common.HexToHash(topic))
+	}
+
+	return f.TopicFilter().Match(hashes, f.Anonymous)
+}
+
+func (tf *TopicFilters) Scan(value interface{}) error {
+	if value == nil {
+		*tf = make(TopicFilters)
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	filters := make(TopicFilters)
+	err := json.Unmarshal(b, &filters)
+	if err != nil {
+		return err
+	}
+	*tf = filters
+
+	return nil
+}
+
+func (tf TopicFilters) Value() (driver.Value, error) {
+	if tf == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(tf)
+}
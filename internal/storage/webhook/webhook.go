@@ -121,43 +121,43 @@ func (s *Storage) ListWebhooks(smartcontractID string) ([]*webhook.Webhook, erro
 	return webhooks, nil
 }
 
-func (s *Storage) GetWebhooksForRetry(inQueue map[string]struct{}) ([]*webhook.Webhook, error) {
-	webhooks := []*webhook.Webhook{}
-	rows, err := s.storage.DB.Query(
-		"SELECT * FROM webhooks WHERE (status = $1 OR status = $2) AND next_retry_at <= $3 AND attempts < max_attempts;",
-		webhook.StatusFailed, webhook.StatusPending, time.Now())
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return []*webhook.Webhook{}, nil
-		}
+// ClaimWebhook marks the pending webhook as being sent, it returns false when other sender
+// already claimed it.
+func (s *Storage) ClaimWebhook(id string, now time.Time) (bool, error) {
+	query := "UPDATE webhooks SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4"
 
-		return nil, err
+	res, err := s.storage.DB.Exec(query, webhook.StatusSending, now, id, webhook.StatusPending)
+	if err != nil {
+		return false, errors.Wrap(err, "webhookstorage: error claiming webhook")
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "webhookstorage: error claiming webhook")
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		wh := &webhook.Webhook{}
-		if err := rows.Scan(
-			&wh.ID,
-			&wh.EntityType,
-			&wh.EntityID,
-			&wh.Endpoint,
-			&wh.Payload,
-			&wh.MaxAttempts,
-			&wh.CreatedAt,
-			&wh.UpdatedAt,
-			&wh.SentAt,
-			&wh.Attempts,
-			&wh.Status,
-			&wh.NextRetryAt,
-		); err != nil {
-			return nil, err
-		}
+	return rows > 0, nil
+}
 
-		// check if webhook are in queue
-		if _, ok := inQueue[wh.ID]; !ok {
-			webhooks = append(webhooks, wh)
-		}
+// ClaimWebhooksForRetry marks as being sent the failed webhooks due for a retry, and the ones
+// that weren't sent or updated since staleBefore because their sender stopped. The rows claimed
+// by other sender meanwhile are skipped, so each webhook is returned to a single sender.
+func (s *Storage) ClaimWebhooksForRetry(now time.Time, staleBefore time.Time) ([]*webhook.Webhook, error) {
+	webhooks := []*webhook.Webhook{}
+	query := `
+		UPDATE webhooks
+		SET status = $1, updated_at = $2
+		WHERE id IN (
+			SELECT id FROM webhooks
+			WHERE attempts < max_attempts
+			AND ((status = $3 AND next_retry_at <= $2) OR (status IN ($1, $4) AND updated_at < $5))
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+
+	err := s.storage.DB.Select(&webhooks, query, webhook.StatusSending, now, webhook.StatusFailed, webhook.StatusPending, staleBefore)
+	if err != nil {
+		return nil, errors.Wrap(err, "webhookstorage: error claiming webhooks for retry")
 	}
 
 	return webhooks, nil
@@ -0,0 +1,320 @@
+package supervisor
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/wrapper"
+	"github.com/pkg/errors"
+)
+
+// DefaultDrainTimeout is the time a stopping worker has to finish its in-flight work when no
+// timeout is configured
+const DefaultDrainTimeout = 30 * time.Second
+
+var (
+	ErrWorkerNotFound      = errors.New("supervisor: worker not found")
+	ErrWorkerAlreadyExists = errors.New("supervisor: worker already registered")
+	ErrWorkerRunning       = errors.New("supervisor: worker is already running")
+	ErrWorkerNotRunning    = errors.New("supervisor: worker isn't running")
+	ErrWorkerStopping      = errors.New("supervisor: worker is stopping")
+	ErrDrainTimeout        = errors.New("supervisor: worker didn't finish before the drain timeout")
+	ErrSupervisorShutdown  = errors.New("supervisor: supervisor is shut down")
+	errWorkerExited        = errors.New("supervisor: worker exited before being stopped")
+)
+
+// Worker is a background process owned by the supervisor. Run blocks until the context is
+// canceled, finishing its in-flight work before returning, or until the worker fails.
+type Worker interface {
+	Run(ctx context.Context) error
+}
+
+// WorkerFunc adapts a function to the Worker interface.
+type WorkerFunc func(ctx context.Context) error
+
+func (f WorkerFunc) Run(ctx context.Context) error {
+	return f(ctx)
+}
+
+type WorkerStatus string
+
+const (
+	StatusIdle     WorkerStatus = "idle"
+	StatusRunning  WorkerStatus = "running"
+	StatusStopping WorkerStatus = "stopping"
+	StatusStopped  WorkerStatus = "stopped"
+	StatusError    WorkerStatus = "error"
+)
+
+// WorkerState is the state of a worker of the supervisor.
+type WorkerState struct {
+	Name      string
+	Status    WorkerStatus
+	Error     string
+	StartedAt *time.Time
+	StoppedAt *time.Time
+}
+
+type worker struct {
+	name   string
+	worker Worker
+
+	status    WorkerStatus
+	err       error
+	startedAt *time.Time
+	stoppedAt *time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Supervisor owns the background workers of the synchronizer. Every worker runs in its own
+// goroutine with a context that is canceled when the worker is stopped, and stopping waits for
+// the worker to drain its in-flight work up to the drain timeout.
+type Supervisor struct {
+	mu       sync.Mutex
+	workers  map[string]*worker
+	order    []string
+	shutdown bool
+
+	drainTimeout time.Duration
+	dateGen      wrapper.DateGenerator
+}
+
+type Config struct {
+	// DrainTimeout is the time a stopping worker has to finish its in-flight work
+	DrainTimeout time.Duration
+	DateGen      wrapper.DateGenerator
+}
+
+func New(conf Config) *Supervisor {
+	s := &Supervisor{
+		workers:      make(map[string]*worker),
+		order:        make([]string, 0),
+		drainTimeout: conf.DrainTimeout,
+		dateGen:      conf.DateGen,
+	}
+
+	if s.drainTimeout <= 0 {
+		s.drainTimeout = DefaultDrainTimeout
+	}
+	if s.dateGen == nil {
+		s.dateGen = time.Now
+	}
+
+	return s
+}
+
+// Register adds a worker to the supervisor, the workers are started in the order they're
+// registered and shut down in the reverse order.
+func (s *Supervisor) Register(name string, w Worker) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.workers[name]; ok {
+		return ErrWorkerAlreadyExists
+	}
+
+	s.workers[name] = &worker{
+		name:   name,
+		worker: w,
+		status: StatusIdle,
+	}
+	s.order = append(s.order, name)
+
+	return nil
+}
+
+// Start runs the worker in background.
+func (s *Supervisor) Start(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shutdown {
+		return ErrSupervisorShutdown
+	}
+
+	w, ok := s.workers[name]
+	if !ok {
+		return ErrWorkerNotFound
+	}
+
+	switch w.status {
+	case StatusRunning:
+		return ErrWorkerRunning
+	case StatusStopping:
+		return ErrWorkerStopping
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	now := s.dateGen()
+	w.status = StatusRunning
+	w.err = nil
+	w.startedAt = &now
+	w.stoppedAt = nil
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go s.run(ctx, w, w.done)
+
+	return nil
+}
+
+// StartAll runs every worker that isn't running yet.
+func (s *Supervisor) StartAll() error {
+	return s.StartWorkers(s.names()...)
+}
+
+// StartWorkers runs the given workers that aren't running yet, in order.
+func (s *Supervisor) StartWorkers(names ...string) error {
+	for _, name := range names {
+		err := s.Start(name)
+		if err != nil && err != ErrWorkerRunning {
+			return errors.Wrapf(err, "supervisor: Supervisor.StartWorkers s.Start error name=%s", name)
+		}
+	}
+
+	return nil
+}
+
+// Stop cancels the context of the worker and waits for it to finish up to the drain timeout.
+// The worker keeps the stopping status when it doesn't finish in time, and can't be started
+// again until its goroutine exits.
+func (s *Supervisor) Stop(name string) error {
+	s.mu.Lock()
+	w, ok := s.workers[name]
+	if !ok {
+		s.mu.Unlock()
+		return ErrWorkerNotFound
+	}
+
+	switch w.status {
+	case StatusRunning:
+		w.status = StatusStopping
+		w.cancel()
+	case StatusStopping:
+	default:
+		s.mu.Unlock()
+		return ErrWorkerNotRunning
+	}
+	done := w.done
+	s.mu.Unlock()
+
+	return s.wait(name, done)
+}
+
+// StopAll stops every running worker in the reverse order they were registered.
+func (s *Supervisor) StopAll() {
+	s.StopWorkers(s.names()...)
+}
+
+// StopWorkers stops the given workers that are running, in the reverse order.
+func (s *Supervisor) StopWorkers(names ...string) {
+	for i := len(names) - 1; i >= 0; i-- {
+		err := s.Stop(names[i])
+		if err != nil && err != ErrWorkerNotRunning {
+			log.Printf("supervisor: Supervisor.StopWorkers worker=%s error: %s \n", names[i], err.Error())
+		}
+	}
+}
+
+// Shutdown stops every worker in the reverse order they were registered, and doesn't allow
+// starting them again.
+func (s *Supervisor) Shutdown() {
+	s.mu.Lock()
+	s.shutdown = true
+	s.mu.Unlock()
+
+	s.StopAll()
+}
+
+// Workers returns the state of the workers in the order they were registered.
+func (s *Supervisor) Workers() []*WorkerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make([]*WorkerState, 0, len(s.order))
+	for _, name := range s.order {
+		states = append(states, s.workers[name].state())
+	}
+
+	return states
+}
+
+// Worker returns the state of the worker.
+func (s *Supervisor) Worker(name string) (*WorkerState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.workers[name]
+	if !ok {
+		return nil, ErrWorkerNotFound
+	}
+
+	return w.state(), nil
+}
+
+// names returns the workers in the order they were registered.
+func (s *Supervisor) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.order...)
+}
+
+func (s *Supervisor) run(ctx context.Context, w *worker, done chan struct{}) {
+	defer close(done)
+
+	err := w.worker.Run(ctx)
+	stopped := ctx.Err() != nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.dateGen()
+	w.stoppedAt = &now
+	w.cancel()
+
+	// a worker that returns without being stopped has failed, even without error
+	if !stopped {
+		if err == nil {
+			err = errWorkerExited
+		}
+		w.status = StatusError
+		w.err = err
+		log.Printf("supervisor: worker=%s failed: %s \n", w.name, err.Error())
+		return
+	}
+
+	w.status = StatusStopped
+	w.err = nil
+}
+
+func (s *Supervisor) wait(name string, done chan struct{}) error {
+	timer := time.NewTimer(s.drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		log.Printf("supervisor: worker=%s didn't finish after %s \n", name, s.drainTimeout)
+		return ErrDrainTimeout
+	}
+}
+
+func (w *worker) state() *WorkerState {
+	state := &WorkerState{
+		Name:      w.name,
+		Status:    w.status,
+		StartedAt: w.startedAt,
+		StoppedAt: w.stoppedAt,
+	}
+	if w.err != nil {
+		state.Error = w.err.Error()
+	}
+
+	return state
+}
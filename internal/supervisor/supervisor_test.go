@@ -0,0 +1,170 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaekwon/testify/require"
+)
+
+// blockingWorker runs until its context is canceled, taking drain to finish after it.
+func blockingWorker(started chan struct{}, drain time.Duration) Worker {
+	return WorkerFunc(func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		time.Sleep(drain)
+		return nil
+	})
+}
+
+func Test_Supervisor_StartStop(t *testing.T) {
+	s := New(Config{DrainTimeout: time.Second})
+
+	started := make(chan struct{}, 1)
+	require.NoError(t, s.Register("worker", blockingWorker(started, 0)))
+	require.Equal(t, ErrWorkerAlreadyExists, s.Register("worker", blockingWorker(started, 0)))
+
+	state, err := s.Worker("worker")
+	require.NoError(t, err)
+	require.Equal(t, StatusIdle, state.Status)
+	require.Equal(t, ErrWorkerNotRunning, s.Stop("worker"))
+
+	require.NoError(t, s.Start("worker"))
+	<-started
+	require.Equal(t, ErrWorkerRunning, s.Start("worker"))
+
+	state, err = s.Worker("worker")
+	require.NoError(t, err)
+	require.Equal(t, StatusRunning, state.Status)
+	require.NotNil(t, state.StartedAt)
+
+	require.NoError(t, s.Stop("worker"))
+	state, err = s.Worker("worker")
+	require.NoError(t, err)
+	require.Equal(t, StatusStopped, state.Status)
+	require.NotNil(t, state.StoppedAt)
+
+	// a stopped worker can run again
+	require.NoError(t, s.Start("worker"))
+	<-started
+	require.NoError(t, s.Stop("worker"))
+
+	_, err = s.Worker("unknown")
+	require.Equal(t, ErrWorkerNotFound, err)
+	require.Equal(t, ErrWorkerNotFound, s.Start("unknown"))
+}
+
+func Test_Supervisor_Failure(t *testing.T) {
+	s := New(Config{})
+
+	done := make(chan struct{})
+	require.NoError(t, s.Register("failing", WorkerFunc(func(ctx context.Context) error {
+		defer close(done)
+		return errors.New("boom")
+	})))
+	require.NoError(t, s.StartAll())
+	<-done
+
+	require.Eventually(t, func() bool {
+		state, err := s.Worker("failing")
+		require.NoError(t, err)
+		return state.Status == StatusError && state.Error == "boom"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_Supervisor_DrainTimeout(t *testing.T) {
+	s := New(Config{DrainTimeout: 50 * time.Millisecond})
+
+	started := make(chan struct{}, 1)
+	require.NoError(t, s.Register("slow", blockingWorker(started, 300*time.Millisecond)))
+	require.NoError(t, s.Start("slow"))
+	<-started
+
+	require.Equal(t, ErrDrainTimeout, s.Stop("slow"))
+	state, err := s.Worker("slow")
+	require.NoError(t, err)
+	require.Equal(t, StatusStopping, state.Status)
+	require.Equal(t, ErrWorkerStopping, s.Start("slow"))
+
+	// the worker is stopped once it drains its work
+	require.Eventually(t, func() bool {
+		state, err := s.Worker("slow")
+		require.NoError(t, err)
+		return state.Status == StatusStopped
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_Supervisor_StopWhileDraining(t *testing.T) {
+	s := New(Config{DrainTimeout: 20 * time.Millisecond})
+
+	// the worker ignores the cancel until it's released
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	exited := make(chan struct{}, 2)
+	require.NoError(t, s.Register("stuck", WorkerFunc(func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		<-release
+		exited <- struct{}{}
+		return nil
+	})))
+	require.NoError(t, s.Start("stuck"))
+	<-started
+
+	// the worker keeps stopping while its goroutine runs, and can't be started again
+	for i := 0; i < 2; i++ {
+		require.Equal(t, ErrDrainTimeout, s.Stop("stuck"))
+		require.Equal(t, ErrWorkerStopping, s.Start("stuck"))
+
+		state, err := s.Worker("stuck")
+		require.NoError(t, err)
+		require.Equal(t, StatusStopping, state.Status)
+		require.Nil(t, state.StoppedAt)
+	}
+
+	// the worker is stopped once its goroutine exits, and then it can be started again
+	close(release)
+	<-exited
+	require.Eventually(t, func() bool {
+		state, err := s.Worker("stuck")
+		require.NoError(t, err)
+		return state.Status == StatusStopped && state.StoppedAt != nil
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, s.Start("stuck"))
+	<-started
+	require.NoError(t, s.Stop("stuck"))
+	<-exited
+}
+
+func Test_Supervisor_Shutdown(t *testing.T) {
+	s := New(Config{DrainTimeout: time.Second})
+
+	stopped := make([]string, 0)
+	stops := make(chan string, 2)
+	for _, name := range []string{"first", "second"} {
+		name := name
+		started := make(chan struct{})
+		require.NoError(t, s.Register(name, WorkerFunc(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			stops <- name
+			return nil
+		})))
+		require.NoError(t, s.Start(name))
+		<-started
+	}
+
+	s.Shutdown()
+	close(stops)
+	for name := range stops {
+		stopped = append(stopped, name)
+	}
+	require.Equal(t, []string{"second", "first"}, stopped)
+	require.Equal(t, ErrSupervisorShutdown, s.Start("first"))
+
+	for _, state := range s.Workers() {
+		require.Equal(t, StatusStopped, state.Status)
+	}
+}
@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidLease = errors.New("sync: invalid lease error")
+
+type AcquireLeaseInput struct {
+	Name   string
+	Holder string
+	// TTL is the time the lease is held without being renewed
+	TTL time.Duration
+}
+
+type AcquireLeaseOutput struct {
+	// Lease is nil when other holder has the lease
+	Lease    *storage.LeaseRecord
+	Acquired bool
+}
+
+// AcquireLease takes or renews the lease for the holder. The lease isn't acquired while other
+// holder has it and it isn't expired.
+func (ng *Engine) AcquireLease(input *AcquireLeaseInput) (*AcquireLeaseOutput, error) {
+	if input.Name == "" || input.Holder == "" || input.TTL <= 0 {
+		return nil, errors.Wrap(ErrInvalidLease, "sync: Engine.AcquireLease invalid input error")
+	}
+
+	lease, err := ng.LeaseQuerier.AcquireLeaseQuery(ng.database, input.Name, input.Holder, input.TTL)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.AcquireLease ng.LeaseQuerier.AcquireLeaseQuery error")
+	}
+
+	return &AcquireLeaseOutput{
+		Lease:    lease,
+		Acquired: lease != nil,
+	}, nil
+}
+
+type ReleaseLeaseInput struct {
+	Name   string
+	Holder string
+}
+
+// ReleaseLease frees the lease when it's held by the holder, so other replica can take it
+// without waiting for it to expire.
+func (ng *Engine) ReleaseLease(input *ReleaseLeaseInput) error {
+	err := ng.LeaseQuerier.ReleaseLeaseQuery(ng.database, input.Name, input.Holder)
+	if err != nil {
+		return errors.Wrap(err, "sync: Engine.ReleaseLease ng.LeaseQuerier.ReleaseLeaseQuery error")
+	}
+
+	return nil
+}
+
+type SelectLeasesInput struct {
+	// Prefix filters the leases by the start of their name
+	Prefix string
+}
+
+type SelectLeasesOutput struct {
+	Leases []*storage.LeaseRecord
+}
+
+func (ng *Engine) SelectLeases(input *SelectLeasesInput) (*SelectLeasesOutput, error) {
+	leases, err := ng.LeaseQuerier.SelectLeasesQuery(ng.database, input.Prefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectLeases ng.LeaseQuerier.SelectLeasesQuery error")
+	}
+
+	return &SelectLeasesOutput{
+		Leases: leases,
+	}, nil
+}
@@ -15,6 +15,27 @@ type SyncEngine interface {
 	SelectEventsAndABI(input *SelectEventsAndABIInput) (*SelectEventsAndABIOutput, error)
 	SelectUserSmartContractsWithEvents(input *SelectUserSmartContractsWithEventsInput) (*SelectUserSmartContractsWithEventsOutput, error)
 	SelectEventData(input *SelectEventDataInput) (*SelectEventDataOutput, error)
+	SelectEventDataMetrics(input *SelectEventDataMetricsInput) (*SelectEventDataMetricsOutput, error)
+	SelectBlockRangeWindows(input *SelectBlockRangeWindowsInput) (*SelectBlockRangeWindowsOutput, error)
+	SelectQuarantinedLogs(input *SelectQuarantinedLogsInput) (*SelectQuarantinedLogsOutput, error)
+	RetryQuarantinedLogs(input *RetryQuarantinedLogsInput) (*RetryQuarantinedLogsOutput, error)
+	InsertFactoryTemplate(input *InsertFactoryTemplateInput) (*InsertFactoryTemplateOutput, error)
+	SelectFactoryTemplates(input *SelectFactoryTemplatesInput) (*SelectFactoryTemplatesOutput, error)
+	SelectRawLogs(input *SelectRawLogsInput) (*SelectRawLogsOutput, error)
+	InsertSmartContractEvents(input *InsertSmartContractEventsInput) (*InsertSmartContractEventsOutput, error)
+	InsertSignatureSubscription(input *InsertSignatureSubscriptionInput) (*InsertSignatureSubscriptionOutput, error)
+	SelectSignatureSubscriptions(input *SelectSignatureSubscriptionsInput) (*SelectSignatureSubscriptionsOutput, error)
+	UpdateSignatureSubscriptionAddresses(input *UpdateSignatureSubscriptionAddressesInput) (*UpdateSignatureSubscriptionAddressesOutput, error)
+	SelectSignatureEventData(input *SelectSignatureEventDataInput) (*SelectSignatureEventDataOutput, error)
+	InsertBackfillJob(input *InsertBackfillJobInput) (*InsertBackfillJobOutput, error)
+	SelectBackfillJobs(input *SelectBackfillJobsInput) (*SelectBackfillJobsOutput, error)
+	SelectCoverage(input *SelectCoverageInput) (*SelectCoverageOutput, error)
+	CompactCoverage(input *CompactCoverageInput) error
+	ScheduleEvents(input *ScheduleEventsInput) error
+	SelectEventErrors(input *SelectEventErrorsInput) (*SelectEventErrorsOutput, error)
+	AcquireLease(input *AcquireLeaseInput) (*AcquireLeaseOutput, error)
+	ReleaseLease(input *ReleaseLeaseInput) error
+	SelectLeases(input *SelectLeasesInput) (*SelectLeasesOutput, error)
 }
 
 type Engine struct {
@@ -26,6 +47,17 @@ type Engine struct {
 	InputQuerier             InputQuerier
 	EventQuerier             EventQuerier
 	EventDataQuerier         EventDataQuerier
+	BlockRangeWindowQuerier  BlockRangeWindowQuerier
+	QuarantinedLogQuerier    QuarantinedLogQuerier
+	BlockQuerier             BlockQuerier
+	FactoryTemplateQuerier   FactoryTemplateQuerier
+	RawLogQuerier            RawLogQuerier
+
+	SignatureSubscriptionQuerier SignatureSubscriptionQuerier
+	BackfillQuerier              BackfillQuerier
+	CoverageQuerier              CoverageQuerier
+	EventErrorQuerier            EventErrorQuerier
+	LeaseQuerier                 LeaseQuerier
 
 	dateGen wrapper.DateGenerator
 	idGen   wrapper.IDGenerator
@@ -51,6 +83,17 @@ func NewEngine(conf *EngineConfig) *Engine {
 		InputQuerier:             query.NewInputQuerier(nil, uuid.NewString, time.Now),
 		EventQuerier:             query.NewEventsQuerier(nil, uuid.NewString, time.Now),
 		EventDataQuerier:         query.NewEventDataQuerier(nil, uuid.NewString, time.Now),
+		BlockRangeWindowQuerier:  query.NewBlockRangeWindowQuerier(nil, uuid.NewString, time.Now),
+		QuarantinedLogQuerier:    query.NewQuarantinedLogQuerier(nil, uuid.NewString, time.Now),
+		BlockQuerier:             query.NewBlockQuerier(nil, uuid.NewString, time.Now),
+		FactoryTemplateQuerier:   query.NewFactoryTemplateQuerier(nil, uuid.NewString, time.Now),
+		RawLogQuerier:            query.NewRawLogQuerier(nil, uuid.NewString, time.Now),
+
+		SignatureSubscriptionQuerier: query.NewSignatureSubscriptionQuerier(nil, uuid.NewString, time.Now),
+		BackfillQuerier:              query.NewBackfillQuerier(nil, uuid.NewString, time.Now),
+		CoverageQuerier:              query.NewCoverageQuerier(nil, uuid.NewString, time.Now),
+		EventErrorQuerier:            query.NewEventErrorQuerier(nil, uuid.NewString, time.Now),
+		LeaseQuerier:                 query.NewLeaseQuerier(nil, uuid.NewString, time.Now),
 	}
 }
 
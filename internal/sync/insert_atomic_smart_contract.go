@@ -1,7 +1,10 @@
 package sync
 
 import (
+	"bytes"
 	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
 	"github.com/jmoiron/sqlx"
@@ -14,6 +17,13 @@ type InsertAtomicSmartContractInput struct {
 	Name       string
 	WebhookURL string
 	NodeURL    string
+	// Filters are the allowed values of the indexed arguments by event, the user only gets
+	// the logs matching them. Events are identified by name, signature or topic0.
+	Filters map[string]map[string][]string
+	// FromBlockNumber is the block the events of a new contract start syncing from, like the
+	// creation block of the contracts deployed by a factory. They sync from the genesis when
+	// it isn't defined.
+	FromBlockNumber *int64
 
 	SmartContract *storage.SmartContractRecord
 	ABI           []*storage.ABIRecord
@@ -29,25 +39,41 @@ type InsertAtomicSmartContractOutput struct {
 // InsertAtomicSmartContract is the function in charge of handling database logic
 // for atomic inserting smart contract and all related data.
 func (ng *Engine) InsertAtomicSmartContract(input *InsertAtomicSmartContractInput) (*InsertAtomicSmartContractOutput, error) {
-	output, err := ng.checkBeforeInsertAtomicSmartcontract(input)
+	var output *InsertAtomicSmartContractOutput
+	err := ng.InTransaction(func(txx *sqlx.Tx) error {
+		var err error
+		output, err = ng.InsertAtomicSmartContractTx(txx, input)
+		return err
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "sync: Engine.InsertAtomicSmartContract ng.checkBeforeInsertAtomicSmartcontract error")
+		return nil, errors.Wrap(err, "sync: Engine.InsertAtomicSmartContract ng.InTransaction error")
+	}
+
+	return output, nil
+}
+
+// InsertAtomicSmartContractTx inserts the smart contract and all related data within the given
+// transaction, so it's atomic with the rest of the changes of the caller.
+func (ng *Engine) InsertAtomicSmartContractTx(txx storage.Transaction, input *InsertAtomicSmartContractInput) (*InsertAtomicSmartContractOutput, error) {
+	output, err := ng.checkBeforeInsertAtomicSmartcontract(txx, input)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertAtomicSmartContractTx ng.checkBeforeInsertAtomicSmartcontract error")
 	}
 	if output != nil {
 		return output, nil
 	}
 
-	output, err = ng.insertAtomicSmartContract(input)
+	output, err = ng.insertAtomicSmartContract(txx, input)
 	if err != nil {
-		return nil, errors.Wrap(err, "sync: Engine.InsertAtomicSmartContract ng.insertAtomicSmartContract error")
+		return nil, errors.Wrap(err, "sync: Engine.InsertAtomicSmartContractTx ng.insertAtomicSmartContract error")
 	}
 
 	return output, nil
 }
 
-func (ng *Engine) checkBeforeInsertAtomicSmartcontract(input *InsertAtomicSmartContractInput) (*InsertAtomicSmartContractOutput, error) {
+func (ng *Engine) checkBeforeInsertAtomicSmartcontract(txx storage.Transaction, input *InsertAtomicSmartContractInput) (*InsertAtomicSmartContractOutput, error) {
 	// select smartcontract
-	sc, err := ng.SmartContractQuerier.SelectSmartContractByAddressQuery(ng.database, input.SmartContract.Address)
+	sc, err := ng.SmartContractQuerier.SelectSmartContractByAddressQuery(txx, input.SmartContract.Address)
 	if err != nil && errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
@@ -57,6 +83,24 @@ func (ng *Engine) checkBeforeInsertAtomicSmartcontract(input *InsertAtomicSmartC
 
 	now := ng.dateGen()
 
+	//ABI               *storage.ABIRecord
+	abi, err := ng.ABIQuerier.SelectABIByAddressQuery(txx, sc.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.ABIQuerier.SelectABIByAddressQuery error")
+	}
+
+	// the filters are built with the abi already stored for the contract
+	topicFilters, err := buildTopicFilters(abi, input.Filters)
+	if err != nil {
+		return nil, errors.Wrap(err, "buildTopicFilters error")
+	}
+
+	// the users that already follow the contract, their filters define the logs synced so far
+	scUsers, err := ng.SmartContractUserQuerier.SelectSmartContractUserQuery(txx, sc.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.SmartContractUserQuerier.SelectSmartContractUserQuery error")
+	}
+
 	// create smart_contract_user
 	scUser := &storage.SmartContractUserRecord{
 		ID:                   ng.idGen(),
@@ -65,33 +109,34 @@ func (ng *Engine) checkBeforeInsertAtomicSmartcontract(input *InsertAtomicSmartC
 		WebhookURL:           input.WebhookURL,
 		NodeURL:              input.NodeURL,
 		Status:               storage.SmartContractStatusIdle,
+		TopicFilters:         topicFilters,
 		CreatedAt:            now,
 		Name:                 input.Name,
 	}
-	err = ng.SmartContractUserQuerier.UpsertSmartContractUserQuery(ng.database, scUser)
+	err = ng.SmartContractUserQuerier.UpsertSmartContractUserQuery(txx, scUser)
 	if err != nil {
 		return nil, errors.Wrap(err, "ng.smartContractUserQuerier.UpsertSmartContractUserQuery error")
 	}
 
-	//ABI               *storage.ABIRecord
-	abi, err := ng.ABIQuerier.SelectABIByAddressQuery(ng.database, sc.Address)
-	if err != nil {
-		return nil, errors.Wrap(err, "ng.ABIQuerier.SelectABIByAddressQuery error")
-	}
-
 	//inputs, err := ng.inputQuerier.SelectInputByABIIDQuery(ng.database, abi.ID)
 	//if err != nil {
 	//return nil, errors.Wrap(err, "ng.inputQuerier.SelectInputByABIIDQuery error")
 	//}
 	//abi.Inputs = inputs
 
-	events, err := ng.EventQuerier.SelectEventsByAddressQuery(ng.database, sc.Address)
+	events, err := ng.EventQuerier.SelectEventsByAddressQuery(txx, sc.Address)
 	if err != nil {
 		return nil, errors.Wrap(err, "ng.eventQuerier.SelectEventsByAddressQuery error")
 	}
 	//Events            []*storage.EventRecord
 	//SmartContractUser *storage.SmartContractUserRecord
 
+	// ingest the logs of the synced blocks that only match the filters of the new user
+	err = ng.backfillWidenedFilters(txx, sc, scUsers, scUser, events, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.backfillWidenedFilters error")
+	}
+
 	return &InsertAtomicSmartContractOutput{
 		SmartContract:     sc,
 		SmartContractUser: scUser,
@@ -100,76 +145,179 @@ func (ng *Engine) checkBeforeInsertAtomicSmartcontract(input *InsertAtomicSmartC
 	}, nil
 }
 
-func (ng *Engine) insertAtomicSmartContract(input *InsertAtomicSmartContractInput) (*InsertAtomicSmartContractOutput, error) {
-	var output InsertAtomicSmartContractOutput
-	err := ng.WithTransaction(ng.database, func(txx *sqlx.Tx) error {
-		now := ng.dateGen()
-		// ids
-		smartContractID := ng.idGen()
-
-		// Insert SmartContract
-		input.SmartContract.ID = smartContractID
-		input.SmartContract.CreatedAt = now
-		err := ng.SmartContractQuerier.InsertSmartContractQuery(txx, input.SmartContract)
-		if err != nil {
-			return errors.Wrap(err, "ng.smartContractQuerier.InsertSmartContractQuery error")
+// backfillWidenedFilters creates a backfill job for the blocks already synced by the events
+// whose logs were filtered by the users of the contract when the filters of the new user are
+// different, so the logs that only match them are ingested. The job covers from the start of
+// the events to their checkpoint, the ticks keep syncing the next blocks with every filter.
+func (ng *Engine) backfillWidenedFilters(txx storage.Transaction, sc *storage.SmartContractRecord, scUsers []*storage.SmartContractUserRecord, scUser *storage.SmartContractUserRecord, events []*storage.EventRecord, now time.Time) error {
+	widened := make([]*storage.EventRecord, 0)
+	eventIDs := make([]string, 0)
+	for _, ev := range events {
+		if widensTopicFilters(scUsers, scUser, ev.Signature) {
+			widened = append(widened, ev)
+			eventIDs = append(eventIDs, ev.ID)
 		}
+	}
+	if len(widened) == 0 {
+		return nil
+	}
 
-		// Insert ABI and Input
-		for _, abi := range input.ABI {
-			abi.ID = ng.idGen()
-		}
-		err = ng.ABIQuerier.InsertABIBatchQuery(txx, input.ABI, input.SmartContract.Address)
-		if err != nil {
-			return errors.Wrap(err, "ng.abiQuerier.InsertABIQuery error")
+	// the events start from the first block of their coverage, or from the genesis
+	ranges, err := ng.CoverageQuerier.SelectCoverageRangesQuery(txx, eventIDs)
+	if err != nil {
+		return errors.Wrap(err, "ng.CoverageQuerier.SelectCoverageRangesQuery error")
+	}
+	starts := make(map[string]int64)
+	for _, r := range ranges {
+		if start, ok := starts[r.EventID]; !ok || r.FromBlockNumber < start {
+			starts[r.EventID] = r.FromBlockNumber
 		}
+	}
 
-		// insert events
-		events := make([]*storage.EventRecord, 0)
-		for _, abi := range input.ABI {
-			if abi.Type == "event" {
-				events = append(events, &storage.EventRecord{
-					AbiID:                abi.ID,
-					Name:                 abi.Name,
-					Network:              storage.EventNetwork(input.SmartContract.Network),
-					NodeURL:              input.NodeURL,
-					LatestBlockNumber:    int64(0), // For explicity since default value por numbers is 0
-					Status:               storage.EventStatusRunning,
-					Address:              input.SmartContract.Address,
-					SmartContractAddress: input.SmartContract.Address,
-				})
-			}
+	fromBlockNumber := int64(-1)
+	toBlockNumber := int64(-1)
+	nodeURL := scUser.NodeURL
+	for _, ev := range widened {
+		nodeURL = ev.NodeURL
+		if start := starts[ev.ID]; fromBlockNumber < 0 || start < fromBlockNumber {
+			fromBlockNumber = start
 		}
-		err = ng.EventQuerier.InsertEventBatchQuery(txx, events, input.SmartContract.Address)
-		if err != nil {
-			return errors.Wrap(err, "ng.eventQuerier.InsertEventBatchQuery error")
+		if ev.LatestBlockNumber > toBlockNumber {
+			toBlockNumber = ev.LatestBlockNumber
 		}
+	}
+	if toBlockNumber < fromBlockNumber {
+		return nil
+	}
 
-		// Insert SmartContractUser
-		smartContractUserInput := &storage.SmartContractUserRecord{
-			ID:                   ng.idGen(),
-			UserID:               input.UserID,
-			SmartContractAddress: input.SmartContract.Address,
-			WebhookURL:           input.WebhookURL,
-			NodeURL:              input.NodeURL,
-			Status:               storage.SmartContractStatusIdle,
-			CreatedAt:            now,
-			Name:                 input.Name,
+	_, err = ng.insertBackfillJobTx(txx, &storage.BackfillJobRecord{
+		ID:                   ng.idGen(),
+		UserID:               scUser.UserID,
+		SmartContractAddress: sc.Address,
+		Network:              storage.EventNetwork(sc.Network),
+		NodeURL:              nodeURL,
+		FromBlockNumber:      fromBlockNumber,
+		ToBlockNumber:        toBlockNumber,
+		ChunkSize:            defaultBackfillChunkSize,
+		Concurrency:          defaultBackfillConcurrency,
+		Status:               storage.BackfillStatusPending,
+		CreatedAt:            now,
+	})
+	if err != nil {
+		return errors.Wrap(err, "ng.insertBackfillJobTx error")
+	}
+
+	return nil
+}
+
+// widensTopicFilters returns true when the logs of the event were filtered by the users of the
+// contract and the filter of the new user isn't one of theirs, so it could match logs that
+// weren't synced. The previous filter of the same user counts, it's replaced by the new one.
+func widensTopicFilters(scUsers []*storage.SmartContractUserRecord, scUser *storage.SmartContractUserRecord, signature string) bool {
+	filter, err := json.Marshal(scUser.TopicFilters[signature])
+	if err != nil {
+		return true
+	}
+
+	for _, u := range scUsers {
+		// the event keeps every log when any user doesn't filter it
+		f, ok := u.TopicFilters[signature]
+		if !ok || f == nil {
+			return false
 		}
-		err = ng.SmartContractUserQuerier.UpsertSmartContractUserQuery(txx, smartContractUserInput)
-		if err != nil {
-			return errors.Wrap(err, "ng.smartContractUserQuerier.UpsertSmartContractUserQuery error")
+
+		b, err := json.Marshal(f)
+		if err == nil && bytes.Equal(b, filter) {
+			return false
 		}
-		output.SmartContractUser = smartContractUserInput
-		output.ABI = input.ABI
-		output.SmartContract = input.SmartContract
+	}
 
-		return nil
-	})
+	return len(scUsers) > 0
+}
+
+func (ng *Engine) insertAtomicSmartContract(txx storage.Transaction, input *InsertAtomicSmartContractInput) (*InsertAtomicSmartContractOutput, error) {
+	topicFilters, err := buildTopicFilters(input.ABI, input.Filters)
+	if err != nil {
+		return nil, errors.Wrap(err, "buildTopicFilters error")
+	}
+
+	now := ng.dateGen()
+	// ids
+	smartContractID := ng.idGen()
+
+	// Insert SmartContract
+	input.SmartContract.ID = smartContractID
+	input.SmartContract.CreatedAt = now
+	err = ng.SmartContractQuerier.InsertSmartContractQuery(txx, input.SmartContract)
 	if err != nil {
-		return nil, errors.Wrap(err, "ng.WithTransaction error")
+		return nil, errors.Wrap(err, "ng.smartContractQuerier.InsertSmartContractQuery error")
 	}
 
-	return &output, nil
+	// Insert ABI and Input
+	for _, abi := range input.ABI {
+		abi.ID = ng.idGen()
+	}
+	err = ng.ABIQuerier.InsertABIBatchQuery(txx, input.ABI, input.SmartContract.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.abiQuerier.InsertABIQuery error")
+	}
+
+	// events start syncing from the given block, or from the genesis
+	latestBlockNumber := int64(0)
+	if input.FromBlockNumber != nil {
+		latestBlockNumber = *input.FromBlockNumber
+	}
+
+	// insert events
+	events := make([]*storage.EventRecord, 0)
+	for _, abi := range input.ABI {
+		if abi.Type == "event" {
+			// events are identified by signature and topic0, the name is only a label
+			signature, topic0, err := abi.EventSignature()
+			if err != nil {
+				return nil, errors.Wrap(err, "abi.EventSignature error")
+			}
 
+			events = append(events, &storage.EventRecord{
+				AbiID:                abi.ID,
+				Name:                 abi.Name,
+				Signature:            signature,
+				Topic0:               topic0.Hex(),
+				Network:              storage.EventNetwork(input.SmartContract.Network),
+				NodeURL:              input.NodeURL,
+				LatestBlockNumber:    latestBlockNumber,
+				Status:               storage.EventStatusRunning,
+				Address:              input.SmartContract.Address,
+				SmartContractAddress: input.SmartContract.Address,
+			})
+		}
+	}
+	err = ng.EventQuerier.InsertEventBatchQuery(txx, events, input.SmartContract.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.eventQuerier.InsertEventBatchQuery error")
+	}
+
+	// Insert SmartContractUser
+	smartContractUserInput := &storage.SmartContractUserRecord{
+		ID:                   ng.idGen(),
+		UserID:               input.UserID,
+		SmartContractAddress: input.SmartContract.Address,
+		WebhookURL:           input.WebhookURL,
+		NodeURL:              input.NodeURL,
+		Status:               storage.SmartContractStatusIdle,
+		TopicFilters:         topicFilters,
+		CreatedAt:            now,
+		Name:                 input.Name,
+	}
+	err = ng.SmartContractUserQuerier.UpsertSmartContractUserQuery(txx, smartContractUserInput)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.smartContractUserQuerier.UpsertSmartContractUserQuery error")
+	}
+
+	return &InsertAtomicSmartContractOutput{
+		SmartContractUser: smartContractUserInput,
+		ABI:               input.ABI,
+		SmartContract:     input.SmartContract,
+		Events:            events,
+	}, nil
 }
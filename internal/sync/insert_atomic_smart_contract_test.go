@@ -19,6 +19,8 @@ func getEngineForTest() (*Engine, error) {
 		SmartContractUserQuerier: query.NewSmartContractUserQuerier(nil, uuid.NewString, time.Now),
 		InputQuerier:             query.NewInputQuerier(nil, uuid.NewString, time.Now),
 		EventQuerier:             query.NewEventsQuerier(nil, uuid.NewString, time.Now),
+		CoverageQuerier:          query.NewCoverageQuerier(nil, uuid.NewString, time.Now),
+		BackfillQuerier:          query.NewBackfillQuerier(nil, uuid.NewString, time.Now),
 
 		dateGen: time.Now,
 		idGen:   uuid.NewString,
@@ -195,3 +197,65 @@ func Test_InsertAtomicSmartContract_NthInsertion(t *testing.T) {
 		require.Equal(t, out2.SmartContractUser.SmartContractAddress, out.SmartContract.Address)
 	})
 }
+
+func Test_WidensTopicFilters(t *testing.T) {
+	signature := "Transfer(address,address,uint256)"
+	alice := &storage.EventTopicFilter{Args: map[string][]string{"from": {"0x01"}}, Topics: [][]string{{"0x01"}}}
+	bob := &storage.EventTopicFilter{Args: map[string][]string{"from": {"0x02"}}, Topics: [][]string{{"0x02"}}}
+	user := func(userID string, filter *storage.EventTopicFilter) *storage.SmartContractUserRecord {
+		filters := storage.TopicFilters{}
+		if filter != nil {
+			filters[signature] = filter
+		}
+		return &storage.SmartContractUserRecord{UserID: userID, TopicFilters: filters}
+	}
+
+	testCases := []struct {
+		name     string
+		scUsers  []*storage.SmartContractUserRecord
+		scUser   *storage.SmartContractUserRecord
+		expected bool
+	}{
+		{
+			name:     "contract without users",
+			scUser:   user("bob", bob),
+			expected: false,
+		},
+		{
+			name:     "synced logs weren't filtered",
+			scUsers:  []*storage.SmartContractUserRecord{user("alice", alice), user("carol", nil)},
+			scUser:   user("bob", bob),
+			expected: false,
+		},
+		{
+			name:     "same filter of other user",
+			scUsers:  []*storage.SmartContractUserRecord{user("alice", alice)},
+			scUser:   user("bob", alice),
+			expected: false,
+		},
+		{
+			name:     "other filter",
+			scUsers:  []*storage.SmartContractUserRecord{user("alice", alice)},
+			scUser:   user("bob", bob),
+			expected: true,
+		},
+		{
+			name:     "without filter",
+			scUsers:  []*storage.SmartContractUserRecord{user("alice", alice)},
+			scUser:   user("bob", nil),
+			expected: true,
+		},
+		{
+			name:     "user replacing its filter",
+			scUsers:  []*storage.SmartContractUserRecord{user("alice", alice)},
+			scUser:   user("alice", bob),
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, widensTopicFilters(tc.scUsers, tc.scUser, signature))
+		})
+	}
+}
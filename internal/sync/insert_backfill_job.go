@@ -0,0 +1,335 @@
+package sync
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/darchlabs/synchronizer-v2/internal/wrapper"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultBackfillChunkSize   = int64(10000)
+	defaultBackfillConcurrency = 4
+	maxBackfillConcurrency     = 16
+	// maxBackfillChunks is the max number of chunks of a job, the chunk size is increased so
+	// the range fits in them
+	maxBackfillChunks = int64(1000)
+)
+
+var (
+	ErrInvalidBackfillJob  = errors.New("invalid backfill job")
+	ErrBackfillJobNotFound = errors.New("backfill job not found")
+)
+
+// BackfillJob is a backfill job with its chunks and progress.
+type BackfillJob struct {
+	Job      *storage.BackfillJobRecord
+	Chunks   []*storage.BackfillChunkRecord
+	Progress *BackfillProgress
+}
+
+// BackfillProgress summarizes the progress of the chunks of a backfill job.
+type BackfillProgress struct {
+	TotalBlocks     int64
+	SyncedBlocks    int64
+	TotalChunks     int64
+	CompletedChunks int64
+	FailedChunks    int64
+}
+
+func newBackfillJob(job *storage.BackfillJobRecord, chunks []*storage.BackfillChunkRecord) *BackfillJob {
+	progress := &BackfillProgress{
+		TotalBlocks: job.ToBlockNumber - job.FromBlockNumber + 1,
+		TotalChunks: int64(len(chunks)),
+	}
+	for _, chunk := range chunks {
+		progress.SyncedBlocks += chunk.SyncedBlocks()
+		switch chunk.Status {
+		case storage.BackfillStatusCompleted:
+			progress.CompletedChunks++
+		case storage.BackfillStatusFailed:
+			progress.FailedChunks++
+		}
+	}
+
+	return &BackfillJob{
+		Job:      job,
+		Chunks:   chunks,
+		Progress: progress,
+	}
+}
+
+// HeadReader returns the heads tracked for the network.
+type HeadReader interface {
+	Head(network string, nodeURL string) (*blockchain.Head, error)
+}
+
+type InsertBackfillJobInput struct {
+	UserID               string
+	SmartContractAddress string
+	FromBlockNumber      int64
+	// ToBlockNumber is the last block of the job, it's the finalized head of the network when
+	// it isn't defined. The job is invalid when it's after the finalized head
+	ToBlockNumber *int64
+	// Heads resolves the finalized head of the network of the contract
+	Heads HeadReader
+	// ChunkSize and Concurrency use the defaults when they're not positive, the chunk size is
+	// increased when the range doesn't fit in the max number of chunks
+	ChunkSize   int64
+	Concurrency int
+	CreatedAt   time.Time
+}
+
+type InsertBackfillJobOutput struct {
+	BackfillJob *BackfillJob
+}
+
+// InsertBackfillJob creates a job that ingests the logs of the events of the contract in the
+// block range, split in chunks synced in parallel by the cronjob. When the owner of the
+// contract creates the job, the checkpoints of the events the range continues are moved to
+// its end, so the events keep following the chain from there while the job ingests the range.
+// The jobs don't send webhooks, so the checkpoints of contracts with webhooks aren't moved.
+func (ng *Engine) InsertBackfillJob(input *InsertBackfillJobInput) (*InsertBackfillJobOutput, error) {
+	scu, err := ng.selectSmartContractUser(input.UserID, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertBackfillJob ng.selectSmartContractUser error")
+	}
+
+	chunkSize := input.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultBackfillChunkSize
+	}
+	concurrency := input.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBackfillConcurrency
+	}
+	if concurrency > maxBackfillConcurrency {
+		concurrency = maxBackfillConcurrency
+	}
+
+	// the range ends at the finalized head, later blocks could still be reorganized
+	sc, err := ng.SmartContractQuerier.SelectSmartContractByAddressQuery(ng.database, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertBackfillJob ng.SmartContractQuerier.SelectSmartContractByAddressQuery error")
+	}
+	head, err := input.Heads.Head(string(sc.Network), scu.NodeURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertBackfillJob input.Heads.Head error")
+	}
+	toBlockNumber := head.Finalized
+	if input.ToBlockNumber != nil {
+		if *input.ToBlockNumber > head.Finalized {
+			err = errors.Wrap(ErrInvalidBackfillJob, fmt.Sprintf("to_block_number=%d is after the finalized head=%d", *input.ToBlockNumber, head.Finalized))
+			return nil, errors.Wrap(err, "sync: Engine.InsertBackfillJob error")
+		}
+		toBlockNumber = *input.ToBlockNumber
+	}
+	if input.FromBlockNumber < 0 || toBlockNumber < input.FromBlockNumber {
+		err = errors.Wrap(ErrInvalidBackfillJob, fmt.Sprintf("invalid range from_block_number=%d to_block_number=%d", input.FromBlockNumber, toBlockNumber))
+		return nil, errors.Wrap(err, "sync: Engine.InsertBackfillJob error")
+	}
+
+	var output *InsertBackfillJobOutput
+	err = ng.InTransaction(func(txx *sqlx.Tx) error {
+		events, err := ng.EventQuerier.SelectEventsByAddressQuery(txx, sc.Address)
+		if err != nil {
+			return errors.Wrap(err, "ng.EventQuerier.SelectEventsByAddressQuery error")
+		}
+		if len(events) == 0 {
+			return errors.Wrap(ErrInvalidBackfillJob, "contract has no events")
+		}
+
+		nodeURL := scu.NodeURL
+		for _, ev := range events {
+			nodeURL = ev.NodeURL
+		}
+
+		owner, err := ng.isSmartContractOwner(txx, input.UserID, sc.Address)
+		if err != nil {
+			return errors.Wrap(err, "ng.isSmartContractOwner error")
+		}
+
+		// the users with webhooks would miss the logs of the range
+		scUsers, err := ng.SmartContractUserQuerier.SelectSmartContractUserQuery(txx, sc.Address)
+		if err != nil {
+			return errors.Wrap(err, "ng.SmartContractUserQuerier.SelectSmartContractUserQuery error")
+		}
+		webhooks := false
+		for _, u := range scUsers {
+			if u.WebhookURL != "" {
+				webhooks = true
+			}
+		}
+
+		job := &storage.BackfillJobRecord{
+			ID:                   ng.idGen(),
+			UserID:               input.UserID,
+			SmartContractAddress: sc.Address,
+			Network:              storage.EventNetwork(sc.Network),
+			NodeURL:              nodeURL,
+			FromBlockNumber:      input.FromBlockNumber,
+			ToBlockNumber:        toBlockNumber,
+			ChunkSize:            chunkSize,
+			Concurrency:          concurrency,
+			Status:               storage.BackfillStatusPending,
+			CreatedAt:            input.CreatedAt,
+		}
+		chunks, err := ng.insertBackfillJobTx(txx, job)
+		if err != nil {
+			return errors.Wrap(err, "ng.insertBackfillJobTx error")
+		}
+
+		// the events the range continues follow the chain from its end
+		for _, ev := range events {
+			if !movesBackfillCheckpoint(ev, job, owner, webhooks) {
+				continue
+			}
+
+			_, err = ng.EventQuerier.UpdateEventQuery(txx, &query.UpdateEventQueryInput{
+				ID:                &ev.ID,
+				LatestBlockNumber: &job.ToBlockNumber,
+				UpdatedAt:         &input.CreatedAt,
+			})
+			if err != nil {
+				return errors.Wrap(err, "ng.EventQuerier.UpdateEventQuery error")
+			}
+		}
+
+		output = &InsertBackfillJobOutput{
+			BackfillJob: newBackfillJob(job, chunks),
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertBackfillJob ng.InTransaction error")
+	}
+
+	return output, nil
+}
+
+// movesBackfillCheckpoint returns true when the job moves the checkpoint of the event to its end.
+// The checkpoints are shared by the users of the contract so only its owner moves them, and only
+// when no user has webhooks, the jobs don't send them. Checkpoints behind the start of the range
+// aren't moved, the blocks between them and the range would be skipped otherwise.
+func movesBackfillCheckpoint(ev *storage.EventRecord, job *storage.BackfillJobRecord, owner bool, webhooks bool) bool {
+	if !owner || webhooks {
+		return false
+	}
+
+	return ev.LatestBlockNumber < job.ToBlockNumber && ev.LatestBlockNumber+1 >= job.FromBlockNumber
+}
+
+// insertBackfillJobTx inserts the job and its chunks within the given transaction.
+func (ng *Engine) insertBackfillJobTx(txx storage.Transaction, job *storage.BackfillJobRecord) ([]*storage.BackfillChunkRecord, error) {
+	chunks := splitBackfillJob(job, ng.idGen)
+
+	err := ng.BackfillQuerier.InsertBackfillJobQuery(txx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.BackfillQuerier.InsertBackfillJobQuery error")
+	}
+
+	err = ng.BackfillQuerier.InsertBackfillChunkBatchQuery(txx, chunks)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.BackfillQuerier.InsertBackfillChunkBatchQuery error")
+	}
+
+	return chunks, nil
+}
+
+// splitBackfillJob splits the range of the job in chunks, each one starts without blocks
+// ingested. The chunk size of the job is increased when the range doesn't fit in the max
+// number of chunks.
+func splitBackfillJob(job *storage.BackfillJobRecord, idGen wrapper.IDGenerator) []*storage.BackfillChunkRecord {
+	blocks := job.ToBlockNumber - job.FromBlockNumber + 1
+	if (blocks+job.ChunkSize-1)/job.ChunkSize > maxBackfillChunks {
+		job.ChunkSize = (blocks + maxBackfillChunks - 1) / maxBackfillChunks
+	}
+
+	chunks := make([]*storage.BackfillChunkRecord, 0)
+	for from := job.FromBlockNumber; from <= job.ToBlockNumber; from += job.ChunkSize {
+		to := from + job.ChunkSize - 1
+		if to > job.ToBlockNumber {
+			to = job.ToBlockNumber
+		}
+
+		chunks = append(chunks, &storage.BackfillChunkRecord{
+			ID:                idGen(),
+			JobID:             job.ID,
+			FromBlockNumber:   from,
+			ToBlockNumber:     to,
+			LatestBlockNumber: from - 1,
+			Status:            storage.BackfillStatusPending,
+			CreatedAt:         job.CreatedAt,
+		})
+	}
+
+	return chunks
+}
+
+type SelectBackfillJobsInput struct {
+	UserID               string
+	SmartContractAddress string
+	// ID selects a single job when it's defined
+	ID string
+}
+
+type SelectBackfillJobsOutput struct {
+	BackfillJobs []*BackfillJob
+}
+
+// SelectBackfillJobs returns the backfill jobs of the user for the contract with their progress.
+func (ng *Engine) SelectBackfillJobs(input *SelectBackfillJobsInput) (*SelectBackfillJobsOutput, error) {
+	_, err := ng.selectSmartContractUser(input.UserID, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectBackfillJobs ng.selectSmartContractUser error")
+	}
+
+	jobs := make([]*storage.BackfillJobRecord, 0)
+	if input.ID != "" {
+		job, err := ng.BackfillQuerier.SelectBackfillJobQuery(ng.database, input.ID)
+		if errors.Cause(err) == sql.ErrNoRows || (err == nil && (job.UserID != input.UserID || job.SmartContractAddress != input.SmartContractAddress)) {
+			return nil, errors.Wrap(ErrBackfillJobNotFound, "sync: Engine.SelectBackfillJobs error")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: Engine.SelectBackfillJobs ng.BackfillQuerier.SelectBackfillJobQuery error")
+		}
+		jobs = append(jobs, job)
+	} else {
+		jobs, err = ng.BackfillQuerier.SelectBackfillJobsQuery(ng.database, &query.SelectBackfillJobsQueryFilters{
+			UserID:               input.UserID,
+			SmartContractAddress: input.SmartContractAddress,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: Engine.SelectBackfillJobs ng.BackfillQuerier.SelectBackfillJobsQuery error")
+		}
+	}
+
+	jobIDs := make([]string, 0)
+	for _, job := range jobs {
+		jobIDs = append(jobIDs, job.ID)
+	}
+	chunks, err := ng.BackfillQuerier.SelectBackfillChunksQuery(ng.database, jobIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectBackfillJobs ng.BackfillQuerier.SelectBackfillChunksQuery error")
+	}
+	chunksByJob := make(map[string][]*storage.BackfillChunkRecord)
+	for _, chunk := range chunks {
+		chunksByJob[chunk.JobID] = append(chunksByJob[chunk.JobID], chunk)
+	}
+
+	output := &SelectBackfillJobsOutput{
+		BackfillJobs: make([]*BackfillJob, 0),
+	}
+	for _, job := range jobs {
+		output.BackfillJobs = append(output.BackfillJobs, newBackfillJob(job, chunksByJob[job.ID]))
+	}
+
+	return output, nil
+}
@@ -0,0 +1,144 @@
+package sync
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/jaekwon/testify/require"
+)
+
+func Test_SplitBackfillJob(t *testing.T) {
+	testCases := []struct {
+		name      string
+		from      int64
+		to        int64
+		chunkSize int64
+		expected  [][2]int64
+		size      int64
+	}{
+		{
+			name:      "range of a single block",
+			from:      100,
+			to:        100,
+			chunkSize: 10,
+			expected:  [][2]int64{{100, 100}},
+			size:      10,
+		},
+		{
+			name:      "range multiple of the chunk size",
+			from:      100,
+			to:        129,
+			chunkSize: 10,
+			expected:  [][2]int64{{100, 109}, {110, 119}, {120, 129}},
+			size:      10,
+		},
+		{
+			name:      "last chunk shorter than the chunk size",
+			from:      0,
+			to:        24,
+			chunkSize: 10,
+			expected:  [][2]int64{{0, 9}, {10, 19}, {20, 24}},
+			size:      10,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &storage.BackfillJobRecord{ID: "job", FromBlockNumber: tc.from, ToBlockNumber: tc.to, ChunkSize: tc.chunkSize}
+			ids := 0
+			chunks := splitBackfillJob(job, func() string {
+				ids++
+				return fmt.Sprintf("chunk-%d", ids)
+			})
+
+			ranges := make([][2]int64, 0)
+			for _, chunk := range chunks {
+				ranges = append(ranges, [2]int64{chunk.FromBlockNumber, chunk.ToBlockNumber})
+
+				// the chunks start without blocks ingested
+				require.Equal(t, "job", chunk.JobID)
+				require.Equal(t, chunk.FromBlockNumber-1, chunk.LatestBlockNumber)
+				require.Equal(t, storage.BackfillStatusPending, chunk.Status)
+			}
+			require.Equal(t, tc.expected, ranges)
+			require.Equal(t, tc.size, job.ChunkSize)
+		})
+	}
+}
+
+func Test_SplitBackfillJob_MaxChunks(t *testing.T) {
+	// the chunk size is increased so the range fits in the max number of chunks
+	job := &storage.BackfillJobRecord{ID: "job", FromBlockNumber: 1, ToBlockNumber: 1000500, ChunkSize: 100}
+	chunks := splitBackfillJob(job, func() string { return "chunk" })
+	require.Equal(t, int64(1001), job.ChunkSize)
+	require.True(t, int64(len(chunks)) <= maxBackfillChunks)
+	require.Equal(t, int64(1), chunks[0].FromBlockNumber)
+	require.Equal(t, int64(1000500), chunks[len(chunks)-1].ToBlockNumber)
+	for i := 1; i < len(chunks); i++ {
+		require.Equal(t, chunks[i-1].ToBlockNumber+1, chunks[i].FromBlockNumber)
+	}
+
+	// the ranges that fit keep the chunk size
+	job = &storage.BackfillJobRecord{ID: "job", FromBlockNumber: 1, ToBlockNumber: 100000, ChunkSize: 100}
+	chunks = splitBackfillJob(job, func() string { return "chunk" })
+	require.Equal(t, int64(100), job.ChunkSize)
+	require.Equal(t, maxBackfillChunks, int64(len(chunks)))
+}
+
+func Test_MovesBackfillCheckpoint(t *testing.T) {
+	job := &storage.BackfillJobRecord{FromBlockNumber: 100, ToBlockNumber: 200}
+
+	testCases := []struct {
+		name     string
+		latest   int64
+		owner    bool
+		webhooks bool
+		expected bool
+	}{
+		{
+			name:     "owner continuing the range",
+			latest:   150,
+			owner:    true,
+			expected: true,
+		},
+		{
+			name:     "checkpoint right before the range",
+			latest:   99,
+			owner:    true,
+			expected: true,
+		},
+		{
+			name:     "user that isn't the owner",
+			latest:   150,
+			owner:    false,
+			expected: false,
+		},
+		{
+			name:     "contract with webhooks",
+			latest:   150,
+			owner:    true,
+			webhooks: true,
+			expected: false,
+		},
+		{
+			name:     "checkpoint behind the range",
+			latest:   98,
+			owner:    true,
+			expected: false,
+		},
+		{
+			name:     "checkpoint after the range",
+			latest:   200,
+			owner:    true,
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ev := &storage.EventRecord{LatestBlockNumber: tc.latest}
+			require.Equal(t, tc.expected, movesBackfillCheckpoint(ev, job, tc.owner, tc.webhooks))
+		})
+	}
+}
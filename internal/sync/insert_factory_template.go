@@ -0,0 +1,171 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidFactoryTemplate    = errors.New("invalid factory template")
+	ErrSmartContractUserNotFound = errors.New("user isn't subscribed to the smart contract")
+	ErrNotSmartContractOwner     = errors.New("user isn't the owner of the smart contract")
+)
+
+type InsertFactoryTemplateInput struct {
+	UserID               string
+	SmartContractAddress string
+	// EventName identifies the creation event by name, canonical signature or topic0
+	EventName string
+	// ChildAddressArg is the argument of the creation event with the child contract address
+	ChildAddressArg string
+	ChildName       string
+	ChildABI        json.RawMessage
+	CreatedAt       time.Time
+}
+
+type InsertFactoryTemplateOutput struct {
+	FactoryTemplate *storage.FactoryTemplateRecord
+}
+
+// InsertFactoryTemplate makes the contract a factory of the child contracts created by the
+// event. The logs of the event ingested from now on register their child contract for the
+// users of the factory, replacing the previous template of the event.
+func (ng *Engine) InsertFactoryTemplate(input *InsertFactoryTemplateInput) (*InsertFactoryTemplateOutput, error) {
+	_, err := ng.selectSmartContractUser(input.UserID, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertFactoryTemplate ng.selectSmartContractUser error")
+	}
+
+	// get event record by name, signature or topic0 and its abi
+	ev, err := ng.selectEventByIdentifier(input.SmartContractAddress, input.EventName)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertFactoryTemplate ng.selectEventByIdentifier error")
+	}
+
+	// the child address must be an address argument of the event
+	event, err := ev.ABI.Event()
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertFactoryTemplate ev.ABI.Event error")
+	}
+	isAddress := false
+	for _, arg := range event.Inputs {
+		if arg.Name == input.ChildAddressArg && arg.Type.T == abi.AddressTy {
+			isAddress = true
+		}
+	}
+	if !isAddress {
+		err = errors.Wrap(ErrInvalidFactoryTemplate, fmt.Sprintf("arg=%s isn't an address argument of event=%s", input.ChildAddressArg, ev.Signature))
+		return nil, errors.Wrap(err, "sync: Engine.InsertFactoryTemplate error")
+	}
+
+	template := &storage.FactoryTemplateRecord{
+		ID:                   ng.idGen(),
+		EventID:              ev.ID,
+		SmartContractAddress: ev.SmartContractAddress,
+		UserID:               input.UserID,
+		ChildAddressArg:      input.ChildAddressArg,
+		ChildName:            input.ChildName,
+		ChildABI:             input.ChildABI,
+		CreatedAt:            input.CreatedAt,
+	}
+
+	// the child abi is only used when a child is created, so it's validated now
+	abis, err := template.ChildABIRecords(ev.SmartContractAddress)
+	if err != nil {
+		err = errors.Wrap(ErrInvalidFactoryTemplate, err.Error())
+		return nil, errors.Wrap(err, "sync: Engine.InsertFactoryTemplate template.ChildABIRecords error")
+	}
+	events := 0
+	for _, a := range abis {
+		if a.Type != "event" {
+			continue
+		}
+		if _, _, err := a.EventSignature(); err != nil {
+			err = errors.Wrap(ErrInvalidFactoryTemplate, err.Error())
+			return nil, errors.Wrap(err, "sync: Engine.InsertFactoryTemplate a.EventSignature error")
+		}
+		events++
+	}
+	if events == 0 {
+		err = errors.Wrap(ErrInvalidFactoryTemplate, "child abi has no events")
+		return nil, errors.Wrap(err, "sync: Engine.InsertFactoryTemplate error")
+	}
+
+	err = ng.FactoryTemplateQuerier.UpsertFactoryTemplateQuery(ng.database, template)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertFactoryTemplate ng.FactoryTemplateQuerier.UpsertFactoryTemplateQuery error")
+	}
+
+	return &InsertFactoryTemplateOutput{
+		FactoryTemplate: template,
+	}, nil
+}
+
+type SelectFactoryTemplatesInput struct {
+	UserID               string
+	SmartContractAddress string
+}
+
+type SelectFactoryTemplatesOutput struct {
+	FactoryTemplates []*storage.FactoryTemplateRecord
+}
+
+// SelectFactoryTemplates returns the factory templates of the contract.
+func (ng *Engine) SelectFactoryTemplates(input *SelectFactoryTemplatesInput) (*SelectFactoryTemplatesOutput, error) {
+	_, err := ng.selectSmartContractUser(input.UserID, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectFactoryTemplates ng.selectSmartContractUser error")
+	}
+
+	templates, err := ng.FactoryTemplateQuerier.SelectFactoryTemplatesQuery(ng.database, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectFactoryTemplates ng.FactoryTemplateQuerier.SelectFactoryTemplatesQuery error")
+	}
+
+	return &SelectFactoryTemplatesOutput{
+		FactoryTemplates: templates,
+	}, nil
+}
+
+// selectSmartContractUser returns the subscription of the user to the contract, or
+// ErrSmartContractUserNotFound when the user isn't subscribed to it.
+func (ng *Engine) selectSmartContractUser(userID string, address string) (*storage.SmartContractUserRecord, error) {
+	scUsers, err := ng.SmartContractUserQuerier.SelectSmartContractUserQuery(ng.database, address)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.selectSmartContractUser ng.SmartContractUserQuerier.SelectSmartContractUserQuery error")
+	}
+
+	for _, scu := range scUsers {
+		if scu.UserID == userID {
+			return scu, nil
+		}
+	}
+
+	return nil, errors.Wrap(ErrSmartContractUserNotFound, fmt.Sprintf("user_id=%s address=%s", userID, address))
+}
+
+// isSmartContractOwner returns true when the user is the owner of the contract, the first user
+// subscribed to it. The state shared by the users of the contract is only changed by its owner.
+func (ng *Engine) isSmartContractOwner(tx storage.Transaction, userID string, address string) (bool, error) {
+	scUsers, err := ng.SmartContractUserQuerier.SelectSmartContractUserQuery(tx, address)
+	if err != nil {
+		return false, errors.Wrap(err, "sync: Engine.isSmartContractOwner ng.SmartContractUserQuerier.SelectSmartContractUserQuery error")
+	}
+
+	var owner *storage.SmartContractUserRecord
+	for _, scu := range scUsers {
+		if scu.DeletedAt != nil {
+			continue
+		}
+		if owner == nil || scu.CreatedAt.Before(owner.CreatedAt) || (scu.CreatedAt.Equal(owner.CreatedAt) && scu.ID < owner.ID) {
+			owner = scu
+		}
+	}
+
+	return owner != nil && owner.UserID == userID, nil
+}
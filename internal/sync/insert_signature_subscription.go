@@ -0,0 +1,203 @@
+package sync
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidSignatureSubscription  = errors.New("invalid signature subscription")
+	ErrSignatureSubscriptionNotFound = errors.New("signature subscription not found")
+)
+
+type InsertSignatureSubscriptionInput struct {
+	UserID  string
+	Name    string
+	Network string
+	NodeURL string
+	// ABI has the definition of the event, it's identified by name, canonical signature or
+	// topic0 with the EventName
+	ABI       json.RawMessage
+	EventName string
+	// Addresses are the contracts whose logs of the event are ingested, the logs of every
+	// address of the network are ingested when AllAddresses is true
+	Addresses    []string
+	AllAddresses bool
+	// Filters are the allowed values of the indexed arguments of the event, they're required
+	// when every address is ingested
+	Filters         map[string][]string
+	FromBlockNumber int64
+	CreatedAt       time.Time
+}
+
+type InsertSignatureSubscriptionOutput struct {
+	SignatureSubscription *storage.SignatureSubscriptionRecord
+}
+
+// InsertSignatureSubscription subscribes the user to the logs of an event signature emitted by
+// a set of addresses or by every address of the network. The logs of all the addresses are
+// requested with a single query per block range from the FromBlockNumber.
+func (ng *Engine) InsertSignatureSubscription(input *InsertSignatureSubscriptionInput) (*InsertSignatureSubscriptionOutput, error) {
+	if input.AllAddresses && len(input.Addresses) > 0 {
+		err := errors.Wrap(ErrInvalidSignatureSubscription, "addresses can't be defined when every address is subscribed")
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription error")
+	}
+	if input.AllAddresses && len(input.Filters) == 0 {
+		err := errors.Wrap(ErrInvalidSignatureSubscription, "filters are required when every address is subscribed")
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription error")
+	}
+
+	addresses := make(pq.StringArray, 0)
+	if !input.AllAddresses {
+		var err error
+		addresses, err = normalizeAddresses(input.Addresses)
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription normalizeAddresses error")
+		}
+	}
+
+	abis := make([]*storage.ABIRecord, 0)
+	err := json.Unmarshal(input.ABI, &abis)
+	if err != nil {
+		err = errors.Wrap(ErrInvalidSignatureSubscription, err.Error())
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription json.Unmarshal error")
+	}
+
+	// get the definition of the event by name, signature or topic0
+	matches, signatures, err := matchABIEvents(abis, input.EventName)
+	if err != nil {
+		err = errors.Wrap(ErrInvalidSignatureSubscription, err.Error())
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription matchABIEvents error")
+	}
+	if len(matches) == 0 {
+		err = errors.Wrap(ErrInvalidSignatureSubscription, fmt.Sprintf("event=%s isn't defined in abi", input.EventName))
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription error")
+	}
+	if len(matches) > 1 {
+		err = errors.Wrap(ErrEventNameAmbiguous, fmt.Sprintf("event_name=%s signatures=%s", input.EventName, strings.Join(signatures, ",")))
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription error")
+	}
+	a, signature := matches[0], signatures[0]
+
+	// anonymous events don't have the topic0 shared by the logs of the signature
+	if a.Anonymous {
+		err = errors.Wrap(ErrInvalidSignatureSubscription, fmt.Sprintf("event=%s is anonymous", signature))
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription error")
+	}
+
+	topicFilters, err := buildTopicFilters(matches, map[string]map[string][]string{signature: input.Filters})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription buildTopicFilters error")
+	}
+
+	// keep only the definition of the event
+	a.InputsJSON, err = json.Marshal(a.Inputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription json.Marshal error")
+	}
+	event, err := a.MarshalJson()
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription a.MarshalJson error")
+	}
+	_, topic0, err := a.EventSignature()
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription a.EventSignature error")
+	}
+
+	subscription := &storage.SignatureSubscriptionRecord{
+		ID:                ng.idGen(),
+		UserID:            input.UserID,
+		Name:              input.Name,
+		Network:           storage.EventNetwork(input.Network),
+		NodeURL:           input.NodeURL,
+		Signature:         signature,
+		Topic0:            topic0.Hex(),
+		ABI:               json.RawMessage(fmt.Sprintf("[%s]", event)),
+		Addresses:         addresses,
+		AllAddresses:      input.AllAddresses,
+		TopicFilters:      topicFilters,
+		FromBlockNumber:   input.FromBlockNumber,
+		LatestBlockNumber: input.FromBlockNumber,
+		Status:            storage.EventStatusRunning,
+		CreatedAt:         input.CreatedAt,
+	}
+	err = ng.SignatureSubscriptionQuerier.InsertSignatureSubscriptionQuery(ng.database, subscription)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertSignatureSubscription ng.SignatureSubscriptionQuerier.InsertSignatureSubscriptionQuery error")
+	}
+
+	return &InsertSignatureSubscriptionOutput{
+		SignatureSubscription: subscription,
+	}, nil
+}
+
+type SelectSignatureSubscriptionsInput struct {
+	UserID string
+}
+
+type SelectSignatureSubscriptionsOutput struct {
+	SignatureSubscriptions []*storage.SignatureSubscriptionRecord
+}
+
+// SelectSignatureSubscriptions returns the signature subscriptions of the user.
+func (ng *Engine) SelectSignatureSubscriptions(input *SelectSignatureSubscriptionsInput) (*SelectSignatureSubscriptionsOutput, error) {
+	subscriptions, err := ng.SignatureSubscriptionQuerier.SelectSignatureSubscriptionsQuery(ng.database, &query.SelectSignatureSubscriptionsQueryFilters{
+		UserID: input.UserID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectSignatureSubscriptions ng.SignatureSubscriptionQuerier.SelectSignatureSubscriptionsQuery error")
+	}
+
+	return &SelectSignatureSubscriptionsOutput{
+		SignatureSubscriptions: subscriptions,
+	}, nil
+}
+
+// selectSignatureSubscription returns the signature subscription of the user, or
+// ErrSignatureSubscriptionNotFound when the user doesn't have it.
+func (ng *Engine) selectSignatureSubscription(userID string, id string) (*storage.SignatureSubscriptionRecord, error) {
+	subscription, err := ng.SignatureSubscriptionQuerier.SelectSignatureSubscriptionQuery(ng.database, id)
+	if errors.Cause(err) == sql.ErrNoRows {
+		return nil, errors.Wrap(ErrSignatureSubscriptionNotFound, "sync: Engine.selectSignatureSubscription error")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.selectSignatureSubscription ng.SignatureSubscriptionQuerier.SelectSignatureSubscriptionQuery error")
+	}
+	if subscription.UserID != userID {
+		return nil, errors.Wrap(ErrSignatureSubscriptionNotFound, "sync: Engine.selectSignatureSubscription error")
+	}
+
+	return subscription, nil
+}
+
+// normalizeAddresses returns the valid addresses in lower case without duplicates.
+func normalizeAddresses(addresses []string) (pq.StringArray, error) {
+	if len(addresses) == 0 {
+		return nil, errors.Wrap(ErrInvalidSignatureSubscription, "addresses are required")
+	}
+
+	normalized := make(pq.StringArray, 0)
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		if !common.IsHexAddress(address) {
+			return nil, errors.Wrap(ErrInvalidSignatureSubscription, fmt.Sprintf("address=%s is invalid", address))
+		}
+
+		address = strings.ToLower(common.HexToAddress(address).Hex())
+		if !seen[address] {
+			seen[address] = true
+			normalized = append(normalized, address)
+		}
+	}
+
+	return normalized, nil
+}
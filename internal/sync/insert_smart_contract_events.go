@@ -0,0 +1,183 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type InsertSmartContractEventsInput struct {
+	UserID               string
+	SmartContractAddress string
+	// ABI has the events added to the abi of the contract, the events already defined are
+	// skipped
+	ABI       []*storage.ABIRecord
+	CreatedAt time.Time
+}
+
+type InsertSmartContractEventsOutput struct {
+	Events []*storage.EventRecord
+	// BackfilledLogs is the number of raw logs decoded into the new events
+	BackfilledLogs int64
+}
+
+// InsertSmartContractEvents adds events to the abi of a contract. The new events of contracts
+// in raw logs mode are backfilled from the stored raw logs up to the checkpoint of the other
+// events, so they only sync the following blocks. The new events of other contracts sync
+// from the genesis.
+func (ng *Engine) InsertSmartContractEvents(input *InsertSmartContractEventsInput) (*InsertSmartContractEventsOutput, error) {
+	scu, err := ng.selectSmartContractUser(input.UserID, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertSmartContractEvents ng.selectSmartContractUser error")
+	}
+
+	output := &InsertSmartContractEventsOutput{
+		Events: make([]*storage.EventRecord, 0),
+	}
+	err = ng.InTransaction(func(txx *sqlx.Tx) error {
+		sc, err := ng.SmartContractQuerier.SelectSmartContractByAddressQuery(txx, input.SmartContractAddress)
+		if err != nil {
+			return errors.Wrap(err, "ng.SmartContractQuerier.SelectSmartContractByAddressQuery error")
+		}
+
+		existing, err := ng.EventQuerier.SelectEventsByAddressQuery(txx, sc.Address)
+		if err != nil {
+			return errors.Wrap(err, "ng.EventQuerier.SelectEventsByAddressQuery error")
+		}
+
+		// new events of raw logs contracts start from the oldest checkpoint, the raw logs
+		// of the blocks before it are already stored
+		nodeURL := scu.NodeURL
+		latestBlockNumber := int64(0)
+		signatures := make(map[string]bool)
+		for i, ev := range existing {
+			signatures[ev.Signature] = true
+			nodeURL = ev.NodeURL
+			if sc.RawLogs && (i == 0 || ev.LatestBlockNumber < latestBlockNumber) {
+				latestBlockNumber = ev.LatestBlockNumber
+			}
+		}
+
+		abis := make([]*storage.ABIRecord, 0)
+		for _, a := range input.ABI {
+			if a.Type != "event" {
+				continue
+			}
+
+			signature, topic0, err := a.EventSignature()
+			if err != nil {
+				return errors.Wrap(err, "a.EventSignature error")
+			}
+			if signatures[signature] {
+				continue
+			}
+			signatures[signature] = true
+
+			a.ID = ng.idGen()
+			abis = append(abis, a)
+			output.Events = append(output.Events, &storage.EventRecord{
+				AbiID:                a.ID,
+				Name:                 a.Name,
+				Signature:            signature,
+				Topic0:               topic0.Hex(),
+				Network:              storage.EventNetwork(sc.Network),
+				NodeURL:              nodeURL,
+				LatestBlockNumber:    latestBlockNumber,
+				Status:               storage.EventStatusRunning,
+				Address:              sc.Address,
+				SmartContractAddress: sc.Address,
+			})
+		}
+		if len(abis) == 0 {
+			return nil
+		}
+
+		err = ng.ABIQuerier.InsertABIBatchQuery(txx, abis, sc.Address)
+		if err != nil {
+			return errors.Wrap(err, "ng.ABIQuerier.InsertABIBatchQuery error")
+		}
+
+		err = ng.EventQuerier.InsertEventBatchQuery(txx, output.Events, sc.Address)
+		if err != nil {
+			return errors.Wrap(err, "ng.EventQuerier.InsertEventBatchQuery error")
+		}
+
+		if !sc.RawLogs {
+			return nil
+		}
+
+		output.BackfilledLogs, err = ng.backfillRawLogs(txx, output.Events, abis, latestBlockNumber, input.CreatedAt)
+		if err != nil {
+			return errors.Wrap(err, "ng.backfillRawLogs error")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.InsertSmartContractEvents ng.InTransaction error")
+	}
+
+	return output, nil
+}
+
+// backfillRawLogs decodes the stored raw logs of the events up to the given block into their
+// event data, the logs that can't be decoded are quarantined like during the ingestion.
+func (ng *Engine) backfillRawLogs(txx storage.Transaction, events []*storage.EventRecord, abis []*storage.ABIRecord, toBlockNumber int64, now time.Time) (int64, error) {
+	decoder, err := newRawLogDecoder(events, abis)
+	if err != nil {
+		return 0, errors.Wrap(err, "sync: Engine.backfillRawLogs newRawLogDecoder error")
+	}
+	topic0s := decoder.topic0s()
+	if len(topic0s) == 0 {
+		return 0, nil
+	}
+
+	rawLogs, err := ng.RawLogQuerier.SelectRawLogsQuery(txx, &query.SelectRawLogsQueryFilters{
+		SmartContractAddress: events[0].SmartContractAddress,
+		Topic0s:              topic0s,
+		ToBlockNumber:        &toBlockNumber,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "sync: Engine.backfillRawLogs ng.RawLogQuerier.SelectRawLogsQuery error")
+	}
+
+	eventsData := make([]*storage.EventDataRecord, 0)
+	for _, rl := range rawLogs {
+		decoded, err := decoder.decode(rl)
+		if err != nil {
+			return 0, errors.Wrap(err, "sync: Engine.backfillRawLogs decoder.decode error")
+		}
+		if decoded.Event == nil {
+			continue
+		}
+
+		if decoded.DecodeError != "" {
+			ql, err := rl.ToQuarantinedLog(ng.idGen(), decoded.Event.ID, decoded.DecodeError, now)
+			if err != nil {
+				return 0, errors.Wrap(err, "sync: Engine.backfillRawLogs rl.ToQuarantinedLog error")
+			}
+
+			err = ng.QuarantinedLogQuerier.InsertQuarantinedLogQuery(txx, ql)
+			if err != nil {
+				return 0, errors.Wrap(err, "sync: Engine.backfillRawLogs ng.QuarantinedLogQuerier.InsertQuarantinedLogQuery error")
+			}
+			continue
+		}
+
+		ed, err := rl.ToEventData(ng.idGen(), decoded.Event.ID, decoded.Data, now)
+		if err != nil {
+			return 0, errors.Wrap(err, "sync: Engine.backfillRawLogs rl.ToEventData error")
+		}
+		eventsData = append(eventsData, ed)
+	}
+
+	err = ng.EventDataQuerier.InsertEventDataBatchQuery(txx, eventsData)
+	if err != nil {
+		return 0, errors.Wrap(err, "sync: Engine.backfillRawLogs ng.EventDataQuerier.InsertEventDataBatchQuery error")
+	}
+
+	return int64(len(rawLogs)), nil
+}
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// AcquireLeaseQuery takes the lease for the holder when it's free, expired or already held by
+// it, extending its expiration by the ttl. The dates are taken from the database clock, so the
+// replicas don't depend on their clocks being in sync. It returns nil when other holder has the
+// lease.
+func (lq *LeaseQuerier) AcquireLeaseQuery(tx storage.Transaction, name string, holder string, ttl time.Duration) (*storage.LeaseRecord, error) {
+	lease := &storage.LeaseRecord{}
+	err := tx.Get(lease, `
+		INSERT INTO lease (name, holder, expires_at, acquired_at, renewed_at)
+		VALUES ($1, $2, now() + $3 * interval '1 millisecond', now(), now())
+		ON CONFLICT(name)
+		DO UPDATE SET
+			holder = excluded.holder,
+			expires_at = excluded.expires_at,
+			acquired_at = CASE WHEN lease.holder = excluded.holder THEN lease.acquired_at ELSE excluded.acquired_at END,
+			renewed_at = excluded.renewed_at
+		WHERE lease.holder = excluded.holder OR lease.expires_at < now()
+		RETURNING *;`,
+		name,
+		holder,
+		ttl.Milliseconds(),
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "query: LeaseQuerier.AcquireLeaseQuery tx.Get error")
+	}
+
+	return lease, nil
+}
@@ -0,0 +1,24 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func (bq *BlockQuerier) DeleteBlocksByHashesQuery(tx storage.Transaction, network storage.EventNetwork, hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		DELETE FROM blocks
+		WHERE network = $1 AND hash = ANY($2);`,
+		network, pq.Array(hashes),
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: BlockQuerier.DeleteBlocksByHashesQuery tx.Exec error")
+	}
+
+	return nil
+}
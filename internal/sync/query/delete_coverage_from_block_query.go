@@ -0,0 +1,31 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// DeleteCoverageFromBlockQuery removes the blocks at or after the given one from the coverage of
+// the event, the ranges that start before it are truncated.
+func (cq *CoverageQuerier) DeleteCoverageFromBlockQuery(tx storage.Transaction, eventID string, fromBlockNumber int64) error {
+	_, err := tx.Exec(`
+		DELETE FROM event_coverage
+		WHERE event_id = $1 AND from_block_number >= $2;`,
+		eventID, fromBlockNumber,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: CoverageQuerier.DeleteCoverageFromBlockQuery tx.Exec delete error")
+	}
+
+	_, err = tx.Exec(`
+		UPDATE event_coverage
+		SET to_block_number = $2 - 1
+		WHERE event_id = $1 AND to_block_number >= $2;`,
+		eventID, fromBlockNumber,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: CoverageQuerier.DeleteCoverageFromBlockQuery tx.Exec update error")
+	}
+
+	return nil
+}
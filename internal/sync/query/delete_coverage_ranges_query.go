@@ -0,0 +1,20 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func (cq *CoverageQuerier) DeleteCoverageRangesQuery(tx storage.Transaction, ids []string) error {
+	_, err := tx.Exec(`
+		DELETE FROM event_coverage
+		WHERE id = ANY($1);`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: CoverageQuerier.DeleteCoverageRangesQuery tx.Exec error")
+	}
+
+	return nil
+}
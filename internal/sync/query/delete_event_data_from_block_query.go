@@ -0,0 +1,27 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (eq *EventDataQuerier) DeleteEventDataFromBlockQuery(
+	tx storage.Transaction,
+	eventID string,
+	fromBlockNumber int64,
+) ([]*storage.EventDataRecord, error) {
+	records := make([]*storage.EventDataRecord, 0)
+
+	err := tx.Select(
+		&records, `
+		DELETE FROM event_data
+		WHERE event_id = $1 AND block_number >= $2
+		RETURNING *;`,
+		eventID, fromBlockNumber,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: EventDataQuerier.DeleteEventDataFromBlockQuery tx.Select error")
+	}
+
+	return records, nil
+}
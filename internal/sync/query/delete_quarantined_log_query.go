@@ -0,0 +1,15 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (qq *QuarantinedLogQuerier) DeleteQuarantinedLogQuery(tx storage.Transaction, id string) error {
+	_, err := tx.Exec(`DELETE FROM quarantined_log WHERE id = $1;`, id)
+	if err != nil {
+		return errors.Wrap(err, "query: QuarantinedLogQuerier.DeleteQuarantinedLogQuery tx.Exec error")
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (qq *QuarantinedLogQuerier) DeleteQuarantinedLogsFromBlockQuery(tx storage.Transaction, eventID string, fromBlockNumber int64) error {
+	_, err := tx.Exec(`
+		DELETE FROM quarantined_log
+		WHERE event_id = $1 AND block_number >= $2;`,
+		eventID, fromBlockNumber,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: QuarantinedLogQuerier.DeleteQuarantinedLogsFromBlockQuery tx.Exec error")
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (rq *RawLogQuerier) DeleteRawLogsFromBlockQuery(tx storage.Transaction, scAddress string, fromBlockNumber int64) error {
+	_, err := tx.Exec(`
+		DELETE FROM raw_log
+		WHERE sc_address = $1 AND block_number >= $2;`,
+		scAddress, fromBlockNumber,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: RawLogQuerier.DeleteRawLogsFromBlockQuery tx.Exec error")
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/lib/pq"
+)
+
+// eventDataTopicConditions returns the conditions on the topics of the event data matching the
+// topic filter of a smart contract user. Postgres arrays start at 1, so the topic at index i of
+// the log is the element i+1 of the column.
+func eventDataTopicConditions(column string, filter *storage.EventTopicFilter) squirrel.And {
+	conditions := squirrel.And{}
+	if filter == nil {
+		return conditions
+	}
+
+	for position, values := range filter.Topics {
+		if len(values) == 0 {
+			continue
+		}
+
+		conditions = append(conditions, squirrel.Expr(
+			fmt.Sprintf("%s[%d] = ANY(?)", column, filter.TopicPosition(position)+1),
+			pq.Array(values),
+		))
+	}
+
+	return conditions
+}
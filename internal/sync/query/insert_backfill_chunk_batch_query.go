@@ -0,0 +1,29 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (bq *BackfillQuerier) InsertBackfillChunkBatchQuery(tx storage.Transaction, records []*storage.BackfillChunkRecord) error {
+	for _, r := range records {
+		_, err := tx.Exec(`
+			INSERT INTO backfill_chunk (id, job_id, from_block_number, to_block_number, latest_block_number, status, attempts, error, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`,
+			r.ID,
+			r.JobID,
+			r.FromBlockNumber,
+			r.ToBlockNumber,
+			r.LatestBlockNumber,
+			r.Status,
+			r.Attempts,
+			r.Error,
+			r.CreatedAt,
+		)
+		if err != nil {
+			return errors.Wrap(err, "query: BackfillQuerier.InsertBackfillChunkBatchQuery tx.Exec error")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (bq *BackfillQuerier) InsertBackfillJobQuery(tx storage.Transaction, input *storage.BackfillJobRecord) error {
+	err := tx.Get(input, `
+		INSERT INTO backfill_job (
+			id, user_id, sc_address, network, node_url, from_block_number, to_block_number,
+			chunk_size, concurrency, status, error, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING *;`,
+		input.ID,
+		input.UserID,
+		input.SmartContractAddress,
+		input.Network,
+		input.NodeURL,
+		input.FromBlockNumber,
+		input.ToBlockNumber,
+		input.ChunkSize,
+		input.Concurrency,
+		input.Status,
+		input.Error,
+		input.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: BackfillQuerier.InsertBackfillJobQuery tx.Get error")
+	}
+
+	return nil
+}
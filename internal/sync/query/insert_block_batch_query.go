@@ -0,0 +1,29 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (bq *BlockQuerier) InsertBlockBatchQuery(tx storage.Transaction, records []*storage.BlockRecord) error {
+	for _, r := range records {
+		_, err := tx.Exec(`
+			INSERT INTO blocks (network, number, hash, parent_hash, timestamp, base_fee, gas_used, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT(network, hash) DO NOTHING;`,
+			r.Network,
+			r.Number,
+			r.Hash,
+			r.ParentHash,
+			r.Timestamp,
+			r.BaseFee,
+			r.GasUsed,
+			r.CreatedAt,
+		)
+		if err != nil {
+			return errors.Wrap(err, "query: BlockQuerier.InsertBlockBatchQuery tx.Exec error")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (cq *CoverageQuerier) InsertCoverageRangeBatchQuery(tx storage.Transaction, records []*storage.CoverageRangeRecord) error {
+	for _, r := range records {
+		_, err := tx.Exec(`
+			INSERT INTO event_coverage (id, event_id, from_block_number, to_block_number, source, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6);`,
+			r.ID,
+			r.EventID,
+			r.FromBlockNumber,
+			r.ToBlockNumber,
+			r.Source,
+			r.CreatedAt,
+		)
+		if err != nil {
+			return errors.Wrap(err, "query: CoverageQuerier.InsertCoverageRangeBatchQuery tx.Exec error")
+		}
+	}
+
+	return nil
+}
@@ -7,14 +7,19 @@ import (
 
 func (eq *EventDataQuerier) InsertEventDataQuery(qCtx storage.QueryContext, record *storage.EventDataRecord) error {
 	_, err := qCtx.Exec(`
-		INSERT INTO event_data (id, event_id, tx, block_number, data, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT(tx) DO NOTHING;`,
+		INSERT INTO event_data (id, event_id, tx, block_number, block_hash, block_timestamp, log_index, tx_index, data, topics, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT(event_id, tx, log_index) DO NOTHING;`,
 		record.ID,
 		record.EventID,
 		record.Tx,
 		record.BlockNumber,
+		record.BlockHash,
+		record.BlockTimestamp,
+		record.LogIndex,
+		record.TxIndex,
 		record.Data,
+		record.Topics,
 		record.CreatedAt,
 	)
 	if err != nil {
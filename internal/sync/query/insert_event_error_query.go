@@ -0,0 +1,40 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// InsertEventErrorQuery stores the error of the event and removes its older errors, so only the
+// latest keep errors of each event are kept.
+func (eq *EventErrorQuerier) InsertEventErrorQuery(tx storage.Transaction, record *storage.EventErrorRecord, keep int) error {
+	_, err := tx.Exec(`
+		INSERT INTO event_error (id, event_id, error, attempt, created_at)
+		VALUES ($1, $2, $3, $4, $5);`,
+		record.ID,
+		record.EventID,
+		record.Error,
+		record.Attempt,
+		record.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: EventErrorQuerier.InsertEventErrorQuery tx.Exec insert error")
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM event_error
+		WHERE event_id = $1 AND id NOT IN (
+			SELECT id FROM event_error
+			WHERE event_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		);`,
+		record.EventID,
+		keep,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: EventErrorQuerier.InsertEventErrorQuery tx.Exec delete error")
+	}
+
+	return nil
+}
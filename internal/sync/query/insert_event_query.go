@@ -17,8 +17,10 @@ func (eq *EventQuerier) InsertEventQuery(qCtx storage.QueryContext, input *stora
 			status,
 			created_at,
 			name,
-			abi_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`,
+			abi_id,
+			signature,
+			topic0
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);`,
 		input.ID,
 		input.Network,
 		input.NodeURL,
@@ -29,6 +31,8 @@ func (eq *EventQuerier) InsertEventQuery(qCtx storage.QueryContext, input *stora
 		input.CreatedAt,
 		input.Name,
 		input.AbiID,
+		input.Signature,
+		input.Topic0,
 	)
 	if err != nil {
 		return errors.Wrap(err, "query: EventQuerier.InsertEventQuery qCtx.Exec error")
@@ -0,0 +1,31 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (qq *QuarantinedLogQuerier) InsertQuarantinedLogQuery(qCtx storage.QueryContext, record *storage.QuarantinedLogRecord) error {
+	_, err := qCtx.Exec(`
+		INSERT INTO quarantined_log (id, event_id, tx, tx_index, log_index, block_number, block_hash, block_timestamp, topics, data, error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT(event_id, tx, log_index) DO NOTHING;`,
+		record.ID,
+		record.EventID,
+		record.Tx,
+		record.TxIndex,
+		record.LogIndex,
+		record.BlockNumber,
+		record.BlockHash,
+		record.BlockTimestamp,
+		record.Topics,
+		record.Data,
+		record.Error,
+		record.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: QuarantinedLogQuerier.InsertQuarantinedLogQuery qCtx.Exec error")
+	}
+
+	return nil
+}
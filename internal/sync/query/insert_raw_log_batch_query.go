@@ -0,0 +1,32 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (rq *RawLogQuerier) InsertRawLogBatchQuery(tx storage.Transaction, records []*storage.RawLogRecord) error {
+	for _, r := range records {
+		_, err := tx.Exec(`
+			INSERT INTO raw_log (id, sc_address, tx, tx_index, log_index, block_number, block_hash, block_timestamp, topics, data, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT(sc_address, tx, log_index) DO NOTHING;`,
+			r.ID,
+			r.SmartContractAddress,
+			r.Tx,
+			r.TxIndex,
+			r.LogIndex,
+			r.BlockNumber,
+			r.BlockHash,
+			r.BlockTimestamp,
+			r.Topics,
+			r.Data,
+			r.CreatedAt,
+		)
+		if err != nil {
+			return errors.Wrap(err, "query: RawLogQuerier.InsertRawLogBatchQuery tx.Exec error")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (sq *SignatureSubscriptionQuerier) InsertSignatureEventDataBatchQuery(tx storage.Transaction, records []*storage.SignatureEventDataRecord) error {
+	for _, r := range records {
+		_, err := tx.Exec(`
+			INSERT INTO signature_event_data (id, subscription_id, address, tx, tx_index, log_index, block_number, block_hash, block_timestamp, topics, data, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT(subscription_id, tx, log_index) DO NOTHING;`,
+			r.ID,
+			r.SubscriptionID,
+			r.Address,
+			r.Tx,
+			r.TxIndex,
+			r.LogIndex,
+			r.BlockNumber,
+			r.BlockHash,
+			r.BlockTimestamp,
+			r.Topics,
+			r.Data,
+			r.CreatedAt,
+		)
+		if err != nil {
+			return errors.Wrap(err, "query: SignatureSubscriptionQuerier.InsertSignatureEventDataBatchQuery tx.Exec error")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (sq *SignatureSubscriptionQuerier) InsertSignatureQuarantinedLogBatchQuery(tx storage.Transaction, records []*storage.SignatureQuarantinedLogRecord) error {
+	for _, r := range records {
+		_, err := tx.Exec(`
+			INSERT INTO signature_quarantined_log (id, subscription_id, address, tx, tx_index, log_index, block_number, block_hash, block_timestamp, topics, data, error, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT(subscription_id, tx, log_index) DO NOTHING;`,
+			r.ID,
+			r.SubscriptionID,
+			r.Address,
+			r.Tx,
+			r.TxIndex,
+			r.LogIndex,
+			r.BlockNumber,
+			r.BlockHash,
+			r.BlockTimestamp,
+			r.Topics,
+			r.Data,
+			r.Error,
+			r.CreatedAt,
+		)
+		if err != nil {
+			return errors.Wrap(err, "query: SignatureSubscriptionQuerier.InsertSignatureQuarantinedLogBatchQuery tx.Exec error")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (sq *SignatureSubscriptionQuerier) InsertSignatureSubscriptionQuery(tx storage.Transaction, input *storage.SignatureSubscriptionRecord) error {
+	err := tx.Get(input, `
+		INSERT INTO signature_subscription (
+			id, user_id, name, network, node_url, signature, topic0, abi, addresses, all_addresses,
+			topic_filters, from_block_number, latest_block_number, status, error, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING *;`,
+		input.ID,
+		input.UserID,
+		input.Name,
+		input.Network,
+		input.NodeURL,
+		input.Signature,
+		input.Topic0,
+		input.ABI,
+		input.Addresses,
+		input.AllAddresses,
+		input.TopicFilters,
+		input.FromBlockNumber,
+		input.LatestBlockNumber,
+		input.Status,
+		input.Error,
+		input.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: SignatureSubscriptionQuerier.InsertSignatureSubscriptionQuery tx.Get error")
+	}
+
+	return nil
+}
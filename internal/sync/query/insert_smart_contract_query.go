@@ -22,13 +22,17 @@ func (sc *SmartContractQuerier) InsertSmartContractQuery(
 			address,
 			last_tx_block_synced,
 			initial_block_number,
+			template,
+			raw_logs,
 			created_at
-		) VALUES ($1, $2, $3, $4, $5, $6);`,
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`,
 		input.ID,
 		input.Network,
 		input.Address,
 		input.LastTxBlockSynced,
 		input.InitialBlockNumber,
+		input.Template,
+		input.RawLogs,
 		input.CreatedAt,
 	)
 	if err != nil {
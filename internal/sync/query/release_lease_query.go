@@ -0,0 +1,16 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// ReleaseLeaseQuery frees the lease when it's held by the holder.
+func (lq *LeaseQuerier) ReleaseLeaseQuery(tx storage.Transaction, name string, holder string) error {
+	_, err := tx.Exec("DELETE FROM lease WHERE name = $1 AND holder = $2;", name, holder)
+	if err != nil {
+		return errors.Wrap(err, "query: LeaseQuerier.ReleaseLeaseQuery tx.Exec error")
+	}
+
+	return nil
+}
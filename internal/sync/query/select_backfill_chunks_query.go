@@ -0,0 +1,23 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func (bq *BackfillQuerier) SelectBackfillChunksQuery(tx storage.Transaction, jobIDs []string) ([]*storage.BackfillChunkRecord, error) {
+	records := make([]*storage.BackfillChunkRecord, 0)
+	err := tx.Select(&records, `
+		SELECT *
+		FROM backfill_chunk
+		WHERE job_id = ANY($1)
+		ORDER BY job_id, from_block_number ASC;`,
+		pq.Array(jobIDs),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: BackfillQuerier.SelectBackfillChunksQuery tx.Select error")
+	}
+
+	return records, nil
+}
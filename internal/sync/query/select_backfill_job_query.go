@@ -0,0 +1,21 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (bq *BackfillQuerier) SelectBackfillJobQuery(tx storage.Transaction, id string) (*storage.BackfillJobRecord, error) {
+	var record storage.BackfillJobRecord
+	err := tx.Get(&record, `
+		SELECT *
+		FROM backfill_job
+		WHERE id = $1;`,
+		id,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: BackfillQuerier.SelectBackfillJobQuery tx.Get error")
+	}
+
+	return &record, nil
+}
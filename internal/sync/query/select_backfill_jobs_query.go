@@ -0,0 +1,48 @@
+package query
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type SelectBackfillJobsQueryFilters struct {
+	// UserID and SmartContractAddress select the jobs of every user and contract when they're empty
+	UserID               string
+	SmartContractAddress string
+	// Statuses select the jobs of any status when it's empty
+	Statuses []storage.BackfillStatus
+}
+
+func (bq *BackfillQuerier) SelectBackfillJobsQuery(tx storage.Transaction, input *SelectBackfillJobsQueryFilters) ([]*storage.BackfillJobRecord, error) {
+	records := make([]*storage.BackfillJobRecord, 0)
+
+	conditions := squirrel.And{}
+	if input.UserID != "" {
+		conditions = append(conditions, squirrel.Eq{"backfill_job.user_id": input.UserID})
+	}
+	if input.SmartContractAddress != "" {
+		conditions = append(conditions, squirrel.Eq{"backfill_job.sc_address": input.SmartContractAddress})
+	}
+	if len(input.Statuses) > 0 {
+		conditions = append(conditions, squirrel.Eq{"backfill_job.status": input.Statuses})
+	}
+
+	query, args, err := squirrel.
+		Select("backfill_job.*").
+		From("backfill_job").
+		Where(conditions).
+		OrderBy("backfill_job.created_at ASC").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query: BackfillQuerier.SelectBackfillJobsQuery q.PlaceholderFormat().ToSql error")
+	}
+
+	err = tx.Select(&records, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: BackfillQuerier.SelectBackfillJobsQuery tx.Select error")
+	}
+
+	return records, nil
+}
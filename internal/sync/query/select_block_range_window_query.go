@@ -0,0 +1,23 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (wq *BlockRangeWindowQuerier) SelectBlockRangeWindowQuery(
+	tx storage.Transaction,
+	nodeURL string,
+	network storage.EventNetwork,
+) (*storage.BlockRangeWindowRecord, error) {
+	var record storage.BlockRangeWindowRecord
+	err := tx.Get(&record, `
+		SELECT * FROM block_range_window WHERE node_url = $1 AND network = $2;`,
+		nodeURL, network,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: BlockRangeWindowQuerier.SelectBlockRangeWindowQuery tx.Get error")
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,35 @@
+package query
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type SelectBlockRangeWindowsQueryFilters struct {
+	Network string
+}
+
+func (wq *BlockRangeWindowQuerier) SelectBlockRangeWindowsQuery(
+	tx storage.Transaction,
+	filters *SelectBlockRangeWindowsQueryFilters,
+) ([]*storage.BlockRangeWindowRecord, error) {
+	q := squirrel.Select("*").From("block_range_window")
+
+	if filters.Network != "" {
+		q = q.Where("network = ?", filters.Network)
+	}
+
+	query, args, err := q.OrderBy("network", "node_url").PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query: BlockRangeWindowQuerier.SelectBlockRangeWindowsQuery q.PlaceholderFormat().ToSql error")
+	}
+
+	records := make([]*storage.BlockRangeWindowRecord, 0)
+	err = tx.Select(&records, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: BlockRangeWindowQuerier.SelectBlockRangeWindowsQuery tx.Select error")
+	}
+
+	return records, nil
+}
@@ -1,13 +1,20 @@
 package query
 
 import (
+	"time"
+
+	"github.com/Masterminds/squirrel"
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
 	"github.com/pkg/errors"
 )
 
 type SelectCountEventDataQueryFilters struct {
-	SmartContractAddress string
-	EventName            string
+	EventID string
+	// FromTime and ToTime filter the event data by block timestamp, both are inclusive
+	FromTime *time.Time
+	ToTime   *time.Time
+	// TopicFilter only counts the event data matching the filter of a smart contract user
+	TopicFilter *storage.EventTopicFilter
 }
 
 func (eq *EventDataQuerier) SelectCountEventDataQuery(
@@ -16,15 +23,27 @@ func (eq *EventDataQuerier) SelectCountEventDataQuery(
 ) (int64, error) {
 	var count int64
 
-	err := tx.Get(
-		&count, `
-		SELECT COUNT(ed.id)
-		FROM event_data ed
-		JOIN event e
-		ON ed.event_id = e.id
-		WHERE e.sc_address = $1 AND e.name = $2`,
-		input.SmartContractAddress, input.EventName,
-	)
+	q := squirrel.
+		Select("COUNT(ed.id)").
+		From("event_data ed").
+		Where("ed.event_id = ?", input.EventID)
+
+	if input.FromTime != nil {
+		q = q.Where("ed.block_timestamp >= ?", *input.FromTime)
+	}
+	if input.ToTime != nil {
+		q = q.Where("ed.block_timestamp <= ?", *input.ToTime)
+	}
+	if conditions := eventDataTopicConditions("ed.topics", input.TopicFilter); len(conditions) > 0 {
+		q = q.Where(conditions)
+	}
+
+	query, args, err := q.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "query: EventDataQuerier.SelectCountEventDataQuery q.PlaceholderFormat().ToSql error")
+	}
+
+	err = tx.Get(&count, query, args...)
 	if err != nil {
 		return 0, errors.Wrap(err, "query: EventDataQuerier.SelectCountEventDataQuery tx.Get error")
 	}
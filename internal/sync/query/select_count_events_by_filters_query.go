@@ -21,6 +21,10 @@ func (eq *EventQuerier) SelectCountEventsQuery(
 		q = q.Where("status = ?", filters.Status)
 	}
 
+	if len(filters.Statuses) > 0 {
+		q = q.Where(squirrel.Eq{"status": filters.Statuses})
+	}
+
 	if filters.SmartContractAddress != "" {
 		q = q.Where("sc_address = ?", filters.SmartContractAddress)
 	}
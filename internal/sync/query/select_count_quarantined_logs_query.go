@@ -0,0 +1,30 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type SelectCountQuarantinedLogsQueryFilters struct {
+	EventID string
+}
+
+func (qq *QuarantinedLogQuerier) SelectCountQuarantinedLogsQuery(
+	tx storage.Transaction,
+	input *SelectCountQuarantinedLogsQueryFilters,
+) (int64, error) {
+	var count int64
+
+	err := tx.Get(
+		&count, `
+		SELECT COUNT(ql.id)
+		FROM quarantined_log ql
+		WHERE ql.event_id = $1`,
+		input.EventID,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "query: QuarantinedLogQuerier.SelectCountQuarantinedLogsQuery tx.Get error")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,28 @@
+package query
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (rq *RawLogQuerier) SelectCountRawLogsQuery(tx storage.Transaction, input *SelectRawLogsQueryFilters) (int64, error) {
+	var count int64
+
+	query, args, err := squirrel.
+		Select("COUNT(raw_log.id)").
+		From("raw_log").
+		Where(rawLogsConditions(input)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "query: RawLogQuerier.SelectCountRawLogsQuery q.PlaceholderFormat().ToSql error")
+	}
+
+	err = tx.Get(&count, query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "query: RawLogQuerier.SelectCountRawLogsQuery tx.Get error")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,28 @@
+package query
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (sq *SignatureSubscriptionQuerier) SelectCountSignatureEventDataQuery(tx storage.Transaction, input *SelectSignatureEventDataQueryFilters) (int64, error) {
+	var count int64
+
+	query, args, err := squirrel.
+		Select("COUNT(signature_event_data.id)").
+		From("signature_event_data").
+		Where(signatureEventDataConditions(input)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "query: SignatureSubscriptionQuerier.SelectCountSignatureEventDataQuery q.PlaceholderFormat().ToSql error")
+	}
+
+	err = tx.Get(&count, query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "query: SignatureSubscriptionQuerier.SelectCountSignatureEventDataQuery tx.Get error")
+	}
+
+	return count, nil
+}
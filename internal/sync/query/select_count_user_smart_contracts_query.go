@@ -5,7 +5,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (q *SmartContractQuerier) SelectCountUserSmartContractsQuery(db storage.Database, userID string) (int64, error) {
+func (q *SmartContractQuerier) SelectCountUserSmartContractsQuery(db storage.Database, userID string, template string) (int64, error) {
 	var count int64
 
 	err := db.Get(
@@ -14,8 +14,10 @@ func (q *SmartContractQuerier) SelectCountUserSmartContractsQuery(db storage.Dat
 		FROM smartcontracts sc
 		JOIN smartcontract_users scu
 		ON sc.address = scu.sc_address
-		WHERE scu.user_id = $1`,
+		WHERE scu.user_id = $1
+		AND ($2::TEXT = '' OR sc.template = $2)`,
 		userID,
+		template,
 	)
 	if err != nil {
 		return 0, errors.Wrap(err, "query: SmartContractQuerier.SelectCountUserSmartContractsQuery db.Get error")
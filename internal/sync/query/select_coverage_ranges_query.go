@@ -0,0 +1,23 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func (cq *CoverageQuerier) SelectCoverageRangesQuery(tx storage.Transaction, eventIDs []string) ([]*storage.CoverageRangeRecord, error) {
+	records := make([]*storage.CoverageRangeRecord, 0)
+	err := tx.Select(&records, `
+		SELECT *
+		FROM event_coverage
+		WHERE event_id = ANY($1)
+		ORDER BY event_id, from_block_number ASC;`,
+		pq.Array(eventIDs),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: CoverageQuerier.SelectCoverageRangesQuery tx.Select error")
+	}
+
+	return records, nil
+}
@@ -0,0 +1,28 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (eq *EventDataQuerier) SelectEventDataBlocksQuery(
+	tx storage.Transaction,
+	eventID string,
+	fromBlockNumber int64,
+) ([]*storage.EventDataBlockRecord, error) {
+	records := make([]*storage.EventDataBlockRecord, 0)
+
+	err := tx.Select(
+		&records, `
+		SELECT DISTINCT block_number, block_hash
+		FROM event_data
+		WHERE event_id = $1 AND block_number >= $2 AND block_hash <> ''
+		ORDER BY block_number DESC;`,
+		eventID, fromBlockNumber,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: EventDataQuerier.SelectEventDataBlocksQuery tx.Select error")
+	}
+
+	return records, nil
+}
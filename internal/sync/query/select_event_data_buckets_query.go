@@ -0,0 +1,53 @@
+package query
+
+import (
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type SelectEventDataBucketsQueryFilters struct {
+	EventID  string
+	FromTime time.Time
+	ToTime   time.Time
+	// Interval is the size of the buckets in seconds
+	Interval int64
+	// TopicFilter only counts the event data matching the filter of a smart contract user
+	TopicFilter *storage.EventTopicFilter
+}
+
+func (eq *EventDataQuerier) SelectEventDataBucketsQuery(
+	tx storage.Transaction,
+	input *SelectEventDataBucketsQueryFilters,
+) ([]*storage.EventDataBucketRecord, error) {
+	records := make([]*storage.EventDataBucketRecord, 0)
+
+	q := squirrel.
+		Select().
+		Column("to_timestamp(floor(extract(epoch FROM ed.block_timestamp) / ?) * ?) AS bucket", input.Interval, input.Interval).
+		Column("COUNT(ed.id) AS count").
+		From("event_data ed").
+		Where("ed.event_id = ?", input.EventID).
+		Where("ed.block_timestamp >= ?", input.FromTime).
+		Where("ed.block_timestamp <= ?", input.ToTime)
+
+	if conditions := eventDataTopicConditions("ed.topics", input.TopicFilter); len(conditions) > 0 {
+		q = q.Where(conditions)
+	}
+
+	q = q.GroupBy("bucket").OrderBy("bucket ASC")
+
+	query, args, err := q.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query: EventDataQuerier.SelectEventDataBucketsQuery q.PlaceholderFormat().ToSql error")
+	}
+
+	err = tx.Select(&records, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: EventDataQuerier.SelectEventDataBucketsQuery tx.Select error")
+	}
+
+	return records, nil
+}
@@ -1,6 +1,8 @@
 package query
 
 import (
+	"time"
+
 	"github.com/Masterminds/squirrel"
 	"github.com/darchlabs/synchronizer-v2/internal/pagination"
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
@@ -8,9 +10,13 @@ import (
 )
 
 type SelectEventDataQueryFilters struct {
-	SmartContractAddress string
-	EventName            string
-	Pagination           *pagination.Pagination
+	EventID string
+	// FromTime and ToTime filter the event data by block timestamp, both are inclusive
+	FromTime *time.Time
+	ToTime   *time.Time
+	// TopicFilter only selects the event data matching the filter of a smart contract user
+	TopicFilter *storage.EventTopicFilter
+	Pagination  *pagination.Pagination
 }
 
 func (eq *EventDataQuerier) SelectEventDataQuery(
@@ -21,13 +27,24 @@ func (eq *EventDataQuerier) SelectEventDataQuery(
 
 	q := squirrel.
 		Select("event_data.*").
-		From("event").
-		Join("event_data ON event.id = event_data.event_id").
-		Where("event.sc_address = ?", input.SmartContractAddress).
-		Where("event.name = ?", input.EventName)
+		From("event_data").
+		Where("event_data.event_id = ?", input.EventID)
+
+	if input.FromTime != nil {
+		q = q.Where("event_data.block_timestamp >= ?", *input.FromTime)
+	}
+	if input.ToTime != nil {
+		q = q.Where("event_data.block_timestamp <= ?", *input.ToTime)
+	}
+	if conditions := eventDataTopicConditions("event_data.topics", input.TopicFilter); len(conditions) > 0 {
+		q = q.Where(conditions)
+	}
 
 	if input.Pagination != nil {
-		q = q.OrderBy("created_at " + input.Pagination.Sort)
+		q = q.OrderBy(
+			"event_data.block_number "+input.Pagination.Sort,
+			"event_data.log_index "+input.Pagination.Sort,
+		)
 		q = q.Limit(uint64(input.Pagination.Limit))
 		q = q.Offset(uint64(input.Pagination.Offset))
 	}
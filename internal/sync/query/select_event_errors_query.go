@@ -0,0 +1,22 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (eq *EventErrorQuerier) SelectEventErrorsQuery(tx storage.Transaction, eventID string) ([]*storage.EventErrorRecord, error) {
+	records := make([]*storage.EventErrorRecord, 0)
+	err := tx.Select(&records, `
+		SELECT *
+		FROM event_error
+		WHERE event_id = $1
+		ORDER BY created_at DESC;`,
+		eventID,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: EventErrorQuerier.SelectEventErrorsQuery tx.Select error")
+	}
+
+	return records, nil
+}
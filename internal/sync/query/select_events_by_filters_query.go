@@ -10,7 +10,10 @@ import (
 type SelectEventsQueryFilters struct {
 	SmartContractAddress string
 	EventName            string
+	EventSignature       string
+	EventTopic0          string
 	Status               string
+	Statuses             []string
 	Pagination           *pagination.Pagination
 }
 
@@ -25,6 +28,10 @@ func (eq *EventQuerier) SelectEventsQuery(
 		q = q.Where("status = ?", filters.Status)
 	}
 
+	if len(filters.Statuses) > 0 {
+		q = q.Where(squirrel.Eq{"status": filters.Statuses})
+	}
+
 	if filters.SmartContractAddress != "" {
 		q = q.Where("sc_address = ?", filters.SmartContractAddress)
 	}
@@ -33,6 +40,14 @@ func (eq *EventQuerier) SelectEventsQuery(
 		q = q.Where("name = ?", filters.EventName)
 	}
 
+	if filters.EventSignature != "" {
+		q = q.Where("signature = ?", filters.EventSignature)
+	}
+
+	if filters.EventTopic0 != "" {
+		q = q.Where("topic0 = ?", filters.EventTopic0)
+	}
+
 	if filters.Pagination != nil {
 		q = q.OrderBy("created_at " + filters.Pagination.Sort)
 		q = q.Limit(uint64(filters.Pagination.Limit))
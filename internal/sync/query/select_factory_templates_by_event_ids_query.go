@@ -0,0 +1,26 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func (fq *FactoryTemplateQuerier) SelectFactoryTemplatesByEventIDsQuery(tx storage.Transaction, eventIDs []string) ([]*storage.FactoryTemplateRecord, error) {
+	records := make([]*storage.FactoryTemplateRecord, 0)
+	if len(eventIDs) == 0 {
+		return records, nil
+	}
+
+	err := tx.Select(&records, `
+		SELECT *
+		FROM factory_templates
+		WHERE event_id = ANY($1);`,
+		pq.Array(eventIDs),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: FactoryTemplateQuerier.SelectFactoryTemplatesByEventIDsQuery tx.Select error")
+	}
+
+	return records, nil
+}
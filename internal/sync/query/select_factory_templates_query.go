@@ -0,0 +1,22 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (fq *FactoryTemplateQuerier) SelectFactoryTemplatesQuery(tx storage.Transaction, scAddress string) ([]*storage.FactoryTemplateRecord, error) {
+	records := make([]*storage.FactoryTemplateRecord, 0)
+	err := tx.Select(&records, `
+		SELECT *
+		FROM factory_templates
+		WHERE sc_address = $1
+		ORDER BY created_at ASC;`,
+		scAddress,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: FactoryTemplateQuerier.SelectFactoryTemplatesQuery tx.Select error")
+	}
+
+	return records, nil
+}
@@ -0,0 +1,18 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// SelectLeasesQuery returns the leases whose name starts with the prefix, an empty prefix
+// returns every lease. The expiration is compared with the database clock.
+func (lq *LeaseQuerier) SelectLeasesQuery(tx storage.Transaction, prefix string) ([]*storage.LeaseRecord, error) {
+	leases := make([]*storage.LeaseRecord, 0)
+	err := tx.Select(&leases, "SELECT *, expires_at < now() AS expired FROM lease WHERE left(name, length($1)) = $1 ORDER BY name;", prefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: LeaseQuerier.SelectLeasesQuery tx.Select error")
+	}
+
+	return leases, nil
+}
@@ -0,0 +1,48 @@
+package query
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type SelectQuarantinedLogsQueryFilters struct {
+	EventID    string
+	Pagination *pagination.Pagination
+}
+
+func (qq *QuarantinedLogQuerier) SelectQuarantinedLogsQuery(
+	tx storage.Transaction,
+	input *SelectQuarantinedLogsQueryFilters,
+) ([]*storage.QuarantinedLogRecord, error) {
+	records := make([]*storage.QuarantinedLogRecord, 0)
+
+	q := squirrel.
+		Select("quarantined_log.*").
+		From("quarantined_log").
+		Where("quarantined_log.event_id = ?", input.EventID)
+
+	if input.Pagination != nil {
+		q = q.OrderBy(
+			"quarantined_log.block_number "+input.Pagination.Sort,
+			"quarantined_log.log_index "+input.Pagination.Sort,
+		)
+		q = q.Limit(uint64(input.Pagination.Limit))
+		q = q.Offset(uint64(input.Pagination.Offset))
+	} else {
+		q = q.OrderBy("quarantined_log.block_number", "quarantined_log.log_index")
+	}
+
+	query, args, err := q.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query: QuarantinedLogQuerier.SelectQuarantinedLogsQuery q.PlaceholderFormat().ToSql error")
+	}
+
+	err = tx.Select(&records, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: QuarantinedLogQuerier.SelectQuarantinedLogsQuery tx.Select error")
+	}
+
+	return records, nil
+}
@@ -0,0 +1,63 @@
+package query
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type SelectRawLogsQueryFilters struct {
+	SmartContractAddress string
+	// Topic0s are the event ids of the logs, every log is selected when it's empty
+	Topic0s []string
+	// ToBlockNumber is the last block of the logs, when it's defined
+	ToBlockNumber *int64
+	Pagination    *pagination.Pagination
+}
+
+// rawLogsConditions returns the conditions of the raw logs matching the filters.
+func rawLogsConditions(input *SelectRawLogsQueryFilters) squirrel.And {
+	conditions := squirrel.And{squirrel.Eq{"raw_log.sc_address": input.SmartContractAddress}}
+	if len(input.Topic0s) > 0 {
+		conditions = append(conditions, squirrel.Expr("raw_log.topics[1] = ANY(?)", pq.Array(input.Topic0s)))
+	}
+	if input.ToBlockNumber != nil {
+		conditions = append(conditions, squirrel.LtOrEq{"raw_log.block_number": *input.ToBlockNumber})
+	}
+
+	return conditions
+}
+
+func (rq *RawLogQuerier) SelectRawLogsQuery(tx storage.Transaction, input *SelectRawLogsQueryFilters) ([]*storage.RawLogRecord, error) {
+	records := make([]*storage.RawLogRecord, 0)
+
+	q := squirrel.
+		Select("raw_log.*").
+		From("raw_log").
+		Where(rawLogsConditions(input))
+
+	if input.Pagination != nil {
+		q = q.OrderBy(
+			"raw_log.block_number "+input.Pagination.Sort,
+			"raw_log.log_index "+input.Pagination.Sort,
+		)
+		q = q.Limit(uint64(input.Pagination.Limit))
+		q = q.Offset(uint64(input.Pagination.Offset))
+	} else {
+		q = q.OrderBy("raw_log.block_number", "raw_log.log_index")
+	}
+
+	query, args, err := q.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query: RawLogQuerier.SelectRawLogsQuery q.PlaceholderFormat().ToSql error")
+	}
+
+	err = tx.Select(&records, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: RawLogQuerier.SelectRawLogsQuery tx.Select error")
+	}
+
+	return records, nil
+}
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type SelectSignatureEventDataQueryFilters struct {
+	SubscriptionID string
+	// Address is the emitter of the logs, the logs of every address are selected when it's empty
+	Address    string
+	Pagination *pagination.Pagination
+}
+
+// signatureEventDataConditions returns the conditions of the event data matching the filters.
+func signatureEventDataConditions(input *SelectSignatureEventDataQueryFilters) squirrel.And {
+	conditions := squirrel.And{squirrel.Eq{"signature_event_data.subscription_id": input.SubscriptionID}}
+	if input.Address != "" {
+		conditions = append(conditions, squirrel.Eq{"signature_event_data.address": strings.ToLower(input.Address)})
+	}
+
+	return conditions
+}
+
+func (sq *SignatureSubscriptionQuerier) SelectSignatureEventDataQuery(tx storage.Transaction, input *SelectSignatureEventDataQueryFilters) ([]*storage.SignatureEventDataRecord, error) {
+	records := make([]*storage.SignatureEventDataRecord, 0)
+
+	q := squirrel.
+		Select("signature_event_data.*").
+		From("signature_event_data").
+		Where(signatureEventDataConditions(input))
+
+	if input.Pagination != nil {
+		q = q.OrderBy(
+			"signature_event_data.block_number "+input.Pagination.Sort,
+			"signature_event_data.log_index "+input.Pagination.Sort,
+		)
+		q = q.Limit(uint64(input.Pagination.Limit))
+		q = q.Offset(uint64(input.Pagination.Offset))
+	} else {
+		q = q.OrderBy("signature_event_data.block_number", "signature_event_data.log_index")
+	}
+
+	query, args, err := q.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query: SignatureSubscriptionQuerier.SelectSignatureEventDataQuery q.PlaceholderFormat().ToSql error")
+	}
+
+	err = tx.Select(&records, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: SignatureSubscriptionQuerier.SelectSignatureEventDataQuery tx.Select error")
+	}
+
+	return records, nil
+}
@@ -0,0 +1,21 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (sq *SignatureSubscriptionQuerier) SelectSignatureSubscriptionQuery(tx storage.Transaction, id string) (*storage.SignatureSubscriptionRecord, error) {
+	var record storage.SignatureSubscriptionRecord
+	err := tx.Get(&record, `
+		SELECT *
+		FROM signature_subscription
+		WHERE id = $1;`,
+		id,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: SignatureSubscriptionQuerier.SelectSignatureSubscriptionQuery tx.Get error")
+	}
+
+	return &record, nil
+}
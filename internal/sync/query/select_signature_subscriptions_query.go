@@ -0,0 +1,43 @@
+package query
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type SelectSignatureSubscriptionsQueryFilters struct {
+	// UserID and Status select every subscription when they're empty
+	UserID string
+	Status storage.EventStatus
+}
+
+func (sq *SignatureSubscriptionQuerier) SelectSignatureSubscriptionsQuery(tx storage.Transaction, input *SelectSignatureSubscriptionsQueryFilters) ([]*storage.SignatureSubscriptionRecord, error) {
+	records := make([]*storage.SignatureSubscriptionRecord, 0)
+
+	conditions := squirrel.And{}
+	if input.UserID != "" {
+		conditions = append(conditions, squirrel.Eq{"signature_subscription.user_id": input.UserID})
+	}
+	if input.Status != "" {
+		conditions = append(conditions, squirrel.Eq{"signature_subscription.status": input.Status})
+	}
+
+	query, args, err := squirrel.
+		Select("signature_subscription.*").
+		From("signature_subscription").
+		Where(conditions).
+		OrderBy("signature_subscription.created_at ASC").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query: SignatureSubscriptionQuerier.SelectSignatureSubscriptionsQuery q.PlaceholderFormat().ToSql error")
+	}
+
+	err = tx.Select(&records, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: SignatureSubscriptionQuerier.SelectSignatureSubscriptionsQuery tx.Select error")
+	}
+
+	return records, nil
+}
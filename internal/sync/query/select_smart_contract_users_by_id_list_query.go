@@ -2,6 +2,7 @@ package query
 
 import (
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
@@ -14,23 +15,15 @@ func (sq *SmartContractUserQuerier) SmartContractUsersByIDListQuery(
 		return records, nil
 	}
 
-	// TODO: improve this
-	queryMade := make(map[string]struct{})
-	for _, addr := range addresses {
-		if _, ok := queryMade[addr]; !ok {
-			var r storage.SmartContractUserRecord
-			err := tx.Get(&r, `
+	// every user of the contracts is selected, each one could have its own topic filters
+	err := tx.Select(&records, `
 		SELECT *
 		FROM smartcontract_users
-		WHERE sc_address = $1;`,
-				addr,
-			)
-			if err != nil {
-				return nil, errors.Wrap(err, "query: SmartContractUserQuerier.SmartContractUsersByIDListQuery tx.Select error")
-			}
-			records = append(records, &r)
-			queryMade[addr] = struct{}{}
-		}
+		WHERE sc_address = ANY($1);`,
+		pq.Array(addresses),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: SmartContractUserQuerier.SmartContractUsersByIDListQuery tx.Select error")
 	}
 
 	return records, nil
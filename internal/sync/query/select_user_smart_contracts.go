@@ -19,28 +19,34 @@ type UserSmartContractOutput struct {
 	Address            string                `db:"address"`
 	LastTxBlockSynced  int64                 `db:"last_tx_block_synced"`
 	InitialBlockNumber int64                 `db:"initial_block_number"`
+	Template           string                `db:"template"`
+	RawLogs            bool                  `db:"raw_logs"`
 	CreatedAt          time.Time             `db:"created_at"`
 	UpdatedAt          time.Time             `db:"updated_at"`
 	Events             []storage.EventRecord `db:"-"`
 }
 
-func (sq *SmartContractQuerier) SelectUserSmartContractsQuery(tx storage.Transaction, userID string, p *pagination.Pagination) ([]*UserSmartContractOutput, error) {
+// SelectUserSmartContractsQuery returns the contracts of the user, only the ones registered with
+// the template when it's defined.
+func (sq *SmartContractQuerier) SelectUserSmartContractsQuery(tx storage.Transaction, userID string, template string, p *pagination.Pagination) ([]*UserSmartContractOutput, error) {
 	records := make([]*UserSmartContractOutput, 0)
 
 	err := tx.Select(
 		&records,
 		fmt.Sprintf(`
-			SELECT sc.id as id, scu.name as name, scu.status as status, scu.error as error, scu.webhook as webhook, sc.network as network, sc.address as address, sc.last_tx_block_synced as last_tx_block_synced, sc.initial_block_number as initial_block_number, sc.created_at as created_at
+			SELECT sc.id as id, scu.name as name, scu.status as status, scu.error as error, scu.webhook as webhook, sc.network as network, sc.address as address, sc.last_tx_block_synced as last_tx_block_synced, sc.initial_block_number as initial_block_number, sc.template as template, sc.raw_logs as raw_logs, sc.created_at as created_at
 			FROM smartcontracts sc
 			JOIN smartcontract_users scu
 			ON sc.address = scu.sc_address
 			WHERE scu.user_id = $3
+			AND ($4::TEXT = '' OR sc.template = $4)
 			ORDER BY sc.created_at %s
 			LIMIT $1
 			OFFSET $2`, p.Sort),
 		p.Limit,
 		p.Offset,
 		userID,
+		template,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "query: SmartcontractQuerier.SelectUserSmartContractsQuery tx.Get error")
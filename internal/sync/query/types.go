@@ -118,3 +118,153 @@ func NewEventDataQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGe
 		logger:  logger,
 	}
 }
+
+// BLOCK RANGE WINDOW
+type BlockRangeWindowQuerier struct {
+	idGen   wrapper.IDGenerator
+	dateGen wrapper.DateGenerator
+	logger  logger.Client
+}
+
+func NewBlockRangeWindowQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGen wrapper.DateGenerator) *BlockRangeWindowQuerier {
+	return &BlockRangeWindowQuerier{
+		idGen:   idGen,
+		dateGen: dateGen,
+		logger:  logger,
+	}
+}
+
+// QUARANTINED LOG
+type QuarantinedLogQuerier struct {
+	idGen   wrapper.IDGenerator
+	dateGen wrapper.DateGenerator
+	logger  logger.Client
+}
+
+func NewQuarantinedLogQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGen wrapper.DateGenerator) *QuarantinedLogQuerier {
+	return &QuarantinedLogQuerier{
+		idGen:   idGen,
+		dateGen: dateGen,
+		logger:  logger,
+	}
+}
+
+// BLOCK
+type BlockQuerier struct {
+	idGen   wrapper.IDGenerator
+	dateGen wrapper.DateGenerator
+	logger  logger.Client
+}
+
+func NewBlockQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGen wrapper.DateGenerator) *BlockQuerier {
+	return &BlockQuerier{
+		idGen:   idGen,
+		dateGen: dateGen,
+		logger:  logger,
+	}
+}
+
+// FACTORY TEMPLATE
+type FactoryTemplateQuerier struct {
+	idGen   wrapper.IDGenerator
+	dateGen wrapper.DateGenerator
+	logger  logger.Client
+}
+
+func NewFactoryTemplateQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGen wrapper.DateGenerator) *FactoryTemplateQuerier {
+	return &FactoryTemplateQuerier{
+		idGen:   idGen,
+		dateGen: dateGen,
+		logger:  logger,
+	}
+}
+
+// RAW LOG
+type RawLogQuerier struct {
+	idGen   wrapper.IDGenerator
+	dateGen wrapper.DateGenerator
+	logger  logger.Client
+}
+
+func NewRawLogQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGen wrapper.DateGenerator) *RawLogQuerier {
+	return &RawLogQuerier{
+		idGen:   idGen,
+		dateGen: dateGen,
+		logger:  logger,
+	}
+}
+
+// SIGNATURE SUBSCRIPTION
+type SignatureSubscriptionQuerier struct {
+	idGen   wrapper.IDGenerator
+	dateGen wrapper.DateGenerator
+	logger  logger.Client
+}
+
+func NewSignatureSubscriptionQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGen wrapper.DateGenerator) *SignatureSubscriptionQuerier {
+	return &SignatureSubscriptionQuerier{
+		idGen:   idGen,
+		dateGen: dateGen,
+		logger:  logger,
+	}
+}
+
+// BACKFILL
+type BackfillQuerier struct {
+	idGen   wrapper.IDGenerator
+	dateGen wrapper.DateGenerator
+	logger  logger.Client
+}
+
+func NewBackfillQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGen wrapper.DateGenerator) *BackfillQuerier {
+	return &BackfillQuerier{
+		idGen:   idGen,
+		dateGen: dateGen,
+		logger:  logger,
+	}
+}
+
+// COVERAGE
+type CoverageQuerier struct {
+	idGen   wrapper.IDGenerator
+	dateGen wrapper.DateGenerator
+	logger  logger.Client
+}
+
+func NewCoverageQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGen wrapper.DateGenerator) *CoverageQuerier {
+	return &CoverageQuerier{
+		idGen:   idGen,
+		dateGen: dateGen,
+		logger:  logger,
+	}
+}
+
+// EVENT ERROR
+type EventErrorQuerier struct {
+	idGen   wrapper.IDGenerator
+	dateGen wrapper.DateGenerator
+	logger  logger.Client
+}
+
+func NewEventErrorQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGen wrapper.DateGenerator) *EventErrorQuerier {
+	return &EventErrorQuerier{
+		idGen:   idGen,
+		dateGen: dateGen,
+		logger:  logger,
+	}
+}
+
+// LEASE
+type LeaseQuerier struct {
+	idGen   wrapper.IDGenerator
+	dateGen wrapper.DateGenerator
+	logger  logger.Client
+}
+
+func NewLeaseQuerier(logger logger.Client, idGen wrapper.IDGenerator, dateGen wrapper.DateGenerator) *LeaseQuerier {
+	return &LeaseQuerier{
+		idGen:   idGen,
+		dateGen: dateGen,
+		logger:  logger,
+	}
+}
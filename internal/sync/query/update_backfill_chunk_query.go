@@ -0,0 +1,44 @@
+package query
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type UpdateBackfillChunkQueryInput struct {
+	ID *string
+	// LatestBlockNumber only moves the progress of the chunk forward
+	LatestBlockNumber *int64
+	Status            *storage.BackfillStatus
+	Attempts          *int
+	Error             *string
+	UpdatedAt         *time.Time
+}
+
+func (bq *BackfillQuerier) UpdateBackfillChunkQuery(tx storage.Transaction, input *UpdateBackfillChunkQueryInput) (*storage.BackfillChunkRecord, error) {
+	var record storage.BackfillChunkRecord
+	err := tx.Get(&record, `
+		UPDATE backfill_chunk
+		SET
+			latest_block_number = GREATEST(COALESCE($2, latest_block_number), latest_block_number),
+			status = COALESCE($3, status),
+			attempts = COALESCE($4, attempts),
+			error = COALESCE($5, error),
+			updated_at = COALESCE($6, updated_at)
+		WHERE id = $1
+		RETURNING *;`,
+		input.ID,
+		input.LatestBlockNumber,
+		input.Status,
+		input.Attempts,
+		input.Error,
+		input.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: BackfillQuerier.UpdateBackfillChunkQuery tx.Get error")
+	}
+
+	return &record, nil
+}
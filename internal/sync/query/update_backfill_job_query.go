@@ -0,0 +1,37 @@
+package query
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type UpdateBackfillJobQueryInput struct {
+	ID        *string
+	Status    *storage.BackfillStatus
+	Error     *string
+	UpdatedAt *time.Time
+}
+
+func (bq *BackfillQuerier) UpdateBackfillJobQuery(tx storage.Transaction, input *UpdateBackfillJobQueryInput) (*storage.BackfillJobRecord, error) {
+	var record storage.BackfillJobRecord
+	err := tx.Get(&record, `
+		UPDATE backfill_job
+		SET
+			status = COALESCE($2, status),
+			error = COALESCE($3, error),
+			updated_at = COALESCE($4, updated_at)
+		WHERE id = $1
+		RETURNING *;`,
+		input.ID,
+		input.Status,
+		input.Error,
+		input.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: BackfillQuerier.UpdateBackfillJobQuery tx.Get error")
+	}
+
+	return &record, nil
+}
@@ -8,17 +8,23 @@ import (
 )
 
 type UpdateEventQueryInput struct {
-	ID                   *string
-	AbiID                *string
-	Network              *storage.EventNetwork
-	Name                 *string
-	NodeURL              *string
-	Address              *string
-	LatestBlockNumber    *int64
-	SmartContractAddress *string
-	Status               *storage.EventStatus
-	Error                *string
-	UpdatedAt            *time.Time
+	ID                    *string
+	AbiID                 *string
+	Network               *storage.EventNetwork
+	Name                  *string
+	NodeURL               *string
+	Address               *string
+	LatestBlockNumber     *int64
+	ObservedBlockNumber   *int64
+	FinalizedBlockNumber  *int64
+	SmartContractAddress  *string
+	Status                *storage.EventStatus
+	Error                 *string
+	Failures              *int
+	NextRunAt             *time.Time
+	CheckpointBlockNumber *int64
+	CheckpointBlockHash   *string
+	UpdatedAt             *time.Time
 }
 
 func (eq *EventQuerier) UpdateEventQuery(tx storage.Transaction, input *UpdateEventQueryInput) (*storage.EventRecord, error) {
@@ -35,7 +41,13 @@ func (eq *EventQuerier) UpdateEventQuery(tx storage.Transaction, input *UpdateEv
 			sc_address = COALESCE($8, sc_address),
 			status = COALESCE($9, status),
 			error = COALESCE($10, error),
-			updated_at = COALESCE($11, updated_at)
+			updated_at = COALESCE($11, updated_at),
+			observed_block_number = COALESCE($12, observed_block_number),
+			finalized_block_number = COALESCE($13, finalized_block_number),
+			failures = COALESCE($14, failures),
+			next_run_at = COALESCE($15, next_run_at),
+			checkpoint_block_number = COALESCE($16, checkpoint_block_number),
+			checkpoint_block_hash = COALESCE($17, checkpoint_block_hash)
 		WHERE id = $1
 		RETURNING *;`,
 		input.ID,
@@ -49,6 +61,12 @@ func (eq *EventQuerier) UpdateEventQuery(tx storage.Transaction, input *UpdateEv
 		input.Status,
 		input.Error,
 		input.UpdatedAt,
+		input.ObservedBlockNumber,
+		input.FinalizedBlockNumber,
+		input.Failures,
+		input.NextRunAt,
+		input.CheckpointBlockNumber,
+		input.CheckpointBlockHash,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "sync: Engine.UpdateEventQuery tx.Get error")
@@ -0,0 +1,36 @@
+package query
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+type UpdateQuarantinedLogQueryInput struct {
+	ID        string
+	Error     string
+	UpdatedAt time.Time
+}
+
+// UpdateQuarantinedLogQuery stores the error of a failed decode attempt and increases the attempts.
+func (qq *QuarantinedLogQuerier) UpdateQuarantinedLogQuery(tx storage.Transaction, input *UpdateQuarantinedLogQueryInput) (*storage.QuarantinedLogRecord, error) {
+	var record storage.QuarantinedLogRecord
+	err := tx.Get(&record, `
+		UPDATE quarantined_log
+		SET
+			error = $2,
+			attempts = attempts + 1,
+			updated_at = $3
+		WHERE id = $1
+		RETURNING *;`,
+		input.ID,
+		input.Error,
+		input.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: QuarantinedLogQuerier.UpdateQuarantinedLogQuery tx.Get error")
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,58 @@
+package query
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type UpdateSignatureSubscriptionQueryInput struct {
+	ID                *string
+	Addresses         *pq.StringArray
+	LatestBlockNumber *int64
+	Status            *storage.EventStatus
+	Error             *string
+	UpdatedAt         *time.Time
+	// BackfillAddresses replaces the addresses whose logs before the checkpoint are ingested
+	BackfillAddresses         *pq.StringArray
+	BackfillToBlockNumber     *int64
+	BackfillLatestBlockNumber *int64
+	// CurrentAddresses only updates the subscription when its address set wasn't replaced
+	// meanwhile, sql.ErrNoRows is returned otherwise
+	CurrentAddresses *pq.StringArray
+}
+
+func (sq *SignatureSubscriptionQuerier) UpdateSignatureSubscriptionQuery(tx storage.Transaction, input *UpdateSignatureSubscriptionQueryInput) (*storage.SignatureSubscriptionRecord, error) {
+	var record storage.SignatureSubscriptionRecord
+	err := tx.Get(&record, `
+		UPDATE signature_subscription
+		SET
+			addresses = COALESCE($2, addresses),
+			latest_block_number = COALESCE($3, latest_block_number),
+			status = COALESCE($4, status),
+			error = COALESCE($5, error),
+			updated_at = COALESCE($6, updated_at),
+			backfill_addresses = COALESCE($8, backfill_addresses),
+			backfill_to_block_number = COALESCE($9, backfill_to_block_number),
+			backfill_latest_block_number = COALESCE($10, backfill_latest_block_number)
+		WHERE id = $1 AND ($7::TEXT[] IS NULL OR addresses = $7)
+		RETURNING *;`,
+		input.ID,
+		input.Addresses,
+		input.LatestBlockNumber,
+		input.Status,
+		input.Error,
+		input.UpdatedAt,
+		input.CurrentAddresses,
+		input.BackfillAddresses,
+		input.BackfillToBlockNumber,
+		input.BackfillLatestBlockNumber,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query: SignatureSubscriptionQuerier.UpdateSignatureSubscriptionQuery tx.Get error")
+	}
+
+	return &record, nil
+}
@@ -10,12 +10,13 @@ func (sq *SmartContractUserQuerier) UpsertSmartContractUserQuery(
 	input *storage.SmartContractUserRecord,
 ) error {
 	err := tx.Get(input, `
-		INSERT INTO smartcontract_users (id, user_id, sc_address, webhook, node_url, status, created_at, name)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO smartcontract_users (id, user_id, sc_address, webhook, node_url, status, created_at, name, topic_filters)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT(user_id, sc_address)
 		DO UPDATE SET
 				created_at = excluded.created_at,
-				webhook = COALESCE(smartcontract_users.webhook, excluded.webhook)
+				webhook = COALESCE(smartcontract_users.webhook, excluded.webhook),
+				topic_filters = excluded.topic_filters
 		RETURNING *;`,
 		input.ID,
 		input.UserID,
@@ -25,6 +26,7 @@ func (sq *SmartContractUserQuerier) UpsertSmartContractUserQuery(
 		input.Status,
 		input.CreatedAt,
 		input.Name,
+		input.TopicFilters,
 	)
 	if err != nil {
 		return errors.Wrap(err, "query: SmartContractUserRecord.UpsertSmartContractUserQuery tx.Get error")
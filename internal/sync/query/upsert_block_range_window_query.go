@@ -0,0 +1,29 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (wq *BlockRangeWindowQuerier) UpsertBlockRangeWindowQuery(tx storage.Transaction, input *storage.BlockRangeWindowRecord) error {
+	err := tx.Get(input, `
+		INSERT INTO block_range_window (node_url, network, size, max_size, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT(node_url, network)
+		DO UPDATE SET
+			size = excluded.size,
+			max_size = CASE WHEN excluded.max_size > 0 THEN excluded.max_size ELSE block_range_window.max_size END,
+			updated_at = excluded.created_at
+		RETURNING *;`,
+		input.NodeURL,
+		input.Network,
+		input.Size,
+		input.MaxSize,
+		input.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: BlockRangeWindowQuerier.UpsertBlockRangeWindowQuery tx.Get error")
+	}
+
+	return nil
+}
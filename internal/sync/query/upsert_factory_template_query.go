@@ -0,0 +1,34 @@
+package query
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+func (fq *FactoryTemplateQuerier) UpsertFactoryTemplateQuery(tx storage.Transaction, input *storage.FactoryTemplateRecord) error {
+	err := tx.Get(input, `
+		INSERT INTO factory_templates (id, event_id, sc_address, user_id, child_address_arg, child_name, child_abi, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT(event_id)
+		DO UPDATE SET
+			user_id = excluded.user_id,
+			child_address_arg = excluded.child_address_arg,
+			child_name = excluded.child_name,
+			child_abi = excluded.child_abi,
+			updated_at = excluded.created_at
+		RETURNING *;`,
+		input.ID,
+		input.EventID,
+		input.SmartContractAddress,
+		input.UserID,
+		input.ChildAddressArg,
+		input.ChildName,
+		input.ChildABI,
+		input.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "query: FactoryTemplateQuerier.UpsertFactoryTemplateQuery tx.Get error")
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package sync
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// DecodedRawLog is a raw log with the event of the abi it belongs to, decoded when the raw
+// log is read. The event is nil when the abi doesn't define the topic0 of the log.
+type DecodedRawLog struct {
+	RawLog      *storage.RawLogRecord
+	Event       *storage.EventRecord
+	Data        map[string]interface{}
+	DecodeError string
+}
+
+// rawLogDecoder decodes the raw logs of a contract with the events of its abi, matching
+// them by topic0. Anonymous events don't have topic0, so their raw logs aren't decoded.
+type rawLogDecoder struct {
+	events      map[string]*storage.EventRecord
+	definitions map[string]abi.Event
+}
+
+func newRawLogDecoder(events []*storage.EventRecord, abis []*storage.ABIRecord) (*rawLogDecoder, error) {
+	abiMap := make(map[string]*storage.ABIRecord)
+	for _, a := range abis {
+		abiMap[a.ID] = a
+	}
+
+	d := &rawLogDecoder{
+		events:      make(map[string]*storage.EventRecord),
+		definitions: make(map[string]abi.Event),
+	}
+	for _, ev := range events {
+		a, ok := abiMap[ev.AbiID]
+		if !ok {
+			continue
+		}
+
+		definition, err := a.Event()
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: newRawLogDecoder a.Event error")
+		}
+		if definition.Anonymous {
+			continue
+		}
+
+		topic0 := definition.ID.Hex()
+		d.events[topic0] = ev
+		d.definitions[topic0] = definition
+	}
+
+	return d, nil
+}
+
+// topic0s returns the event ids of the events the decoder knows.
+func (d *rawLogDecoder) topic0s() []string {
+	topics := make([]string, 0)
+	for topic0 := range d.events {
+		topics = append(topics, topic0)
+	}
+
+	return topics
+}
+
+// decode returns the raw log decoded with the event of its topic0.
+func (d *rawLogDecoder) decode(rl *storage.RawLogRecord) (*DecodedRawLog, error) {
+	decoded := &DecodedRawLog{RawLog: rl}
+	if len(rl.Topics) == 0 {
+		return decoded, nil
+	}
+
+	topic0 := common.HexToHash(rl.Topics[0]).Hex()
+	ev, ok := d.events[topic0]
+	if !ok {
+		return decoded, nil
+	}
+	decoded.Event = ev
+
+	topics, data, err := rl.RawLog()
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: rawLogDecoder.decode rl.RawLog error")
+	}
+
+	decoded.Data, err = blockchain.DecodeLog(d.definitions[topic0], topics, data)
+	if err != nil {
+		decoded.DecodeError = err.Error()
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,118 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type RetryQuarantinedLogsInput struct {
+	SmartContractAddress string
+	// EventName identifies the event by name, canonical signature or topic0
+	EventName string
+	UpdatedAt time.Time
+}
+
+type RetryQuarantinedLogsOutput struct {
+	EventsData      []*storage.EventDataRecord
+	QuarantinedLogs []*storage.QuarantinedLogRecord
+}
+
+// RetryQuarantinedLogs decodes again the quarantined logs of the event using its current ABI.
+// The logs decoded are moved to the event data, and the ones that still fail are kept in
+// quarantine with the new error.
+func (ng *Engine) RetryQuarantinedLogs(input *RetryQuarantinedLogsInput) (*RetryQuarantinedLogsOutput, error) {
+	// get event record by name, signature or topic0 and its abi
+	ev, err := ng.selectEventByIdentifier(input.SmartContractAddress, input.EventName)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.RetryQuarantinedLogs ng.selectEventByIdentifier error")
+	}
+
+	// parse event definition from abi, it's the only event of the abi record
+	b, err := ev.ABI.MarshalJson()
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.RetryQuarantinedLogs ev.ABI.MarshalJson error")
+	}
+	contractABI, err := abi.JSON(strings.NewReader(fmt.Sprintf("[%s]", string(b))))
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.RetryQuarantinedLogs abi.JSON error")
+	}
+	var event abi.Event
+	found := false
+	for _, e := range contractABI.Events {
+		event = e
+		found = true
+	}
+	if !found {
+		err = fmt.Errorf("event=%s is not defined in abi", input.EventName)
+		return nil, errors.Wrap(err, "sync: Engine.RetryQuarantinedLogs error")
+	}
+
+	logs, err := ng.QuarantinedLogQuerier.SelectQuarantinedLogsQuery(ng.database, &query.SelectQuarantinedLogsQueryFilters{
+		EventID: ev.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.RetryQuarantinedLogs ng.QuarantinedLogQuerier.SelectQuarantinedLogsQuery error")
+	}
+
+	output := &RetryQuarantinedLogsOutput{
+		EventsData:      make([]*storage.EventDataRecord, 0),
+		QuarantinedLogs: make([]*storage.QuarantinedLogRecord, 0),
+	}
+	for _, ql := range logs {
+		topics, data, err := ql.RawLog()
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: Engine.RetryQuarantinedLogs ql.RawLog error")
+		}
+
+		// keep the log in quarantine with the new error when it still can't be decoded
+		decoded, decodeErr := blockchain.DecodeLog(event, topics, data)
+		if decodeErr != nil {
+			record, err := ng.QuarantinedLogQuerier.UpdateQuarantinedLogQuery(ng.database, &query.UpdateQuarantinedLogQueryInput{
+				ID:        ql.ID,
+				Error:     decodeErr.Error(),
+				UpdatedAt: input.UpdatedAt,
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "sync: Engine.RetryQuarantinedLogs ng.QuarantinedLogQuerier.UpdateQuarantinedLogQuery error")
+			}
+
+			output.QuarantinedLogs = append(output.QuarantinedLogs, record)
+			continue
+		}
+
+		// move the decoded log to the event data
+		eventData, err := ql.ToEventData(ng.idGen(), decoded, input.UpdatedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: Engine.RetryQuarantinedLogs ql.ToEventData error")
+		}
+
+		err = ng.InTransaction(func(txx *sqlx.Tx) error {
+			err := ng.EventDataQuerier.InsertEventDataQuery(txx, eventData)
+			if err != nil {
+				return errors.Wrap(err, "ng.EventDataQuerier.InsertEventDataQuery error")
+			}
+
+			err = ng.QuarantinedLogQuerier.DeleteQuarantinedLogQuery(txx, ql.ID)
+			if err != nil {
+				return errors.Wrap(err, "ng.QuarantinedLogQuerier.DeleteQuarantinedLogQuery error")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: Engine.RetryQuarantinedLogs ng.InTransaction error")
+		}
+
+		output.EventsData = append(output.EventsData, eventData)
+	}
+
+	return output, nil
+}
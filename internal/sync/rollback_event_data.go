@@ -0,0 +1,133 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type RollbackEventDataInput struct {
+	EventID         string
+	ForkBlockNumber int64
+	UpdatedAt       time.Time
+}
+
+type RollbackEventDataOutput struct {
+	Event             *storage.EventRecord
+	RemovedEventsData []*storage.EventDataRecord
+	// Siblings are the other events of the contract synced past the fork block, they're rewound
+	// with the event because the raw logs of the contract are deleted from the fork block
+	Siblings []*RollbackEventDataOutput
+}
+
+// RollbackEventData deletes the event data orphaned by a chain reorganization, that is every row
+// at or after the fork block, and rewinds the event latest block number to the last block before
+// the fork so the range is ingested again from the canonical chain. The raw logs are stored by
+// contract, so the events of the same contract synced past the fork block are rolled back too.
+// Only the block headers of the removed rows are deleted, the other contracts of the network
+// keep theirs until they detect the reorganization.
+func (ng *Engine) RollbackEventData(input *RollbackEventDataInput) (*RollbackEventDataOutput, error) {
+	var output *RollbackEventDataOutput
+	err := ng.InTransaction(func(txx *sqlx.Tx) error {
+		var err error
+		output, err = ng.rollbackEventTx(txx, input.EventID, input.ForkBlockNumber, input.UpdatedAt)
+		if err != nil {
+			return errors.Wrap(err, "ng.rollbackEventTx error")
+		}
+		event := output.Event
+
+		events, err := ng.EventQuerier.SelectEventsByAddressQuery(txx, event.Address)
+		if err != nil {
+			return errors.Wrap(err, "ng.EventQuerier.SelectEventsByAddressQuery error")
+		}
+		for _, sibling := range events {
+			if sibling.ID == event.ID || sibling.Network != event.Network || sibling.LatestBlockNumber < input.ForkBlockNumber {
+				continue
+			}
+
+			rolledBack, err := ng.rollbackEventTx(txx, sibling.ID, input.ForkBlockNumber, input.UpdatedAt)
+			if err != nil {
+				return errors.Wrap(err, "ng.rollbackEventTx error")
+			}
+			output.Siblings = append(output.Siblings, rolledBack)
+		}
+
+		// the raw logs of the orphaned blocks are ingested again with the canonical ones
+		err = ng.RawLogQuerier.DeleteRawLogsFromBlockQuery(txx, event.SmartContractAddress, input.ForkBlockNumber)
+		if err != nil {
+			return errors.Wrap(err, "ng.RawLogQuerier.DeleteRawLogsFromBlockQuery error")
+		}
+
+		// the headers of the orphaned blocks of the removed rows are stored again with the
+		// canonical ones
+		hashes := make([]string, 0)
+		seen := make(map[string]bool)
+		for _, rolledBack := range append([]*RollbackEventDataOutput{output}, output.Siblings...) {
+			for _, ed := range rolledBack.RemovedEventsData {
+				if ed.BlockHash != "" && !seen[ed.BlockHash] {
+					seen[ed.BlockHash] = true
+					hashes = append(hashes, ed.BlockHash)
+				}
+			}
+		}
+		err = ng.BlockQuerier.DeleteBlocksByHashesQuery(txx, event.Network, hashes)
+		if err != nil {
+			return errors.Wrap(err, "ng.BlockQuerier.DeleteBlocksByHashesQuery error")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.RollbackEventData ng.InTransaction error")
+	}
+
+	return output, nil
+}
+
+// rollbackEventTx deletes the event data, quarantined logs and coverage of the event from the
+// fork block, and rewinds the event to the block before the fork.
+func (ng *Engine) rollbackEventTx(txx *sqlx.Tx, eventID string, forkBlockNumber int64, updatedAt time.Time) (*RollbackEventDataOutput, error) {
+	removed, err := ng.EventDataQuerier.DeleteEventDataFromBlockQuery(txx, eventID, forkBlockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.EventDataQuerier.DeleteEventDataFromBlockQuery error")
+	}
+
+	// the quarantined logs of the orphaned blocks are ingested again too
+	err = ng.QuarantinedLogQuerier.DeleteQuarantinedLogsFromBlockQuery(txx, eventID, forkBlockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.QuarantinedLogQuerier.DeleteQuarantinedLogsFromBlockQuery error")
+	}
+
+	// the orphaned blocks aren't covered until they're ingested again
+	err = ng.CoverageQuerier.DeleteCoverageFromBlockQuery(txx, eventID, forkBlockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.CoverageQuerier.DeleteCoverageFromBlockQuery error")
+	}
+
+	latestBlockNumber := forkBlockNumber - 1
+	if latestBlockNumber < 0 {
+		latestBlockNumber = 0
+	}
+
+	// the checkpoint block was orphaned too, it is stored again when the event catches up
+	checkpointBlockNumber := int64(0)
+	checkpointBlockHash := ""
+	event, err := ng.EventQuerier.UpdateEventQuery(txx, &query.UpdateEventQueryInput{
+		ID:                    &eventID,
+		LatestBlockNumber:     &latestBlockNumber,
+		CheckpointBlockNumber: &checkpointBlockNumber,
+		CheckpointBlockHash:   &checkpointBlockHash,
+		UpdatedAt:             &updatedAt,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ng.EventQuerier.UpdateEventQuery error")
+	}
+
+	return &RollbackEventDataOutput{
+		Event:             event,
+		RemovedEventsData: removed,
+	}, nil
+}
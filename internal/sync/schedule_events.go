@@ -0,0 +1,99 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// maxEventErrors is the number of recent errors kept for each event
+const maxEventErrors = 20
+
+type ScheduleEventsInput struct {
+	EventIDs  []string
+	Failures  int
+	NextRunAt time.Time
+	// Error is the error of the run, it's stored in the error history of the events. When it's
+	// empty the run succeeded, so the error of the events is cleared and they're running again,
+	// otherwise they're marked as errored until the retry succeeds.
+	Error     string
+	UpdatedAt time.Time
+}
+
+// ScheduleEvents stores the result of a run of the events and when they run next.
+func (ng *Engine) ScheduleEvents(input *ScheduleEventsInput) error {
+	status := storage.EventStatusRunning
+	if input.Error != "" {
+		status = storage.EventStatusError
+	}
+
+	err := ng.InTransaction(func(txx *sqlx.Tx) error {
+		for _, id := range input.EventIDs {
+			id := id
+			_, err := ng.EventQuerier.UpdateEventQuery(txx, &query.UpdateEventQueryInput{
+				ID:        &id,
+				Status:    &status,
+				Error:     &input.Error,
+				Failures:  &input.Failures,
+				NextRunAt: &input.NextRunAt,
+				UpdatedAt: &input.UpdatedAt,
+			})
+			if err != nil {
+				return errors.Wrap(err, "ng.EventQuerier.UpdateEventQuery error")
+			}
+
+			if input.Error == "" {
+				continue
+			}
+			err = ng.EventErrorQuerier.InsertEventErrorQuery(txx, &storage.EventErrorRecord{
+				ID:        ng.idGen(),
+				EventID:   id,
+				Error:     input.Error,
+				Attempt:   input.Failures,
+				CreatedAt: input.UpdatedAt,
+			}, maxEventErrors)
+			if err != nil {
+				return errors.Wrap(err, "ng.EventErrorQuerier.InsertEventErrorQuery error")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "sync: Engine.ScheduleEvents ng.InTransaction error")
+	}
+
+	return nil
+}
+
+type SelectEventErrorsInput struct {
+	SmartContractAddress string
+	// EventName identifies the event by name, canonical signature or topic0
+	EventName string
+}
+
+type SelectEventErrorsOutput struct {
+	Event  *storage.EventRecord
+	Errors []*storage.EventErrorRecord
+}
+
+// SelectEventErrors returns the recent errors of the event, the latest first.
+func (ng *Engine) SelectEventErrors(input *SelectEventErrorsInput) (*SelectEventErrorsOutput, error) {
+	event, err := ng.selectEventByIdentifier(input.SmartContractAddress, input.EventName)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectEventErrors ng.selectEventByIdentifier error")
+	}
+
+	records, err := ng.EventErrorQuerier.SelectEventErrorsQuery(ng.database, event.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectEventErrors ng.EventErrorQuerier.SelectEventErrorsQuery error")
+	}
+
+	return &SelectEventErrorsOutput{
+		Event:  event,
+		Errors: records,
+	}, nil
+}
@@ -0,0 +1,28 @@
+package sync
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/pkg/errors"
+)
+
+type SelectBlockRangeWindowsInput struct {
+	Network string
+}
+
+type SelectBlockRangeWindowsOutput struct {
+	Windows []*storage.BlockRangeWindowRecord
+}
+
+func (ng *Engine) SelectBlockRangeWindows(input *SelectBlockRangeWindowsInput) (*SelectBlockRangeWindowsOutput, error) {
+	windows, err := ng.BlockRangeWindowQuerier.SelectBlockRangeWindowsQuery(ng.database, &query.SelectBlockRangeWindowsQueryFilters{
+		Network: input.Network,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectBlockRangeWindows ng.BlockRangeWindowQuerier.SelectBlockRangeWindowsQuery error")
+	}
+
+	return &SelectBlockRangeWindowsOutput{
+		Windows: windows,
+	}, nil
+}
@@ -0,0 +1,136 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/coverage"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// EventCoverage is the coverage ledger of an event with the report of its holes and overlaps.
+type EventCoverage struct {
+	Event  *storage.EventRecord
+	Ranges []*storage.CoverageRangeRecord
+	Report *coverage.Report
+}
+
+// NewEventCoverage analyzes the ranges of the event. The event is expected to cover every block
+// from its first recorded range to its checkpoint, events without ranges have an empty report.
+func NewEventCoverage(ev *storage.EventRecord, ranges []*storage.CoverageRangeRecord) *EventCoverage {
+	from := int64(0)
+	to := int64(-1)
+	recorded := make([]coverage.Range, 0)
+	for i, r := range ranges {
+		recorded = append(recorded, r.Range())
+		if i == 0 || r.FromBlockNumber < from {
+			from = r.FromBlockNumber
+		}
+	}
+	if len(ranges) > 0 {
+		to = ev.LatestBlockNumber
+	}
+
+	return &EventCoverage{
+		Event:  ev,
+		Ranges: ranges,
+		Report: coverage.Analyze(recorded, from, to),
+	}
+}
+
+type SelectCoverageInput struct {
+	UserID               string
+	SmartContractAddress string
+}
+
+type SelectCoverageOutput struct {
+	Events []*EventCoverage
+}
+
+// SelectCoverage returns the coverage ledger of every event of the contract.
+func (ng *Engine) SelectCoverage(input *SelectCoverageInput) (*SelectCoverageOutput, error) {
+	_, err := ng.selectSmartContractUser(input.UserID, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectCoverage ng.selectSmartContractUser error")
+	}
+
+	events, err := ng.EventQuerier.SelectEventsByAddressQuery(ng.database, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectCoverage ng.EventQuerier.SelectEventsByAddressQuery error")
+	}
+
+	eventIDs := make([]string, 0)
+	for _, ev := range events {
+		eventIDs = append(eventIDs, ev.ID)
+	}
+	ranges, err := ng.CoverageQuerier.SelectCoverageRangesQuery(ng.database, eventIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectCoverage ng.CoverageQuerier.SelectCoverageRangesQuery error")
+	}
+	rangesByEvent := make(map[string][]*storage.CoverageRangeRecord)
+	for _, r := range ranges {
+		rangesByEvent[r.EventID] = append(rangesByEvent[r.EventID], r)
+	}
+
+	output := &SelectCoverageOutput{
+		Events: make([]*EventCoverage, 0),
+	}
+	for _, ev := range events {
+		output.Events = append(output.Events, NewEventCoverage(ev, rangesByEvent[ev.ID]))
+	}
+
+	return output, nil
+}
+
+type CompactCoverageInput struct {
+	EventID string
+	// Ranges are replaced by their merged ranges, the ranges recorded meanwhile are kept
+	Ranges    []*storage.CoverageRangeRecord
+	CreatedAt time.Time
+}
+
+// CompactCoverage replaces the ranges of the event by the merged ones, so the ledger doesn't grow
+// with a range for each batch ingested.
+func (ng *Engine) CompactCoverage(input *CompactCoverageInput) error {
+	ids := make([]string, 0)
+	recorded := make([]coverage.Range, 0)
+	for _, r := range input.Ranges {
+		ids = append(ids, r.ID)
+		recorded = append(recorded, r.Range())
+	}
+
+	merged := make([]*storage.CoverageRangeRecord, 0)
+	for _, r := range coverage.Analyze(recorded, 0, -1).Covered {
+		merged = append(merged, &storage.CoverageRangeRecord{
+			ID:              ng.idGen(),
+			EventID:         input.EventID,
+			FromBlockNumber: r.From,
+			ToBlockNumber:   r.To,
+			Source:          storage.CoverageSourceCompacted,
+			CreatedAt:       input.CreatedAt,
+		})
+	}
+	if len(merged) == len(input.Ranges) {
+		return nil
+	}
+
+	err := ng.InTransaction(func(txx *sqlx.Tx) error {
+		err := ng.CoverageQuerier.DeleteCoverageRangesQuery(txx, ids)
+		if err != nil {
+			return errors.Wrap(err, "ng.CoverageQuerier.DeleteCoverageRangesQuery error")
+		}
+
+		err = ng.CoverageQuerier.InsertCoverageRangeBatchQuery(txx, merged)
+		if err != nil {
+			return errors.Wrap(err, "ng.CoverageQuerier.InsertCoverageRangeBatchQuery error")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "sync: Engine.CompactCoverage ng.InTransaction error")
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package sync
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrEventNotFound      = errors.New("no event found")
+	ErrEventNameAmbiguous = errors.New("event name matches more than one event, use the event signature or topic0")
+
+	topicHashRegexp = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+)
+
+// eventIdentifierFilters returns the filters to find the events of a contract by an identifier,
+// that could be the event topic0 hash, the canonical signature like Transfer(address,address,uint256),
+// or the event name.
+func eventIdentifierFilters(address string, identifier string) *query.SelectEventsQueryFilters {
+	filters := &query.SelectEventsQueryFilters{
+		SmartContractAddress: address,
+	}
+
+	switch {
+	case topicHashRegexp.MatchString(identifier):
+		filters.EventTopic0 = strings.ToLower(identifier)
+	case strings.Contains(identifier, "("):
+		filters.EventSignature = strings.ReplaceAll(identifier, " ", "")
+	default:
+		filters.EventName = identifier
+	}
+
+	return filters
+}
+
+// selectEventByIdentifier returns the event of the contract with its abi. Overloaded events share
+// the name, so an error is returned when the name isn't enough to identify one event.
+func (ng *Engine) selectEventByIdentifier(address string, identifier string) (*storage.EventRecord, error) {
+	events, err := ng.EventQuerier.SelectEventsQuery(ng.database, eventIdentifierFilters(address, identifier))
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.selectEventByIdentifier ng.EventQuerier.SelectEventsQuery error")
+	}
+	if len(events) == 0 {
+		return nil, errors.Wrap(ErrEventNotFound, "sync: Engine.selectEventByIdentifier error")
+	}
+	if len(events) > 1 {
+		signatures := make([]string, 0)
+		for _, ev := range events {
+			signatures = append(signatures, ev.Signature)
+		}
+
+		err = errors.Wrap(ErrEventNameAmbiguous, fmt.Sprintf("event_name=%s signatures=%s", identifier, strings.Join(signatures, ",")))
+		return nil, errors.Wrap(err, "sync: Engine.selectEventByIdentifier error")
+	}
+	event := events[0]
+
+	// select abi of the event
+	abis, err := ng.ABIQuerier.SelectABIByIDs(ng.database, []string{event.AbiID})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.selectEventByIdentifier ng.ABIQuerier.SelectABIByIDs error")
+	}
+	if len(abis) == 0 {
+		err = errors.New("no abi found")
+		return nil, errors.Wrap(err, "sync: Engine.selectEventByIdentifier ng.ABIQuerier.SelectABIByIDs error")
+	}
+	event.ABI = abis[0]
+
+	return event, nil
+}
@@ -1,6 +1,8 @@
 package sync
 
 import (
+	"time"
+
 	"github.com/darchlabs/synchronizer-v2/internal/pagination"
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
 	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
@@ -8,9 +10,15 @@ import (
 )
 
 type SelectEventDataInput struct {
+	// UserID selects the filtered view of the user, when it filters the event
+	UserID               string
 	SmartContractAddress string
-	EventName            string
-	Pagination           *pagination.Pagination
+	// EventName identifies the event by name, canonical signature or topic0
+	EventName string
+	// FromTime and ToTime filter the event data by block timestamp, both are optional
+	FromTime   *time.Time
+	ToTime     *time.Time
+	Pagination *pagination.Pagination
 }
 
 type SelectEventDataOutput struct {
@@ -20,46 +28,38 @@ type SelectEventDataOutput struct {
 }
 
 func (ng *Engine) SelectEventData(input *SelectEventDataInput) (*SelectEventDataOutput, error) {
-	// Select events by status
-	eventsData, err := ng.EventDataQuerier.SelectEventDataQuery(ng.database, &query.SelectEventDataQueryFilters{
-		SmartContractAddress: input.SmartContractAddress,
-		EventName:            input.EventName,
-		Pagination:           input.Pagination,
-	})
+	// get event record by name, signature or topic0
+	event, err := ng.selectEventByIdentifier(input.SmartContractAddress, input.EventName)
 	if err != nil {
-		return nil, errors.Wrap(err, "sync: Engine.SelectEventData ng.eventQuerier.SelectEventDataQuery error")
+		return nil, errors.Wrap(err, "sync: Engine.SelectEventData ng.selectEventByIdentifier error")
 	}
 
-	// get event record
-	events, err := ng.EventQuerier.SelectEventsQuery(ng.database, &query.SelectEventsQueryFilters{
-		SmartContractAddress: input.SmartContractAddress,
-		EventName:            input.EventName,
-	})
+	// get the topic filter of the user
+	topicFilter, err := ng.selectUserTopicFilter(input.UserID, input.SmartContractAddress, event)
 	if err != nil {
-		return nil, errors.Wrap(err, "sync: Engine.SelectEventData ng.eventQuerier.SelectEventsQuery error")
-	}
-	if len(events) == 0 {
-		err = errors.New("no event found")
-		return nil, errors.Wrap(err, "sync: Engine.SelectEventData ng.eventQuerier.SelectEventsQuery error")
+		return nil, errors.Wrap(err, "sync: Engine.SelectEventData ng.selectUserTopicFilter error")
 	}
 
-	// Select abi from an id list
-	abi, err := ng.ABIQuerier.SelectABIByIDs(ng.database, []string{events[0].AbiID})
+	// Select event data of the event
+	eventsData, err := ng.EventDataQuerier.SelectEventDataQuery(ng.database, &query.SelectEventDataQueryFilters{
+		EventID:     event.ID,
+		FromTime:    input.FromTime,
+		ToTime:      input.ToTime,
+		TopicFilter: topicFilter,
+		Pagination:  input.Pagination,
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "sync: Engine.SelectEventData ng.eventQuerier.SelectABIByIDs error")
-	}
-	if len(abi) == 0 {
-		err = errors.New("no abi found")
-		return nil, errors.Wrap(err, "sync: Engine.SelectEventData ng.eventQuerier.SelectABIByIDs error")
+		return nil, errors.Wrap(err, "sync: Engine.SelectEventData ng.eventQuerier.SelectEventDataQuery error")
 	}
-	events[0].ABI = abi[0]
 
 	// Count total elements if pagination is defined
 	var totalElements int64
 	if input.Pagination != nil {
 		totalElements, err = ng.EventDataQuerier.SelectCountEventDataQuery(ng.database, &query.SelectCountEventDataQueryFilters{
-			SmartContractAddress: input.SmartContractAddress,
-			EventName:            input.EventName,
+			EventID:     event.ID,
+			FromTime:    input.FromTime,
+			ToTime:      input.ToTime,
+			TopicFilter: topicFilter,
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "sync: Engine.SelectEventData ng.eventQuerier.SelectCountEventDataQuery error")
@@ -68,7 +68,7 @@ func (ng *Engine) SelectEventData(input *SelectEventDataInput) (*SelectEventData
 
 	return &SelectEventDataOutput{
 		EventsData:    eventsData,
-		Event:         events[0],
+		Event:         event,
 		TotalElements: totalElements,
 	}, nil
 }
@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/pkg/errors"
+)
+
+type SelectEventDataMetricsInput struct {
+	// UserID counts the filtered view of the user, when it filters the event
+	UserID               string
+	SmartContractAddress string
+	// EventName identifies the event by name, canonical signature or topic0
+	EventName string
+	FromTime  time.Time
+	ToTime    time.Time
+	// Interval is the size of the buckets in seconds
+	Interval int64
+}
+
+type SelectEventDataMetricsOutput struct {
+	Event   *storage.EventRecord
+	Buckets []*storage.EventDataBucketRecord
+}
+
+// SelectEventDataMetrics counts the event data of an event by buckets of block time. Buckets
+// without event data aren't returned.
+func (ng *Engine) SelectEventDataMetrics(input *SelectEventDataMetricsInput) (*SelectEventDataMetricsOutput, error) {
+	if input.Interval <= 0 {
+		return nil, errors.New("sync: Engine.SelectEventDataMetrics invalid interval error")
+	}
+
+	// get event record by name, signature or topic0
+	event, err := ng.selectEventByIdentifier(input.SmartContractAddress, input.EventName)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectEventDataMetrics ng.selectEventByIdentifier error")
+	}
+
+	// get the topic filter of the user
+	topicFilter, err := ng.selectUserTopicFilter(input.UserID, input.SmartContractAddress, event)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectEventDataMetrics ng.selectUserTopicFilter error")
+	}
+
+	buckets, err := ng.EventDataQuerier.SelectEventDataBucketsQuery(ng.database, &query.SelectEventDataBucketsQueryFilters{
+		EventID:     event.ID,
+		FromTime:    input.FromTime,
+		ToTime:      input.ToTime,
+		Interval:    input.Interval,
+		TopicFilter: topicFilter,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectEventDataMetrics ng.EventDataQuerier.SelectEventDataBucketsQuery error")
+	}
+
+	return &SelectEventDataMetricsOutput{
+		Event:   event,
+		Buckets: buckets,
+	}, nil
+}
@@ -10,6 +10,7 @@ import (
 type SelectEventsAndABIInput struct {
 	SmartContractAddress string
 	EventStatus          string
+	EventStatuses        []string
 	Pagination           *pagination.Pagination
 }
 
@@ -22,6 +23,7 @@ func (ng *Engine) SelectEventsAndABI(input *SelectEventsAndABIInput) (*SelectEve
 	// Select events by status
 	events, err := ng.EventQuerier.SelectEventsQuery(ng.database, &query.SelectEventsQueryFilters{
 		Status:               input.EventStatus,
+		Statuses:             input.EventStatuses,
 		SmartContractAddress: input.SmartContractAddress,
 		Pagination:           input.Pagination,
 	})
@@ -55,6 +57,16 @@ func (ng *Engine) SelectEventsAndABI(input *SelectEventsAndABIInput) (*SelectEve
 		return nil, errors.Wrap(err, "sync: Engine.SelectEventsAndABI ng.SmartContractQuerier.SmartContractUsersByIDListQuery error")
 	}
 
+	// Select the factory templates of the events
+	eventIDs := make([]string, 0)
+	for _, ev := range events {
+		eventIDs = append(eventIDs, ev.ID)
+	}
+	templates, err := ng.FactoryTemplateQuerier.SelectFactoryTemplatesByEventIDsQuery(ng.database, eventIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectEventsAndABI ng.FactoryTemplateQuerier.SelectFactoryTemplatesByEventIDsQuery error")
+	}
+
 	// mapping of abis and smart contracts
 	abiMap := make(map[string]*storage.ABIRecord)
 	for _, a := range abi {
@@ -71,12 +83,17 @@ func (ng *Engine) SelectEventsAndABI(input *SelectEventsAndABIInput) (*SelectEve
 		}
 		scuMap[scu.SmartContractAddress] = append(scuMap[scu.SmartContractAddress], scu)
 	}
+	templateMap := make(map[string]*storage.FactoryTemplateRecord)
+	for _, ft := range templates {
+		templateMap[ft.EventID] = ft
+	}
 
 	// Link abi ans sc with events
 	for _, ev := range events {
 		ev.ABI = abiMap[ev.AbiID]
 		ev.SmartContract = scMap[ev.SmartContractAddress]
 		ev.SmartContractUsers = scuMap[ev.SmartContractAddress]
+		ev.FactoryTemplate = templateMap[ev.ID]
 	}
 
 	// Count total elements if pagination is defined
@@ -84,6 +101,7 @@ func (ng *Engine) SelectEventsAndABI(input *SelectEventsAndABIInput) (*SelectEve
 	if input.Pagination != nil {
 		totalElements, err = ng.EventQuerier.SelectCountEventsQuery(ng.database, &query.SelectEventsQueryFilters{
 			Status:               input.EventStatus,
+			Statuses:             input.EventStatuses,
 			SmartContractAddress: input.SmartContractAddress,
 		})
 		if err != nil {
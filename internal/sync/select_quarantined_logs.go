@@ -0,0 +1,52 @@
+package sync
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/pkg/errors"
+)
+
+type SelectQuarantinedLogsInput struct {
+	SmartContractAddress string
+	// EventName identifies the event by name, canonical signature or topic0
+	EventName  string
+	Pagination *pagination.Pagination
+}
+
+type SelectQuarantinedLogsOutput struct {
+	QuarantinedLogs []*storage.QuarantinedLogRecord
+	TotalElements   int64
+}
+
+func (ng *Engine) SelectQuarantinedLogs(input *SelectQuarantinedLogsInput) (*SelectQuarantinedLogsOutput, error) {
+	// get event record by name, signature or topic0
+	event, err := ng.selectEventByIdentifier(input.SmartContractAddress, input.EventName)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectQuarantinedLogs ng.selectEventByIdentifier error")
+	}
+
+	logs, err := ng.QuarantinedLogQuerier.SelectQuarantinedLogsQuery(ng.database, &query.SelectQuarantinedLogsQueryFilters{
+		EventID:    event.ID,
+		Pagination: input.Pagination,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectQuarantinedLogs ng.QuarantinedLogQuerier.SelectQuarantinedLogsQuery error")
+	}
+
+	// Count total elements if pagination is defined
+	var totalElements int64
+	if input.Pagination != nil {
+		totalElements, err = ng.QuarantinedLogQuerier.SelectCountQuarantinedLogsQuery(ng.database, &query.SelectCountQuarantinedLogsQueryFilters{
+			EventID: event.ID,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: Engine.SelectQuarantinedLogs ng.QuarantinedLogQuerier.SelectCountQuarantinedLogsQuery error")
+		}
+	}
+
+	return &SelectQuarantinedLogsOutput{
+		QuarantinedLogs: logs,
+		TotalElements:   totalElements,
+	}, nil
+}
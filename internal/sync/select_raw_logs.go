@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/pkg/errors"
+)
+
+type SelectRawLogsInput struct {
+	UserID               string
+	SmartContractAddress string
+	Pagination           *pagination.Pagination
+}
+
+type SelectRawLogsOutput struct {
+	RawLogs       []*DecodedRawLog
+	TotalElements int64
+}
+
+// SelectRawLogs returns the raw logs of a contract in raw logs mode, each one decoded with
+// the event of the abi it belongs to when the abi defines it.
+func (ng *Engine) SelectRawLogs(input *SelectRawLogsInput) (*SelectRawLogsOutput, error) {
+	_, err := ng.selectSmartContractUser(input.UserID, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectRawLogs ng.selectSmartContractUser error")
+	}
+
+	events, err := ng.EventQuerier.SelectEventsByAddressQuery(ng.database, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectRawLogs ng.EventQuerier.SelectEventsByAddressQuery error")
+	}
+
+	abis, err := ng.ABIQuerier.SelectABIByAddressQuery(ng.database, input.SmartContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectRawLogs ng.ABIQuerier.SelectABIByAddressQuery error")
+	}
+
+	decoder, err := newRawLogDecoder(events, abis)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectRawLogs newRawLogDecoder error")
+	}
+
+	rawLogs, err := ng.RawLogQuerier.SelectRawLogsQuery(ng.database, &query.SelectRawLogsQueryFilters{
+		SmartContractAddress: input.SmartContractAddress,
+		Pagination:           input.Pagination,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectRawLogs ng.RawLogQuerier.SelectRawLogsQuery error")
+	}
+
+	// decode the raw logs with the events known now
+	decoded := make([]*DecodedRawLog, 0)
+	for _, rl := range rawLogs {
+		d, err := decoder.decode(rl)
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: Engine.SelectRawLogs decoder.decode error")
+		}
+		decoded = append(decoded, d)
+	}
+
+	// Count total elements if pagination is defined
+	var totalElements int64
+	if input.Pagination != nil {
+		totalElements, err = ng.RawLogQuerier.SelectCountRawLogsQuery(ng.database, &query.SelectRawLogsQueryFilters{
+			SmartContractAddress: input.SmartContractAddress,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: Engine.SelectRawLogs ng.RawLogQuerier.SelectCountRawLogsQuery error")
+		}
+	}
+
+	return &SelectRawLogsOutput{
+		RawLogs:       decoded,
+		TotalElements: totalElements,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package sync
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/pkg/errors"
+)
+
+type SelectSignatureEventDataInput struct {
+	UserID         string
+	SubscriptionID string
+	// Address is the emitter of the logs, the logs of every address are returned when it's empty
+	Address    string
+	Pagination *pagination.Pagination
+}
+
+type SelectSignatureEventDataOutput struct {
+	SignatureSubscription *storage.SignatureSubscriptionRecord
+	EventData             []*storage.SignatureEventDataRecord
+	TotalElements         int64
+}
+
+// SelectSignatureEventData returns the logs ingested by the signature subscription of the user.
+func (ng *Engine) SelectSignatureEventData(input *SelectSignatureEventDataInput) (*SelectSignatureEventDataOutput, error) {
+	subscription, err := ng.selectSignatureSubscription(input.UserID, input.SubscriptionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectSignatureEventData ng.selectSignatureSubscription error")
+	}
+
+	filters := &query.SelectSignatureEventDataQueryFilters{
+		SubscriptionID: subscription.ID,
+		Address:        input.Address,
+		Pagination:     input.Pagination,
+	}
+	eventData, err := ng.SignatureSubscriptionQuerier.SelectSignatureEventDataQuery(ng.database, filters)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.SelectSignatureEventData ng.SignatureSubscriptionQuerier.SelectSignatureEventDataQuery error")
+	}
+
+	// Count total elements if pagination is defined
+	var totalElements int64
+	if input.Pagination != nil {
+		totalElements, err = ng.SignatureSubscriptionQuerier.SelectCountSignatureEventDataQuery(ng.database, filters)
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: Engine.SelectSignatureEventData ng.SignatureSubscriptionQuerier.SelectCountSignatureEventDataQuery error")
+		}
+	}
+
+	return &SelectSignatureEventDataOutput{
+		SignatureSubscription: subscription,
+		EventData:             eventData,
+		TotalElements:         totalElements,
+	}, nil
+}
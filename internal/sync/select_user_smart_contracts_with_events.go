@@ -8,7 +8,9 @@ import (
 )
 
 type SelectUserSmartContractsWithEventsInput struct {
-	UserID     string
+	UserID string
+	// Template filters the contracts registered with the built-in abi of a standard interface
+	Template   string
 	Pagination *pagination.Pagination
 }
 
@@ -19,7 +21,7 @@ type SelectUserSmartContractsWithEventsOutput struct {
 
 func (ng *Engine) SelectUserSmartContractsWithEvents(input *SelectUserSmartContractsWithEventsInput) (*SelectUserSmartContractsWithEventsOutput, error) {
 	// Select user smart contracts
-	smartContracts, err := ng.SmartContractQuerier.SelectUserSmartContractsQuery(ng.database, input.UserID, input.Pagination)
+	smartContracts, err := ng.SmartContractQuerier.SelectUserSmartContractsQuery(ng.database, input.UserID, input.Template, input.Pagination)
 	if err != nil {
 		return nil, errors.Wrap(err, "sync: Engine.SelectUserSmartContractsWithEvents ng.SmartContractQuerier.SelectUserSmartContractsQuery error")
 	}
@@ -51,7 +53,7 @@ func (ng *Engine) SelectUserSmartContractsWithEvents(input *SelectUserSmartContr
 	}
 
 	// Get the total count of user's smart contracts
-	totalElements, err := ng.SmartContractQuerier.SelectCountUserSmartContractsQuery(ng.database, input.UserID)
+	totalElements, err := ng.SmartContractQuerier.SelectCountUserSmartContractsQuery(ng.database, input.UserID, input.Template)
 	if err != nil {
 		return nil, errors.Wrap(err, "sync: Engine.SelectUserSmartContractsWithEvents ng.SmartContractQuerier.CountUserSmartContractsQuery error")
 	}
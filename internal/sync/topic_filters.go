@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidTopicFilter = errors.New("invalid topic filter")
+
+// buildTopicFilters builds the topic filters of a smart contract user from the allowed values
+// of the indexed arguments by event. Events are identified by name, canonical signature or
+// topic0 like in the rest of the api.
+func buildTopicFilters(abis []*storage.ABIRecord, filters map[string]map[string][]string) (storage.TopicFilters, error) {
+	topicFilters := make(storage.TopicFilters)
+
+	for identifier, args := range filters {
+		if len(args) == 0 {
+			continue
+		}
+
+		matches, signatures, err := matchABIEvents(abis, identifier)
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: buildTopicFilters matchABIEvents error")
+		}
+		if len(matches) == 0 {
+			err := errors.Wrap(ErrInvalidTopicFilter, fmt.Sprintf("event=%s isn't defined in abi", identifier))
+			return nil, errors.Wrap(err, "sync: buildTopicFilters error")
+		}
+		if len(matches) > 1 {
+			err := errors.Wrap(ErrEventNameAmbiguous, fmt.Sprintf("event_name=%s signatures=%s", identifier, strings.Join(signatures, ",")))
+			return nil, errors.Wrap(err, "sync: buildTopicFilters error")
+		}
+
+		event, err := matches[0].Event()
+		if err != nil {
+			return nil, errors.Wrap(err, "sync: buildTopicFilters matches[0].Event error")
+		}
+
+		filter, err := blockchain.NewTopicFilter(event, args)
+		if err != nil {
+			err = errors.Wrap(ErrInvalidTopicFilter, err.Error())
+			return nil, errors.Wrap(err, "sync: buildTopicFilters blockchain.NewTopicFilter error")
+		}
+
+		topicFilters[signatures[0]] = storage.NewEventTopicFilter(args, filter, event.Anonymous)
+	}
+
+	return topicFilters, nil
+}
+
+// matchABIEvents returns the events of the abi identified by name, canonical signature or
+// topic0, and their signatures.
+func matchABIEvents(abis []*storage.ABIRecord, identifier string) ([]*storage.ABIRecord, []string, error) {
+	matches := make([]*storage.ABIRecord, 0)
+	signatures := make([]string, 0)
+	for _, a := range abis {
+		if a.Type != "event" {
+			continue
+		}
+
+		signature, topic0, err := a.EventSignature()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "sync: matchABIEvents a.EventSignature error")
+		}
+
+		if a.Name == identifier || signature == strings.ReplaceAll(identifier, " ", "") || strings.EqualFold(topic0.Hex(), identifier) {
+			matches = append(matches, a)
+			signatures = append(signatures, signature)
+		}
+	}
+
+	return matches, signatures, nil
+}
+
+// selectUserTopicFilter returns the topic filter of the event for the user of the contract, it's
+// nil when the user doesn't filter the event.
+func (ng *Engine) selectUserTopicFilter(userID string, address string, event *storage.EventRecord) (*storage.EventTopicFilter, error) {
+	if userID == "" {
+		return nil, nil
+	}
+
+	scUsers, err := ng.SmartContractUserQuerier.SelectSmartContractUserQuery(ng.database, address)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.selectUserTopicFilter ng.SmartContractUserQuerier.SelectSmartContractUserQuery error")
+	}
+
+	for _, scu := range scUsers {
+		if scu.UserID == userID {
+			return scu.TopicFilters[event.Signature], nil
+		}
+	}
+
+	return nil, nil
+}
@@ -1,6 +1,8 @@
 package sync
 
 import (
+	"time"
+
 	"github.com/darchlabs/synchronizer-v2/internal/pagination"
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
 	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
@@ -11,8 +13,8 @@ type SmartContractQuerier interface {
 	InsertSmartContractQuery(storage.QueryContext, *storage.SmartContractRecord) error
 	SelectSmartContractByAddressQuery(storage.Transaction, string) (*storage.SmartContractRecord, error)
 	SelectSmartContractsByAddressesList(tx storage.Transaction, addresses []string) ([]*storage.SmartContractRecord, error)
-	SelectUserSmartContractsQuery(tx storage.Transaction, userID string, p *pagination.Pagination) ([]*query.UserSmartContractOutput, error)
-	SelectCountUserSmartContractsQuery(db storage.Database, userID string) (int64, error)
+	SelectUserSmartContractsQuery(tx storage.Transaction, userID string, template string, p *pagination.Pagination) ([]*query.UserSmartContractOutput, error)
+	SelectCountUserSmartContractsQuery(db storage.Database, userID string, template string) (int64, error)
 }
 
 // ABI
@@ -48,4 +50,79 @@ type EventDataQuerier interface {
 	InsertEventDataBatchQuery(storage.Transaction, []*storage.EventDataRecord) error
 	SelectCountEventDataQuery(tx storage.Transaction, input *query.SelectCountEventDataQueryFilters) (int64, error)
 	SelectEventDataQuery(tx storage.Transaction, input *query.SelectEventDataQueryFilters) ([]*storage.EventDataRecord, error)
+	SelectEventDataBlocksQuery(tx storage.Transaction, eventID string, fromBlockNumber int64) ([]*storage.EventDataBlockRecord, error)
+	SelectEventDataBucketsQuery(tx storage.Transaction, input *query.SelectEventDataBucketsQueryFilters) ([]*storage.EventDataBucketRecord, error)
+	DeleteEventDataFromBlockQuery(tx storage.Transaction, eventID string, fromBlockNumber int64) ([]*storage.EventDataRecord, error)
+}
+
+type BlockRangeWindowQuerier interface {
+	UpsertBlockRangeWindowQuery(storage.Transaction, *storage.BlockRangeWindowRecord) error
+	SelectBlockRangeWindowQuery(tx storage.Transaction, nodeURL string, network storage.EventNetwork) (*storage.BlockRangeWindowRecord, error)
+	SelectBlockRangeWindowsQuery(storage.Transaction, *query.SelectBlockRangeWindowsQueryFilters) ([]*storage.BlockRangeWindowRecord, error)
+}
+
+type BlockQuerier interface {
+	InsertBlockBatchQuery(storage.Transaction, []*storage.BlockRecord) error
+	DeleteBlocksByHashesQuery(tx storage.Transaction, network storage.EventNetwork, hashes []string) error
+}
+
+type QuarantinedLogQuerier interface {
+	InsertQuarantinedLogQuery(storage.QueryContext, *storage.QuarantinedLogRecord) error
+	SelectQuarantinedLogsQuery(storage.Transaction, *query.SelectQuarantinedLogsQueryFilters) ([]*storage.QuarantinedLogRecord, error)
+	SelectCountQuarantinedLogsQuery(storage.Transaction, *query.SelectCountQuarantinedLogsQueryFilters) (int64, error)
+	UpdateQuarantinedLogQuery(storage.Transaction, *query.UpdateQuarantinedLogQueryInput) (*storage.QuarantinedLogRecord, error)
+	DeleteQuarantinedLogQuery(tx storage.Transaction, id string) error
+	DeleteQuarantinedLogsFromBlockQuery(tx storage.Transaction, eventID string, fromBlockNumber int64) error
+}
+
+type RawLogQuerier interface {
+	InsertRawLogBatchQuery(storage.Transaction, []*storage.RawLogRecord) error
+	SelectRawLogsQuery(storage.Transaction, *query.SelectRawLogsQueryFilters) ([]*storage.RawLogRecord, error)
+	SelectCountRawLogsQuery(storage.Transaction, *query.SelectRawLogsQueryFilters) (int64, error)
+	DeleteRawLogsFromBlockQuery(tx storage.Transaction, scAddress string, fromBlockNumber int64) error
+}
+
+type FactoryTemplateQuerier interface {
+	UpsertFactoryTemplateQuery(storage.Transaction, *storage.FactoryTemplateRecord) error
+	SelectFactoryTemplatesQuery(tx storage.Transaction, scAddress string) ([]*storage.FactoryTemplateRecord, error)
+	SelectFactoryTemplatesByEventIDsQuery(tx storage.Transaction, eventIDs []string) ([]*storage.FactoryTemplateRecord, error)
+}
+
+type SignatureSubscriptionQuerier interface {
+	InsertSignatureSubscriptionQuery(storage.Transaction, *storage.SignatureSubscriptionRecord) error
+	SelectSignatureSubscriptionsQuery(storage.Transaction, *query.SelectSignatureSubscriptionsQueryFilters) ([]*storage.SignatureSubscriptionRecord, error)
+	SelectSignatureSubscriptionQuery(tx storage.Transaction, id string) (*storage.SignatureSubscriptionRecord, error)
+	UpdateSignatureSubscriptionQuery(storage.Transaction, *query.UpdateSignatureSubscriptionQueryInput) (*storage.SignatureSubscriptionRecord, error)
+	InsertSignatureEventDataBatchQuery(storage.Transaction, []*storage.SignatureEventDataRecord) error
+	InsertSignatureQuarantinedLogBatchQuery(storage.Transaction, []*storage.SignatureQuarantinedLogRecord) error
+	SelectSignatureEventDataQuery(storage.Transaction, *query.SelectSignatureEventDataQueryFilters) ([]*storage.SignatureEventDataRecord, error)
+	SelectCountSignatureEventDataQuery(storage.Transaction, *query.SelectSignatureEventDataQueryFilters) (int64, error)
+}
+
+type BackfillQuerier interface {
+	InsertBackfillJobQuery(storage.Transaction, *storage.BackfillJobRecord) error
+	InsertBackfillChunkBatchQuery(storage.Transaction, []*storage.BackfillChunkRecord) error
+	SelectBackfillJobsQuery(storage.Transaction, *query.SelectBackfillJobsQueryFilters) ([]*storage.BackfillJobRecord, error)
+	SelectBackfillJobQuery(tx storage.Transaction, id string) (*storage.BackfillJobRecord, error)
+	SelectBackfillChunksQuery(tx storage.Transaction, jobIDs []string) ([]*storage.BackfillChunkRecord, error)
+	UpdateBackfillJobQuery(storage.Transaction, *query.UpdateBackfillJobQueryInput) (*storage.BackfillJobRecord, error)
+	UpdateBackfillChunkQuery(storage.Transaction, *query.UpdateBackfillChunkQueryInput) (*storage.BackfillChunkRecord, error)
+}
+
+type CoverageQuerier interface {
+	InsertCoverageRangeBatchQuery(storage.Transaction, []*storage.CoverageRangeRecord) error
+	SelectCoverageRangesQuery(tx storage.Transaction, eventIDs []string) ([]*storage.CoverageRangeRecord, error)
+	DeleteCoverageFromBlockQuery(tx storage.Transaction, eventID string, fromBlockNumber int64) error
+	DeleteCoverageRangesQuery(tx storage.Transaction, ids []string) error
+}
+
+type EventErrorQuerier interface {
+	InsertEventErrorQuery(tx storage.Transaction, record *storage.EventErrorRecord, keep int) error
+	SelectEventErrorsQuery(tx storage.Transaction, eventID string) ([]*storage.EventErrorRecord, error)
+}
+
+type LeaseQuerier interface {
+	AcquireLeaseQuery(tx storage.Transaction, name string, holder string, ttl time.Duration) (*storage.LeaseRecord, error)
+	ReleaseLeaseQuery(tx storage.Transaction, name string, holder string) error
+	SelectLeasesQuery(tx storage.Transaction, prefix string) ([]*storage.LeaseRecord, error)
 }
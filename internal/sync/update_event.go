@@ -16,6 +16,8 @@ type UpdateEventInput struct {
 	NodeURL              *string
 	Address              *string
 	LatestBlockNumber    *int64
+	ObservedBlockNumber  *int64
+	FinalizedBlockNumber *int64
 	SmartContractAddress *string
 	Status               *storage.EventStatus
 	Error                *string
@@ -31,6 +33,8 @@ func (ng *Engine) UpdateEvent(input *UpdateEventInput) (*storage.EventRecord, er
 		NodeURL:              input.NodeURL,
 		Address:              input.Address,
 		LatestBlockNumber:    input.LatestBlockNumber,
+		ObservedBlockNumber:  input.ObservedBlockNumber,
+		FinalizedBlockNumber: input.FinalizedBlockNumber,
 		SmartContractAddress: input.SmartContractAddress,
 		Status:               input.Status,
 		Error:                input.Error,
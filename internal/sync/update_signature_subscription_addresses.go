@@ -0,0 +1,80 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync/query"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type UpdateSignatureSubscriptionAddressesInput struct {
+	UserID string
+	ID     string
+	// Addresses replace the address set of the subscription
+	Addresses []string
+	UpdatedAt time.Time
+}
+
+type UpdateSignatureSubscriptionAddressesOutput struct {
+	SignatureSubscription *storage.SignatureSubscriptionRecord
+}
+
+// UpdateSignatureSubscriptionAddresses replaces the address set of the subscription. The
+// checkpoint of the subscription is kept, and the logs of the new addresses before it are
+// backfilled from its FromBlockNumber, so the addresses already synced aren't requested again.
+func (ng *Engine) UpdateSignatureSubscriptionAddresses(input *UpdateSignatureSubscriptionAddressesInput) (*UpdateSignatureSubscriptionAddressesOutput, error) {
+	subscription, err := ng.selectSignatureSubscription(input.UserID, input.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.UpdateSignatureSubscriptionAddresses ng.selectSignatureSubscription error")
+	}
+	if subscription.AllAddresses {
+		err = errors.Wrap(ErrInvalidSignatureSubscription, "the subscription of every address doesn't have address set")
+		return nil, errors.Wrap(err, "sync: Engine.UpdateSignatureSubscriptionAddresses error")
+	}
+
+	addresses, err := normalizeAddresses(input.Addresses)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.UpdateSignatureSubscriptionAddresses normalizeAddresses error")
+	}
+
+	update := &query.UpdateSignatureSubscriptionQueryInput{
+		ID:        &subscription.ID,
+		Addresses: &addresses,
+		UpdatedAt: &input.UpdatedAt,
+	}
+
+	// the pending backfill keeps the addresses that weren't removed, and the new ones start it
+	// again up to the checkpoint of the subscription
+	backfill := make(pq.StringArray, 0)
+	added := false
+	for _, address := range addresses {
+		if !subscription.HasAddress(address) {
+			added = true
+			backfill = append(backfill, address)
+			continue
+		}
+		if subscription.IsBackfilling(address) {
+			backfill = append(backfill, address)
+		}
+	}
+	update.BackfillAddresses = &backfill
+	if added {
+		toBlockNumber := subscription.LatestBlockNumber
+		if len(subscription.BackfillAddresses) > 0 && subscription.BackfillToBlockNumber > toBlockNumber {
+			toBlockNumber = subscription.BackfillToBlockNumber
+		}
+		update.BackfillToBlockNumber = &toBlockNumber
+		update.BackfillLatestBlockNumber = &subscription.FromBlockNumber
+	}
+
+	subscription, err = ng.SignatureSubscriptionQuerier.UpdateSignatureSubscriptionQuery(ng.database, update)
+	if err != nil {
+		return nil, errors.Wrap(err, "sync: Engine.UpdateSignatureSubscriptionAddresses ng.SignatureSubscriptionQuerier.UpdateSignatureSubscriptionQuery error")
+	}
+
+	return &UpdateSignatureSubscriptionAddressesOutput{
+		SignatureSubscription: subscription,
+	}, nil
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/darchlabs/synchronizer-v2/pkg/smartcontract"
-	"github.com/darchlabs/synchronizer-v2/pkg/util"
 )
 
 func checkAndGetApis(contract *smartcontract.SmartContract, networksApiUrls map[string]string, networksApiKeys map[string]string) (string, string, error) {
@@ -22,27 +21,3 @@ func checkAndGetApis(contract *smartcontract.SmartContract, networksApiUrls map[
 
 	return etherscanApiURL, etherscanApiKey, nil
 }
-
-func checkAndGetNodeURL(contract *smartcontract.SmartContract, networksNodeUrl map[string]string) (string, error) {
-	// Get contract network
-	contractNewtork := string(contract.Network)
-
-	// Check if the contract node url is valid
-	err := util.NodeURLIsValid(contract.NodeURL, contractNewtork)
-	if err != nil {
-		// If it is not valid, check the backend node url (obtained from the env)
-		networkNodeUrl := networksNodeUrl[contractNewtork]
-		err := util.NodeURLIsValid(networkNodeUrl, contractNewtork)
-		if err != nil {
-			// If the backend node url is not valid, return err since both nodes are bad
-			return "", err
-		}
-
-		// return the backend node url if it is valid
-		return networkNodeUrl, nil
-
-	}
-
-	// return the contract node url if it is valid
-	return contract.NodeURL, nil
-}
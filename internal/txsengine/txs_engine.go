@@ -5,20 +5,21 @@ import (
 	"log"
 	"math"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/darchlabs/synchronizer-v2"
+	"github.com/darchlabs/synchronizer-v2/internal/chainhead"
 	ethclientrate "github.com/darchlabs/synchronizer-v2/internal/ethclient_rate"
+	"github.com/darchlabs/synchronizer-v2/internal/rpcpool"
 	"github.com/darchlabs/synchronizer-v2/pkg/smartcontract"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type idGenerator func() string
 
 type TxsEngine interface {
-	Start(seconds int64)
-	Run() error
-	Halt()
+	Run(ctx context.Context) error
+	RunOnce() error
 	GetStatus() StatusEngine
 	SetStatus(status StatusEngine)
 	GetContractTransactions(contractId string, apiUrl string, apiKey string) error
@@ -27,11 +28,14 @@ type TxsEngine interface {
 type T struct {
 	smartContractStorage    synchronizer.SmartContractStorage
 	transactionStorage      synchronizer.TransactionStorage
+	mu                      sync.Mutex
 	status                  StatusEngine
+	seconds                 int64
 	idGen                   idGenerator
 	networksEtherscanURL    map[string]string
 	networksEtherscanAPIKey map[string]string
-	networksNodesURL        map[string]string
+	pool                    *rpcpool.Pool
+	heads                   *chainhead.Tracker
 	maxTransactions         int
 
 	client HTTPClient
@@ -59,9 +63,12 @@ type Config struct {
 	IdGen              idGenerator
 	EtherscanUrlMap    map[string]string
 	ApiKeyMap          map[string]string
-	NodesUrlMap        map[string]string
+	Pool               *rpcpool.Pool
+	Heads              *chainhead.Tracker
 	Client             HTTPClient
 	MaxTransactions    int
+	// Seconds is the time between the runs of the engine
+	Seconds int64
 }
 
 func New(c Config) *T {
@@ -71,32 +78,39 @@ func New(c Config) *T {
 		idGen:                   c.IdGen,
 		networksEtherscanURL:    c.EtherscanUrlMap,
 		networksEtherscanAPIKey: c.ApiKeyMap,
-		networksNodesURL:        c.NodesUrlMap,
+		pool:                    c.Pool,
+		heads:                   c.Heads,
 		client:                  c.Client,
 		maxTransactions:         c.MaxTransactions,
+		seconds:                 c.Seconds,
 
 		status: StatusIdle,
 	}
 }
 
-func (t *T) Start(seconds int64) {
-	go func() {
-		for t.GetStatus() == StatusIdle || t.GetStatus() == StatusRunning {
-			// log.Print("starting ...")
-			err := t.Run()
-			if err != nil {
-				t.SetStatus(StatusError)
-			}
-			// log.Print("finished!")
-
-			// log.Print("sleeping ...")
-			time.Sleep(time.Duration(time.Duration(seconds) * time.Second))
-			// log.Print("sleept!")
+// Run syncs the transactions of the contracts every t.seconds until the context is canceled.
+// The engine is paused while its status is stopped or errored, and resumes when it's set to
+// running again.
+func (t *T) Run(ctx context.Context) error {
+	t.SetStatus(StatusRunning)
+	defer t.SetStatus(StatusStopped)
+
+	for {
+		err := t.RunOnce()
+		if err != nil {
+			log.Printf("txsengine: T.Run t.RunOnce error: %s \n", err.Error())
+			t.SetStatus(StatusError)
 		}
-	}()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Duration(t.seconds) * time.Second):
+		}
+	}
 }
 
-func (t *T) Run() error {
+func (t *T) RunOnce() error {
 	if t.GetStatus() == StatusStopped || t.GetStatus() == StatusStopping || t.GetStatus() == StatusError {
 		return nil
 	}
@@ -137,17 +151,18 @@ func (t *T) Run() error {
 
 // Get status
 func (t *T) GetStatus() StatusEngine {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	return t.status
 }
 
 // Set status with mutex
 func (t *T) SetStatus(status StatusEngine) {
-	t.status = status
-}
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
-// Stop the tx engine
-func (t *T) Halt() {
-	t.status = StatusStopped
+	t.status = status
 }
 
 func (t *T) GetContractTransactions(contractId string, apiUrl string, apiKey string) error {
@@ -176,28 +191,17 @@ func (t *T) GetContractTransactions(contractId string, apiUrl string, apiKey str
 		return nil
 	}
 
-	// validate and get the node url if node url are not defined
-	nodeURL := contract.NodeURL
-	if contract.NodeURL == "" {
-		nodeURL, err = checkAndGetNodeURL(contract, t.networksNodesURL)
-		if err != nil {
-			_ = t.smartContractStorage.UpdateStatusAndError(contract.ID, smartcontract.StatusError, err)
-			return err
-		}
-	}
-
-	// create instance client with the node url
-	client, err := ethclient.Dial(nodeURL)
+	// get last block that reached the network finality
+	head, err := t.heads.Head(string(contract.Network), contract.NodeURL)
 	if err != nil {
 		_ = t.smartContractStorage.UpdateStatusAndError(contract.ID, smartcontract.StatusError, err)
 		return err
 	}
+	lastBlock := head.Finalized
 
-	// get last block number
-	lastBlock, err := client.BlockNumber(context.Background())
-	if err != nil {
-		_ = t.smartContractStorage.UpdateStatusAndError(contract.ID, smartcontract.StatusError, err)
-		return err
+	// nothing to sync when the finalized head is behind the latest synced block
+	if lastBlock <= contract.LastTxBlockSynced {
+		return nil
 	}
 
 	// Update contract status to synching
@@ -205,7 +209,7 @@ func (t *T) GetContractTransactions(contractId string, apiUrl string, apiKey str
 
 	// get transaction from etherscan
 	startBlock := contract.LastTxBlockSynced + 1
-	transactions, err := t.getTransactionsFromEtherscan(apiUrl, apiKey, contract.Address, startBlock, int64(lastBlock))
+	transactions, err := t.getTransactionsFromEtherscan(apiUrl, apiKey, contract.Address, startBlock, lastBlock)
 	if err != nil && !strings.Contains(err.Error(), "No transactions found") {
 		_ = t.smartContractStorage.UpdateStatusAndError(contract.ID, smartcontract.StatusError, err)
 		return err
@@ -214,17 +218,25 @@ func (t *T) GetContractTransactions(contractId string, apiUrl string, apiKey str
 	// when the response from the scan does not have any transactions
 	if len(transactions) == 0 {
 		_ = t.smartContractStorage.UpdateStatusAndError(contract.ID, smartcontract.StatusRunning, nil)
-		t.smartContractStorage.UpdateLastBlockNumber(contract.ID, int64(lastBlock))
+		t.smartContractStorage.UpdateLastBlockNumber(contract.ID, lastBlock)
 
 		return nil
 	}
 
+	// get a client of the contract node, or of other node of the network when it isn't healthy
+	client, _, err := t.pool.Client(string(contract.Network), contract.NodeURL)
+	if err != nil {
+		_ = t.smartContractStorage.UpdateStatusAndError(contract.ID, smartcontract.StatusError, err)
+		return err
+	}
+	defer t.pool.Release(client)
+
 	// initialize eth client with rate limiter
 	clientWithRateLimiter := ethclientrate.NewClient(&ethclientrate.Options{
 		MaxRetry:        2,
 		MaxRequest:      25,
 		WindowInSeconds: 1,
-	}, client)
+	}, client.Client)
 
 	// prepare iterators
 	var from, to, count int
@@ -2,11 +2,13 @@ package webhooksender
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	webhookstorage "github.com/darchlabs/synchronizer-v2/internal/storage/webhook"
@@ -14,54 +16,108 @@ import (
 	"github.com/pkg/errors"
 )
 
+// DefaultStaleTime is the time a claimed webhook waits to be sent or updated before other
+// sender retries it, the sender that claimed it is considered stopped then.
+const DefaultStaleTime = 5 * time.Minute
+
+// Storage keeps the webhooks shared by the senders of the replicas. A webhook is sent by the
+// sender that claims it, so it's sent once even when several senders load it.
+type Storage interface {
+	CreateWebhook(wh *webhook.Webhook) (*webhook.Webhook, error)
+	UpdateWebhook(wh *webhook.Webhook) (*webhook.Webhook, error)
+	GetQueuedWebhooks() ([]*webhook.Webhook, error)
+	ClaimWebhook(id string, now time.Time) (bool, error)
+	ClaimWebhooksForRetry(now time.Time, staleBefore time.Time) ([]*webhook.Webhook, error)
+}
+
 type WebhookSender struct {
-	WebhookStorage *webhookstorage.Storage
+	WebhookStorage Storage
 	HTTPClient     *http.Client
 	TickerTime     time.Duration
-	webhookQueue   *WebhookPriorityQueue
-	InQueue        map[string]struct{}
+	// StaleTime is the time a claimed webhook waits before it's retried by other sender
+	StaleTime time.Duration
+
+	// mu guards the queue, webhooks are enqueued by the cronjob while the sender delivers them
+	mu           sync.Mutex
+	webhookQueue *WebhookPriorityQueue
+	inQueue      map[string]struct{}
 }
 
-func NewWebhookSender(storage *webhookstorage.Storage, client *http.Client, tickerTime time.Duration) *WebhookSender {
+func NewWebhookSender(storage Storage, client *http.Client, tickerTime time.Duration) *WebhookSender {
 	return &WebhookSender{
 		WebhookStorage: storage,
 		HTTPClient:     client,
 		TickerTime:     tickerTime,
+		StaleTime:      DefaultStaleTime,
 		webhookQueue:   NewWebhookPriorityQueue(),
-		InQueue:        make(map[string]struct{}),
+		inQueue:        make(map[string]struct{}),
 	}
 }
 
 func (s *WebhookSender) EnqueueWebhook(wh *webhook.Webhook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.webhookQueue.Push(wh)
-	s.InQueue[wh.ID] = struct{}{}
+	s.inQueue[wh.ID] = struct{}{}
 }
 
-func (s *WebhookSender) ProcessWebhooks() {
-	for {
-		if s.webhookQueue.Len() == 0 {
-			time.Sleep(s.TickerTime * time.Second)
-			continue
-		}
+// Run enqueues the pending webhooks of the storage, delivers the queued webhooks and retries
+// the failed ones until the context is canceled, then delivers the webhooks still in the queue
+// before returning.
+func (s *WebhookSender) Run(ctx context.Context) error {
+	err := s.InitializeFromStorage()
+	if err != nil {
+		return errors.Wrap(err, "webhooksender: WebhookSender.Run s.InitializeFromStorage error")
+	}
 
-		wh := s.webhookQueue.Pop()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.StartRetries(ctx)
+	}()
+	s.Deliver(ctx)
+	wg.Wait()
 
-		err := s.SendWebhook(wh)
-		if err != nil {
-			wh.Status = webhook.StatusFailed
-			wh.NextRetryAt = sql.NullTime{Time: time.Now().Add(s.TickerTime * time.Second), Valid: true}
-		} else {
-			wh.Status = webhook.StatusDelivered
-			wh.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
-		}
-		wh.UpdatedAt = time.Now()
-		wh.Attempts++
+	return nil
+}
 
-		if _, err = s.WebhookStorage.UpdateWebhook(wh); err != nil {
-			log.Fatalf("Fatal error updating webhook in the database: %s\n", err)
+// Deliver delivers the queued webhooks until the context is canceled, then delivers the
+// webhooks still in the queue before returning. The webhooks not delivered are kept as
+// pending in the storage.
+func (s *WebhookSender) Deliver(ctx context.Context) error {
+	s.ProcessWebhooks(ctx)
+
+	for wh := s.pop(); wh != nil; wh = s.pop() {
+		s.deliver(wh)
+	}
+
+	return nil
+}
+
+// Retry retries the failed webhooks and the stale ones until the context is canceled, the
+// pending webhooks are delivered by the senders that queued them.
+func (s *WebhookSender) Retry(ctx context.Context) error {
+	s.StartRetries(ctx)
+
+	return nil
+}
+
+func (s *WebhookSender) ProcessWebhooks(ctx context.Context) {
+	for {
+		wh := s.pop()
+		if wh == nil {
+			if !s.sleep(ctx) {
+				return
+			}
+			continue
 		}
 
-		delete(s.InQueue, wh.ID)
+		s.deliver(wh)
+		if ctx.Err() != nil {
+			return
+		}
 	}
 }
 
@@ -87,38 +143,106 @@ func (s *WebhookSender) SendWebhook(wh *webhook.Webhook) error {
 	return err
 }
 
-func (s *WebhookSender) StartRetries() {
+// StartRetries sends the webhooks claimed for a retry until the context is canceled. Besides
+// the failed webhooks, it claims the ones not sent after the stale time, like the webhooks
+// left pending or being sent by a sender that stopped.
+func (s *WebhookSender) StartRetries(ctx context.Context) {
 	for {
-		webhooks, err := s.WebhookStorage.GetWebhooksForRetry(s.InQueue)
+		now := time.Now()
+		webhooks, err := s.WebhookStorage.ClaimWebhooksForRetry(now, now.Add(-s.StaleTime))
 		if err != nil {
-			time.Sleep(s.TickerTime * time.Second)
-			continue
+			log.Printf("webhooksender: WebhookSender.StartRetries s.WebhookStorage.ClaimWebhooksForRetry error: %s \n", err.Error())
 		}
-
-		if len(webhooks) == 0 {
-			time.Sleep(s.TickerTime * time.Second)
+		if err != nil || len(webhooks) == 0 {
+			if !s.sleep(ctx) {
+				return
+			}
 			continue
 		}
 
 		for _, wh := range webhooks {
-			err := s.SendWebhook(wh)
-			if err != nil {
-				wh.Status = webhook.StatusFailed
-				wh.NextRetryAt = sql.NullTime{Time: time.Now().Add(s.TickerTime * time.Second), Valid: true}
-			} else {
-				wh.Status = webhook.StatusDelivered
-				wh.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
-			}
-			wh.UpdatedAt = time.Now()
-			wh.Attempts++
-
-			if _, err = s.WebhookStorage.UpdateWebhook(wh); err != nil {
-				log.Fatalf("Fatal error updating webhook in the database: %s\n", err)
+			s.update(wh, s.SendWebhook(wh))
+			if ctx.Err() != nil {
+				return
 			}
 		}
 	}
 }
 
+// deliver sends the webhook when no other sender claimed it, and removes it from the queue.
+// The webhooks that can't be claimed because of an error are sent later by the retries.
+func (s *WebhookSender) deliver(wh *webhook.Webhook) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.inQueue, wh.ID)
+		s.mu.Unlock()
+	}()
+
+	claimed, err := s.WebhookStorage.ClaimWebhook(wh.ID, time.Now())
+	if err != nil {
+		log.Printf("webhooksender: WebhookSender.deliver s.WebhookStorage.ClaimWebhook id=%s error: %s \n", wh.ID, err.Error())
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	s.update(wh, s.SendWebhook(wh))
+}
+
+// update stores the result of sending the webhook.
+func (s *WebhookSender) update(wh *webhook.Webhook, err error) {
+	if err != nil {
+		wh.Status = webhook.StatusFailed
+		wh.NextRetryAt = sql.NullTime{Time: time.Now().Add(s.TickerTime * time.Second), Valid: true}
+	} else {
+		wh.Status = webhook.StatusDelivered
+		wh.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	wh.UpdatedAt = time.Now()
+	wh.Attempts++
+
+	if _, err = s.WebhookStorage.UpdateWebhook(wh); err != nil {
+		log.Fatalf("Fatal error updating webhook in the database: %s\n", err)
+	}
+}
+
+// pop returns the next webhook of the queue, or nil when it's empty. The webhook stays in
+// inQueue until it's delivered, so it isn't enqueued again from the storage meanwhile.
+func (s *WebhookSender) pop() *webhook.Webhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.webhookQueue.Len() == 0 {
+		return nil
+	}
+
+	return s.webhookQueue.Pop()
+}
+
+// queued returns a copy of the ids of the webhooks in the queue.
+func (s *WebhookSender) queued() map[string]struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make(map[string]struct{}, len(s.inQueue))
+	for id := range s.inQueue {
+		ids[id] = struct{}{}
+	}
+
+	return ids
+}
+
+// sleep waits the ticker time, it returns false when the context is canceled meanwhile.
+func (s *WebhookSender) sleep(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(s.TickerTime * time.Second):
+		return true
+	}
+}
+
 func (s *WebhookSender) CreateAndSendWebhook(wh *webhook.Webhook) error {
 	// Create the webhook in the database
 	wh, err := s.WebhookStorage.CreateWebhook(wh)
@@ -135,13 +259,19 @@ func (s *WebhookSender) CreateAndSendWebhook(wh *webhook.Webhook) error {
 	return nil
 }
 
+// InitializeFromStorage enqueues the pending webhooks of the storage, the ones other sender
+// already delivers are skipped when they're claimed.
 func (s *WebhookSender) InitializeFromStorage() error {
 	webhooks, err := s.WebhookStorage.GetQueuedWebhooks()
 	if err != nil {
 		return errors.Wrap(err, "webhooksender: error retrieving queued webhooks from storage")
 	}
 
+	queued := s.queued()
 	for _, wh := range webhooks {
+		if _, ok := queued[wh.ID]; ok {
+			continue
+		}
 		s.EnqueueWebhook(wh)
 	}
 
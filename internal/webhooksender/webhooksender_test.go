@@ -0,0 +1,173 @@
+package webhooksender
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/pkg/webhook"
+	"github.com/jaekwon/testify/require"
+)
+
+// fakeStorage keeps the webhooks in memory and claims them like the webhooks table, a webhook
+// is claimed by a single sender.
+type fakeStorage struct {
+	mu       sync.Mutex
+	webhooks map[string]*webhook.Webhook
+}
+
+func (f *fakeStorage) CreateWebhook(wh *webhook.Webhook) (*webhook.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	copied := *wh
+	f.webhooks[wh.ID] = &copied
+	return wh, nil
+}
+
+func (f *fakeStorage) UpdateWebhook(wh *webhook.Webhook) (*webhook.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	copied := *wh
+	f.webhooks[wh.ID] = &copied
+	return wh, nil
+}
+
+func (f *fakeStorage) GetQueuedWebhooks() ([]*webhook.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	webhooks := make([]*webhook.Webhook, 0)
+	for _, wh := range f.webhooks {
+		if wh.Status == webhook.StatusPending {
+			copied := *wh
+			webhooks = append(webhooks, &copied)
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (f *fakeStorage) ClaimWebhook(id string, now time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wh, ok := f.webhooks[id]
+	if !ok || wh.Status != webhook.StatusPending {
+		return false, nil
+	}
+	wh.Status = webhook.StatusSending
+	wh.UpdatedAt = now
+
+	return true, nil
+}
+
+func (f *fakeStorage) ClaimWebhooksForRetry(now time.Time, staleBefore time.Time) ([]*webhook.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	webhooks := make([]*webhook.Webhook, 0)
+	for _, wh := range f.webhooks {
+		if wh.Attempts >= wh.MaxAttempts {
+			continue
+		}
+		failed := wh.Status == webhook.StatusFailed && !wh.NextRetryAt.Time.After(now)
+		stale := (wh.Status == webhook.StatusSending || wh.Status == webhook.StatusPending) && wh.UpdatedAt.Before(staleBefore)
+		if !failed && !stale {
+			continue
+		}
+
+		wh.Status = webhook.StatusSending
+		wh.UpdatedAt = now
+		copied := *wh
+		webhooks = append(webhooks, &copied)
+	}
+
+	return webhooks, nil
+}
+
+func (f *fakeStorage) status(id string) webhook.WebhookStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.webhooks[id].Status
+}
+
+func Test_WebhookSender_SendsOnce(t *testing.T) {
+	// the endpoint counts the deliveries of each webhook
+	var mu sync.Mutex
+	deliveries := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := &webhook.WebhookResponse{}
+		err := json.NewDecoder(r.Body).Decode(res)
+		require.NoError(t, err)
+
+		mu.Lock()
+		deliveries[res.ID]++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	storage := &fakeStorage{webhooks: make(map[string]*webhook.Webhook)}
+	ids := make([]string, 0)
+	newWebhook := func(status webhook.WebhookStatus, updatedAt time.Time) {
+		wh := &webhook.Webhook{
+			ID:          fmt.Sprintf("webhook-%d", len(ids)),
+			Endpoint:    server.URL,
+			Payload:     json.RawMessage(`{}`),
+			MaxAttempts: 3,
+			CreatedAt:   updatedAt,
+			UpdatedAt:   updatedAt,
+			Status:      status,
+		}
+		if status == webhook.StatusFailed {
+			wh.Attempts = 1
+		}
+		ids = append(ids, wh.ID)
+		storage.CreateWebhook(wh)
+	}
+
+	// the pending webhooks are loaded by both senders, the failed one is due for a retry and
+	// the sender of the sending one stopped before updating it
+	for i := 0; i < 20; i++ {
+		newWebhook(webhook.StatusPending, now)
+	}
+	newWebhook(webhook.StatusFailed, now.Add(-time.Minute))
+	newWebhook(webhook.StatusSending, now.Add(-time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		sender := NewWebhookSender(storage, server.Client(), 1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, sender.Run(ctx))
+		}()
+	}
+
+	// wait until every webhook is delivered
+	deadline := time.Now().Add(5 * time.Second)
+	for _, id := range ids {
+		for storage.status(id) != webhook.StatusDelivered {
+			require.True(t, time.Now().Before(deadline), "webhook %s wasn't delivered", id)
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	cancel()
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, deliveries, len(ids))
+	for _, id := range ids {
+		require.Equal(t, 1, deliveries[id], "webhook %s", id)
+	}
+}
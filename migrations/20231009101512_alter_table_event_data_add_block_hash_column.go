@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upAlterTableEventDataAddBlockHashColumn, downAlterTableEventDataAddBlockHashColumn)
+}
+
+func upAlterTableEventDataAddBlockHashColumn(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec("ALTER TABLE event_data ADD COLUMN block_hash TEXT NOT NULL DEFAULT '';")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_event_data_event_id_block_number ON event_data (event_id, block_number);")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAlterTableEventDataAddBlockHashColumn(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP INDEX IF EXISTS idx_event_data_event_id_block_number;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("ALTER TABLE event_data DROP COLUMN IF EXISTS block_hash;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upAlterTableEventAddHeadColumns, downAlterTableEventAddHeadColumns)
+}
+
+func upAlterTableEventAddHeadColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		ALTER TABLE event
+		ADD COLUMN observed_block_number BIGINT NOT NULL DEFAULT 0,
+		ADD COLUMN finalized_block_number BIGINT NOT NULL DEFAULT 0;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAlterTableEventAddHeadColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`
+		ALTER TABLE event
+		DROP COLUMN IF EXISTS observed_block_number,
+		DROP COLUMN IF EXISTS finalized_block_number;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upAlterTableEventDataAddLogPositionColumns, downAlterTableEventDataAddLogPositionColumns)
+}
+
+func upAlterTableEventDataAddLogPositionColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		ALTER TABLE event_data
+		ADD COLUMN log_index BIGINT NOT NULL DEFAULT 0,
+		ADD COLUMN tx_index BIGINT NOT NULL DEFAULT 0,
+		ADD COLUMN block_timestamp TIMESTAMP WITH TIME ZONE;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	// a transaction can emit several logs of the same event, so the log index is part of the uniqueness
+	_, err = tx.Exec("ALTER TABLE event_data DROP CONSTRAINT IF EXISTS unique_tx_event_data;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		ALTER TABLE event_data
+		ADD CONSTRAINT unique_event_id_tx_log_index_event_data
+		UNIQUE(event_id, tx, log_index);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_event_data_block_number_log_index ON event_data (block_number, log_index);")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAlterTableEventDataAddLogPositionColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP INDEX IF EXISTS idx_event_data_block_number_log_index;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("ALTER TABLE event_data DROP CONSTRAINT IF EXISTS unique_event_id_tx_log_index_event_data;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		ALTER TABLE event_data
+		DROP COLUMN IF EXISTS log_index,
+		DROP COLUMN IF EXISTS tx_index,
+		DROP COLUMN IF EXISTS block_timestamp;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upCreateBlockRangeWindowTable, downCreateBlockRangeWindowTable)
+}
+
+func upCreateBlockRangeWindowTable(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS block_range_window (
+			node_url TEXT NOT NULL,
+			network TEXT NOT NULL,
+			size BIGINT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE,
+			PRIMARY KEY (node_url, network)
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downCreateBlockRangeWindowTable(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP TABLE IF EXISTS block_range_window;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
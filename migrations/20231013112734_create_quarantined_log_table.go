@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upCreateQuarantinedLogTable, downCreateQuarantinedLogTable)
+}
+
+func upCreateQuarantinedLogTable(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS quarantined_log (
+			id TEXT PRIMARY KEY,
+			event_id TEXT NOT NULL,
+			tx TEXT NOT NULL,
+			tx_index BIGINT NOT NULL DEFAULT 0,
+			log_index BIGINT NOT NULL DEFAULT 0,
+			block_number BIGINT NOT NULL,
+			block_hash TEXT NOT NULL DEFAULT '',
+			block_timestamp TIMESTAMP WITH TIME ZONE,
+			topics JSONB NOT NULL,
+			data TEXT NOT NULL,
+			error TEXT NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE,
+			FOREIGN KEY (event_id) REFERENCES event (id) ON DELETE CASCADE,
+			CONSTRAINT unique_event_id_tx_log_index_quarantined_log UNIQUE(event_id, tx, log_index)
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_quarantined_log_event_id_block_number ON quarantined_log (event_id, block_number);")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downCreateQuarantinedLogTable(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP TABLE IF EXISTS quarantined_log;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upAlterTableEventAddSignatureColumns, downAlterTableEventAddSignatureColumns)
+}
+
+func upAlterTableEventAddSignatureColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		ALTER TABLE event
+		ADD COLUMN signature TEXT NOT NULL DEFAULT '',
+		ADD COLUMN topic0 TEXT NOT NULL DEFAULT '';`,
+	)
+	if err != nil {
+		return err
+	}
+
+	// set the signature and topic0 of the existing events using their abi
+	rows, err := tx.Query(`
+		SELECT event.id, abi.name, COALESCE(NULLIF(abi.inputs, ''), '[]')
+		FROM event
+		JOIN abi ON abi.id = event.abi_id;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	type eventSignature struct {
+		id        string
+		signature string
+		topic0    string
+	}
+	signatures := make([]eventSignature, 0)
+	for rows.Next() {
+		var id string
+		record := &storage.ABIRecord{}
+		err = rows.Scan(&id, &record.Name, &record.InputsJSON)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		signature, topic0, err := record.EventSignature()
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		signatures = append(signatures, eventSignature{id: id, signature: signature, topic0: topic0.Hex()})
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range signatures {
+		_, err = tx.Exec("UPDATE event SET signature = $2, topic0 = $3 WHERE id = $1;", s.id, s.signature, s.topic0)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_event_sc_address_topic0 ON event (sc_address, topic0);")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAlterTableEventAddSignatureColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP INDEX IF EXISTS idx_event_sc_address_topic0;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		ALTER TABLE event
+		DROP COLUMN IF EXISTS signature,
+		DROP COLUMN IF EXISTS topic0;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upCreateBlocksTable, downCreateBlocksTable)
+}
+
+func upCreateBlocksTable(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS blocks (
+			network TEXT NOT NULL,
+			number BIGINT NOT NULL,
+			hash TEXT NOT NULL,
+			parent_hash TEXT NOT NULL,
+			timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+			base_fee NUMERIC,
+			gas_used BIGINT NOT NULL DEFAULT 0,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (network, hash)
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_blocks_network_number ON blocks (network, number);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_event_data_event_id_block_timestamp ON event_data (event_id, block_timestamp);")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downCreateBlocksTable(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP INDEX IF EXISTS idx_event_data_event_id_block_timestamp;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DROP TABLE IF EXISTS blocks;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upAlterTableAddTopicFiltersColumns, downAlterTableAddTopicFiltersColumns)
+}
+
+func upAlterTableAddTopicFiltersColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		ALTER TABLE smartcontract_users
+		ADD COLUMN topic_filters JSONB NOT NULL DEFAULT '{}';`,
+	)
+	if err != nil {
+		return err
+	}
+
+	// the topics of the log are needed to return the filtered view of each user
+	_, err = tx.Exec(`
+		ALTER TABLE event_data
+		ADD COLUMN topics TEXT[] NOT NULL DEFAULT '{}';`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAlterTableAddTopicFiltersColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("ALTER TABLE event_data DROP COLUMN IF EXISTS topics;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("ALTER TABLE smartcontract_users DROP COLUMN IF EXISTS topic_filters;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
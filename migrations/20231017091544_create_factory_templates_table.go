@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upCreateFactoryTemplatesTable, downCreateFactoryTemplatesTable)
+}
+
+func upCreateFactoryTemplatesTable(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS factory_templates (
+			id TEXT PRIMARY KEY,
+			event_id TEXT NOT NULL REFERENCES event(id) ON DELETE CASCADE,
+			sc_address TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			child_address_arg TEXT NOT NULL,
+			child_name TEXT NOT NULL,
+			child_abi JSONB NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE,
+			UNIQUE (event_id)
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_factory_templates_sc_address ON factory_templates (sc_address);")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downCreateFactoryTemplatesTable(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP TABLE IF EXISTS factory_templates;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upAlterTableSmartcontractsAddTemplateColumn, downAlterTableSmartcontractsAddTemplateColumn)
+}
+
+func upAlterTableSmartcontractsAddTemplateColumn(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		ALTER TABLE smartcontracts
+		ADD COLUMN template TEXT NOT NULL DEFAULT '';`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_smartcontracts_template ON smartcontracts (template);")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAlterTableSmartcontractsAddTemplateColumn(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("ALTER TABLE smartcontracts DROP COLUMN IF EXISTS template;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upCreateRawLogTable, downCreateRawLogTable)
+}
+
+func upCreateRawLogTable(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		ALTER TABLE smartcontracts
+		ADD COLUMN raw_logs BOOLEAN NOT NULL DEFAULT false;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS raw_log (
+			id TEXT PRIMARY KEY,
+			sc_address TEXT NOT NULL,
+			tx TEXT NOT NULL,
+			tx_index BIGINT NOT NULL DEFAULT 0,
+			log_index BIGINT NOT NULL DEFAULT 0,
+			block_number BIGINT NOT NULL,
+			block_hash TEXT NOT NULL DEFAULT '',
+			block_timestamp TIMESTAMP WITH TIME ZONE,
+			topics TEXT[] NOT NULL DEFAULT '{}',
+			data TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			CONSTRAINT unique_sc_address_tx_log_index_raw_log UNIQUE(sc_address, tx, log_index)
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_raw_log_sc_address_block_number ON raw_log (sc_address, block_number);")
+	if err != nil {
+		return err
+	}
+
+	// the raw logs are decoded lazily by the topic0 of the events
+	_, err = tx.Exec("CREATE INDEX idx_raw_log_sc_address_topic0 ON raw_log (sc_address, (topics[1]));")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downCreateRawLogTable(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP TABLE IF EXISTS raw_log;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("ALTER TABLE smartcontracts DROP COLUMN IF EXISTS raw_logs;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upCreateSignatureSubscriptionTables, downCreateSignatureSubscriptionTables)
+}
+
+func upCreateSignatureSubscriptionTables(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS signature_subscription (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			network TEXT NOT NULL,
+			node_url TEXT NOT NULL,
+			signature TEXT NOT NULL,
+			topic0 TEXT NOT NULL,
+			abi JSONB NOT NULL,
+			addresses TEXT[] NOT NULL DEFAULT '{}',
+			all_addresses BOOLEAN NOT NULL DEFAULT false,
+			topic_filters JSONB NOT NULL DEFAULT '{}',
+			from_block_number BIGINT NOT NULL DEFAULT 0,
+			latest_block_number BIGINT NOT NULL DEFAULT 0,
+			status TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_signature_subscription_user_id ON signature_subscription (user_id);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS signature_event_data (
+			id TEXT PRIMARY KEY,
+			subscription_id TEXT NOT NULL REFERENCES signature_subscription(id) ON DELETE CASCADE,
+			address TEXT NOT NULL,
+			tx TEXT NOT NULL,
+			tx_index BIGINT NOT NULL DEFAULT 0,
+			log_index BIGINT NOT NULL DEFAULT 0,
+			block_number BIGINT NOT NULL,
+			block_hash TEXT NOT NULL DEFAULT '',
+			block_timestamp TIMESTAMP WITH TIME ZONE,
+			topics TEXT[] NOT NULL DEFAULT '{}',
+			data JSONB NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			CONSTRAINT unique_subscription_id_tx_log_index_signature_event_data UNIQUE(subscription_id, tx, log_index)
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	// the data of a subscription is queried by the emitting address
+	_, err = tx.Exec("CREATE INDEX idx_signature_event_data_subscription_id_address_block_number ON signature_event_data (subscription_id, address, block_number);")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downCreateSignatureSubscriptionTables(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP TABLE IF EXISTS signature_event_data;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DROP TABLE IF EXISTS signature_subscription;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upCreateBackfillTables, downCreateBackfillTables)
+}
+
+func upCreateBackfillTables(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS backfill_job (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			sc_address TEXT NOT NULL,
+			network TEXT NOT NULL,
+			node_url TEXT NOT NULL,
+			from_block_number BIGINT NOT NULL,
+			to_block_number BIGINT NOT NULL,
+			chunk_size BIGINT NOT NULL,
+			concurrency INTEGER NOT NULL,
+			status TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_backfill_job_sc_address ON backfill_job (sc_address);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_backfill_job_status ON backfill_job (status);")
+	if err != nil {
+		return err
+	}
+
+	// the chunks keep the progress of each range, so the jobs resume after restarts
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS backfill_chunk (
+			id TEXT PRIMARY KEY,
+			job_id TEXT NOT NULL REFERENCES backfill_job(id) ON DELETE CASCADE,
+			from_block_number BIGINT NOT NULL,
+			to_block_number BIGINT NOT NULL,
+			latest_block_number BIGINT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE,
+			CONSTRAINT unique_job_id_from_block_number_backfill_chunk UNIQUE(job_id, from_block_number)
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downCreateBackfillTables(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP TABLE IF EXISTS backfill_chunk;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DROP TABLE IF EXISTS backfill_job;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
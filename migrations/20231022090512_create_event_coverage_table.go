@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upCreateEventCoverageTable, downCreateEventCoverageTable)
+}
+
+func upCreateEventCoverageTable(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS event_coverage (
+			id TEXT PRIMARY KEY,
+			event_id TEXT NOT NULL,
+			from_block_number BIGINT NOT NULL,
+			to_block_number BIGINT NOT NULL,
+			source TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			FOREIGN KEY (event_id) REFERENCES event (id) ON DELETE CASCADE
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_event_coverage_event_id_from_block_number ON event_coverage (event_id, from_block_number);")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downCreateEventCoverageTable(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP TABLE IF EXISTS event_coverage;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upAlterTableEventAddScheduleColumns, downAlterTableEventAddScheduleColumns)
+}
+
+func upAlterTableEventAddScheduleColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		ALTER TABLE event
+		ADD COLUMN failures INTEGER NOT NULL DEFAULT 0,
+		ADD COLUMN next_run_at TIMESTAMP WITH TIME ZONE;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	// the recent errors of each event, older ones are removed as new ones are stored
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS event_error (
+			id TEXT PRIMARY KEY,
+			event_id TEXT NOT NULL,
+			error TEXT NOT NULL,
+			attempt INTEGER NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			FOREIGN KEY (event_id) REFERENCES event (id) ON DELETE CASCADE
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX idx_event_error_event_id_created_at ON event_error (event_id, created_at);")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAlterTableEventAddScheduleColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP TABLE IF EXISTS event_error;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		ALTER TABLE event
+		DROP COLUMN IF EXISTS failures,
+		DROP COLUMN IF EXISTS next_run_at;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
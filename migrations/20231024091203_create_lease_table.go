@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upCreateLeaseTable, downCreateLeaseTable)
+}
+
+func upCreateLeaseTable(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS lease (
+			name TEXT PRIMARY KEY,
+			holder TEXT NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			acquired_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			renewed_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downCreateLeaseTable(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP TABLE IF EXISTS lease;")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upAlterTableEventAddCheckpointColumns, downAlterTableEventAddCheckpointColumns)
+}
+
+func upAlterTableEventAddCheckpointColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		ALTER TABLE event
+		ADD COLUMN checkpoint_block_number BIGINT NOT NULL DEFAULT 0,
+		ADD COLUMN checkpoint_block_hash TEXT NOT NULL DEFAULT '';`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAlterTableEventAddCheckpointColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`
+		ALTER TABLE event
+		DROP COLUMN IF EXISTS checkpoint_block_number,
+		DROP COLUMN IF EXISTS checkpoint_block_hash;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
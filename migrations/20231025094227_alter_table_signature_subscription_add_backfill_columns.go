@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upAlterTableSignatureSubscriptionAddBackfillColumns, downAlterTableSignatureSubscriptionAddBackfillColumns)
+}
+
+func upAlterTableSignatureSubscriptionAddBackfillColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		ALTER TABLE signature_subscription
+		ADD COLUMN backfill_addresses TEXT[] NOT NULL DEFAULT '{}',
+		ADD COLUMN backfill_to_block_number BIGINT NOT NULL DEFAULT 0,
+		ADD COLUMN backfill_latest_block_number BIGINT NOT NULL DEFAULT 0;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	// the logs of the subscriptions that couldn't be decoded
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS signature_quarantined_log (
+			id TEXT PRIMARY KEY,
+			subscription_id TEXT NOT NULL REFERENCES signature_subscription(id) ON DELETE CASCADE,
+			address TEXT NOT NULL,
+			tx TEXT NOT NULL,
+			tx_index BIGINT NOT NULL DEFAULT 0,
+			log_index BIGINT NOT NULL DEFAULT 0,
+			block_number BIGINT NOT NULL,
+			block_hash TEXT NOT NULL DEFAULT '',
+			block_timestamp TIMESTAMP WITH TIME ZONE,
+			topics JSONB NOT NULL,
+			data TEXT NOT NULL,
+			error TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			CONSTRAINT unique_subscription_id_tx_log_index_signature_quarantined_log UNIQUE(subscription_id, tx, log_index)
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAlterTableSignatureSubscriptionAddBackfillColumns(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec("DROP TABLE IF EXISTS signature_quarantined_log;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		ALTER TABLE signature_subscription
+		DROP COLUMN IF EXISTS backfill_addresses,
+		DROP COLUMN IF EXISTS backfill_to_block_number,
+		DROP COLUMN IF EXISTS backfill_latest_block_number;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
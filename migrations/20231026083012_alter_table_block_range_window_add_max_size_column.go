@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upAlterTableBlockRangeWindowAddMaxSizeColumn, downAlterTableBlockRangeWindowAddMaxSizeColumn)
+}
+
+func upAlterTableBlockRangeWindowAddMaxSizeColumn(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`
+		ALTER TABLE block_range_window
+		ADD COLUMN max_size BIGINT NOT NULL DEFAULT 0;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAlterTableBlockRangeWindowAddMaxSizeColumn(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`
+		ALTER TABLE block_range_window
+		DROP COLUMN IF EXISTS max_size;`,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
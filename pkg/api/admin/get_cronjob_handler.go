@@ -0,0 +1,34 @@
+package admin
+
+import (
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type getCronjobHandler struct{}
+
+type getCronjobHandlerResponse struct {
+	Cronjob *CronjobRes `json:"cronjob"`
+}
+
+func (h *getCronjobHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	return h.invoke(ctx)
+}
+
+// BUSINESS LOGIC
+func (h *getCronjobHandler) invoke(ctx *api.Context) (interface{}, int, error) {
+	if ctx.Cronjob == nil {
+		return nil, fiber.StatusInternalServerError, errors.New(
+			"admin: getCronjobHandler.invoke cronjob not configured error",
+		)
+	}
+
+	return &getCronjobHandlerResponse{
+		Cronjob: &CronjobRes{
+			Status:  ctx.Cronjob.GetStatus(),
+			Seconds: ctx.Cronjob.GetSeconds(),
+			Error:   ctx.Cronjob.GetError(),
+		},
+	}, fiber.StatusOK, nil
+}
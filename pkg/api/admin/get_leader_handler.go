@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/leader"
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type getLeaderHandler struct{}
+
+type getLeaderHandlerResponse struct {
+	// Replica is the leadership of the replica serving the request, it's nil when the leader
+	// election is disabled
+	Replica *LeaderRes `json:"replica"`
+	// Lease is the lease of the current leader, whichever replica it is
+	Lease *LeaseRes `json:"lease"`
+}
+
+func (h *getLeaderHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	return h.invoke(ctx)
+}
+
+// BUSINESS LOGIC
+func (h *getLeaderHandler) invoke(ctx *api.Context) (interface{}, int, error) {
+	res := &getLeaderHandlerResponse{}
+
+	if ctx.Leader != nil {
+		status := ctx.Leader.Status()
+		res.Replica = &LeaderRes{
+			Holder: status.Holder,
+			Leader: status.Leader,
+			Error:  status.Error,
+		}
+		if status.Lease != nil {
+			res.Replica.Lease = toLeaseRes(status.Lease)
+		}
+	}
+
+	output, err := ctx.SyncEngine.SelectLeases(&sync.SelectLeasesInput{
+		Prefix: leader.DefaultLeaseName,
+	})
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"admin: getLeaderHandler.invoke ctx.SyncEngine.SelectLeases error",
+		)
+	}
+	for _, lease := range output.Leases {
+		if lease.Name == leader.DefaultLeaseName {
+			res.Lease = toLeaseRes(lease)
+		}
+	}
+
+	return res, fiber.StatusOK, nil
+}
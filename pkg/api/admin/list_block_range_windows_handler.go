@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type listBlockRangeWindowsHandler struct{}
+
+type listBlockRangeWindowsHandlerRequest struct {
+	Network string
+}
+
+type listBlockRangeWindowsHandlerResponse struct {
+	Windows []*BlockRangeWindowRes `json:"windows"`
+}
+
+func (h *listBlockRangeWindowsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request, the network filter is optional
+	req := &listBlockRangeWindowsHandlerRequest{
+		Network: c.Query("network"),
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *listBlockRangeWindowsHandler) invoke(ctx *api.Context, req *listBlockRangeWindowsHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.SelectBlockRangeWindows(&sync.SelectBlockRangeWindowsInput{
+		Network: req.Network,
+	})
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"admin: listBlockRangeWindowsHandler.invoke ctx.SyncEngine.SelectBlockRangeWindows error",
+		)
+	}
+
+	// define response
+	res := &listBlockRangeWindowsHandlerResponse{
+		Windows: make([]*BlockRangeWindowRes, 0),
+	}
+
+	for _, w := range output.Windows {
+		res.Windows = append(res.Windows, &BlockRangeWindowRes{
+			NodeURL:   redactNodeURL(w.NodeURL),
+			Network:   string(w.Network),
+			Size:      w.Size,
+			MaxSize:   w.MaxSize,
+			CreatedAt: w.CreatedAt,
+			UpdatedAt: w.UpdatedAt,
+		})
+	}
+
+	return res, fiber.StatusOK, nil
+}
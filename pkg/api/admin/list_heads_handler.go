@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type listHeadsHandler struct{}
+
+type listHeadsHandlerResponse struct {
+	Heads []*NetworkHeadRes `json:"heads"`
+}
+
+func (h *listHeadsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	return h.invoke(ctx)
+}
+
+// BUSINESS LOGIC
+func (h *listHeadsHandler) invoke(ctx *api.Context) (interface{}, int, error) {
+	if ctx.Heads == nil {
+		return nil, fiber.StatusInternalServerError, errors.New(
+			"admin: listHeadsHandler.invoke head tracker not configured error",
+		)
+	}
+
+	// define response
+	res := &listHeadsHandlerResponse{
+		Heads: make([]*NetworkHeadRes, 0),
+	}
+
+	for _, nh := range ctx.Heads.Heads() {
+		h := &NetworkHeadRes{
+			Network:   nh.Network,
+			Error:     nh.Error,
+			UpdatedAt: nh.UpdatedAt,
+		}
+		if nh.Head != nil {
+			h.Observed = nh.Head.Observed
+			h.Finalized = nh.Head.Finalized
+			h.Hash = nh.Head.Hash
+			h.Timestamp = nh.Head.Timestamp
+		}
+		res.Heads = append(res.Heads, h)
+	}
+
+	return res, fiber.StatusOK, nil
+}
@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type listRPCPoolHandler struct{}
+
+type listRPCPoolHandlerRequest struct {
+	Network string
+}
+
+type listRPCPoolHandlerResponse struct {
+	Endpoints []*RPCEndpointRes `json:"endpoints"`
+}
+
+func (h *listRPCPoolHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request, the network filter is optional
+	req := &listRPCPoolHandlerRequest{
+		Network: c.Query("network"),
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *listRPCPoolHandler) invoke(ctx *api.Context, req *listRPCPoolHandlerRequest) (interface{}, int, error) {
+	if ctx.RPCPool == nil {
+		return nil, fiber.StatusInternalServerError, errors.New(
+			"admin: listRPCPoolHandler.invoke rpc pool not configured error",
+		)
+	}
+
+	// define response
+	res := &listRPCPoolHandlerResponse{
+		Endpoints: make([]*RPCEndpointRes, 0),
+	}
+
+	for _, e := range ctx.RPCPool.Health(req.Network) {
+		res.Endpoints = append(res.Endpoints, &RPCEndpointRes{
+			Network:    e.Network,
+			URL:        redactNodeURL(e.URL),
+			Configured: e.Configured,
+			Connected:  e.Connected,
+			Healthy:    e.Healthy,
+			Score:      e.Score,
+			Head:       e.Head,
+			Lag:        e.Lag,
+			ErrorRate:  e.ErrorRate,
+			Failures:   e.Failures,
+			LastError:  e.LastError,
+			CheckedAt:  e.CheckedAt,
+		})
+	}
+
+	return res, fiber.StatusOK, nil
+}
@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/shard"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type listShardsHandler struct{}
+
+type listShardsHandlerResponse struct {
+	// Replica is the assignment of the replica serving the request
+	Replica   *ShardReplicaRes    `json:"replica"`
+	Shards    []*LeaseRes         `json:"shards"`
+	Replicas  []*LeaseRes         `json:"replicas"`
+	Contracts []*ShardContractRes `json:"contracts"`
+}
+
+func (h *listShardsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	return h.invoke(ctx)
+}
+
+// BUSINESS LOGIC
+func (h *listShardsHandler) invoke(ctx *api.Context) (interface{}, int, error) {
+	if ctx.Shards == nil {
+		return nil, fiber.StatusInternalServerError, errors.New(
+			"admin: listShardsHandler.invoke shards not configured error",
+		)
+	}
+
+	status := ctx.Shards.Status()
+	res := &listShardsHandlerResponse{
+		Replica: &ShardReplicaRes{
+			Holder:   status.Holder,
+			Shards:   status.Shards,
+			Replicas: status.Replicas,
+			Target:   status.Target,
+			Owned:    status.Owned,
+			Error:    status.Error,
+		},
+		Shards:    make([]*LeaseRes, 0),
+		Replicas:  make([]*LeaseRes, 0),
+		Contracts: make([]*ShardContractRes, 0),
+	}
+
+	// get the leases of the shards and the heartbeats of the replicas
+	holders := make(map[string]string)
+	for _, prefix := range []string{shard.ShardLeasePrefix, shard.ReplicaLeasePrefix} {
+		output, err := ctx.SyncEngine.SelectLeases(&sync.SelectLeasesInput{
+			Prefix: prefix,
+		})
+		if err != nil {
+			return nil, fiber.StatusInternalServerError, errors.Wrap(
+				err,
+				"admin: listShardsHandler.invoke ctx.SyncEngine.SelectLeases error",
+			)
+		}
+
+		for _, lease := range output.Leases {
+			if prefix == shard.ShardLeasePrefix {
+				res.Shards = append(res.Shards, toLeaseRes(lease))
+				if !lease.Expired {
+					holders[lease.Name] = lease.Holder
+				}
+				continue
+			}
+			res.Replicas = append(res.Replicas, toLeaseRes(lease))
+		}
+	}
+
+	// get the contracts being synced and the replica holding their shard
+	output, err := ctx.SyncEngine.SelectEventsAndABI(&sync.SelectEventsAndABIInput{
+		EventStatuses: []string{string(storage.EventStatusRunning), string(storage.EventStatusError)},
+	})
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"admin: listShardsHandler.invoke ctx.SyncEngine.SelectEventsAndABI error",
+		)
+	}
+
+	keys := make(map[string]bool)
+	for _, ev := range output.Events {
+		key := shard.ContractKey(string(ev.Network), ev.NodeURL, ev.Address)
+		if keys[key] {
+			continue
+		}
+		keys[key] = true
+
+		n := shard.Of(key, status.Shards)
+		res.Contracts = append(res.Contracts, &ShardContractRes{
+			Network: string(ev.Network),
+			NodeURL: ev.NodeURL,
+			Address: ev.Address,
+			Shard:   n,
+			Holder:  holders[shard.LeaseName(n)],
+		})
+	}
+
+	return res, fiber.StatusOK, nil
+}
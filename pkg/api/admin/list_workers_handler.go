@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type listWorkersHandler struct{}
+
+type listWorkersHandlerResponse struct {
+	Workers []*WorkerRes `json:"workers"`
+}
+
+func (h *listWorkersHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	return h.invoke(ctx)
+}
+
+// BUSINESS LOGIC
+func (h *listWorkersHandler) invoke(ctx *api.Context) (interface{}, int, error) {
+	if ctx.Supervisor == nil {
+		return nil, fiber.StatusInternalServerError, errors.New(
+			"admin: listWorkersHandler.invoke supervisor not configured error",
+		)
+	}
+
+	// define response
+	res := &listWorkersHandlerResponse{
+		Workers: make([]*WorkerRes, 0),
+	}
+
+	for _, state := range ctx.Supervisor.Workers() {
+		res.Workers = append(res.Workers, toWorkerRes(state))
+	}
+
+	return res, fiber.StatusOK, nil
+}
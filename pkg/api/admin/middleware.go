@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminTokenHeader is the header carrying the operator token of the admin routes
+const AdminTokenHeader = "X-Admin-Token"
+
+// operatorAuth allows the request only when it carries the operator token, tenant tokens are
+// rejected. The admin routes are closed while no token is configured.
+func operatorAuth(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		got := c.Get(AdminTokenHeader)
+		if got == "" {
+			got = strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		}
+
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return c.Status(fiber.StatusForbidden).JSON(struct {
+				Error string `json:"error"`
+			}{
+				Error: "admin: operator token required",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,138 @@
+package admin
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/supervisor"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type BlockRangeWindowRes struct {
+	NodeURL   string     `json:"nodeURL"`
+	Network   string     `json:"network"`
+	Size      int64      `json:"size"`
+	MaxSize   int64      `json:"maxSize"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// redactNodeURL returns the host of the node url, the path, query and credentials of the urls
+// usually carry the api keys of the providers.
+func redactNodeURL(nodeURL string) string {
+	u, err := url.Parse(nodeURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	return u.Hostname()
+}
+
+type RPCEndpointRes struct {
+	Network    string     `json:"network"`
+	URL        string     `json:"url"`
+	Configured bool       `json:"configured"`
+	Connected  bool       `json:"connected"`
+	Healthy    bool       `json:"healthy"`
+	Score      float64    `json:"score"`
+	Head       int64      `json:"head"`
+	Lag        int64      `json:"lag"`
+	ErrorRate  float64    `json:"error_rate"`
+	Failures   int        `json:"failures"`
+	LastError  string     `json:"last_error"`
+	CheckedAt  *time.Time `json:"checked_at"`
+}
+
+type NetworkHeadRes struct {
+	Network   string     `json:"network"`
+	Observed  int64      `json:"observed"`
+	Finalized int64      `json:"finalized"`
+	Hash      string     `json:"hash"`
+	Timestamp uint64     `json:"timestamp"`
+	Error     string     `json:"error"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type WorkerRes struct {
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	Error     string     `json:"error"`
+	StartedAt *time.Time `json:"started_at"`
+	StoppedAt *time.Time `json:"stopped_at"`
+}
+
+type CronjobRes struct {
+	Status  string `json:"status"`
+	Seconds int64  `json:"seconds"`
+	Error   string `json:"error"`
+}
+
+func toWorkerRes(state *supervisor.WorkerState) *WorkerRes {
+	return &WorkerRes{
+		Name:      state.Name,
+		Status:    string(state.Status),
+		Error:     state.Error,
+		StartedAt: state.StartedAt,
+		StoppedAt: state.StoppedAt,
+	}
+}
+
+func toLeaseRes(lease *storage.LeaseRecord) *LeaseRes {
+	return &LeaseRes{
+		Name:       lease.Name,
+		Holder:     lease.Holder,
+		ExpiresAt:  lease.ExpiresAt,
+		AcquiredAt: lease.AcquiredAt,
+		RenewedAt:  lease.RenewedAt,
+		Expired:    lease.Expired,
+	}
+}
+
+func getWorkerErrorStatus(err error) int {
+	switch errors.Cause(err) {
+	case supervisor.ErrWorkerNotFound:
+		return fiber.StatusNotFound
+	case supervisor.ErrWorkerRunning, supervisor.ErrWorkerNotRunning, supervisor.ErrWorkerStopping, supervisor.ErrSupervisorShutdown:
+		return fiber.StatusConflict
+	case supervisor.ErrDrainTimeout:
+		return fiber.StatusAccepted
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+type LeaderRes struct {
+	Holder string    `json:"holder"`
+	Leader bool      `json:"leader"`
+	Lease  *LeaseRes `json:"lease"`
+	Error  string    `json:"error"`
+}
+
+type LeaseRes struct {
+	Name       string    `json:"name"`
+	Holder     string    `json:"holder"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at"`
+	Expired    bool      `json:"expired"`
+}
+
+type ShardReplicaRes struct {
+	Holder   string `json:"holder"`
+	Shards   int    `json:"shards"`
+	Replicas int    `json:"replicas"`
+	Target   int    `json:"target"`
+	Owned    []int  `json:"owned"`
+	Error    string `json:"error"`
+}
+
+type ShardContractRes struct {
+	Network string `json:"network"`
+	NodeURL string `json:"nodeURL"`
+	Address string `json:"address"`
+	Shard   int    `json:"shard"`
+	// Holder is empty while no replica holds the shard
+	Holder string `json:"holder"`
+}
@@ -0,0 +1,33 @@
+package admin
+
+import (
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+)
+
+func Route(app *fiber.App, apiContext *api.Context) {
+	// the admin routes act on every tenant, so they're for the operators only
+	auth := operatorAuth(apiContext.Env.AdminAPIToken)
+
+	// handlers
+	listBlockRangeWindowsHandler := &listBlockRangeWindowsHandler{}
+	listRPCPoolHandler := &listRPCPoolHandler{}
+	listHeadsHandler := &listHeadsHandler{}
+	listWorkersHandler := &listWorkersHandler{}
+	startWorkerHandler := &startWorkerHandler{}
+	stopWorkerHandler := &stopWorkerHandler{}
+	getCronjobHandler := &getCronjobHandler{}
+	getLeaderHandler := &getLeaderHandler{}
+	listShardsHandler := &listShardsHandler{}
+
+	// routing
+	app.Get("/api/v2/admin/windows", auth, api.HandleFunc(apiContext, listBlockRangeWindowsHandler.Invoke))
+	app.Get("/api/v2/admin/rpc-pool", auth, api.HandleFunc(apiContext, listRPCPoolHandler.Invoke))
+	app.Get("/api/v2/admin/heads", auth, api.HandleFunc(apiContext, listHeadsHandler.Invoke))
+	app.Get("/api/v2/admin/workers", auth, api.HandleFunc(apiContext, listWorkersHandler.Invoke))
+	app.Post("/api/v2/admin/workers/:name/start", auth, api.HandleFunc(apiContext, startWorkerHandler.Invoke))
+	app.Post("/api/v2/admin/workers/:name/stop", auth, api.HandleFunc(apiContext, stopWorkerHandler.Invoke))
+	app.Get("/api/v2/admin/cronjob", auth, api.HandleFunc(apiContext, getCronjobHandler.Invoke))
+	app.Get("/api/v2/admin/leader", auth, api.HandleFunc(apiContext, getLeaderHandler.Invoke))
+	app.Get("/api/v2/admin/shards", auth, api.HandleFunc(apiContext, listShardsHandler.Invoke))
+}
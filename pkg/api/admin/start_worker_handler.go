@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/leader"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type startWorkerHandler struct{}
+
+type startWorkerHandlerRequest struct {
+	Name string
+}
+
+type startWorkerHandlerResponse struct {
+	Worker *WorkerRes `json:"worker"`
+}
+
+func (h *startWorkerHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &startWorkerHandlerRequest{
+		Name: c.Params("name"),
+	}
+	if req.Name == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"admin: startWorkerHandler.Invoke invalid name param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *startWorkerHandler) invoke(ctx *api.Context, req *startWorkerHandlerRequest) (interface{}, int, error) {
+	if ctx.Supervisor == nil {
+		return nil, fiber.StatusInternalServerError, errors.New(
+			"admin: startWorkerHandler.invoke supervisor not configured error",
+		)
+	}
+
+	// only the leader runs the leader workers, they're started before the replica can be demoted
+	var err error
+	if ctx.Leader != nil && isLeaderWorker(ctx, req.Name) {
+		err = ctx.Leader.RunAsLeader(func() error {
+			return ctx.Supervisor.Start(req.Name)
+		})
+	} else {
+		err = ctx.Supervisor.Start(req.Name)
+	}
+	if errors.Is(err, leader.ErrNotLeader) {
+		return nil, fiber.StatusConflict, errors.Wrap(
+			err,
+			"admin: startWorkerHandler.invoke ctx.Leader.RunAsLeader error",
+		)
+	}
+	if err != nil {
+		return nil, getWorkerErrorStatus(err), errors.Wrap(
+			err,
+			"admin: startWorkerHandler.invoke ctx.Supervisor.Start error",
+		)
+	}
+
+	state, err := ctx.Supervisor.Worker(req.Name)
+	if err != nil {
+		return nil, getWorkerErrorStatus(err), errors.Wrap(
+			err,
+			"admin: startWorkerHandler.invoke ctx.Supervisor.Worker error",
+		)
+	}
+
+	return &startWorkerHandlerResponse{
+		Worker: toWorkerRes(state),
+	}, fiber.StatusOK, nil
+}
+
+func isLeaderWorker(ctx *api.Context, name string) bool {
+	for _, w := range ctx.LeaderWorkers {
+		if w == name {
+			return true
+		}
+	}
+
+	return false
+}
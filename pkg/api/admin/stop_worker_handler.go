@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/supervisor"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type stopWorkerHandler struct{}
+
+type stopWorkerHandlerRequest struct {
+	Name string
+}
+
+type stopWorkerHandlerResponse struct {
+	Worker *WorkerRes `json:"worker"`
+}
+
+func (h *stopWorkerHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &stopWorkerHandlerRequest{
+		Name: c.Params("name"),
+	}
+	if req.Name == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"admin: stopWorkerHandler.Invoke invalid name param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *stopWorkerHandler) invoke(ctx *api.Context, req *stopWorkerHandlerRequest) (interface{}, int, error) {
+	if ctx.Supervisor == nil {
+		return nil, fiber.StatusInternalServerError, errors.New(
+			"admin: stopWorkerHandler.invoke supervisor not configured error",
+		)
+	}
+
+	// a worker that didn't drain in time keeps stopping until it exits, so its state is
+	// returned with the accepted status
+	stopErr := ctx.Supervisor.Stop(req.Name)
+	if stopErr != nil && stopErr != supervisor.ErrDrainTimeout {
+		return nil, getWorkerErrorStatus(stopErr), errors.Wrap(
+			stopErr,
+			"admin: stopWorkerHandler.invoke ctx.Supervisor.Stop error",
+		)
+	}
+
+	state, err := ctx.Supervisor.Worker(req.Name)
+	if err != nil {
+		return nil, getWorkerErrorStatus(err), errors.Wrap(
+			err,
+			"admin: stopWorkerHandler.invoke ctx.Supervisor.Worker error",
+		)
+	}
+
+	status := fiber.StatusOK
+	if stopErr == supervisor.ErrDrainTimeout {
+		status = fiber.StatusAccepted
+	}
+
+	return &stopWorkerHandlerResponse{
+		Worker: toWorkerRes(state),
+	}, status, nil
+}
@@ -5,7 +5,13 @@ import (
 	"time"
 
 	"github.com/darchlabs/synchronizer-v2"
+	"github.com/darchlabs/synchronizer-v2/internal/chainhead"
 	"github.com/darchlabs/synchronizer-v2/internal/env"
+	"github.com/darchlabs/synchronizer-v2/internal/explorer"
+	"github.com/darchlabs/synchronizer-v2/internal/leader"
+	"github.com/darchlabs/synchronizer-v2/internal/rpcpool"
+	"github.com/darchlabs/synchronizer-v2/internal/shard"
+	"github.com/darchlabs/synchronizer-v2/internal/supervisor"
 	"github.com/darchlabs/synchronizer-v2/internal/sync"
 	"github.com/darchlabs/synchronizer-v2/internal/txsengine"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -21,6 +27,16 @@ type Context struct {
 	Cronjob      synchronizer.Cronjob
 	TxsEngine    txsengine.TxsEngine
 	Clients      *map[string]*ethclient.Client
+	RPCPool      *rpcpool.Pool
+	Heads        *chainhead.Tracker
+	Explorer     *explorer.Client
+	Supervisor   *supervisor.Supervisor
+	// Leader is nil when the leader election is disabled, the replica runs the workers then
+	Leader *leader.Elector
+	// LeaderWorkers are the workers of the supervisor run only by the leader
+	LeaderWorkers []string
+	// Shards is nil when the ingestion isn't sharded across replicas
+	Shards *shard.Coordinator
 
 	// Engine
 	SyncEngine sync.SyncEngine
@@ -0,0 +1,23 @@
+package events
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+)
+
+// getBlockTimeRange returns the block time range of the startTime and endTime query params,
+// as unix seconds. The limits that weren't defined are returned as nil.
+func getBlockTimeRange(p *pagination.Pagination) (*time.Time, *time.Time) {
+	var from, to *time.Time
+	if p.StartTime != pagination.DefaultStartTime {
+		t := time.Unix(p.StartTime, 0).UTC()
+		from = &t
+	}
+	if p.EndTime != pagination.DefaultEndTime {
+		t := time.Unix(p.EndTime, 0).UTC()
+		to = &t
+	}
+
+	return from, to
+}
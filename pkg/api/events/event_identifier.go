@@ -0,0 +1,32 @@
+package events
+
+import (
+	"net/url"
+
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+// getEventIdentifierParam returns the event_name param, that could be the event name, the canonical
+// signature like Transfer(address,address,uint256) or the topic0 hash of the event.
+func getEventIdentifierParam(c *fiber.Ctx) (string, error) {
+	identifier, err := url.PathUnescape(c.Params("event_name"))
+	if err != nil {
+		return "", errors.Wrap(err, "url.PathUnescape error")
+	}
+
+	return identifier, nil
+}
+
+// getEventErrorStatus returns the status code of an error selecting an event by its identifier.
+func getEventErrorStatus(err error) int {
+	switch errors.Cause(err) {
+	case sync.ErrEventNotFound:
+		return fiber.StatusNotFound
+	case sync.ErrEventNameAmbiguous:
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,23 @@
+package events
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+)
+
+// setEventLag sets the head of the event network and how many blocks the event is behind it.
+// The head published by the tracker is used when the network is followed, otherwise the
+// head observed by the event on its last sync.
+func setEventLag(ctx *api.Context, res *EventRes, event *storage.EventRecord) {
+	res.HeadBlockNumber = event.ObservedBlockNumber
+	if ctx.Heads != nil {
+		if head, ok := ctx.Heads.Latest(string(event.Network)); ok {
+			res.HeadBlockNumber = head.Observed
+		}
+	}
+
+	res.Lag = res.HeadBlockNumber - event.LatestBlockNumber
+	if res.Lag < 0 {
+		res.Lag = 0
+	}
+}
@@ -2,6 +2,7 @@ package events
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/darchlabs/synchronizer-v2/internal/pagination"
 	"github.com/darchlabs/synchronizer-v2/internal/sync"
@@ -16,6 +17,8 @@ type getEventDataV2HandlerRequest struct {
 	UserID     string
 	Address    string
 	EventName  string
+	FromTime   *time.Time
+	ToTime     *time.Time
 	Pagination *pagination.Pagination
 }
 
@@ -40,6 +43,9 @@ func (h *getEventDataV2Handler) Invoke(ctx *api.Context, c *fiber.Ctx) (interfac
 	}
 	req.Pagination = p
 
+	// get the block time range, only applied when it is defined
+	req.FromTime, req.ToTime = getBlockTimeRange(p)
+
 	// get user id
 	req.UserID, err = api.GetUserIDFromRequestCtx(c)
 	if err != nil {
@@ -59,11 +65,16 @@ func (h *getEventDataV2Handler) Invoke(ctx *api.Context, c *fiber.Ctx) (interfac
 	}
 
 	// get eventName from params
-	req.EventName = c.Params("event_name")
-	if req.Address == "" {
-		return nil, fiber.StatusInternalServerError, errors.Wrap(
+	req.EventName, err = getEventIdentifierParam(c)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
 			err,
-			"events: getEventDataV2Handler.Invoke c.Params(event_name) error",
+			"events: getEventDataV2Handler.Invoke getEventIdentifierParam error",
+		)
+	}
+	if req.EventName == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"events: getEventDataV2Handler.Invoke invalid event_name param error",
 		)
 	}
 
@@ -78,12 +89,15 @@ func (h *getEventDataV2Handler) invoke(ctx *api.Context, req *getEventDataV2Hand
 	}
 
 	output, err := ctx.SyncEngine.SelectEventData(&sync.SelectEventDataInput{
+		UserID:               req.UserID,
 		SmartContractAddress: req.Address,
 		EventName:            req.EventName,
+		FromTime:             req.FromTime,
+		ToTime:               req.ToTime,
 		Pagination:           req.Pagination,
 	})
 	if err != nil {
-		return nil, fiber.StatusInternalServerError, errors.Wrap(
+		return nil, getEventErrorStatus(err), errors.Wrap(
 			err,
 			"events: getEventDataV2Handler.invoke syncEngine.SelectEventData error",
 		)
@@ -100,15 +114,22 @@ func (h *getEventDataV2Handler) invoke(ctx *api.Context, req *getEventDataV2Hand
 			AbiID:                output.Event.AbiID,
 			Network:              string(output.Event.Network),
 			Name:                 output.Event.Name,
+			Signature:            output.Event.Signature,
+			Topic0:               output.Event.Topic0,
 			NodeURL:              output.Event.NodeURL,
 			Address:              output.Event.Address,
 			LatestBlockNumber:    output.Event.LatestBlockNumber,
+			ObservedBlockNumber:  output.Event.ObservedBlockNumber,
+			FinalizedBlockNumber: output.Event.FinalizedBlockNumber,
 			SmartContractAddress: output.Event.SmartContractAddress,
 			Status:               string(output.Event.Status),
 			Error:                output.Event.Error,
+			Failures:             output.Event.Failures,
+			NextRunAt:            output.Event.NextRunAt,
 			CreatedAt:            output.Event.CreatedAt,
 			UpdatedAt:            output.Event.UpdatedAt,
 		}
+		setEventLag(ctx, res.Event, output.Event)
 
 		// prepare ABI if exists
 		if output.Event.ABI != nil {
@@ -137,12 +158,16 @@ func (h *getEventDataV2Handler) invoke(ctx *api.Context, req *getEventDataV2Hand
 		// iterate over events
 		for _, data := range output.EventsData {
 			dataReq := &EventDataRes{
-				ID:          data.ID,
-				EventID:     data.EventID,
-				Tx:          data.Tx,
-				BlockNumber: data.BlockNumber,
-				Data:        data.Data,
-				CreatedAt:   data.CreatedAt,
+				ID:             data.ID,
+				EventID:        data.EventID,
+				Tx:             data.Tx,
+				TxIndex:        data.TxIndex,
+				LogIndex:       data.LogIndex,
+				BlockNumber:    data.BlockNumber,
+				BlockHash:      data.BlockHash,
+				BlockTimestamp: data.BlockTimestamp,
+				Data:           data.Data,
+				CreatedAt:      data.CreatedAt,
 			}
 
 			res.Datas = append(res.Datas, dataReq)
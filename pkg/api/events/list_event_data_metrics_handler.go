@@ -0,0 +1,122 @@
+package events
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+// defaultMetricsInterval is the size of the buckets in seconds when the interval isn't defined
+const defaultMetricsInterval = int64(3600)
+
+type listEventDataMetricsHandler struct{}
+
+type listEventDataMetricsHandlerRequest struct {
+	UserID    string
+	Address   string
+	EventName string
+	FromTime  time.Time
+	ToTime    time.Time
+	Interval  int64
+}
+
+type listEventDataMetricsHandlerResponse struct {
+	Interval int64                 `json:"interval"`
+	Buckets  []*EventDataBucketRes `json:"buckets"`
+}
+
+func (h *listEventDataMetricsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &listEventDataMetricsHandlerRequest{}
+
+	// get time range and interval
+	p := &pagination.Pagination{}
+	err := p.GetPaginationFromFiber(c)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"events: listEventDataMetricsHandler.Invoke p.GetPaginationFromFiber error",
+		)
+	}
+	req.FromTime = time.Unix(p.StartTime, 0).UTC()
+	req.ToTime = time.Unix(p.EndTime, 0).UTC()
+	req.Interval = p.Interval
+	if c.Query("interval") == "" {
+		req.Interval = defaultMetricsInterval
+	}
+	if req.Interval <= 0 {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"events: listEventDataMetricsHandler.Invoke invalid interval param error",
+		)
+	}
+
+	// get user id
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"events: listEventDataMetricsHandler.Invoke api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address from params
+	req.Address = c.Params("address")
+	if req.Address == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"events: listEventDataMetricsHandler.Invoke invalid address param error",
+		)
+	}
+
+	// get eventName from params
+	req.EventName, err = getEventIdentifierParam(c)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"events: listEventDataMetricsHandler.Invoke getEventIdentifierParam error",
+		)
+	}
+	if req.EventName == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"events: listEventDataMetricsHandler.Invoke invalid event_name param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *listEventDataMetricsHandler) invoke(ctx *api.Context, req *listEventDataMetricsHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.SelectEventDataMetrics(&sync.SelectEventDataMetricsInput{
+		UserID:               req.UserID,
+		SmartContractAddress: req.Address,
+		EventName:            req.EventName,
+		FromTime:             req.FromTime,
+		ToTime:               req.ToTime,
+		Interval:             req.Interval,
+	})
+	if err != nil {
+		return nil, getEventErrorStatus(err), errors.Wrap(
+			err,
+			"events: listEventDataMetricsHandler.invoke ctx.SyncEngine.SelectEventDataMetrics error",
+		)
+	}
+
+	// define response
+	res := &listEventDataMetricsHandlerResponse{
+		Interval: req.Interval,
+		Buckets:  make([]*EventDataBucketRes, 0),
+	}
+
+	for _, b := range output.Buckets {
+		res.Buckets = append(res.Buckets, &EventDataBucketRes{
+			Timestamp: b.Bucket.Unix(),
+			Count:     b.Count,
+		})
+	}
+
+	return res, fiber.StatusOK, nil
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type listEventErrorsHandler struct{}
+
+type listEventErrorsHandlerRequest struct {
+	UserID    string
+	Address   string
+	EventName string
+}
+
+type listEventErrorsHandlerResponse struct {
+	Failures  int              `json:"failures"`
+	NextRunAt *time.Time       `json:"nextRunAt"`
+	Errors    []*EventErrorRes `json:"errors"`
+}
+
+func (h *listEventErrorsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &listEventErrorsHandlerRequest{}
+
+	// get user id
+	var err error
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"events: listEventErrorsHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address and event name from params
+	req.Address = c.Params("address")
+	req.EventName, err = getEventIdentifierParam(c)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"events: listEventErrorsHandler.Invoke getEventIdentifierParam error",
+		)
+	}
+	if req.Address == "" || req.EventName == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"events: listEventErrorsHandler.Invoke invalid address or event_name params error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *listEventErrorsHandler) invoke(ctx *api.Context, req *listEventErrorsHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.SelectEventErrors(&sync.SelectEventErrorsInput{
+		SmartContractAddress: req.Address,
+		EventName:            req.EventName,
+	})
+	if err != nil {
+		return nil, getEventErrorStatus(err), errors.Wrap(
+			err,
+			"events: listEventErrorsHandler.invoke ctx.SyncEngine.SelectEventErrors error",
+		)
+	}
+
+	return &listEventErrorsHandlerResponse{
+		Failures:  output.Event.Failures,
+		NextRunAt: output.Event.NextRunAt,
+		Errors:    toEventErrorsRes(output.Errors),
+	}, fiber.StatusOK, nil
+}
+
+func toEventErrorsRes(records []*storage.EventErrorRecord) []*EventErrorRes {
+	res := make([]*EventErrorRes, 0)
+	for _, ee := range records {
+		res = append(res, &EventErrorRes{
+			ID:        ee.ID,
+			EventID:   ee.EventID,
+			Error:     ee.Error,
+			Attempt:   ee.Attempt,
+			CreatedAt: ee.CreatedAt,
+		})
+	}
+
+	return res
+}
@@ -84,15 +84,22 @@ func (h *getEventsByAddressV2Handler) invoke(ctx *api.Context, req *getEventsByA
 			AbiID:                event.AbiID,
 			Network:              string(event.Network),
 			Name:                 event.Name,
+			Signature:            event.Signature,
+			Topic0:               event.Topic0,
 			NodeURL:              event.NodeURL,
 			Address:              event.Address,
 			LatestBlockNumber:    event.LatestBlockNumber,
+			ObservedBlockNumber:  event.ObservedBlockNumber,
+			FinalizedBlockNumber: event.FinalizedBlockNumber,
 			SmartContractAddress: event.SmartContractAddress,
 			Status:               string(event.Status),
 			Error:                event.Error,
+			Failures:             event.Failures,
+			NextRunAt:            event.NextRunAt,
 			CreatedAt:            event.CreatedAt,
 			UpdatedAt:            event.UpdatedAt,
 		}
+		setEventLag(ctx, eventRes, event)
 
 		// marshal ABI record
 		b, err := event.ABI.MarshalJson()
@@ -0,0 +1,113 @@
+package events
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type listQuarantinedLogsHandler struct{}
+
+type listQuarantinedLogsHandlerRequest struct {
+	UserID     string
+	Address    string
+	EventName  string
+	Pagination *pagination.Pagination
+}
+
+type listQuarantinedLogsHandlerResponse struct {
+	QuarantinedLogs []*QuarantinedLogRes       `json:"quarantinedLogs"`
+	Pagination      *pagination.PaginationMeta `json:"pagination,omitempty"`
+}
+
+func (h *listQuarantinedLogsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &listQuarantinedLogsHandlerRequest{}
+
+	// get pagination
+	p := &pagination.Pagination{}
+	err := p.GetPaginationFromFiber(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"events: listQuarantinedLogsHandler.Invoke p.GetPaginationFromFiber error",
+		)
+	}
+	req.Pagination = p
+
+	// get user id
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"events: listQuarantinedLogsHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address and event name from params
+	req.Address = c.Params("address")
+	req.EventName, err = getEventIdentifierParam(c)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"events: listQuarantinedLogsHandler.Invoke getEventIdentifierParam error",
+		)
+	}
+	if req.Address == "" || req.EventName == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"events: listQuarantinedLogsHandler.Invoke invalid address or event_name params error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *listQuarantinedLogsHandler) invoke(ctx *api.Context, req *listQuarantinedLogsHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.SelectQuarantinedLogs(&sync.SelectQuarantinedLogsInput{
+		SmartContractAddress: req.Address,
+		EventName:            req.EventName,
+		Pagination:           req.Pagination,
+	})
+	if err != nil {
+		return nil, getEventErrorStatus(err), errors.Wrap(
+			err,
+			"events: listQuarantinedLogsHandler.invoke ctx.SyncEngine.SelectQuarantinedLogs error",
+		)
+	}
+
+	// prepare pagination
+	pagination := req.Pagination.GetPaginationMeta(output.TotalElements)
+
+	return &listQuarantinedLogsHandlerResponse{
+		QuarantinedLogs: toQuarantinedLogsRes(output.QuarantinedLogs),
+		Pagination:      &pagination,
+	}, fiber.StatusOK, nil
+}
+
+func toQuarantinedLogsRes(records []*storage.QuarantinedLogRecord) []*QuarantinedLogRes {
+	res := make([]*QuarantinedLogRes, 0)
+	for _, ql := range records {
+		res = append(res, &QuarantinedLogRes{
+			ID:             ql.ID,
+			EventID:        ql.EventID,
+			Tx:             ql.Tx,
+			TxIndex:        ql.TxIndex,
+			LogIndex:       ql.LogIndex,
+			BlockNumber:    ql.BlockNumber,
+			BlockHash:      ql.BlockHash,
+			BlockTimestamp: ql.BlockTimestamp,
+			Topics:         ql.Topics,
+			Data:           ql.Data,
+			Error:          ql.Error,
+			Attempts:       ql.Attempts,
+			CreatedAt:      ql.CreatedAt,
+			UpdatedAt:      ql.UpdatedAt,
+		})
+	}
+
+	return res
+}
@@ -10,12 +10,20 @@ type EventRes struct {
 	AbiID                string     `json:"abi_id"`
 	Network              string     `json:"network"`
 	Name                 string     `json:"name"`
+	Signature            string     `json:"signature"`
+	Topic0               string     `json:"topic0"`
 	NodeURL              string     `json:"nodeURL"`
 	Address              string     `json:"address"`
 	LatestBlockNumber    int64      `json:"latestBlockNumber"`
+	ObservedBlockNumber  int64      `json:"observedBlockNumber"`
+	FinalizedBlockNumber int64      `json:"finalizedBlockNumber"`
+	HeadBlockNumber      int64      `json:"headBlockNumber"`
+	Lag                  int64      `json:"lag"`
 	SmartContractAddress string     `json:"scAddress"`
 	Status               string     `json:"status"`
 	Error                string     `json:"error"`
+	Failures             int        `json:"failures"`
+	NextRunAt            *time.Time `json:"nextRunAt"`
 	CreatedAt            time.Time  `json:"created_at"`
 	UpdatedAt            *time.Time `json:"updated_at"`
 	ABI                  *AbiRes    `json:"abi"`
@@ -37,10 +45,44 @@ type InputRes struct {
 }
 
 type EventDataRes struct {
-	ID          string          `json:"id"`
-	EventID     string          `json:"eventId"`
-	Tx          string          `json:"tx"`
-	Data        json.RawMessage `json:"data"`
-	BlockNumber int64           `json:"block_number"`
-	CreatedAt   time.Time       `json:"created_at"`
+	ID             string          `json:"id"`
+	EventID        string          `json:"eventId"`
+	Tx             string          `json:"tx"`
+	TxIndex        int64           `json:"tx_index"`
+	LogIndex       int64           `json:"log_index"`
+	Data           json.RawMessage `json:"data"`
+	BlockNumber    int64           `json:"block_number"`
+	BlockHash      string          `json:"block_hash"`
+	BlockTimestamp *time.Time      `json:"block_timestamp"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type QuarantinedLogRes struct {
+	ID             string          `json:"id"`
+	EventID        string          `json:"eventId"`
+	Tx             string          `json:"tx"`
+	TxIndex        int64           `json:"tx_index"`
+	LogIndex       int64           `json:"log_index"`
+	BlockNumber    int64           `json:"block_number"`
+	BlockHash      string          `json:"block_hash"`
+	BlockTimestamp *time.Time      `json:"block_timestamp"`
+	Topics         json.RawMessage `json:"topics"`
+	Data           string          `json:"data"`
+	Error          string          `json:"error"`
+	Attempts       int64           `json:"attempts"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      *time.Time      `json:"updated_at"`
+}
+
+type EventDataBucketRes struct {
+	Timestamp int64 `json:"timestamp"`
+	Count     int64 `json:"count"`
+}
+
+type EventErrorRes struct {
+	ID        string    `json:"id"`
+	EventID   string    `json:"eventId"`
+	Error     string    `json:"error"`
+	Attempt   int       `json:"attempt"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type retryQuarantinedLogsHandler struct{}
+
+type retryQuarantinedLogsHandlerRequest struct {
+	UserID    string
+	Address   string
+	EventName string
+}
+
+type retryQuarantinedLogsHandlerResponse struct {
+	Datas           []*EventDataRes      `json:"datas"`
+	QuarantinedLogs []*QuarantinedLogRes `json:"quarantinedLogs"`
+}
+
+func (h *retryQuarantinedLogsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &retryQuarantinedLogsHandlerRequest{}
+
+	// get user id
+	var err error
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"events: retryQuarantinedLogsHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address and event name from params
+	req.Address = c.Params("address")
+	req.EventName, err = getEventIdentifierParam(c)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"events: retryQuarantinedLogsHandler.Invoke getEventIdentifierParam error",
+		)
+	}
+	if req.Address == "" || req.EventName == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"events: retryQuarantinedLogsHandler.Invoke invalid address or event_name params error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *retryQuarantinedLogsHandler) invoke(ctx *api.Context, req *retryQuarantinedLogsHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.RetryQuarantinedLogs(&sync.RetryQuarantinedLogsInput{
+		SmartContractAddress: req.Address,
+		EventName:            req.EventName,
+		UpdatedAt:            ctx.DateGen(),
+	})
+	if err != nil {
+		return nil, getEventErrorStatus(err), errors.Wrap(
+			err,
+			"events: retryQuarantinedLogsHandler.invoke ctx.SyncEngine.RetryQuarantinedLogs error",
+		)
+	}
+
+	// define response
+	res := &retryQuarantinedLogsHandlerResponse{
+		Datas:           make([]*EventDataRes, 0),
+		QuarantinedLogs: toQuarantinedLogsRes(output.QuarantinedLogs),
+	}
+
+	for _, data := range output.EventsData {
+		res.Datas = append(res.Datas, &EventDataRes{
+			ID:             data.ID,
+			EventID:        data.EventID,
+			Tx:             data.Tx,
+			TxIndex:        data.TxIndex,
+			LogIndex:       data.LogIndex,
+			BlockNumber:    data.BlockNumber,
+			BlockHash:      data.BlockHash,
+			BlockTimestamp: data.BlockTimestamp,
+			Data:           data.Data,
+			CreatedAt:      data.CreatedAt,
+		})
+	}
+
+	return res, fiber.StatusOK, nil
+}
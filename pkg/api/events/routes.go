@@ -34,8 +34,16 @@ func Route(app *fiber.App, apiContext *api.Context) {
 	// handlers
 	getEventsByAddressV2Handler := &getEventsByAddressV2Handler{}
 	getEventDataV2Handler := &getEventDataV2Handler{}
+	listQuarantinedLogsHandler := &listQuarantinedLogsHandler{}
+	retryQuarantinedLogsHandler := &retryQuarantinedLogsHandler{}
+	listEventDataMetricsHandler := &listEventDataMetricsHandler{}
+	listEventErrorsHandler := &listEventErrorsHandler{}
 
 	// routing
 	app.Get("/api/v2/events/:address", auth.Middleware, api.HandleFunc(apiContext, getEventsByAddressV2Handler.Invoke))
 	app.Get("/api/v2/events/:address/data/:event_name", auth.Middleware, api.HandleFunc(apiContext, getEventDataV2Handler.Invoke))
+	app.Get("/api/v2/events/:address/metrics/:event_name", auth.Middleware, api.HandleFunc(apiContext, listEventDataMetricsHandler.Invoke))
+	app.Get("/api/v2/events/:address/errors/:event_name", auth.Middleware, api.HandleFunc(apiContext, listEventErrorsHandler.Invoke))
+	app.Get("/api/v2/events/:address/quarantine/:event_name", auth.Middleware, api.HandleFunc(apiContext, listQuarantinedLogsHandler.Invoke))
+	app.Post("/api/v2/events/:address/quarantine/:event_name/retry", auth.Middleware, api.HandleFunc(apiContext, retryQuarantinedLogsHandler.Invoke))
 }
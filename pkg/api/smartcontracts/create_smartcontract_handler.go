@@ -14,7 +14,6 @@ import (
 	"github.com/darchlabs/synchronizer-v2/pkg/smartcontract"
 	"github.com/darchlabs/synchronizer-v2/pkg/util"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
 	"github.com/pkg/errors"
@@ -75,28 +74,21 @@ func insertSmartContractHandler(ctx *api.Context, c *fiber.Ctx) (interface{}, in
 	// get and validate node url
 	nodeURL := body.SmartContract.NodeURL
 	network := string(body.SmartContract.Network)
-	err = util.NodeURLIsValid(nodeURL, network)
+	err = util.NodeURLIsValid(ctx.RPCPool, nodeURL, network)
 	if err != nil {
-		networksEtherscanURL, err := util.ParseStringifiedMap(ctx.Env.NetworksNodeURL)
-		if err != nil {
-			// CHECKPOINT
-			return nil, fiber.StatusInternalServerError, errors.Wrap(
-				err,
-				"smartcontracts: insertSmartContractHandler util.ParseStringifiedMap can't valid ethclient error",
-			)
-		}
-
-		nodeURL = networksEtherscanURL[network]
+		// use the nodes of the network pool when the given node url isn't valid
+		nodeURL = ""
 	}
 
-	// instance client
-	client, err := ethclient.Dial(nodeURL)
+	// get client of the node from the pool
+	client, nodeURL, err := ctx.RPCPool.Client(network, nodeURL)
 	if err != nil {
 		return nil, fiber.StatusInternalServerError, errors.Wrap(
 			err,
-			"smartcontracts: insertSmartContractHandler ethclient.Dial can't valid ethclient error",
+			"smartcontracts: insertSmartContractHandler ctx.RPCPool.Client can't valid ethclient error",
 		)
 	}
+	defer ctx.RPCPool.Release(client)
 
 	// validate contract exists at the given address
 	code, err := client.CodeAt(context.Background(), common.HexToAddress(body.SmartContract.Address), nil)
@@ -0,0 +1,76 @@
+package smartcontracts
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type getBackfillsHandler struct{}
+
+type getBackfillsHandlerRequest struct {
+	UserID  string
+	Address string
+	// ID returns a single job with its chunks when it's defined
+	ID string
+}
+
+type getBackfillsHandlerResponse struct {
+	Backfills []*BackfillResponse `json:"backfills"`
+}
+
+func (h *getBackfillsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &getBackfillsHandlerRequest{
+		ID: c.Params("id"),
+	}
+
+	// get user id
+	var err error
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"smartcontracts: getBackfillsHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address from params
+	req.Address = c.Params("address")
+	if req.Address == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"smartcontracts: getBackfillsHandler.Invoke invalid address param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *getBackfillsHandler) invoke(ctx *api.Context, req *getBackfillsHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.SelectBackfillJobs(&sync.SelectBackfillJobsInput{
+		UserID:               req.UserID,
+		SmartContractAddress: req.Address,
+		ID:                   req.ID,
+	})
+	if err != nil {
+		return nil, getBackfillErrorStatus(err), errors.Wrap(
+			err,
+			"smartcontracts: getBackfillsHandler.invoke ctx.SyncEngine.SelectBackfillJobs error",
+		)
+	}
+
+	if req.ID != "" {
+		return toBackfillResponse(output.BackfillJobs[0], true), fiber.StatusOK, nil
+	}
+
+	res := &getBackfillsHandlerResponse{
+		Backfills: make([]*BackfillResponse, 0),
+	}
+	for _, b := range output.BackfillJobs {
+		res.Backfills = append(res.Backfills, toBackfillResponse(b, false))
+	}
+
+	return res, fiber.StatusOK, nil
+}
@@ -0,0 +1,67 @@
+package smartcontracts
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type getCoverageHandler struct{}
+
+type getCoverageHandlerRequest struct {
+	UserID  string
+	Address string
+}
+
+type getCoverageHandlerResponse struct {
+	Events []*EventCoverageResponse `json:"events"`
+}
+
+func (h *getCoverageHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &getCoverageHandlerRequest{}
+
+	// get user id
+	var err error
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"smartcontracts: getCoverageHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address from params
+	req.Address = c.Params("address")
+	if req.Address == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"smartcontracts: getCoverageHandler.Invoke invalid address param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *getCoverageHandler) invoke(ctx *api.Context, req *getCoverageHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.SelectCoverage(&sync.SelectCoverageInput{
+		UserID:               req.UserID,
+		SmartContractAddress: req.Address,
+	})
+	if err != nil {
+		return nil, getCoverageErrorStatus(err), errors.Wrap(
+			err,
+			"smartcontracts: getCoverageHandler.invoke ctx.SyncEngine.SelectCoverage error",
+		)
+	}
+
+	res := &getCoverageHandlerResponse{
+		Events: make([]*EventCoverageResponse, 0),
+	}
+	for _, ec := range output.Events {
+		res.Events = append(res.Events, toEventCoverageResponse(ec))
+	}
+
+	return res, fiber.StatusOK, nil
+}
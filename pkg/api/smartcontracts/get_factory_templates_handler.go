@@ -0,0 +1,67 @@
+package smartcontracts
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type getFactoryTemplatesHandler struct{}
+
+type getFactoryTemplatesHandlerRequest struct {
+	UserID  string
+	Address string
+}
+
+type getFactoryTemplatesHandlerResponse struct {
+	FactoryTemplates []*FactoryTemplateResponse `json:"factories"`
+}
+
+func (h *getFactoryTemplatesHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &getFactoryTemplatesHandlerRequest{}
+
+	// get user id
+	var err error
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"smartcontracts: getFactoryTemplatesHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address from params
+	req.Address = c.Params("address")
+	if req.Address == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"smartcontracts: getFactoryTemplatesHandler.Invoke invalid address param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *getFactoryTemplatesHandler) invoke(ctx *api.Context, req *getFactoryTemplatesHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.SelectFactoryTemplates(&sync.SelectFactoryTemplatesInput{
+		UserID:               req.UserID,
+		SmartContractAddress: req.Address,
+	})
+	if err != nil {
+		return nil, getFactoryTemplateErrorStatus(err), errors.Wrap(
+			err,
+			"smartcontracts: getFactoryTemplatesHandler.invoke ctx.SyncEngine.SelectFactoryTemplates error",
+		)
+	}
+
+	res := &getFactoryTemplatesHandlerResponse{
+		FactoryTemplates: make([]*FactoryTemplateResponse, 0),
+	}
+	for _, ft := range output.FactoryTemplates {
+		res.FactoryTemplates = append(res.FactoryTemplates, toFactoryTemplateResponse(ft))
+	}
+
+	return res, fiber.StatusOK, nil
+}
@@ -0,0 +1,84 @@
+package smartcontracts
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type getRawLogsHandler struct{}
+
+type getRawLogsHandlerRequest struct {
+	UserID     string
+	Address    string
+	Pagination *pagination.Pagination
+}
+
+type getRawLogsHandlerResponse struct {
+	RawLogs    []*RawLogResponse          `json:"logs"`
+	Pagination *pagination.PaginationMeta `json:"pagination,omitempty"`
+}
+
+func (h *getRawLogsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &getRawLogsHandlerRequest{}
+
+	// get pagination
+	p := &pagination.Pagination{}
+	err := p.GetPaginationFromFiber(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"smartcontracts: getRawLogsHandler.Invoke p.GetPaginationFromFiber error",
+		)
+	}
+	req.Pagination = p
+
+	// get user id
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"smartcontracts: getRawLogsHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address from params
+	req.Address = c.Params("address")
+	if req.Address == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"smartcontracts: getRawLogsHandler.Invoke invalid address param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *getRawLogsHandler) invoke(ctx *api.Context, req *getRawLogsHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.SelectRawLogs(&sync.SelectRawLogsInput{
+		UserID:               req.UserID,
+		SmartContractAddress: req.Address,
+		Pagination:           req.Pagination,
+	})
+	if err != nil {
+		return nil, getRawLogsErrorStatus(err), errors.Wrap(
+			err,
+			"smartcontracts: getRawLogsHandler.invoke ctx.SyncEngine.SelectRawLogs error",
+		)
+	}
+
+	res := &getRawLogsHandlerResponse{
+		RawLogs: make([]*RawLogResponse, 0),
+	}
+	for _, rl := range output.RawLogs {
+		res.RawLogs = append(res.RawLogs, toRawLogResponse(rl))
+	}
+
+	pagination := req.Pagination.GetPaginationMeta(output.TotalElements)
+	res.Pagination = &pagination
+
+	return res, fiber.StatusOK, nil
+}
@@ -1,6 +1,7 @@
 package smartcontracts
 
 import (
+	"github.com/darchlabs/synchronizer-v2/internal/abitemplate"
 	"github.com/darchlabs/synchronizer-v2/internal/pagination"
 	"github.com/darchlabs/synchronizer-v2/internal/sync"
 	"github.com/darchlabs/synchronizer-v2/pkg/api"
@@ -12,6 +13,7 @@ type getSmartContractV2Handler struct{}
 
 type getSmartContractV2HandlerRequest struct {
 	UserID     string
+	Template   string
 	Pagination *pagination.Pagination
 }
 
@@ -44,6 +46,18 @@ func (h *getSmartContractV2Handler) Invoke(ctx *api.Context, c *fiber.Ctx) (inte
 		)
 	}
 
+	// get the template of the contracts, all the contracts are returned when it isn't defined
+	if template := c.Query("template"); template != "" {
+		t, err := abitemplate.Parse(template)
+		if err != nil {
+			return nil, fiber.StatusBadRequest, errors.Wrap(
+				err,
+				"smartcontracts: getSmartContractV2Handler.Invoke abitemplate.Parse error",
+			)
+		}
+		req.Template = string(t)
+	}
+
 	return h.invoke(ctx, req)
 }
 
@@ -51,6 +65,7 @@ func (h *getSmartContractV2Handler) Invoke(ctx *api.Context, c *fiber.Ctx) (inte
 func (h *getSmartContractV2Handler) invoke(ctx *api.Context, req *getSmartContractV2HandlerRequest) (interface{}, int, error) {
 	output, err := ctx.SyncEngine.SelectUserSmartContractsWithEvents(&sync.SelectUserSmartContractsWithEventsInput{
 		UserID:     req.UserID,
+		Template:   req.Template,
 		Pagination: req.Pagination,
 	})
 	if err != nil {
@@ -76,6 +91,8 @@ func (h *getSmartContractV2Handler) invoke(ctx *api.Context, req *getSmartContra
 			Status:             sc.Status,
 			LastTxBlockSynced:  sc.LastTxBlockSynced,
 			InitialBlockNumber: sc.InitialBlockNumber,
+			Template:           sc.Template,
+			RawLogs:            sc.RawLogs,
 			Error:              sc.Error,
 		}
 
@@ -83,10 +100,12 @@ func (h *getSmartContractV2Handler) invoke(ctx *api.Context, req *getSmartContra
 		events := make([]*EventResponse, 0)
 		for _, e := range sc.Events {
 			eventRes := &EventResponse{
-				ID:     e.ID,
-				Name:   e.Name,
-				Status: e.Status,
-				Error:  e.Error,
+				ID:        e.ID,
+				Name:      e.Name,
+				Signature: e.Signature,
+				Topic0:    e.Topic0,
+				Status:    e.Status,
+				Error:     e.Error,
 			}
 			events = append(events, eventRes)
 		}
@@ -0,0 +1,83 @@
+package smartcontracts
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type postBackfillHandler struct {
+	validate *validator.Validate
+}
+
+type postBackfillHandlerRequest struct {
+	UserID   string
+	Address  string
+	Backfill *BackfillRequest
+}
+
+func (h *postBackfillHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &postBackfillHandlerRequest{
+		Backfill: &BackfillRequest{},
+	}
+
+	err := c.BodyParser(req.Backfill)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"smartcontracts: postBackfillHandler.Invoke c.BodyParser error",
+		)
+	}
+
+	err = h.validate.Struct(req.Backfill)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"smartcontracts: postBackfillHandler.Invoke h.validate.Struct error",
+		)
+	}
+
+	// get user id
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"smartcontracts: postBackfillHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address from params
+	req.Address = c.Params("address")
+	if req.Address == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"smartcontracts: postBackfillHandler.Invoke invalid address param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *postBackfillHandler) invoke(ctx *api.Context, req *postBackfillHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.InsertBackfillJob(&sync.InsertBackfillJobInput{
+		UserID:               req.UserID,
+		SmartContractAddress: req.Address,
+		FromBlockNumber:      *req.Backfill.FromBlockNumber,
+		ToBlockNumber:        req.Backfill.ToBlockNumber,
+		Heads:                ctx.Heads,
+		ChunkSize:            req.Backfill.ChunkSize,
+		Concurrency:          req.Backfill.Concurrency,
+		CreatedAt:            ctx.DateGen(),
+	})
+	if err != nil {
+		return nil, getBackfillErrorStatus(err), errors.Wrap(
+			err,
+			"smartcontracts: postBackfillHandler.invoke ctx.SyncEngine.InsertBackfillJob error",
+		)
+	}
+
+	return toBackfillResponse(output.BackfillJob, false), fiber.StatusCreated, nil
+}
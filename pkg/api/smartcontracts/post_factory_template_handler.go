@@ -0,0 +1,82 @@
+package smartcontracts
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type postFactoryTemplateHandler struct {
+	validate *validator.Validate
+}
+
+type postFactoryTemplateHandlerRequest struct {
+	UserID          string
+	Address         string
+	FactoryTemplate *FactoryTemplateRequest
+}
+
+func (h *postFactoryTemplateHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &postFactoryTemplateHandlerRequest{
+		FactoryTemplate: &FactoryTemplateRequest{},
+	}
+
+	err := c.BodyParser(req.FactoryTemplate)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"smartcontracts: postFactoryTemplateHandler.Invoke c.BodyParser error",
+		)
+	}
+
+	err = h.validate.Struct(req.FactoryTemplate)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"smartcontracts: postFactoryTemplateHandler.Invoke h.validate.Struct error",
+		)
+	}
+
+	// get user id
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"smartcontracts: postFactoryTemplateHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address from params
+	req.Address = c.Params("address")
+	if req.Address == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"smartcontracts: postFactoryTemplateHandler.Invoke invalid address param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *postFactoryTemplateHandler) invoke(ctx *api.Context, req *postFactoryTemplateHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.InsertFactoryTemplate(&sync.InsertFactoryTemplateInput{
+		UserID:               req.UserID,
+		SmartContractAddress: req.Address,
+		EventName:            req.FactoryTemplate.EventName,
+		ChildAddressArg:      req.FactoryTemplate.ChildAddressArg,
+		ChildName:            req.FactoryTemplate.ChildName,
+		ChildABI:             req.FactoryTemplate.ABI,
+		CreatedAt:            ctx.DateGen(),
+	})
+	if err != nil {
+		return nil, getFactoryTemplateErrorStatus(err), errors.Wrap(
+			err,
+			"smartcontracts: postFactoryTemplateHandler.invoke ctx.SyncEngine.InsertFactoryTemplate error",
+		)
+	}
+
+	return toFactoryTemplateResponse(output.FactoryTemplate), fiber.StatusCreated, nil
+}
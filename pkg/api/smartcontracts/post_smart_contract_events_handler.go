@@ -0,0 +1,101 @@
+package smartcontracts
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type postSmartContractEventsHandler struct {
+	validate *validator.Validate
+}
+
+type postSmartContractEventsHandlerRequest struct {
+	UserID  string
+	Address string
+	Events  *SmartContractEventsRequest
+}
+
+type postSmartContractEventsHandlerResponse struct {
+	Events []*EventResponse `json:"events"`
+	// BackfilledLogs is the number of stored raw logs decoded into the new events
+	BackfilledLogs int64 `json:"backfilledLogs"`
+}
+
+func (h *postSmartContractEventsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &postSmartContractEventsHandlerRequest{
+		Events: &SmartContractEventsRequest{},
+	}
+
+	err := c.BodyParser(req.Events)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"smartcontracts: postSmartContractEventsHandler.Invoke c.BodyParser error",
+		)
+	}
+
+	err = h.validate.Struct(req.Events)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"smartcontracts: postSmartContractEventsHandler.Invoke h.validate.Struct error",
+		)
+	}
+
+	// get user id
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"smartcontracts: postSmartContractEventsHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get address from params
+	req.Address = c.Params("address")
+	if req.Address == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"smartcontracts: postSmartContractEventsHandler.Invoke invalid address param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *postSmartContractEventsHandler) invoke(ctx *api.Context, req *postSmartContractEventsHandlerRequest) (interface{}, int, error) {
+	abi, err := TransformAbiReqToABIRecords(req.Address, req.Events.ABI)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"smartcontracts: postSmartContractEventsHandler.invoke TransformAbiReqToABIRecords error",
+		)
+	}
+
+	output, err := ctx.SyncEngine.InsertSmartContractEvents(&sync.InsertSmartContractEventsInput{
+		UserID:               req.UserID,
+		SmartContractAddress: req.Address,
+		ABI:                  abi,
+		CreatedAt:            ctx.DateGen(),
+	})
+	if err != nil {
+		return nil, getRawLogsErrorStatus(err), errors.Wrap(
+			err,
+			"smartcontracts: postSmartContractEventsHandler.invoke ctx.SyncEngine.InsertSmartContractEvents error",
+		)
+	}
+
+	res := &postSmartContractEventsHandlerResponse{
+		Events:         make([]*EventResponse, 0),
+		BackfilledLogs: output.BackfilledLogs,
+	}
+	for _, ev := range output.Events {
+		res.Events = append(res.Events, toEventResponse(ev))
+	}
+
+	return res, fiber.StatusCreated, nil
+}
@@ -4,12 +4,13 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/darchlabs/synchronizer-v2/internal/abitemplate"
+	"github.com/darchlabs/synchronizer-v2/internal/explorer"
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
 	"github.com/darchlabs/synchronizer-v2/internal/sync"
 	"github.com/darchlabs/synchronizer-v2/pkg/api"
 	"github.com/darchlabs/synchronizer-v2/pkg/util"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
 	"github.com/pkg/errors"
@@ -62,28 +63,21 @@ func (h *postSmartContractV2Handler) invoke(ctx *api.Context, req *postSmartCont
 	// get and validate node url
 	nodeURL := req.SmartContract.NodeURL
 	network := string(req.SmartContract.Network)
-	err := util.NodeURLIsValid(nodeURL, network)
+	err := util.NodeURLIsValid(ctx.RPCPool, nodeURL, network)
 	if err != nil {
-		networksEtherscanURL, err := util.ParseStringifiedMap(ctx.Env.NetworksNodeURL)
-		if err != nil {
-			// CHECKPOINT
-			return nil, fiber.StatusInternalServerError, errors.Wrap(
-				err,
-				"smartcontracts: postSmartContractV2Handler.invoke util.ParseStringifiedMap can't valid ethclient error",
-			)
-		}
-
-		nodeURL = networksEtherscanURL[network]
+		// use the nodes of the network pool when the given node url isn't valid
+		nodeURL = ""
 	}
 
-	// instance client
-	client, err := ethclient.Dial(nodeURL)
+	// get client of the node from the pool
+	client, nodeURL, err := ctx.RPCPool.Client(network, nodeURL)
 	if err != nil {
 		return nil, fiber.StatusInternalServerError, errors.Wrap(
 			err,
-			"smartcontracts: postSmartContractV2Handler.invoke ethclient.Dial can't valid ethclient error",
+			"smartcontracts: postSmartContractV2Handler.invoke ctx.RPCPool.Client can't valid ethclient error",
 		)
 	}
+	defer ctx.RPCPool.Release(client)
 
 	// validate contract exists at the given address
 	code, err := client.CodeAt(context.Background(), common.HexToAddress(req.SmartContract.Address), nil)
@@ -110,37 +104,80 @@ func (h *postSmartContractV2Handler) invoke(ctx *api.Context, req *postSmartCont
 		)
 	}
 
-	// Loop over ABI
-	abi := make([]*storage.ABIRecord, 0)
-	for _, a := range req.SmartContract.ABI {
-		// input
-		bytes, err := json.Marshal(a.Inputs)
+	// use the built-in abi of the template in place of the abi
+	abiSource := &ABISourceRes{Source: abiSourceRequest}
+	var template abitemplate.Template
+	if req.SmartContract.Template != "" {
+		if len(req.SmartContract.ABI) > 0 {
+			return nil, fiber.StatusBadRequest, errors.New(
+				"smartcontracts: postSmartContractV2Handler.invoke abi and template can't be both defined error",
+			)
+		}
+
+		template, err = abitemplate.Parse(req.SmartContract.Template)
+		if err != nil {
+			return nil, fiber.StatusBadRequest, errors.Wrap(
+				err,
+				"smartcontracts: postSmartContractV2Handler.invoke abitemplate.Parse error",
+			)
+		}
+
+		templateABI, err := abitemplate.ABI(template)
 		if err != nil {
 			return nil, fiber.StatusInternalServerError, errors.Wrap(
 				err,
-				"smartcontracts: postSmartContractV2Handler.invoke json.Marshal input abi error",
+				"smartcontracts: postSmartContractV2Handler.invoke abitemplate.ABI error",
 			)
+		}
 
+		err = json.Unmarshal(templateABI, &req.SmartContract.ABI)
+		if err != nil {
+			return nil, fiber.StatusInternalServerError, errors.Wrap(
+				err,
+				"smartcontracts: postSmartContractV2Handler.invoke json.Unmarshal template abi error",
+			)
 		}
+		abiSource = &ABISourceRes{Source: abiSourceTemplate}
+	}
 
-		ipts := make([]*storage.InputABI, 0)
-		err = json.Unmarshal(bytes, &ipts)
+	// fetch the abi from the explorer when it isn't given, merging the implementation abi of proxies
+	if len(req.SmartContract.ABI) == 0 {
+		resolved, err := ctx.Explorer.ResolveABI(context.Background(), client, network, req.SmartContract.Address)
+		if err != nil {
+			status := fiber.StatusInternalServerError
+			switch errors.Cause(err) {
+			case explorer.ErrNotConfigured, explorer.ErrABINotFound:
+				status = fiber.StatusBadRequest
+			}
+
+			return nil, status, errors.Wrap(
+				err,
+				"smartcontracts: postSmartContractV2Handler.invoke ctx.Explorer.ResolveABI error",
+			)
+		}
+
+		err = json.Unmarshal(resolved.ABI, &req.SmartContract.ABI)
 		if err != nil {
 			return nil, fiber.StatusInternalServerError, errors.Wrap(
 				err,
-				"smartcontracts: postSmartContractV2Handler.invoke json.Unmarshal input abi error",
+				"smartcontracts: postSmartContractV2Handler.invoke json.Unmarshal resolved abi error",
 			)
+		}
 
+		abiSource = &ABISourceRes{
+			Source:         resolved.Source,
+			Proxy:          string(resolved.Proxy),
+			Implementation: resolved.Implementation,
 		}
+	}
 
-		// create ABI
-		abi = append(abi, &storage.ABIRecord{
-			SmartContractAddress: req.SmartContract.Address,
-			Name:                 a.Name,
-			Type:                 a.Type,
-			Anonymous:            a.Anonymous,
-			Inputs:               ipts,
-		})
+	// Loop over ABI
+	abi, err := TransformAbiReqToABIRecords(req.SmartContract.Address, req.SmartContract.ABI)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"smartcontracts: postSmartContractV2Handler.invoke TransformAbiReqToABIRecords error",
+		)
 	}
 
 	output, err := ctx.SyncEngine.InsertAtomicSmartContract(&sync.InsertAtomicSmartContractInput{
@@ -148,15 +185,24 @@ func (h *postSmartContractV2Handler) invoke(ctx *api.Context, req *postSmartCont
 		Name:       req.SmartContract.Name,
 		NodeURL:    nodeURL,
 		WebhookURL: req.SmartContract.WebhookURL,
+		Filters:    req.SmartContract.Filters,
 		SmartContract: &storage.SmartContractRecord{
 			Address:            req.SmartContract.Address,
 			Network:            storage.Network(req.SmartContract.Network),
 			InitialBlockNumber: int64(blockNumber),
+			Template:           string(template),
+			RawLogs:            req.SmartContract.RawLogs,
 		},
 		ABI: abi,
 	})
 	if err != nil {
-		return nil, fiber.StatusInternalServerError, errors.Wrap(
+		status := fiber.StatusInternalServerError
+		switch errors.Cause(err) {
+		case sync.ErrInvalidTopicFilter, sync.ErrEventNameAmbiguous:
+			status = fiber.StatusBadRequest
+		}
+
+		return nil, status, errors.Wrap(
 			err,
 			"smartcontracts: postSmartContractV2Handler.invoke syncEngine.InsertAtomicSmartContract error",
 		)
@@ -172,7 +218,11 @@ func (h *postSmartContractV2Handler) invoke(ctx *api.Context, req *postSmartCont
 		Status:             string(output.SmartContractUser.Status),
 		LastTxBlockSynced:  output.SmartContract.LastTxBlockSynced,
 		InitialBlockNumber: output.SmartContract.InitialBlockNumber,
+		Template:           output.SmartContract.Template,
+		RawLogs:            output.SmartContract.RawLogs,
 		Error:              output.SmartContractUser.ErrorMessage,
+		Filters:            TransformTopicFiltersToFilters(output.SmartContractUser.TopicFilters),
+		ABISource:          abiSource,
 	}
 
 	return scRes, fiber.StatusCreated, nil
@@ -1,19 +1,49 @@
 package smartcontracts
 
 import (
+	"encoding/json"
 	"time"
 
+	"github.com/darchlabs/synchronizer-v2/internal/coverage"
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
 )
 
 type SmartContractRequest struct {
-	UserID     string    `json:"-"`
-	Network    string    `json:"network" validate:"required"`
-	Name       string    `json:"name" validate:"required"`
-	Address    string    `json:"address" validate:"required"`
-	NodeURL    string    `json:"nodeUrl"`
-	WebhookURL string    `json:"webhook" validate:"omitempty,url"`
-	ABI        []*AbiReq `json:"abi"`
+	UserID     string `json:"-"`
+	Network    string `json:"network" validate:"required"`
+	Name       string `json:"name" validate:"required"`
+	Address    string `json:"address" validate:"required"`
+	NodeURL    string `json:"nodeUrl"`
+	WebhookURL string `json:"webhook" validate:"omitempty,url"`
+	// ABI is optional, it's fetched from the explorer of the network when it isn't given
+	ABI []*AbiReq `json:"abi"`
+	// Template is the standard interface of the contract, like erc20, erc721 or erc1155,
+	// its built-in abi is used in place of the abi
+	Template string `json:"template"`
+	// RawLogs stores every log of the contract, so the events added to the abi later are
+	// backfilled without requesting the logs again
+	RawLogs bool `json:"rawLogs"`
+	// Filters are the allowed values of the indexed arguments by event name, signature or
+	// topic0, like {"Transfer": {"to": ["0x..."]}}
+	Filters map[string]map[string][]string `json:"filters"`
+}
+
+const (
+	// abiSourceRequest is the source of the abis given in the request
+	abiSourceRequest = "request"
+	// abiSourceTemplate is the source of the built-in abis of the templates
+	abiSourceTemplate = "template"
+)
+
+// ABISourceRes is where the abi of the contract comes from, the request or the explorer. For
+// proxies fetched from the explorer it has the proxy standard and the implementation address.
+type ABISourceRes struct {
+	Source         string `json:"source"`
+	Proxy          string `json:"proxy,omitempty"`
+	Implementation string `json:"implementation,omitempty"`
 }
 
 type AbiReq struct {
@@ -31,6 +61,35 @@ type InputReq struct {
 	Type         string `json:"type"`
 }
 
+// TransformAbiReqToABIRecords returns the abi records of the contract from the abi request.
+func TransformAbiReqToABIRecords(address string, abi []*AbiReq) ([]*storage.ABIRecord, error) {
+	records := make([]*storage.ABIRecord, 0)
+	for _, a := range abi {
+		// input
+		bytes, err := json.Marshal(a.Inputs)
+		if err != nil {
+			return nil, errors.Wrap(err, "smartcontracts: TransformAbiReqToABIRecords json.Marshal input abi error")
+		}
+
+		ipts := make([]*storage.InputABI, 0)
+		err = json.Unmarshal(bytes, &ipts)
+		if err != nil {
+			return nil, errors.Wrap(err, "smartcontracts: TransformAbiReqToABIRecords json.Unmarshal input abi error")
+		}
+
+		// create ABI
+		records = append(records, &storage.ABIRecord{
+			SmartContractAddress: address,
+			Name:                 a.Name,
+			Type:                 a.Type,
+			Anonymous:            a.Anonymous,
+			Inputs:               ipts,
+		})
+	}
+
+	return records, nil
+}
+
 func TransformInputsJsonToArray(inputs []*storage.InputABI) ([]InputReq, error) {
 	inputReqs := make([]InputReq, 0)
 	for _, i := range inputs {
@@ -46,10 +105,12 @@ func TransformInputsJsonToArray(inputs []*storage.InputABI) ([]InputReq, error)
 }
 
 type EventResponse struct {
-	ID     string              `json:"id"`
-	Name   string              `json:"name"`
-	Status storage.EventStatus `json:"status"`
-	Error  string              `json:"error"`
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	Signature string              `json:"signature"`
+	Topic0    string              `json:"topic0"`
+	Status    storage.EventStatus `json:"status"`
+	Error     string              `json:"error"`
 }
 
 type SmartContractResponse struct {
@@ -62,10 +123,269 @@ type SmartContractResponse struct {
 	WebhookURL         string  `json:"webhook"`
 	LastTxBlockSynced  int64   `json:"lastTxBlockSynced"`
 	InitialBlockNumber int64   `json:"initialBlockNumber"`
+	Template           string  `json:"template,omitempty"`
+	RawLogs            bool    `json:"rawLogs"`
 	Error              *string `json:"error"`
 
 	Events []*EventResponse `json:"events,omitempty"`
+	// Filters are the allowed values of the indexed arguments by event signature
+	Filters map[string]map[string][]string `json:"filters,omitempty"`
+	// ABISource is where the abi of the contract comes from
+	ABISource *ABISourceRes `json:"abiSource,omitempty"`
 
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
 }
+
+// TransformTopicFiltersToFilters returns the allowed values of the indexed arguments of the
+// topic filters by event signature.
+func TransformTopicFiltersToFilters(topicFilters storage.TopicFilters) map[string]map[string][]string {
+	filters := make(map[string]map[string][]string)
+	for signature, f := range topicFilters {
+		if f != nil {
+			filters[signature] = f.Args
+		}
+	}
+
+	return filters
+}
+
+type FactoryTemplateRequest struct {
+	// EventName identifies the creation event by name, canonical signature or topic0
+	EventName       string          `json:"eventName" validate:"required"`
+	ChildAddressArg string          `json:"childAddressArg" validate:"required"`
+	ChildName       string          `json:"childName" validate:"required"`
+	ABI             json.RawMessage `json:"abi" validate:"required"`
+}
+
+type FactoryTemplateResponse struct {
+	ID              string          `json:"id"`
+	EventID         string          `json:"eventId"`
+	Address         string          `json:"address"`
+	ChildAddressArg string          `json:"childAddressArg"`
+	ChildName       string          `json:"childName"`
+	ABI             json.RawMessage `json:"abi"`
+	CreatedAt       time.Time       `json:"createdAt"`
+	UpdatedAt       *time.Time      `json:"updatedAt,omitempty"`
+}
+
+func toFactoryTemplateResponse(ft *storage.FactoryTemplateRecord) *FactoryTemplateResponse {
+	return &FactoryTemplateResponse{
+		ID:              ft.ID,
+		EventID:         ft.EventID,
+		Address:         ft.SmartContractAddress,
+		ChildAddressArg: ft.ChildAddressArg,
+		ChildName:       ft.ChildName,
+		ABI:             ft.ChildABI,
+		CreatedAt:       ft.CreatedAt,
+		UpdatedAt:       ft.UpdatedAt,
+	}
+}
+
+// getFactoryTemplateErrorStatus returns the http status of the errors of the factory templates.
+func getFactoryTemplateErrorStatus(err error) int {
+	switch errors.Cause(err) {
+	case sync.ErrSmartContractUserNotFound, sync.ErrEventNotFound:
+		return fiber.StatusNotFound
+	case sync.ErrInvalidFactoryTemplate, sync.ErrEventNameAmbiguous:
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+type SmartContractEventsRequest struct {
+	ABI []*AbiReq `json:"abi" validate:"required"`
+}
+
+type RawLogResponse struct {
+	ID             string                 `json:"id"`
+	Tx             string                 `json:"tx"`
+	TxIndex        int64                  `json:"txIndex"`
+	LogIndex       int64                  `json:"logIndex"`
+	BlockNumber    int64                  `json:"blockNumber"`
+	BlockHash      string                 `json:"blockHash"`
+	BlockTimestamp *time.Time             `json:"blockTimestamp,omitempty"`
+	Topics         []string               `json:"topics"`
+	Data           string                 `json:"data"`
+	EventName      string                 `json:"eventName,omitempty"`
+	Signature      string                 `json:"signature,omitempty"`
+	DecodedData    map[string]interface{} `json:"decodedData,omitempty"`
+	DecodeError    string                 `json:"decodeError,omitempty"`
+	CreatedAt      time.Time              `json:"createdAt"`
+}
+
+func toRawLogResponse(d *sync.DecodedRawLog) *RawLogResponse {
+	res := &RawLogResponse{
+		ID:             d.RawLog.ID,
+		Tx:             d.RawLog.Tx,
+		TxIndex:        d.RawLog.TxIndex,
+		LogIndex:       d.RawLog.LogIndex,
+		BlockNumber:    d.RawLog.BlockNumber,
+		BlockHash:      d.RawLog.BlockHash,
+		BlockTimestamp: d.RawLog.BlockTimestamp,
+		Topics:         d.RawLog.Topics,
+		Data:           d.RawLog.Data,
+		DecodedData:    d.Data,
+		DecodeError:    d.DecodeError,
+		CreatedAt:      d.RawLog.CreatedAt,
+	}
+	if d.Event != nil {
+		res.EventName = d.Event.Name
+		res.Signature = d.Event.Signature
+	}
+
+	return res
+}
+
+func toEventResponse(e *storage.EventRecord) *EventResponse {
+	return &EventResponse{
+		ID:        e.ID,
+		Name:      e.Name,
+		Signature: e.Signature,
+		Topic0:    e.Topic0,
+		Status:    e.Status,
+		Error:     e.Error,
+	}
+}
+
+// getRawLogsErrorStatus returns the http status of the errors of the raw logs.
+func getRawLogsErrorStatus(err error) int {
+	switch errors.Cause(err) {
+	case sync.ErrSmartContractUserNotFound:
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+type BackfillRequest struct {
+	FromBlockNumber *int64 `json:"fromBlockNumber" validate:"required"`
+	// ToBlockNumber is the finalized head of the contract events when it isn't defined
+	ToBlockNumber *int64 `json:"toBlockNumber"`
+	ChunkSize     int64  `json:"chunkSize"`
+	Concurrency   int    `json:"concurrency"`
+}
+
+type BackfillChunkResponse struct {
+	ID                string     `json:"id"`
+	FromBlockNumber   int64      `json:"fromBlockNumber"`
+	ToBlockNumber     int64      `json:"toBlockNumber"`
+	LatestBlockNumber int64      `json:"latestBlockNumber"`
+	Status            string     `json:"status"`
+	Attempts          int        `json:"attempts"`
+	Error             string     `json:"error,omitempty"`
+	UpdatedAt         *time.Time `json:"updatedAt,omitempty"`
+}
+
+type BackfillProgressResponse struct {
+	TotalBlocks     int64 `json:"totalBlocks"`
+	SyncedBlocks    int64 `json:"syncedBlocks"`
+	TotalChunks     int64 `json:"totalChunks"`
+	CompletedChunks int64 `json:"completedChunks"`
+	FailedChunks    int64 `json:"failedChunks"`
+}
+
+type BackfillResponse struct {
+	ID              string                    `json:"id"`
+	Address         string                    `json:"address"`
+	Network         string                    `json:"network"`
+	FromBlockNumber int64                     `json:"fromBlockNumber"`
+	ToBlockNumber   int64                     `json:"toBlockNumber"`
+	ChunkSize       int64                     `json:"chunkSize"`
+	Concurrency     int                       `json:"concurrency"`
+	Status          string                    `json:"status"`
+	Error           string                    `json:"error,omitempty"`
+	Progress        *BackfillProgressResponse `json:"progress"`
+	Chunks          []*BackfillChunkResponse  `json:"chunks,omitempty"`
+	CreatedAt       time.Time                 `json:"createdAt"`
+	UpdatedAt       *time.Time                `json:"updatedAt,omitempty"`
+}
+
+// toBackfillResponse returns the response of the job, the chunks are only included when
+// withChunks is true.
+func toBackfillResponse(b *sync.BackfillJob, withChunks bool) *BackfillResponse {
+	res := &BackfillResponse{
+		ID:              b.Job.ID,
+		Address:         b.Job.SmartContractAddress,
+		Network:         string(b.Job.Network),
+		FromBlockNumber: b.Job.FromBlockNumber,
+		ToBlockNumber:   b.Job.ToBlockNumber,
+		ChunkSize:       b.Job.ChunkSize,
+		Concurrency:     b.Job.Concurrency,
+		Status:          string(b.Job.Status),
+		Error:           b.Job.Error,
+		Progress: &BackfillProgressResponse{
+			TotalBlocks:     b.Progress.TotalBlocks,
+			SyncedBlocks:    b.Progress.SyncedBlocks,
+			TotalChunks:     b.Progress.TotalChunks,
+			CompletedChunks: b.Progress.CompletedChunks,
+			FailedChunks:    b.Progress.FailedChunks,
+		},
+		CreatedAt: b.Job.CreatedAt,
+		UpdatedAt: b.Job.UpdatedAt,
+	}
+	if !withChunks {
+		return res
+	}
+
+	res.Chunks = make([]*BackfillChunkResponse, 0)
+	for _, chunk := range b.Chunks {
+		res.Chunks = append(res.Chunks, &BackfillChunkResponse{
+			ID:                chunk.ID,
+			FromBlockNumber:   chunk.FromBlockNumber,
+			ToBlockNumber:     chunk.ToBlockNumber,
+			LatestBlockNumber: chunk.LatestBlockNumber,
+			Status:            string(chunk.Status),
+			Attempts:          chunk.Attempts,
+			Error:             chunk.Error,
+			UpdatedAt:         chunk.UpdatedAt,
+		})
+	}
+
+	return res
+}
+
+// getBackfillErrorStatus returns the http status of the errors of the backfill jobs.
+func getBackfillErrorStatus(err error) int {
+	switch errors.Cause(err) {
+	case sync.ErrSmartContractUserNotFound, sync.ErrBackfillJobNotFound:
+		return fiber.StatusNotFound
+	case sync.ErrInvalidBackfillJob:
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+type EventCoverageResponse struct {
+	EventID           string           `json:"eventId"`
+	Name              string           `json:"name"`
+	LatestBlockNumber int64            `json:"latestBlockNumber"`
+	CoveredBlocks     int64            `json:"coveredBlocks"`
+	Covered           []coverage.Range `json:"covered"`
+	Holes             []coverage.Range `json:"holes"`
+	Overlaps          []coverage.Range `json:"overlaps"`
+}
+
+func toEventCoverageResponse(ec *sync.EventCoverage) *EventCoverageResponse {
+	return &EventCoverageResponse{
+		EventID:           ec.Event.ID,
+		Name:              ec.Event.Name,
+		LatestBlockNumber: ec.Event.LatestBlockNumber,
+		CoveredBlocks:     ec.Report.CoveredBlocks,
+		Covered:           ec.Report.Covered,
+		Holes:             ec.Report.Holes,
+		Overlaps:          ec.Report.Overlaps,
+	}
+}
+
+// getCoverageErrorStatus returns the http status of the errors of the coverage ledger.
+func getCoverageErrorStatus(err error) int {
+	switch errors.Cause(err) {
+	case sync.ErrSmartContractUserNotFound:
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/darchlabs/backoffice/pkg/client"
 	"github.com/darchlabs/backoffice/pkg/middleware"
 	"github.com/darchlabs/synchronizer-v2"
+	"github.com/darchlabs/synchronizer-v2/internal/chainhead"
 	"github.com/darchlabs/synchronizer-v2/internal/env"
+	"github.com/darchlabs/synchronizer-v2/internal/explorer"
+	"github.com/darchlabs/synchronizer-v2/internal/rpcpool"
 	"github.com/darchlabs/synchronizer-v2/internal/sync"
 	txsengine "github.com/darchlabs/synchronizer-v2/internal/txsengine"
 	"github.com/darchlabs/synchronizer-v2/pkg/api"
@@ -23,6 +26,9 @@ type Context struct {
 	EventStorage synchronizer.EventStorage
 	Env          *env.Env
 	TxsEngine    txsengine.TxsEngine
+	RPCPool      *rpcpool.Pool
+	Heads        *chainhead.Tracker
+	Explorer     *explorer.Client
 
 	Engine *sync.Engine
 
@@ -44,6 +50,9 @@ func Route(app *fiber.App, ctx Context) {
 		EventStorage: ctx.EventStorage,
 		Env:          ctx.Env,
 		TxsEngine:    ctx.TxsEngine,
+		RPCPool:      ctx.RPCPool,
+		Heads:        ctx.Heads,
+		Explorer:     ctx.Explorer,
 		SyncEngine:   ctx.Engine,
 		IDGen:        api.IDGenerator(ctx.IDGen),
 		DateGen:      api.DateGenerator(ctx.DateGen),
@@ -60,6 +69,13 @@ func Route(app *fiber.App, ctx Context) {
 	// handlers
 	postSmartContractV2Handler := &postSmartContractV2Handler{validate}
 	getSmartContractV2Handler := &getSmartContractV2Handler{}
+	postFactoryTemplateHandler := &postFactoryTemplateHandler{validate}
+	getFactoryTemplatesHandler := &getFactoryTemplatesHandler{}
+	postSmartContractEventsHandler := &postSmartContractEventsHandler{validate}
+	getRawLogsHandler := &getRawLogsHandler{}
+	postBackfillHandler := &postBackfillHandler{validate}
+	getBackfillsHandler := &getBackfillsHandler{}
+	getCoverageHandler := &getCoverageHandler{}
 
 	// routing
 	app.Post(
@@ -68,4 +84,44 @@ func Route(app *fiber.App, ctx Context) {
 		api.HandleFunc(apiContext, postSmartContractV2Handler.Invoke),
 	)
 	app.Get("/api/v2/smartcontracts", auth.Middleware, api.HandleFunc(apiContext, getSmartContractV2Handler.Invoke))
+	app.Post(
+		"/api/v2/smartcontracts/:address/factories",
+		auth.Middleware,
+		api.HandleFunc(apiContext, postFactoryTemplateHandler.Invoke),
+	)
+	app.Get(
+		"/api/v2/smartcontracts/:address/factories",
+		auth.Middleware,
+		api.HandleFunc(apiContext, getFactoryTemplatesHandler.Invoke),
+	)
+	app.Post(
+		"/api/v2/smartcontracts/:address/events",
+		auth.Middleware,
+		api.HandleFunc(apiContext, postSmartContractEventsHandler.Invoke),
+	)
+	app.Get(
+		"/api/v2/smartcontracts/:address/logs",
+		auth.Middleware,
+		api.HandleFunc(apiContext, getRawLogsHandler.Invoke),
+	)
+	app.Post(
+		"/api/v2/smartcontracts/:address/backfills",
+		auth.Middleware,
+		api.HandleFunc(apiContext, postBackfillHandler.Invoke),
+	)
+	app.Get(
+		"/api/v2/smartcontracts/:address/backfills",
+		auth.Middleware,
+		api.HandleFunc(apiContext, getBackfillsHandler.Invoke),
+	)
+	app.Get(
+		"/api/v2/smartcontracts/:address/backfills/:id",
+		auth.Middleware,
+		api.HandleFunc(apiContext, getBackfillsHandler.Invoke),
+	)
+	app.Get(
+		"/api/v2/smartcontracts/:address/coverage",
+		auth.Middleware,
+		api.HandleFunc(apiContext, getCoverageHandler.Invoke),
+	)
 }
@@ -0,0 +1,91 @@
+package subscriptions
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/pagination"
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type listSignatureEventDataHandler struct{}
+
+type listSignatureEventDataHandlerRequest struct {
+	UserID     string
+	ID         string
+	Address    string
+	Pagination *pagination.Pagination
+}
+
+type listSignatureEventDataHandlerResponse struct {
+	Datas        []*SignatureEventDataResponse  `json:"datas"`
+	Subscription *SignatureSubscriptionResponse `json:"subscription,omitempty"`
+	Pagination   *pagination.PaginationMeta     `json:"pagination,omitempty"`
+}
+
+func (h *listSignatureEventDataHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &listSignatureEventDataHandlerRequest{}
+
+	// get pagination
+	p := &pagination.Pagination{}
+	err := p.GetPaginationFromFiber(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"subscriptions: listSignatureEventDataHandler.Invoke p.GetPaginationFromFiber error",
+		)
+	}
+	req.Pagination = p
+
+	// get user id
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"subscriptions: listSignatureEventDataHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get id from params
+	req.ID = c.Params("id")
+	if req.ID == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"subscriptions: listSignatureEventDataHandler.Invoke invalid id param error",
+		)
+	}
+
+	// get the emitter of the logs, the logs of every address are returned when it isn't defined
+	req.Address = c.Query("address")
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *listSignatureEventDataHandler) invoke(ctx *api.Context, req *listSignatureEventDataHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.SelectSignatureEventData(&sync.SelectSignatureEventDataInput{
+		UserID:         req.UserID,
+		SubscriptionID: req.ID,
+		Address:        req.Address,
+		Pagination:     req.Pagination,
+	})
+	if err != nil {
+		return nil, getSignatureSubscriptionErrorStatus(err), errors.Wrap(
+			err,
+			"subscriptions: listSignatureEventDataHandler.invoke ctx.SyncEngine.SelectSignatureEventData error",
+		)
+	}
+
+	res := &listSignatureEventDataHandlerResponse{
+		Datas:        make([]*SignatureEventDataResponse, 0),
+		Subscription: toSignatureSubscriptionResponse(output.SignatureSubscription),
+	}
+	for _, sd := range output.EventData {
+		res.Datas = append(res.Datas, toSignatureEventDataResponse(sd))
+	}
+
+	pagination := req.Pagination.GetPaginationMeta(output.TotalElements)
+	res.Pagination = &pagination
+
+	return res, fiber.StatusOK, nil
+}
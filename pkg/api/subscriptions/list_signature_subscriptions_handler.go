@@ -0,0 +1,57 @@
+package subscriptions
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type listSignatureSubscriptionsHandler struct{}
+
+type listSignatureSubscriptionsHandlerRequest struct {
+	UserID string
+}
+
+type listSignatureSubscriptionsHandlerResponse struct {
+	Subscriptions []*SignatureSubscriptionResponse `json:"subscriptions"`
+}
+
+func (h *listSignatureSubscriptionsHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &listSignatureSubscriptionsHandlerRequest{}
+
+	// get user id
+	var err error
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"subscriptions: listSignatureSubscriptionsHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *listSignatureSubscriptionsHandler) invoke(ctx *api.Context, req *listSignatureSubscriptionsHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.SelectSignatureSubscriptions(&sync.SelectSignatureSubscriptionsInput{
+		UserID: req.UserID,
+	})
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"subscriptions: listSignatureSubscriptionsHandler.invoke ctx.SyncEngine.SelectSignatureSubscriptions error",
+		)
+	}
+
+	res := &listSignatureSubscriptionsHandlerResponse{
+		Subscriptions: make([]*SignatureSubscriptionResponse, 0),
+	}
+	for _, s := range output.SignatureSubscriptions {
+		res.Subscriptions = append(res.Subscriptions, toSignatureSubscriptionResponse(s))
+	}
+
+	return res, fiber.StatusOK, nil
+}
@@ -0,0 +1,112 @@
+package subscriptions
+
+import (
+	"context"
+
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/darchlabs/synchronizer-v2/pkg/util"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type postSignatureSubscriptionHandler struct {
+	validate *validator.Validate
+}
+
+type postSignatureSubscriptionHandlerRequest struct {
+	UserID       string
+	Subscription *SignatureSubscriptionRequest
+}
+
+func (h *postSignatureSubscriptionHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &postSignatureSubscriptionHandlerRequest{
+		Subscription: &SignatureSubscriptionRequest{},
+	}
+
+	err := c.BodyParser(req.Subscription)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"subscriptions: postSignatureSubscriptionHandler.Invoke c.BodyParser error",
+		)
+	}
+
+	err = h.validate.Struct(req.Subscription)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"subscriptions: postSignatureSubscriptionHandler.Invoke h.validate.Struct error",
+		)
+	}
+
+	// get user id
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"subscriptions: postSignatureSubscriptionHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *postSignatureSubscriptionHandler) invoke(ctx *api.Context, req *postSignatureSubscriptionHandlerRequest) (interface{}, int, error) {
+	// use the nodes of the network pool when the given node url isn't valid
+	nodeURL := req.Subscription.NodeURL
+	network := req.Subscription.Network
+	err := util.NodeURLIsValid(ctx.RPCPool, nodeURL, network)
+	if err != nil {
+		nodeURL = ""
+	}
+
+	client, nodeURL, err := ctx.RPCPool.Client(network, nodeURL)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"subscriptions: postSignatureSubscriptionHandler.invoke ctx.RPCPool.Client error",
+		)
+	}
+	defer ctx.RPCPool.Release(client)
+
+	// every address of the network syncs from the latest block unless the block is defined
+	fromBlockNumber := int64(0)
+	if req.Subscription.FromBlockNumber != nil {
+		fromBlockNumber = *req.Subscription.FromBlockNumber
+	} else if req.Subscription.AllAddresses {
+		blockNumber, err := client.BlockNumber(context.Background())
+		if err != nil {
+			return nil, fiber.StatusInternalServerError, errors.Wrap(
+				err,
+				"subscriptions: postSignatureSubscriptionHandler.invoke client.BlockNumber error",
+			)
+		}
+		fromBlockNumber = int64(blockNumber)
+	}
+
+	output, err := ctx.SyncEngine.InsertSignatureSubscription(&sync.InsertSignatureSubscriptionInput{
+		UserID:          req.UserID,
+		Name:            req.Subscription.Name,
+		Network:         network,
+		NodeURL:         nodeURL,
+		ABI:             req.Subscription.ABI,
+		EventName:       req.Subscription.EventName,
+		Addresses:       req.Subscription.Addresses,
+		AllAddresses:    req.Subscription.AllAddresses,
+		Filters:         req.Subscription.Filters,
+		FromBlockNumber: fromBlockNumber,
+		CreatedAt:       ctx.DateGen(),
+	})
+	if err != nil {
+		return nil, getSignatureSubscriptionErrorStatus(err), errors.Wrap(
+			err,
+			"subscriptions: postSignatureSubscriptionHandler.invoke ctx.SyncEngine.InsertSignatureSubscription error",
+		)
+	}
+
+	return toSignatureSubscriptionResponse(output.SignatureSubscription), fiber.StatusCreated, nil
+}
@@ -0,0 +1,79 @@
+package subscriptions
+
+import (
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type putSignatureSubscriptionAddressesHandler struct {
+	validate *validator.Validate
+}
+
+type putSignatureSubscriptionAddressesHandlerRequest struct {
+	UserID    string
+	ID        string
+	Addresses *SignatureSubscriptionAddressesRequest
+}
+
+func (h *putSignatureSubscriptionAddressesHandler) Invoke(ctx *api.Context, c *fiber.Ctx) (interface{}, int, error) {
+	// define request
+	req := &putSignatureSubscriptionAddressesHandlerRequest{
+		Addresses: &SignatureSubscriptionAddressesRequest{},
+	}
+
+	err := c.BodyParser(req.Addresses)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"subscriptions: putSignatureSubscriptionAddressesHandler.Invoke c.BodyParser error",
+		)
+	}
+
+	err = h.validate.Struct(req.Addresses)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.Wrap(
+			err,
+			"subscriptions: putSignatureSubscriptionAddressesHandler.Invoke h.validate.Struct error",
+		)
+	}
+
+	// get user id
+	req.UserID, err = api.GetUserIDFromRequestCtx(c)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, errors.Wrap(
+			err,
+			"subscriptions: putSignatureSubscriptionAddressesHandler.Invoke c.api.GetUserIDFromRequestCtx error",
+		)
+	}
+
+	// get id from params
+	req.ID = c.Params("id")
+	if req.ID == "" {
+		return nil, fiber.StatusBadRequest, errors.New(
+			"subscriptions: putSignatureSubscriptionAddressesHandler.Invoke invalid id param error",
+		)
+	}
+
+	return h.invoke(ctx, req)
+}
+
+// BUSINESS LOGIC
+func (h *putSignatureSubscriptionAddressesHandler) invoke(ctx *api.Context, req *putSignatureSubscriptionAddressesHandlerRequest) (interface{}, int, error) {
+	output, err := ctx.SyncEngine.UpdateSignatureSubscriptionAddresses(&sync.UpdateSignatureSubscriptionAddressesInput{
+		UserID:    req.UserID,
+		ID:        req.ID,
+		Addresses: req.Addresses.Addresses,
+		UpdatedAt: ctx.DateGen(),
+	})
+	if err != nil {
+		return nil, getSignatureSubscriptionErrorStatus(err), errors.Wrap(
+			err,
+			"subscriptions: putSignatureSubscriptionAddressesHandler.invoke ctx.SyncEngine.UpdateSignatureSubscriptionAddresses error",
+		)
+	}
+
+	return toSignatureSubscriptionResponse(output.SignatureSubscription), fiber.StatusOK, nil
+}
@@ -0,0 +1,128 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/darchlabs/synchronizer-v2/internal/storage"
+	"github.com/darchlabs/synchronizer-v2/internal/sync"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+type SignatureSubscriptionRequest struct {
+	Name    string `json:"name" validate:"required"`
+	Network string `json:"network" validate:"required"`
+	NodeURL string `json:"nodeUrl"`
+	// EventName identifies the event of the abi by name, canonical signature or topic0
+	EventName string          `json:"eventName" validate:"required"`
+	ABI       json.RawMessage `json:"abi" validate:"required"`
+	// Addresses are the emitters of the logs, every address of the network is subscribed when
+	// AllAddresses is true
+	Addresses    []string `json:"addresses"`
+	AllAddresses bool     `json:"allAddresses"`
+	// Filters are the allowed values of the indexed arguments of the event by argument name
+	Filters map[string][]string `json:"filters"`
+	// FromBlockNumber is the first block synced, the address sets sync from the genesis and
+	// every address from the latest block when it isn't defined
+	FromBlockNumber *int64 `json:"fromBlockNumber"`
+}
+
+type SignatureSubscriptionAddressesRequest struct {
+	Addresses []string `json:"addresses" validate:"required"`
+}
+
+type SignatureSubscriptionResponse struct {
+	ID                string              `json:"id"`
+	Name              string              `json:"name"`
+	Network           string              `json:"network"`
+	NodeURL           string              `json:"nodeUrl"`
+	Signature         string              `json:"signature"`
+	Topic0            string              `json:"topic0"`
+	ABI               json.RawMessage     `json:"abi"`
+	Addresses         []string            `json:"addresses"`
+	AllAddresses      bool                `json:"allAddresses"`
+	Filters           map[string][]string `json:"filters,omitempty"`
+	FromBlockNumber   int64               `json:"fromBlockNumber"`
+	LatestBlockNumber int64               `json:"latestBlockNumber"`
+	// BackfillAddresses are the added addresses whose logs before the checkpoint are being ingested
+	BackfillAddresses         []string            `json:"backfillAddresses"`
+	BackfillLatestBlockNumber int64               `json:"backfillLatestBlockNumber"`
+	Status                    storage.EventStatus `json:"status"`
+	Error                     string              `json:"error"`
+	CreatedAt                 time.Time           `json:"createdAt"`
+	UpdatedAt                 *time.Time          `json:"updatedAt,omitempty"`
+}
+
+func toSignatureSubscriptionResponse(s *storage.SignatureSubscriptionRecord) *SignatureSubscriptionResponse {
+	res := &SignatureSubscriptionResponse{
+		ID:                        s.ID,
+		Name:                      s.Name,
+		Network:                   string(s.Network),
+		NodeURL:                   s.NodeURL,
+		Signature:                 s.Signature,
+		Topic0:                    s.Topic0,
+		ABI:                       s.ABI,
+		Addresses:                 s.Addresses,
+		AllAddresses:              s.AllAddresses,
+		FromBlockNumber:           s.FromBlockNumber,
+		LatestBlockNumber:         s.LatestBlockNumber,
+		BackfillAddresses:         s.BackfillAddresses,
+		BackfillLatestBlockNumber: s.BackfillLatestBlockNumber,
+		Status:                    s.Status,
+		Error:                     s.Error,
+		CreatedAt:                 s.CreatedAt,
+		UpdatedAt:                 s.UpdatedAt,
+	}
+	if f, ok := s.TopicFilters[s.Signature]; ok && f != nil {
+		res.Filters = f.Args
+	}
+	if res.Addresses == nil {
+		res.Addresses = make([]string, 0)
+	}
+	if res.BackfillAddresses == nil {
+		res.BackfillAddresses = make([]string, 0)
+	}
+
+	return res
+}
+
+type SignatureEventDataResponse struct {
+	ID             string          `json:"id"`
+	Address        string          `json:"address"`
+	Tx             string          `json:"tx"`
+	TxIndex        int64           `json:"txIndex"`
+	LogIndex       int64           `json:"logIndex"`
+	BlockNumber    int64           `json:"blockNumber"`
+	BlockHash      string          `json:"blockHash"`
+	BlockTimestamp *time.Time      `json:"blockTimestamp,omitempty"`
+	Data           json.RawMessage `json:"data"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+func toSignatureEventDataResponse(sd *storage.SignatureEventDataRecord) *SignatureEventDataResponse {
+	return &SignatureEventDataResponse{
+		ID:             sd.ID,
+		Address:        sd.Address,
+		Tx:             sd.Tx,
+		TxIndex:        sd.TxIndex,
+		LogIndex:       sd.LogIndex,
+		BlockNumber:    sd.BlockNumber,
+		BlockHash:      sd.BlockHash,
+		BlockTimestamp: sd.BlockTimestamp,
+		Data:           sd.Data,
+		CreatedAt:      sd.CreatedAt,
+	}
+}
+
+// getSignatureSubscriptionErrorStatus returns the http status of the errors of the signature subscriptions.
+func getSignatureSubscriptionErrorStatus(err error) int {
+	switch errors.Cause(err) {
+	case sync.ErrSignatureSubscriptionNotFound:
+		return fiber.StatusNotFound
+	case sync.ErrInvalidSignatureSubscription, sync.ErrEventNameAmbiguous, sync.ErrInvalidTopicFilter:
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
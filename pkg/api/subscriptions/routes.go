@@ -0,0 +1,33 @@
+package subscriptions
+
+import (
+	"net/http"
+
+	"github.com/darchlabs/backoffice/pkg/client"
+	"github.com/darchlabs/backoffice/pkg/middleware"
+	"github.com/darchlabs/synchronizer-v2/pkg/api"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+func Route(app *fiber.App, apiContext *api.Context) {
+	cl := client.New(&client.Config{
+		Client:  http.DefaultClient,
+		BaseURL: apiContext.Env.BackofficeApiURL,
+	})
+	auth := middleware.NewAuth(cl)
+	validate := validator.New()
+
+	// V2 ROUTES
+	// handlers
+	postSignatureSubscriptionHandler := &postSignatureSubscriptionHandler{validate}
+	listSignatureSubscriptionsHandler := &listSignatureSubscriptionsHandler{}
+	putSignatureSubscriptionAddressesHandler := &putSignatureSubscriptionAddressesHandler{validate}
+	listSignatureEventDataHandler := &listSignatureEventDataHandler{}
+
+	// routing
+	app.Post("/api/v2/subscriptions", auth.Middleware, api.HandleFunc(apiContext, postSignatureSubscriptionHandler.Invoke))
+	app.Get("/api/v2/subscriptions", auth.Middleware, api.HandleFunc(apiContext, listSignatureSubscriptionsHandler.Invoke))
+	app.Put("/api/v2/subscriptions/:id/addresses", auth.Middleware, api.HandleFunc(apiContext, putSignatureSubscriptionAddressesHandler.Invoke))
+	app.Get("/api/v2/subscriptions/:id/data", auth.Middleware, api.HandleFunc(apiContext, listSignatureEventDataHandler.Invoke))
+}
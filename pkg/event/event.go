@@ -6,6 +6,7 @@ import (
 
 	"github.com/darchlabs/synchronizer-v2/internal/blockchain"
 	"github.com/darchlabs/synchronizer-v2/pkg/webhook"
+	"github.com/lib/pq"
 )
 
 type EventNetwork string
@@ -61,12 +62,17 @@ type Input struct {
 }
 
 type EventData struct {
-	ID          string          `json:"id" db:"id"`
-	EventID     string          `json:"eventId" db:"event_id"`
-	Tx          string          `json:"tx" db:"tx"`
-	BlockNumber int64           `json:"blockNumber" db:"block_number"`
-	Data        json.RawMessage `json:"data" db:"data"`
-	CreatedAt   time.Time       `json:"createdAt" db:"created_at"`
+	ID             string          `json:"id" db:"id"`
+	EventID        string          `json:"eventId" db:"event_id"`
+	Tx             string          `json:"tx" db:"tx"`
+	BlockNumber    int64           `json:"blockNumber" db:"block_number"`
+	BlockHash      string          `json:"blockHash" db:"block_hash"`
+	BlockTimestamp *time.Time      `json:"blockTimestamp" db:"block_timestamp"`
+	LogIndex       int64           `json:"logIndex" db:"log_index"`
+	TxIndex        int64           `json:"txIndex" db:"tx_index"`
+	Data           json.RawMessage `json:"data" db:"data"`
+	Topics         pq.StringArray  `json:"topics" db:"topics"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
 }
 
 func (ed *EventData) FromLogData(logData blockchain.LogData, id string, eventID string, createdAt time.Time) error {
@@ -83,9 +89,22 @@ func (ed *EventData) FromLogData(logData blockchain.LogData, id string, eventID
 	ed.EventID = eventID
 	ed.Tx = tx
 	ed.BlockNumber = int64(logData.BlockNumber)
+	ed.BlockHash = logData.BlockHash.Hex()
+	ed.LogIndex = int64(logData.LogIndex)
+	ed.TxIndex = int64(logData.TxIndex)
 	ed.Data = data
 	ed.CreatedAt = createdAt
 
+	ed.Topics = make(pq.StringArray, 0)
+	for _, topic := range logData.Topics {
+		ed.Topics = append(ed.Topics, topic.Hex())
+	}
+
+	if logData.BlockTimestamp > 0 {
+		blockTimestamp := time.Unix(int64(logData.BlockTimestamp), 0).UTC()
+		ed.BlockTimestamp = &blockTimestamp
+	}
+
 	return nil
 }
 
@@ -1,13 +1,16 @@
 package util
 
-import (
-	"context"
-	"fmt"
+import "fmt"
 
-	"github.com/ethereum/go-ethereum/ethclient"
-)
+// ChainIDChecker verifies the chain id of a node is the one of the network, the rpc pool
+// implements it.
+type ChainIDChecker interface {
+	Verify(network string, nodeURL string) error
+}
 
-func NodeURLIsValid(nodeURL string, network string) error {
+// NodeURLIsValid checks the node url belongs to the given network. The node isn't added to the
+// shared nodes of the pool, it's only used by the requests preferring it.
+func NodeURLIsValid(checker ChainIDChecker, nodeURL string, network string) error {
 	if network == "" {
 		return fmt.Errorf("\nthe network was not provided for checking the node url")
 	}
@@ -21,21 +24,8 @@ func NodeURLIsValid(nodeURL string, network string) error {
 		return fmt.Errorf("\nthe network %s is not currently supported by darchlabs", network)
 	}
 
-	client, err := ethclient.Dial(nodeURL)
-	if err != nil {
-		return err
-	}
-
-	clientNetworkId, err := client.NetworkID(context.Background())
-	if err != nil {
-		return err
-	}
-
-	if clientNetworkId.Int64() != networkChainId {
-		return fmt.Errorf("/nthe node url network %v chain id doesn't much the given network %d chain id", clientNetworkId, clientNetworkId)
-	}
-
-	return nil
+	// the checker compares the chain id of the node with the one of the network
+	return checker.Verify(network, nodeURL)
 }
 
 var SupportedNetworks = map[string]int64{
@@ -12,6 +12,8 @@ const (
 	StatusPending   WebhookStatus = "pending"
 	StatusFailed    WebhookStatus = "failed"
 	StatusDelivered WebhookStatus = "delivered"
+	// StatusSending is the webhook claimed by a sender, no other sender sends it meanwhile
+	StatusSending WebhookStatus = "sending"
 )
 
 type WebhookEntityType string
@@ -49,11 +51,16 @@ func (w *Webhook) ToWebhookEventResponse() *WebhookResponse {
 }
 
 type WebhookEventPayload struct {
-	Id          string          `json:"id"`
-	Name        string          `json:"name"`
-	BlockNumber int64           `json:"block_number"`
-	Tx          string          `json:"tx"`
-	Data        json.RawMessage `json:"data"`
+	Id             string          `json:"id"`
+	Name           string          `json:"name"`
+	BlockNumber    int64           `json:"block_number"`
+	BlockHash      string          `json:"block_hash,omitempty"`
+	BlockTimestamp *time.Time      `json:"block_timestamp,omitempty"`
+	Tx             string          `json:"tx"`
+	TxIndex        int64           `json:"tx_index"`
+	LogIndex       int64           `json:"log_index"`
+	Data           json.RawMessage `json:"data"`
+	Removed        bool            `json:"removed"`
 }
 
 type WebhookResponse struct {
@@ -1,6 +1,9 @@
 package synchronizer
 
 import (
+	"context"
+	"time"
+
 	"github.com/darchlabs/synchronizer-v2/internal/storage"
 	"github.com/darchlabs/synchronizer-v2/pkg/event"
 	"github.com/darchlabs/synchronizer-v2/pkg/smartcontract"
@@ -26,13 +29,11 @@ type EventStorage interface {
 }
 
 type Cronjob interface {
-	Stop() error
-	Restart() error
-	Start() error
-	Halt()
+	Run(ctx context.Context) error
 	GetStatus() string
 	GetSeconds() int64
 	GetError() string
+	StopUnowned()
 }
 
 type SmartContractStorage interface {
@@ -74,7 +75,9 @@ type WebhookStorage interface {
 	GetWebhookByID(id string) (*webhook.Webhook, error)
 	ListAllWebhooks() ([]*webhook.Webhook, error)
 	ListWebhooks(smartcontractID string) ([]*webhook.Webhook, error)
-	GetWebhooksForRetry() ([]*webhook.Webhook, error)
+	GetQueuedWebhooks() ([]*webhook.Webhook, error)
+	ClaimWebhook(id string, now time.Time) (bool, error)
+	ClaimWebhooksForRetry(now time.Time, staleBefore time.Time) ([]*webhook.Webhook, error)
 }
 
 type SmartcontractUserStorage interface {